@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountDeletionDTO struct {
+	Password string `json:"password"`
+}
+
+type AccountController struct {
+	accountUsecase domain.IAccountUsecase
+}
+
+func NewAccountController(au domain.IAccountUsecase) *AccountController {
+	return &AccountController{
+		accountUsecase: au,
+	}
+}
+
+func (ac *AccountController) RequestExport(ctx *gin.Context) {
+	if err := ac.accountUsecase.RequestExport(ctx.GetString("user_id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "your export is being prepared, a download link will be emailed to you"})
+}
+
+func (ac *AccountController) DownloadExport(ctx *gin.Context) {
+	archive, err := ac.accountUsecase.DownloadExport(ctx.Param("token"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Content-Disposition", `attachment; filename="personal-data.zip"`)
+	ctx.Data(http.StatusOK, "application/zip", archive)
+}
+
+func (ac *AccountController) RequestDeletion(ctx *gin.Context) {
+	var body AccountDeletionDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Password == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "password is required to delete the account"})
+		return
+	}
+
+	deletion, err := ac.accountUsecase.RequestDeletion(ctx.GetString("user_id"), body.Password)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"scheduled_for": deletion.ScheduledFor,
+		"message":       "your account will be deleted after the grace period, all sessions have been signed out",
+	})
+}
+
+func (ac *AccountController) CancelDeletion(ctx *gin.Context) {
+	if err := ac.accountUsecase.CancelDeletion(ctx.GetString("user_id")); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "account deletion cancelled"})
+}
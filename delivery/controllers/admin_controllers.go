@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type SuspendUserDTO struct {
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"` // omit to ban
+}
+
+type ImpersonateUserDTO struct {
+	Reason string `json:"reason"`
+}
+
+type AdminController struct {
+	adminUsecase domain.IAdminUsecase
+}
+
+func NewAdminController(au domain.IAdminUsecase) *AdminController {
+	return &AdminController{
+		adminUsecase: au,
+	}
+}
+
+// ListUsers accepts q, role, status, email_status, created_from and
+// created_to (RFC 3339), page and limit query parameters.
+func (ac *AdminController) ListUsers(ctx *gin.Context) {
+	filter := domain.UserFilter{
+		Query:       ctx.Query("q"),
+		Role:        ctx.Query("role"),
+		Status:      ctx.Query("status"),
+		EmailStatus: ctx.Query("email_status"),
+		Page:        1,
+	}
+	var err error
+	if value := ctx.Query("page"); value != "" {
+		if filter.Page, err = strconv.Atoi(value); err != nil || filter.Page < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+			return
+		}
+	}
+	if value := ctx.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	for param, target := range map[string]**time.Time{"created_from": &filter.CreatedFrom, "created_to": &filter.CreatedTo} {
+		if value := ctx.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+				return
+			}
+			*target = &t
+		}
+	}
+
+	page, err := ac.adminUsecase.ListUsers(filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (ac *AdminController) Sessions(ctx *gin.Context) {
+	sessions, err := ac.adminUsecase.Sessions(ctx.Param("id"))
+	if err != nil {
+		adminError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+func (ac *AdminController) Activity(ctx *gin.Context) {
+	activity, err := ac.adminUsecase.Activity(ctx.Param("id"))
+	if err != nil {
+		adminError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, activity)
+}
+
+func (ac *AdminController) Suspend(ctx *gin.Context) {
+	var body SuspendUserDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	err := ac.adminUsecase.Suspend(actor(ctx), ctx.Param("id"), domain.Suspension{Reason: body.Reason, Until: body.ExpiresAt})
+	if err != nil {
+		adminError(ctx, err)
+		return
+	}
+	if body.ExpiresAt == nil {
+		ctx.JSON(http.StatusOK, gin.H{"message": "user banned"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "user suspended"})
+}
+
+func (ac *AdminController) Reinstate(ctx *gin.Context) {
+	if err := ac.adminUsecase.Reinstate(actor(ctx), ctx.Param("id")); err != nil {
+		adminError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "user reinstated"})
+}
+
+func (ac *AdminController) ForcePasswordReset(ctx *gin.Context) {
+	if err := ac.adminUsecase.ForcePasswordReset(actor(ctx), ctx.Param("id")); err != nil {
+		adminError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "password reset, the user has been sent a reset link"})
+}
+
+func (ac *AdminController) Impersonate(ctx *gin.Context) {
+	var body ImpersonateUserDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	token, err := ac.adminUsecase.Impersonate(actor(ctx), ctx.Param("id"), body.Reason)
+	if err != nil {
+		adminError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"access":  token,
+		"message": "impersonation session started, it cannot be refreshed",
+	})
+}
+
+func (ac *AdminController) HardDelete(ctx *gin.Context) {
+	if err := ac.adminUsecase.HardDelete(actor(ctx), ctx.Param("id")); err != nil {
+		adminError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "user deleted"})
+}
+
+func (ac *AdminController) ClearEmailStatus(ctx *gin.Context) {
+	if err := ac.adminUsecase.ClearEmailStatus(actor(ctx), ctx.Param("id")); err != nil {
+		adminError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "email address will be mailed again"})
+}
+
+func actor(ctx *gin.Context) domain.Principal {
+	return domain.Principal{UserID: ctx.GetString("user_id"), Role: ctx.GetString("role"), Client: clientInfo(ctx)}
+}
+
+// clientInfo is what the audit log records about where a request came from.
+func clientInfo(ctx *gin.Context) domain.ClientInfo {
+	return domain.ClientInfo{IP: ctx.ClientIP(), UserAgent: ctx.Request.UserAgent()}
+}
+
+func adminError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrResourceNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, domain.ErrRoleNotAllowed):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type DraftPromptDTO struct {
+	Prompt string `json:"prompt"`
+}
+
+type ExpandOutlineDTO struct {
+	Outline string `json:"outline"`
+}
+
+type ImproveParagraphDTO struct {
+	Paragraph string `json:"paragraph"`
+	Goal      string `json:"goal"` // optional, e.g. "shorter"
+}
+
+type AIController struct {
+	aiUsecase domain.IAIUsecase
+}
+
+func NewAIController(au domain.IAIUsecase) *AIController {
+	return &AIController{
+		aiUsecase: au,
+	}
+}
+
+func (ac *AIController) GenerateDraft(ctx *gin.Context) {
+	var body DraftPromptDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	draft, err := ac.aiUsecase.GenerateDraft(ctx.Request.Context(), ctx.GetString("user_id"), body.Prompt)
+	if err != nil {
+		aiError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, draft)
+}
+
+func (ac *AIController) ExpandOutline(ctx *gin.Context) {
+	var body ExpandOutlineDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	draft, err := ac.aiUsecase.ExpandOutline(ctx.Request.Context(), ctx.GetString("user_id"), body.Outline)
+	if err != nil {
+		aiError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, draft)
+}
+
+func (ac *AIController) ImproveParagraph(ctx *gin.Context) {
+	blogID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": domain.ErrResourceNotFound.Error()})
+		return
+	}
+	var body ImproveParagraphDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	text, err := ac.aiUsecase.ImproveParagraph(ctx.Request.Context(), ctx.GetString("user_id"), blogID, body.Paragraph, body.Goal)
+	if err != nil {
+		aiError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"paragraph": text})
+}
+
+func aiError(ctx *gin.Context, err error) {
+	var limitedErr *domain.RateLimitedError
+	switch {
+	case errors.As(err, &limitedErr):
+		ctx.Header("Retry-After", strconv.Itoa(int(limitedErr.RetryAfter.Seconds()+0.5)))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": limitedErr.Error()})
+	case errors.Is(err, domain.ErrAIUnavailable):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrAIProviderFailed):
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrResourceNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
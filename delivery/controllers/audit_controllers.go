@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditUsecase domain.IAuditUsecase
+}
+
+func NewAuditController(au domain.IAuditUsecase) *AuditController {
+	return &AuditController{
+		auditUsecase: au,
+	}
+}
+
+// Search accepts actor_id, target_id, action, outcome, from and to (RFC 3339),
+// page and limit query parameters.
+func (ac *AuditController) Search(ctx *gin.Context) {
+	filter := domain.AuditFilter{
+		Action:  ctx.Query("action"),
+		Outcome: ctx.Query("outcome"),
+		Page:    1,
+	}
+	ints := map[string]*int64{"actor_id": &filter.ActorID, "target_id": &filter.TargetID}
+	for param, target := range ints {
+		if value := ctx.Query(param); value != "" {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 1 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+				return
+			}
+			*target = n
+		}
+	}
+	var err error
+	if value := ctx.Query("page"); value != "" {
+		if filter.Page, err = strconv.Atoi(value); err != nil || filter.Page < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+			return
+		}
+	}
+	if value := ctx.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := ctx.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+				return
+			}
+			*target = &t
+		}
+	}
+
+	page, err := ac.auditUsecase.Search(filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (ac *AuditController) Verify(ctx *gin.Context) {
+	result, err := ac.auditUsecase.Verify()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const maxBounceReportSize = 1 << 20
+
+type BounceController struct {
+	bounceUsecase domain.IBounceUsecase
+	secret        string
+}
+
+// NewBounceController accepts reports carrying secret. Without a secret the
+// endpoint answers 404, so bounces cannot be forged on a fresh install.
+func NewBounceController(bu domain.IBounceUsecase, secret string) *BounceController {
+	return &BounceController{
+		bounceUsecase: bu,
+		secret:        secret,
+	}
+}
+
+// Ingest takes a report from the source named in the path: a raw DSN or
+// abuse report from the mail server, or a provider webhook. The secret goes
+// in the X-Webhook-Token header, or the token query parameter for providers
+// that only let you configure a URL.
+func (bc *BounceController) Ingest(ctx *gin.Context) {
+	if bc.secret == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	token := ctx.GetHeader("X-Webhook-Token")
+	if token == "" {
+		token = ctx.Query("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(bc.secret)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook token"})
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBounceReportSize))
+	if err != nil {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "report too large"})
+		return
+	}
+
+	report, err := bc.bounceUsecase.Ingest(ctx.Param("source"), payload)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownBounceSource) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
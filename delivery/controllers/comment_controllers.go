@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	commentUsecase domain.ICommentUsecase
+}
+
+func NewCommentController(cu domain.ICommentUsecase) *CommentController {
+	return &CommentController{
+		commentUsecase: cu,
+	}
+}
+
+type CommentDTO struct {
+	Content string `json:"content" binding:"required"`
+}
+
+func (cc *CommentController) Create(ctx *gin.Context) {
+	var body CommentDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	comment, err := cc.commentUsecase.Create(ctx.GetString("user_id"), ctx.Param("id"), body.Content)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"id": comment.ID, "blog_id": comment.BlogID, "content": comment.Content, "created_at": comment.CreatedAt})
+}
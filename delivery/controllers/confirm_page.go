@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>{{.Label}}</title></head>
+<body>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Label}}</button>
+</form>
+</body>
+</html>
+`))
+
+type confirmPageData struct {
+	Action string
+	Token  string
+	Label  string
+}
+
+// renderConfirmPage answers the GET that an emailed link makes with a button
+// posting the token to action. Link scanners only ever send the GET.
+func renderConfirmPage(ctx *gin.Context, action string, label string) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Status(http.StatusOK)
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	_ = confirmPage.Execute(ctx.Writer, confirmPageData{Action: action, Token: ctx.Query("token"), Label: label})
+}
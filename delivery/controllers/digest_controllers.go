@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type DigestSubscriptionDTO struct {
+	Tags []string `json:"tags"`
+}
+
+type NewsletterSubscribeDTO struct {
+	Email string   `json:"email" binding:"required"`
+	Tags  []string `json:"tags"`
+}
+
+type SubscriptionTokenDTO struct {
+	Token string `json:"token" form:"token"`
+}
+
+type DigestController struct {
+	digestUsecase domain.IDigestUsecase
+}
+
+func NewDigestController(du domain.IDigestUsecase) *DigestController {
+	return &DigestController{
+		digestUsecase: du,
+	}
+}
+
+func (dc *DigestController) Subscription(ctx *gin.Context) {
+	subscription, err := dc.digestUsecase.Subscription(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+func (dc *DigestController) Subscribe(ctx *gin.Context) {
+	var body DigestSubscriptionDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	subscription, err := dc.digestUsecase.Subscribe(ctx.GetString("user_id"), body.Tags)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+func (dc *DigestController) Unsubscribe(ctx *gin.Context) {
+	if err := dc.digestUsecase.Unsubscribe(ctx.GetString("user_id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "unsubscribed from the digest"})
+}
+
+// SubscribeEmail answers the same for new and existing subscribers, so it
+// cannot be used to find out who reads the newsletter.
+func (dc *DigestController) SubscribeEmail(ctx *gin.Context) {
+	var body NewsletterSubscribeDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := dc.digestUsecase.SubscribeEmail(body.Email, body.Tags); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "check your inbox to confirm the subscription"})
+}
+
+// ConfirmPage is where the link in the confirmation email lands.
+func (dc *DigestController) ConfirmPage(ctx *gin.Context) {
+	renderConfirmPage(ctx, "/newsletter/confirm", "Confirm subscription")
+}
+
+func (dc *DigestController) Confirm(ctx *gin.Context) {
+	var body SubscriptionTokenDTO
+	if err := ctx.ShouldBind(&body); err != nil || body.Token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := dc.digestUsecase.Confirm(body.Token); err != nil {
+		subscriptionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "subscription confirmed"})
+}
+
+// UnsubscribePage is where the unsubscribe link in a digest lands.
+func (dc *DigestController) UnsubscribePage(ctx *gin.Context) {
+	renderConfirmPage(ctx, "/newsletter/unsubscribe", "Unsubscribe")
+}
+
+// UnsubscribeByToken serves both the form on UnsubscribePage and RFC 8058
+// one-click requests, which post "List-Unsubscribe=One-Click" to the URL
+// from the List-Unsubscribe header, token in the query string.
+func (dc *DigestController) UnsubscribeByToken(ctx *gin.Context) {
+	var body SubscriptionTokenDTO
+	if err := ctx.ShouldBind(&body); err != nil || body.Token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := dc.digestUsecase.UnsubscribeByToken(body.Token); err != nil {
+		subscriptionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "unsubscribed from the digest"})
+}
+
+func subscriptionError(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrSubscriptionTokenInvalid) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailOutboxController struct {
+	emailOutboxUsecase domain.IEmailOutboxUsecase
+}
+
+func NewEmailOutboxController(eu domain.IEmailOutboxUsecase) *EmailOutboxController {
+	return &EmailOutboxController{
+		emailOutboxUsecase: eu,
+	}
+}
+
+// Search accepts status, page and limit query parameters.
+func (ec *EmailOutboxController) Search(ctx *gin.Context) {
+	filter := domain.OutboxFilter{Status: ctx.Query("status"), Page: 1}
+	var err error
+	if value := ctx.Query("page"); value != "" {
+		if filter.Page, err = strconv.Atoi(value); err != nil || filter.Page < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+			return
+		}
+	}
+	if value := ctx.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	page, err := ec.emailOutboxUsecase.Search(filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (ec *EmailOutboxController) Fetch(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid email id"})
+		return
+	}
+	email, err := ec.emailOutboxUsecase.Fetch(id)
+	if err != nil {
+		outboxError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": email})
+}
+
+func (ec *EmailOutboxController) Retry(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid email id"})
+		return
+	}
+	email, err := ec.emailOutboxUsecase.Retry(id)
+	if err != nil {
+		outboxError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": email})
+}
+
+func outboxError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrResourceNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "email not found"})
+	case errors.Is(err, domain.ErrOutboxEmailNotRetryable):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailTemplateController struct {
+	emailTemplateUsecase domain.IEmailTemplateUsecase
+}
+
+func NewEmailTemplateController(eu domain.IEmailTemplateUsecase) *EmailTemplateController {
+	return &EmailTemplateController{
+		emailTemplateUsecase: eu,
+	}
+}
+
+func (ec *EmailTemplateController) List(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"data": ec.emailTemplateUsecase.List()})
+}
+
+// Preview renders a template with sample data. The locale query parameter
+// picks a translation; format=html or format=text returns that part alone so
+// it can be opened in a browser.
+func (ec *EmailTemplateController) Preview(ctx *gin.Context) {
+	email, err := ec.emailTemplateUsecase.Preview(ctx.Param("name"), ctx.Query("locale"))
+	if errors.Is(err, domain.ErrEmailTemplateNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch ctx.Query("format") {
+	case "html":
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+	case "text":
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(email.Text))
+	default:
+		ctx.JSON(http.StatusOK, gin.H{"data": email})
+	}
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	jwtService domain.IJWTInfrastructure
+}
+
+func NewJWKSController(js domain.IJWTInfrastructure) *JWKSController {
+	return &JWKSController{
+		jwtService: js,
+	}
+}
+
+func (kc *JWKSController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, kc.jwtService.JWKS())
+}
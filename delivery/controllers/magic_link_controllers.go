@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkRequestDTO struct {
+	Email string `json:"email"`
+}
+
+type MagicLinkVerifyDTO struct {
+	Token string `json:"token" form:"token"`
+}
+
+type MagicLinkController struct {
+	magicLinkUsecase domain.IMagicLinkUsecase
+}
+
+func NewMagicLinkController(mu domain.IMagicLinkUsecase) *MagicLinkController {
+	return &MagicLinkController{
+		magicLinkUsecase: mu,
+	}
+}
+
+func (mc *MagicLinkController) Request(ctx *gin.Context) {
+	var body MagicLinkRequestDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Email == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	err := mc.magicLinkUsecase.RequestLink(body.Email)
+	var limitedErr *domain.RateLimitedError
+	if errors.As(err, &limitedErr) {
+		ctx.Header("Retry-After", strconv.Itoa(int(limitedErr.RetryAfter.Seconds()+0.5)))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": limitedErr.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "if an account exists for this address, a sign-in link is on its way"})
+}
+
+// Confirm only renders a button. GET requests, which is what link scanners
+// send, never redeem the token.
+func (mc *MagicLinkController) Confirm(ctx *gin.Context) {
+	renderConfirmPage(ctx, "/login/magic-link/verify", "Sign in")
+}
+
+func (mc *MagicLinkController) Verify(ctx *gin.Context) {
+	var body MagicLinkVerifyDTO
+	if err := ctx.ShouldBind(&body); err != nil || body.Token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	accessToken, refreshToken, err := mc.magicLinkUsecase.Verify(body.Token, clientInfo(ctx))
+	var mfaErr *domain.MFARequiredError
+	if errors.As(err, &mfaErr) {
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"mfa_required": true,
+			"challenge":    mfaErr.ChallengeToken,
+			"message":      "enter the code from your authenticator app",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"access":  accessToken,
+		"refresh": refreshToken,
+		"message": "Logged in successfully",
+	})
+}
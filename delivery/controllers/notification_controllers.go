@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationPreferenceDTO struct {
+	Type  string `json:"type" binding:"required"`
+	InApp bool   `json:"in_app"`
+	Email string `json:"email" binding:"required"`
+}
+
+type NotificationController struct {
+	notificationUsecase domain.INotificationUsecase
+}
+
+func NewNotificationController(nu domain.INotificationUsecase) *NotificationController {
+	return &NotificationController{
+		notificationUsecase: nu,
+	}
+}
+
+// List accepts page, limit and unread=true query parameters.
+func (nc *NotificationController) List(ctx *gin.Context) {
+	page, limit := 1, 0
+	var err error
+	if value := ctx.Query("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+			return
+		}
+	}
+	if value := ctx.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	unreadOnly := false
+	if value := ctx.Query("unread"); value != "" {
+		if unreadOnly, err = strconv.ParseBool(value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid unread"})
+			return
+		}
+	}
+
+	notifications, err := nc.notificationUsecase.List(ctx.GetString("user_id"), unreadOnly, page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, notifications)
+}
+
+func (nc *NotificationController) MarkRead(ctx *gin.Context) {
+	err := nc.notificationUsecase.MarkRead(ctx.GetString("user_id"), ctx.Param("id"))
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
+
+func (nc *NotificationController) MarkAllRead(ctx *gin.Context) {
+	count, err := nc.notificationUsecase.MarkAllRead(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"marked": count})
+}
+
+func (nc *NotificationController) Preferences(ctx *gin.Context) {
+	preferences, err := nc.notificationUsecase.Preferences(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": preferences})
+}
+
+// UpdatePreferences takes a list of preferences. Types left out keep their
+// current setting.
+func (nc *NotificationController) UpdatePreferences(ctx *gin.Context) {
+	var body []NotificationPreferenceDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	preferences := make([]domain.NotificationPreference, 0, len(body))
+	for _, item := range body {
+		preferences = append(preferences, domain.NotificationPreference{Type: item.Type, InApp: item.InApp, Email: item.Email})
+	}
+	updated, err := nc.notificationUsecase.UpdatePreferences(ctx.GetString("user_id"), preferences)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": updated})
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie pins a login to the browser that started it, so a callback
+// URL lured into another browser cannot log that browser in.
+const oidcStateCookie = "oidc_state"
+
+type OIDCController struct {
+	oidcUsecase domain.IOIDCUsecase
+}
+
+func NewOIDCController(ou domain.IOIDCUsecase) *OIDCController {
+	return &OIDCController{
+		oidcUsecase: ou,
+	}
+}
+
+func (oc *OIDCController) Login(ctx *gin.Context) {
+	authURL, state, err := oc.oidcUsecase.Begin(ctx.Param("provider"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Lax so the cookie rides along on the provider's top-level redirect back.
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookie, state, int((10 * time.Minute).Seconds()), "/", "", true, true)
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+func (oc *OIDCController) Callback(ctx *gin.Context) {
+	browserState, _ := ctx.Cookie(oidcStateCookie)
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookie, "", -1, "/", "", true, true)
+
+	if providerErr := ctx.Query("error"); providerErr != "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "login was cancelled or denied: " + providerErr})
+		return
+	}
+
+	accessToken, refreshToken, err := oc.oidcUsecase.Complete(ctx.Param("provider"), ctx.Query("state"), browserState, ctx.Query("code"), clientInfo(ctx))
+	var mfaErr *domain.MFARequiredError
+	if errors.As(err, &mfaErr) {
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"mfa_required": true,
+			"challenge":    mfaErr.ChallengeToken,
+			"message":      "enter the code from your authenticator app",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"access":  accessToken,
+		"refresh": refreshToken,
+		"message": "Logged in successfully",
+	})
+}
+
+func (oc *OIDCController) ListIdentities(ctx *gin.Context) {
+	identities, err := oc.oidcUsecase.ListIdentities(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+func (oc *OIDCController) Unlink(ctx *gin.Context) {
+	if err := oc.oidcUsecase.Unlink(ctx.GetString("user_id"), ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "identity unlinked"})
+}
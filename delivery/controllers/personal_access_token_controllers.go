@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalAccessTokenDTO struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type PersonalAccessTokenController struct {
+	tokenUsecase domain.IPersonalAccessTokenUsecase
+}
+
+func NewPersonalAccessTokenController(pu domain.IPersonalAccessTokenUsecase) *PersonalAccessTokenController {
+	return &PersonalAccessTokenController{
+		tokenUsecase: pu,
+	}
+}
+
+func (pc *PersonalAccessTokenController) Create(ctx *gin.Context) {
+	var body PersonalAccessTokenDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	secret, token, err := pc.tokenUsecase.Create(ctx.GetString("user_id"), body.Name, body.Scopes, body.ExpiresAt, clientInfo(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"token":                 secret,
+		"personal_access_token": token,
+		"message":               "store this token now, it will not be shown again",
+	})
+}
+
+func (pc *PersonalAccessTokenController) List(ctx *gin.Context) {
+	tokens, err := pc.tokenUsecase.List(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"personal_access_tokens": tokens})
+}
+
+func (pc *PersonalAccessTokenController) Revoke(ctx *gin.Context) {
+	if err := pc.tokenUsecase.Revoke(ctx.GetString("user_id"), ctx.Param("id"), clientInfo(ctx)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "token revoked"})
+}
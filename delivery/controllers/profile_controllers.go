@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type ProfilePrivacyDTO struct {
+	HideBio            bool `json:"hide_bio"`
+	HideProfilePicture bool `json:"hide_profile_picture"`
+	HideJoinDate       bool `json:"hide_join_date"`
+	HidePostCount      bool `json:"hide_post_count"`
+	HideFollowerCount  bool `json:"hide_follower_count"`
+	HideReactions      bool `json:"hide_reactions"`
+	HidePosts          bool `json:"hide_posts"`
+}
+
+type ProfileController struct {
+	profileUsecase domain.IProfileUsecase
+}
+
+func NewProfileController(pu domain.IProfileUsecase) *ProfileController {
+	return &ProfileController{
+		profileUsecase: pu,
+	}
+}
+
+func (pc *ProfileController) PublicProfile(ctx *gin.Context) {
+	page, limit := 1, 0
+	var err error
+	if value := ctx.Query("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+			return
+		}
+	}
+	if value := ctx.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	profile, err := pc.profileUsecase.PublicProfile(ctx.Param("username"), page, limit)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
+func (pc *ProfileController) Privacy(ctx *gin.Context) {
+	privacy, err := pc.profileUsecase.Privacy(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, privacy)
+}
+
+func (pc *ProfileController) UpdatePrivacy(ctx *gin.Context) {
+	var body ProfilePrivacyDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	privacy, err := pc.profileUsecase.UpdatePrivacy(ctx.Param("id"), domain.ProfilePrivacy{
+		HideBio:            body.HideBio,
+		HideProfilePicture: body.HideProfilePicture,
+		HideJoinDate:       body.HideJoinDate,
+		HidePostCount:      body.HidePostCount,
+		HideFollowerCount:  body.HideFollowerCount,
+		HideReactions:      body.HideReactions,
+		HidePosts:          body.HidePosts,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, privacy)
+}
+
+func (pc *ProfileController) Follow(ctx *gin.Context) {
+	err := pc.profileUsecase.Follow(ctx.GetString("user_id"), ctx.Param("username"))
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "following " + ctx.Param("username")})
+}
+
+func (pc *ProfileController) Unfollow(ctx *gin.Context) {
+	err := pc.profileUsecase.Unfollow(ctx.GetString("user_id"), ctx.Param("username"))
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "no longer following " + ctx.Param("username")})
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type ReactionController struct {
+	reactionUsecase domain.IReactionUsecase
+}
+
+func NewReactionController(ru domain.IReactionUsecase) *ReactionController {
+	return &ReactionController{
+		reactionUsecase: ru,
+	}
+}
+
+type ReactionDTO struct {
+	Kind string `json:"kind" binding:"required"`
+}
+
+func (rc *ReactionController) React(ctx *gin.Context) {
+	var body ReactionDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	err := rc.reactionUsecase.React(ctx.GetString("user_id"), ctx.Param("id"), body.Kind)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"kind": body.Kind})
+}
+
+func (rc *ReactionController) Unreact(ctx *gin.Context) {
+	err := rc.reactionUsecase.Unreact(ctx.GetString("user_id"), ctx.Param("id"))
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "reaction removed"})
+}
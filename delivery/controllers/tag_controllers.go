@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type SuggestTagsDTO struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Limit   int    `json:"limit"` // 5 when omitted, at most 10
+}
+
+type TagController struct {
+	tagUsecase domain.ITagUsecase
+}
+
+func NewTagController(tu domain.ITagUsecase) *TagController {
+	return &TagController{
+		tagUsecase: tu,
+	}
+}
+
+// Suggest proposes tags for a draft before it is created, so CreateBlog
+// gets the names already in use instead of new spellings of them.
+func (tc *TagController) Suggest(ctx *gin.Context) {
+	var body SuggestTagsDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	suggestions, err := tc.tagUsecase.SuggestTags(ctx.Request.Context(), ctx.GetString("user_id"), body.Title, body.Content, body.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"tags": suggestions})
+}
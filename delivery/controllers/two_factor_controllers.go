@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorCodeDTO struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorLoginDTO struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+type TwoFactorController struct {
+	twoFactorUsecase domain.ITwoFactorUsecase
+}
+
+func NewTwoFactorController(tu domain.ITwoFactorUsecase) *TwoFactorController {
+	return &TwoFactorController{
+		twoFactorUsecase: tu,
+	}
+}
+
+func (tc *TwoFactorController) Enroll(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	secret, uri, err := tc.twoFactorUsecase.Enroll(userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"secret": secret, "otpauth_uri": uri})
+}
+
+func (tc *TwoFactorController) Confirm(ctx *gin.Context) {
+	var body TwoFactorCodeDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	codes, err := tc.twoFactorUsecase.Confirm(ctx.GetString("user_id"), body.Code)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+		"message":        "two-factor authentication enabled, store the recovery codes somewhere safe",
+	})
+}
+
+func (tc *TwoFactorController) Disable(ctx *gin.Context) {
+	var body TwoFactorCodeDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := tc.twoFactorUsecase.Disable(ctx.GetString("user_id"), body.Code); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+func (tc *TwoFactorController) VerifyLogin(ctx *gin.Context) {
+	var body TwoFactorLoginDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Challenge == "" || body.Code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	accessToken, refreshToken, err := tc.twoFactorUsecase.VerifyLogin(body.Challenge, body.Code, clientInfo(ctx))
+	var throttledErr *domain.LoginThrottledError
+	if errors.As(err, &throttledErr) {
+		ctx.Header("Retry-After", strconv.Itoa(int(throttledErr.RetryAfter.Seconds()+0.5)))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": throttledErr.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"access":  accessToken,
+		"refresh": refreshToken,
+		"message": "Logged in successfully",
+	})
+}
+
+func (tc *TwoFactorController) Reset(ctx *gin.Context) {
+	if err := tc.twoFactorUsecase.Reset(actor(ctx), ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "two-factor authentication reset"})
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -12,6 +14,7 @@ type UserRegisterDTO struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Locale   string `json:"locale"`
 }
 
 type UserLoginDTO struct {
@@ -19,11 +22,21 @@ type UserLoginDTO struct {
 	Password   string `json:"password"`
 }
 
+type LogoutDTO struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type ResetPasswordDTO struct {
 	OldPassword string `json:"old_password"`
 	NewPassword string `json:"new_password"`
 }
 
+type PasswordCheckDTO struct {
+	Password string `json:"password"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
 type ForgotPasswordDTO struct {
 	Email string `json:"email"`
 }
@@ -32,6 +45,23 @@ type UpdatePasswordDirectDTO struct {
 	NewPassword string `json:"new_password"`
 }
 
+type UpdateProfileDTO struct {
+	Username       *string `json:"username"`
+	Email          *string `json:"email"`
+	Bio            *string `json:"bio"`
+	ProfilePicture *string `json:"profile_picture"`
+	Phone          *string `json:"phone"`
+	Locale         *string `json:"locale"`
+}
+
+type ConfirmEmailChangeDTO struct {
+	Token string `json:"token" form:"token"`
+}
+
+type AssignRoleDTO struct {
+	Role string `json:"role"`
+}
+
 type UserController struct {
 	userUsecase domain.IUserUsecase
 }
@@ -54,9 +84,13 @@ func (uc *UserController) Register(ctx *gin.Context) {
 		Email:    userInput.Email,
 		Username: userInput.Username,
 		Password: userInput.Password,
+		Locale:   userInput.Locale,
 	}
 
 	user, err := uc.userUsecase.Register(&user)
+	if passwordRejected(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -84,7 +118,26 @@ func (uc *UserController) Login(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := uc.userUsecase.Login(userInput.Identifier, userInput.Password)
+	accessToken, refreshToken, err := uc.userUsecase.Login(userInput.Identifier, userInput.Password, clientInfo(ctx))
+	var throttledErr *domain.LoginThrottledError
+	if errors.As(err, &throttledErr) {
+		ctx.Header("Retry-After", strconv.Itoa(int(throttledErr.RetryAfter.Seconds()+0.5)))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": throttledErr.Error()})
+		return
+	}
+	var mfaErr *domain.MFARequiredError
+	if errors.As(err, &mfaErr) {
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"mfa_required": true,
+			"challenge":    mfaErr.ChallengeToken,
+			"message":      "enter the code from your authenticator app",
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrAccountSuspended) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
@@ -97,6 +150,20 @@ func (uc *UserController) Login(ctx *gin.Context) {
 	})
 }
 
+func (uc *UserController) Logout(ctx *gin.Context) {
+	var body LogoutDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	authHeader := ctx.GetHeader("Authorization")
+	if err := uc.userUsecase.Logout(authHeader, body.RefreshToken, clientInfo(ctx)); err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
 func (uc *UserController) GetProfile(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 	userID, err := strconv.ParseInt(idParam, 10, 64)
@@ -117,25 +184,69 @@ func (uc *UserController) GetProfile(ctx *gin.Context) {
 }
 
 func (uc *UserController) Promote(ctx *gin.Context) {
-	id := ctx.Param("id")
-	err := uc.userUsecase.Promote(id)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !uc.changeRole(ctx, domain.RoleAdmin) {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "user promoted to admin"})
 }
 
-func (uc *UserController) Demote(ctx *gin.Context) {
+func (uc *UserController) UnlockAccount(ctx *gin.Context) {
 	id := ctx.Param("id")
-	err := uc.userUsecase.Demote(id)
-	if err != nil {
+	if err := uc.userUsecase.UnlockAccount(actor(ctx), id); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "user demoted to user"})
+	ctx.JSON(http.StatusOK, gin.H{"message": "user account unlocked"})
+}
+
+func (uc *UserController) Demote(ctx *gin.Context) {
+	if !uc.changeRole(ctx, domain.DefaultRole) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "user demoted to " + domain.DefaultRole})
+}
+
+func (uc *UserController) AssignRole(ctx *gin.Context) {
+	var body AssignRoleDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Role == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if !uc.changeRole(ctx, body.Role) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "role updated"})
+}
+
+func (uc *UserController) ListRoles(ctx *gin.Context) {
+	roles := make([]gin.H, 0, len(domain.Roles))
+	for _, role := range domain.Roles {
+		roles = append(roles, gin.H{"role": role, "permissions": domain.RolePermissions[role]})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// changeRole writes the error response itself and reports whether the
+// handler should go on.
+func (uc *UserController) changeRole(ctx *gin.Context, role string) bool {
+	err := uc.userUsecase.AssignRole(actor(ctx), ctx.Param("id"), role)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, domain.ErrLastSuperadmin):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRoleNotAllowed):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+	return false
 }
   
 func (uc *UserController) UpdateProfile(ctx *gin.Context) {
@@ -145,17 +256,54 @@ func (uc *UserController) UpdateProfile(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
 		return
 	}
-	var updates map[string]interface{}
-	if err := ctx.ShouldBindJSON(&updates); err != nil {
+	var body UpdateProfileDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
-	err = uc.userUsecase.UpdateUserProfile(userID, updates)
+
+	err = uc.userUsecase.UpdateUserProfile(userID, domain.ProfileUpdate{
+		Username:       body.Username,
+		Email:          body.Email,
+		Bio:            body.Bio,
+		ProfilePicture: body.ProfilePicture,
+		Phone:          body.Phone,
+		Locale:         body.Locale,
+	})
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message, "field": validationErr.Field})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "profile updated successfully"})
+
+	message := "profile updated successfully"
+	if body.Email != nil {
+		message = "profile updated successfully, a new email address takes effect once confirmed"
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// ConfirmEmailChangePage is where the link in the confirmation email lands.
+func (uc *UserController) ConfirmEmailChangePage(ctx *gin.Context) {
+	renderConfirmPage(ctx, "/email/confirm", "Confirm email address")
+}
+
+func (uc *UserController) ConfirmEmailChange(ctx *gin.Context) {
+	var body ConfirmEmailChangeDTO
+	if err := ctx.ShouldBind(&body); err != nil || body.Token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := uc.userUsecase.ConfirmEmailChange(body.Token); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "email address updated"})
 }
 
 func (uc *UserController) RefreshToken(ctx *gin.Context) {
@@ -180,7 +328,11 @@ func (uc *UserController) ResetPassword(ctx *gin.Context) {
 		return
 	}
 	userID, _ := userIDVal.(string)
-	if err := uc.userUsecase.ResetPassword(userID, body.OldPassword, body.NewPassword); err != nil {
+	err := uc.userUsecase.ResetPassword(userID, body.OldPassword, body.NewPassword, clientInfo(ctx))
+	if passwordRejected(ctx, err) {
+		return
+	}
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -193,7 +345,7 @@ func (uc *UserController) ForgotPassword(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
-	if err := uc.userUsecase.ForgotPassword(body.Email); err != nil {
+	if err := uc.userUsecase.ForgotPassword(body.Email, clientInfo(ctx)); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -212,9 +364,35 @@ func (uc *UserController) UpdatePasswordDirect(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "token required"})
 		return
 	}
-	if err := uc.userUsecase.UpdatePasswordDirect(userID, body.NewPassword, token); err != nil {
+	err := uc.userUsecase.UpdatePasswordDirect(userID, body.NewPassword, token, clientInfo(ctx))
+	if passwordRejected(ctx, err) {
+		return
+	}
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "password updated"})
 }
+
+// CheckPassword lets clients show password feedback before submitting it.
+func (uc *UserController) CheckPassword(ctx *gin.Context) {
+	var body PasswordCheckDTO
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	feedback := uc.userUsecase.CheckPassword(body.Password, domain.PasswordContext{Username: body.Username, Email: body.Email})
+	ctx.JSON(http.StatusOK, gin.H{"data": feedback})
+}
+
+// passwordRejected answers with the per-rule feedback when err is a password
+// policy violation.
+func passwordRejected(ctx *gin.Context, err error) bool {
+	var policyErr *domain.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": policyErr.Error(), "password": policyErr.Feedback})
+	return true
+}
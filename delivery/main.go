@@ -16,7 +16,10 @@ func main() {
     }
 
 	repositories.ConnectDB()
-	infrastructure.ConnectClient()
-	route := routers.Init(gin.Default())
+	engine := gin.Default()
+	if err := engine.SetTrustedProxies(infrastructure.LoadTrustedProxiesFromEnv()); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+	route := routers.Init(engine)
 	route.Run()
 }
\ No newline at end of file
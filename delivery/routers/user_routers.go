@@ -1,9 +1,12 @@
 package routers
 
 import (
+	"log"
 	"os"
+	"time"
 
 	"github.com/blog-platform/delivery/controllers"
+	"github.com/blog-platform/domain"
 	"github.com/blog-platform/infrastructure"
 	"github.com/blog-platform/repositories"
 	"github.com/blog-platform/usecases"
@@ -14,27 +17,224 @@ func AuthRoutes(group *gin.RouterGroup) {
 	DB := repositories.DB
 	ur := repositories.NewUserRepository(DB)
 	ei := infrastructure.NewSMTPEmailService()
+	emailTemplates, err := infrastructure.LoadEmailTemplates(os.Getenv("EMAIL_TEMPLATE_DIR"))
+	if err != nil {
+		log.Fatal("Failed to load email templates:", err)
+	}
+	ei.Templates = emailTemplates
+	ei.Transport, err = infrastructure.LoadEmailTransportFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load email transport:", err)
+	}
+	ei.DKIM, err = infrastructure.LoadDKIMSignerFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load DKIM signing key:", err)
+	}
+	outboxConfig, err := infrastructure.LoadEmailOutboxConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load email outbox config:", err)
+	}
+	dr := repositories.NewDeliverabilityRepository(DB)
+	eou := usecases.NewEmailOutboxUsecase(repositories.NewEmailOutboxRepository(DB), ei, outboxConfig, usecases.WithSuppressionList(dr))
+	infrastructure.Every(outboxConfig.PollInterval, "email outbox", eou.ProcessOutbox)
+	infrastructure.Every(time.Hour, "email outbox purge", eou.PurgeSent)
 	pi := infrastructure.NewPasswordInfrastructure()
+	hashConfig, err := infrastructure.LoadPasswordHashConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load password hash config:", err)
+	}
+	pi.Config = hashConfig
 	tr := repositories.NewTokenRepository(DB)
-	js := infrastructure.NewJWTInfrastructure([]byte(os.Getenv("JWT_ACCESS_SECRET")), []byte(os.Getenv("JWT_REFRESH_SECRET")), tr)
-	uu := usecases.NewUserUsecase(ur, ei, pi, js, tr)
+	accessKeys, err := infrastructure.LoadKeyRingFromEnv("JWT_ACCESS_KEYRING")
+	if err != nil {
+		log.Fatal("Failed to load access key ring:", err)
+	}
+	refreshKeys, err := infrastructure.LoadKeyRingFromEnv("JWT_REFRESH_KEYRING")
+	if err != nil {
+		log.Fatal("Failed to load refresh key ring:", err)
+	}
+	jwtConfig, err := infrastructure.LoadJWTConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load JWT config:", err)
+	}
+	js, err := infrastructure.NewJWTInfrastructureWithKeyRings(accessKeys, refreshKeys, []byte(os.Getenv("JWT_ACCESS_SECRET")), []byte(os.Getenv("JWT_REFRESH_SECRET")), tr)
+	if err != nil {
+		log.Fatal("Failed to set up JWT signing:", err)
+	}
+	js.Config = jwtConfig
+	js.Denylist = repositories.NewTokenDenylistRepository(DB)
+	tfr := repositories.NewTwoFactorRepository(DB)
+	ti := infrastructure.NewTOTPInfrastructure(os.Getenv("TOTP_ISSUER"))
+	throttleConfig, err := infrastructure.LoadLoginThrottleConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load login throttle config:", err)
+	}
+	las := repositories.NewLoginAttemptRepository(DB)
+	lt := infrastructure.NewLoginThrottler(las, throttleConfig)
+	ar := repositories.NewAuditRepository(DB)
+	if path := os.Getenv("AUDIT_LOG_FILE"); path != "" {
+		sink, err := infrastructure.NewFileAuditSink(path)
+		if err != nil {
+			log.Fatal("Failed to open audit log file:", err)
+		}
+		ar.Sink = sink
+	}
+	passwordConfig, err := infrastructure.LoadPasswordPolicyConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load password policy config:", err)
+	}
+	pp, err := infrastructure.NewPasswordPolicy(passwordConfig)
+	if err != nil {
+		log.Fatal("Failed to load password policy:", err)
+	}
+	uu := usecases.NewUserUsecase(ur, eou, pi, js, tr, usecases.WithTwoFactor(tfr), usecases.WithLoginThrottle(lt), usecases.WithEmailChanges(repositories.NewEmailChangeRepository(DB)), usecases.WithAuditLog(ar), usecases.WithPasswordPolicy(pp), usecases.WithPasswordHistory(repositories.NewPasswordHistoryRepository(DB), passwordConfig.HistoryDepth), usecases.WithSessionRevocation(repositories.NewAccountRepository(DB)))
 	uc := controllers.NewUserController(uu)
+	tu := usecases.NewTwoFactorUsecase(ur, tfr, ti, pi, js, tr, lt, ar)
+	tc := controllers.NewTwoFactorController(tu)
+	oidcProviders, err := infrastructure.LoadOIDCProvidersFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load OIDC providers:", err)
+	}
+	ou := usecases.NewOIDCUsecase(ur, repositories.NewOIDCRepository(DB), infrastructure.NewOIDCInfrastructure(oidcProviders...), pi, js, tr, tfr, ar)
+	oc := controllers.NewOIDCController(ou)
+	magicLinkLimit, magicLinkWindow, err := infrastructure.LoadRateLimitFromEnv("MAGIC_LINK_RATE_LIMIT", "MAGIC_LINK_RATE_WINDOW", 3, 15*time.Minute)
+	if err != nil {
+		log.Fatal("Failed to load magic link rate limit:", err)
+	}
+	mu := usecases.NewMagicLinkUsecase(ur, eou, js, tr, tfr, infrastructure.NewRateLimiter(las, "magic-link:", magicLinkLimit, magicLinkWindow), ar)
+	mc := controllers.NewMagicLinkController(mu)
+	pu := usecases.NewPersonalAccessTokenUsecase(ur, repositories.NewPersonalAccessTokenRepository(DB), ar)
+	pc := controllers.NewPersonalAccessTokenController(pu)
+	nu := usecases.NewNotificationUsecase(repositories.NewNotificationRepository(DB), ur, eou)
+	nc := controllers.NewNotificationController(nu)
+	infrastructure.Every(24*time.Hour, "notification digests", nu.SendDigests)
+	digestConfig, err := infrastructure.LoadDigestConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load digest config:", err)
+	}
+	du := usecases.NewDigestUsecase(repositories.NewSubscriptionRepository(DB), ur, eou, digestConfig)
+	dc := controllers.NewDigestController(du)
+	infrastructure.Every(time.Hour, "weekly digests", du.SendDigests)
+	aiConfig, err := infrastructure.LoadAIConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load AI assistant config:", err)
+	}
+	aiLimit, aiWindow, err := infrastructure.LoadRateLimitFromEnv("AI_RATE_LIMIT", "AI_RATE_WINDOW", 30, time.Hour)
+	if err != nil {
+		log.Fatal("Failed to load AI assistant rate limit:", err)
+	}
+	aii := infrastructure.NewAIInfrastructure(aiConfig)
+	ail := infrastructure.NewRateLimiter(las, "ai:", aiLimit, aiWindow)
+	aic := controllers.NewAIController(usecases.NewAIUsecase(aii, repositories.NewBlogRepository(DB), ail))
+	tgc := controllers.NewTagController(usecases.NewTagUsecase(repositories.NewTagRepository(DB), infrastructure.NewKeywordExtractor(), usecases.WithAITagSuggestions(aii, ail)))
+	owners := repositories.NewOwnershipRepository(DB)
+	rxc := controllers.NewReactionController(usecases.NewReactionUsecase(ur, repositories.NewReactionRepository(DB), owners, usecases.WithReactionNotifications(nu, infrastructure.NewRateLimiter(las, "reaction-notification:", 1, 24*time.Hour))))
+	cmc := controllers.NewCommentController(usecases.NewCommentUsecase(ur, repositories.NewCommentRepository(DB), owners, usecases.WithCommentNotifications(nu)))
+	prc := controllers.NewProfileController(usecases.NewProfileUsecase(ur, repositories.NewProfileRepository(DB), usecases.WithFollowNotifications(nu, infrastructure.NewRateLimiter(las, "follow-notification:", 1, 24*time.Hour))))
+	deletionConfig, err := infrastructure.LoadAccountDeletionConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load account deletion config:", err)
+	}
+	au := usecases.NewAccountUsecase(ur, repositories.NewAccountRepository(DB), eou, pi, js, deletionConfig)
+	ac := controllers.NewAccountController(au)
+	infrastructure.Every(time.Minute, "data exports", au.ProcessPendingExports)
+	infrastructure.Every(time.Hour, "expired data exports", au.PurgeExpiredExports)
+	infrastructure.Every(time.Hour, "account deletions", au.ProcessDueDeletions)
+	adu := usecases.NewAdminUsecase(ur, repositories.NewAdminRepository(DB), repositories.NewAccountRepository(DB), ar, tr, js)
+	adc := controllers.NewAdminController(adu)
+	infrastructure.Every(time.Minute, "suspension expiry", adu.LiftExpiredSuspensions)
 	ao := infrastructure.NewMiddleware(js)
+	ao.PersonalAccessTokens = pu
+	ao.Audit = ar
+	auc := controllers.NewAuditController(usecases.NewAuditUsecase(ar))
+	kc := controllers.NewJWKSController(js)
+	etc := controllers.NewEmailTemplateController(usecases.NewEmailTemplateUsecase(emailTemplates))
+	eoc := controllers.NewEmailOutboxController(eou)
+	bc := controllers.NewBounceController(usecases.NewBounceUsecase(infrastructure.NewBounceParser(), dr), os.Getenv("BOUNCE_WEBHOOK_SECRET"))
 
 	group.POST("/register", uc.Register)
 	group.POST("/login", uc.Login)
+	group.POST("/login/2fa", tc.VerifyLogin)
+	group.POST("/login/magic-link", mc.Request)
+	group.GET("/login/magic-link/confirm", mc.Confirm)
+	group.POST("/login/magic-link/verify", mc.Verify)
+	group.POST("/logout", ao.AuthMiddleware(), uc.Logout)
+	group.POST("/2fa/enroll", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), tc.Enroll)
+	group.POST("/2fa/confirm", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), tc.Confirm)
+	group.POST("/2fa/disable", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), tc.Disable)
+	group.POST("/tokens", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), pc.Create)
+	group.GET("/tokens", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), pc.List)
+	group.DELETE("/tokens/:id", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), pc.Revoke)
+	group.GET("/oidc/:provider/login", oc.Login)
+	group.GET("/oidc/:provider/callback", oc.Callback)
+	group.GET("/oidc/identities", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), oc.ListIdentities)
+	group.DELETE("/oidc/identities/:id", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), oc.Unlink)
 	group.POST("/token/refresh", uc.RefreshToken)
-	group.POST("/reset-password", ao.AuthMiddleware(), uc.ResetPassword)
+	group.GET("/.well-known/jwks.json", kc.JWKS)
+	group.POST("/reset-password", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), uc.ResetPassword)
 	group.POST("/forgot-password", uc.ForgotPassword)
 	group.POST("/password/:id/update", uc.UpdatePasswordDirect)
-	group.GET("/users/:id", ao.AccountOwnerMiddleware(), uc.GetProfile)
+	group.POST("/password/check", uc.CheckPassword)
+	group.GET("/email/confirm", uc.ConfirmEmailChangePage)
+	group.POST("/email/confirm", uc.ConfirmEmailChange)
+	group.POST("/me/export", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), ac.RequestExport)
+	group.GET("/exports/:token", ac.DownloadExport)
+	group.DELETE("/me", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), ac.RequestDeletion)
+	group.POST("/me/deletion/cancel", ao.AuthMiddleware(), ao.SessionOnlyMiddleware(), ac.CancelDeletion)
+	group.GET("/me/digest", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:read"), dc.Subscription)
+	group.PUT("/me/digest", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), dc.Subscribe)
+	group.DELETE("/me/digest", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), dc.Unsubscribe)
+	group.POST("/newsletter/subscribe", dc.SubscribeEmail)
+	group.POST("/inbound/bounces/:source", bc.Ingest)
+	group.GET("/newsletter/confirm", dc.ConfirmPage)
+	group.POST("/newsletter/confirm", dc.Confirm)
+	group.GET("/newsletter/unsubscribe", dc.UnsubscribePage)
+	group.POST("/newsletter/unsubscribe", dc.UnsubscribeByToken)
+	group.POST("/assistant/drafts", ao.AuthMiddleware(), ao.ScopeMiddleware("blogs:write"), ao.RequirePermission(domain.PermissionBlogsWrite), aic.GenerateDraft)
+	group.POST("/assistant/outlines", ao.AuthMiddleware(), ao.ScopeMiddleware("blogs:write"), ao.RequirePermission(domain.PermissionBlogsWrite), aic.ExpandOutline)
+	group.POST("/assistant/tags", ao.AuthMiddleware(), ao.ScopeMiddleware("blogs:write"), ao.RequirePermission(domain.PermissionBlogsWrite), tgc.Suggest)
+	group.POST("/blogs/:id/assistant/improve", ao.AuthMiddleware(), ao.ScopeMiddleware("blogs:write"), ao.Authorize(infrastructure.BlogPolicy(owners, domain.PermissionBlogsEditAny)), aic.ImproveParagraph)
+	group.POST("/blogs/:id/comments", ao.AuthMiddleware(), ao.ScopeMiddleware("comments:write"), ao.RequirePermission(domain.PermissionCommentsWrite), cmc.Create)
+	group.PUT("/blogs/:id/reaction", ao.AuthMiddleware(), ao.ScopeMiddleware("comments:write"), ao.RequirePermission(domain.PermissionCommentsWrite), rxc.React)
+	group.DELETE("/blogs/:id/reaction", ao.AuthMiddleware(), ao.ScopeMiddleware("comments:write"), ao.RequirePermission(domain.PermissionCommentsWrite), rxc.Unreact)
+	group.GET("/@:username", prc.PublicProfile)
+	group.POST("/@:username/follow", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), prc.Follow)
+	group.DELETE("/@:username/follow", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), prc.Unfollow)
+	group.GET("/notifications", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:read"), nc.List)
+	group.POST("/notifications/read-all", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), nc.MarkAllRead)
+	group.POST("/notifications/:id/read", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), nc.MarkRead)
+	group.GET("/notifications/preferences", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:read"), nc.Preferences)
+	group.PUT("/notifications/preferences", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), nc.UpdatePreferences)
+	group.GET("/users/:id/privacy", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:read"), ao.AccountOwnerMiddleware(), prc.Privacy)
+	group.PUT("/users/:id/privacy", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), ao.AccountOwnerMiddleware(), prc.UpdatePrivacy)
+	group.GET("/users/:id", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:read"), ao.Authorize(infrastructure.UserPolicy(domain.PermissionUsersRead)), uc.GetProfile)
   
 	adminRoutes := group.Group("/users")
-	adminRoutes.Use(ao.AuthMiddleware(), ao.AdminMiddleware())
+	adminRoutes.Use(ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"))
 	{
-		adminRoutes.PUT("/:id/promote", uc.Promote)
-		adminRoutes.PUT("/:id/demote", uc.Demote)
+		adminRoutes.PUT("/:id/promote", ao.RequirePermission(domain.PermissionRolesAssign), uc.Promote)
+		adminRoutes.PUT("/:id/demote", ao.RequirePermission(domain.PermissionRolesAssign), uc.Demote)
+		adminRoutes.PUT("/:id/role", ao.RequirePermission(domain.PermissionRolesAssign), uc.AssignRole)
+		adminRoutes.DELETE("/:id/2fa", ao.RequirePermission(domain.PermissionUsersManage), tc.Reset)
+		adminRoutes.POST("/:id/unlock", ao.RequirePermission(domain.PermissionUsersManage), uc.UnlockAccount)
+		adminRoutes.GET("", ao.AdminMiddleware(), adc.ListUsers)
+		adminRoutes.GET("/:id/sessions", ao.AdminMiddleware(), adc.Sessions)
+		adminRoutes.GET("/:id/activity", ao.AdminMiddleware(), adc.Activity)
+		adminRoutes.POST("/:id/suspend", ao.AdminMiddleware(), adc.Suspend)
+		adminRoutes.POST("/:id/reinstate", ao.AdminMiddleware(), adc.Reinstate)
+		adminRoutes.POST("/:id/password-reset", ao.AdminMiddleware(), adc.ForcePasswordReset)
+		adminRoutes.POST("/:id/impersonate", ao.AdminMiddleware(), ao.SessionOnlyMiddleware(), adc.Impersonate)
+		adminRoutes.DELETE("/:id", ao.AdminMiddleware(), ao.SessionOnlyMiddleware(), adc.HardDelete)
+		adminRoutes.DELETE("/:id/email-status", ao.AdminMiddleware(), adc.ClearEmailStatus)
 	}
+	group.GET("/audit-events", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.AdminMiddleware(), auc.Search)
+	group.GET("/audit-events/verify", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.AdminMiddleware(), auc.Verify)
+	group.GET("/email-templates", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.AdminMiddleware(), etc.List)
+	group.GET("/email-templates/:name/preview", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.AdminMiddleware(), etc.Preview)
+	group.GET("/email-outbox", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.AdminMiddleware(), eoc.Search)
+	group.GET("/email-outbox/:id", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.AdminMiddleware(), eoc.Fetch)
+	group.POST("/email-outbox/:id/retry", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.AdminMiddleware(), eoc.Retry)
+	group.GET("/roles", ao.AuthMiddleware(), ao.ScopeMiddleware("users:admin"), ao.RequirePermission(domain.PermissionRolesAssign), uc.ListRoles)
   
-	group.PATCH("/users/:id", ao.AccountOwnerMiddleware(), uc.UpdateProfile)
+	group.PATCH("/users/:id", ao.AuthMiddleware(), ao.ScopeMiddleware("profile:write"), ao.AccountOwnerMiddleware(), uc.UpdateProfile)
 }
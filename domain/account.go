@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DeletionPolicyAnonymize scrubs the account but keeps posts and comments
+	// under a placeholder name, so threads others replied to stay readable.
+	DeletionPolicyAnonymize = "anonymize"
+	// DeletionPolicyDelete removes the account together with its posts and
+	// comments.
+	DeletionPolicyDelete = "delete"
+)
+
+type AccountDeletionConfig struct {
+	Policy      string
+	GracePeriod time.Duration
+}
+
+// DataExport is a personal data archive requested by its owner. Archive is
+// filled in once Status is "ready"; the emailed download link carries the
+// token whose SHA-256 is TokenHash.
+type DataExport struct {
+	gorm.Model
+	ID        int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64      `gorm:"index" json:"user_id"`                                   // Foreign key column
+	User      User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"` // GORM relation
+	Status    string     `gorm:"type:varchar(20)" json:"status"`
+	TokenHash *string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	Archive   []byte     `json:"-"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"`
+	// LockedUntil is the lease of the worker building the archive
+	LockedUntil *time.Time `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"` // auto set on insert
+	UpdatedAt   time.Time  `json:"updated_at"` // auto set on update
+}
+
+// AccountDeletion is a deletion the owner asked for. It can be cancelled
+// until ScheduledFor, after which the configured policy is applied.
+type AccountDeletion struct {
+	gorm.Model
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       int64     `gorm:"uniqueIndex" json:"user_id"`                             // Foreign key column
+	User         User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"` // GORM relation
+	ScheduledFor time.Time `gorm:"index" json:"scheduled_for"`
+	CreatedAt    time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt    time.Time `json:"updated_at"` // auto set on update
+}
+
+// PersonalData is everything stored about a user, as collected for an export.
+type PersonalData struct {
+	User                 User
+	Posts                []Blog
+	Comments             []Comment
+	Reactions            []Reaction
+	Sessions             []Token
+	PersonalAccessTokens []PersonalAccessToken
+	LinkedIdentities     []LinkedIdentity
+	Following            []Follow
+	Followers            []Follow
+}
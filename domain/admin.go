@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// UserFilter narrows down the admin user listing. Zero values match all
+// users; Query matches a substring of the username or email.
+type UserFilter struct {
+	Query       string
+	Role        string
+	Status      string
+	EmailStatus string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Page        int
+	Limit       int
+}
+
+// AdminUserView is what the admin console sees of a user.
+type AdminUserView struct {
+	ID                int64      `json:"id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	Role              string     `json:"role"`
+	Status            string     `json:"status"`
+	SuspensionReason  string     `json:"suspension_reason,omitempty"`
+	SuspendedUntil    *time.Time `json:"suspended_until,omitempty"`
+	EmailStatus       string     `json:"email_status,omitempty"`
+	EmailStatusReason string     `json:"email_status_reason,omitempty"`
+	EmailStatusAt     *time.Time `json:"email_status_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+type AdminUserPage struct {
+	Items []AdminUserView `json:"items"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+	Total int64           `json:"total"`
+}
+
+// SessionView describes a stored token without its content.
+type SessionView struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ActivityComment struct {
+	ID        int64     `json:"id"`
+	BlogID    int64     `json:"blog_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserActivity struct {
+	RecentPosts    []PublicPost      `json:"recent_posts"`
+	RecentComments []ActivityComment `json:"recent_comments"`
+	AuditEvents    []AuditEvent      `json:"audit_events"`
+}
+
+// Suspension takes a user out of service. A nil Until bans the user until an
+// admin reinstates them.
+type Suspension struct {
+	Reason string
+	Until  *time.Time
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAIUnavailable    = errors.New("the writing assistant is not enabled")
+	ErrAIProviderFailed = errors.New("the writing assistant could not answer, try again later")
+)
+
+// AIConfig configures the writing assistant. An empty Provider turns it off.
+// A request is attempted up to MaxRetries more times after a timeout, rate
+// limit or server error, waiting RetryBase * 2^(n-1) before retry n.
+type AIConfig struct {
+	Provider    string
+	BaseURL     string
+	APIKey      string
+	Model       string
+	Timeout     time.Duration // per attempt
+	MaxRetries  int
+	RetryBase   time.Duration
+	MaxTokens   int
+	Temperature float64
+}
+
+// AIRequest asks a language model to follow Instructions on Input.
+type AIRequest struct {
+	Instructions string
+	Input        string
+	MaxTokens    int // zero for the configured default
+}
+
+// AIDraft is a post written by the assistant for the author to edit.
+type AIDraft struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
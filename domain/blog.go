@@ -19,3 +19,21 @@ type Blog struct {
 	CreatedAt time.Time `json:"created_at"`                                     // auto set on insert
 	UpdatedAt time.Time `json:"updated_at"`                                     // auto set on update
 }
+
+// Reaction kinds a user can leave on a post.
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
+// Reaction records one user's reaction to a post; Blog.Likes and
+// Blog.Dislikes hold the totals.
+type Reaction struct {
+	gorm.Model
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64     `gorm:"uniqueIndex:idx_reaction_pair" json:"user_id"`
+	BlogID    int64     `gorm:"uniqueIndex:idx_reaction_pair;index" json:"blog_id"`
+	Kind      string    `gorm:"type:varchar(20)" json:"kind"`
+	CreatedAt time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt time.Time `json:"updated_at"` // auto set on update
+}
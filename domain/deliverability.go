@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Email statuses recorded on a user. An empty status means mail to the
+// address is delivered.
+const (
+	EmailStatusHardBounced = "hard_bounced"
+	EmailStatusComplained  = "complained"
+)
+
+// Bounce types reported by DSNs and provider webhooks. Soft bounces are
+// temporary and never stop delivery.
+const (
+	BounceHard      = "hard"
+	BounceSoft      = "soft"
+	BounceComplaint = "complaint"
+)
+
+// Sources bounce reports are accepted from.
+const (
+	BounceSourceDSN      = "dsn"
+	BounceSourceSES      = "ses"
+	BounceSourceSendGrid = "sendgrid"
+	BounceSourceMailgun  = "mailgun"
+	BounceSourcePostmark = "postmark"
+)
+
+var ErrUnknownBounceSource = errors.New("unknown bounce source")
+
+// SuppressedAddress stops mail to an address that bounced or complained,
+// whether or not an account uses it. Email is stored lowercased.
+type SuppressedAddress struct {
+	gorm.Model
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Email        string    `gorm:"type:varchar(255);uniqueIndex" json:"email"`
+	Status       string    `gorm:"type:varchar(20)" json:"status"`
+	Reason       string    `gorm:"type:varchar(500)" json:"reason"`
+	SuppressedAt time.Time `json:"suppressed_at"`
+	CreatedAt    time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt    time.Time `json:"updated_at"` // auto set on update
+}
+
+// BounceEvent is what a report says about one recipient.
+type BounceEvent struct {
+	Email  string
+	Type   string
+	Reason string
+}
+
+// BounceReport sums up one processed report.
+type BounceReport struct {
+	Received   int `json:"received"`
+	Suppressed int `json:"suppressed"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "errors"
+
+// Email templates. Each has an HTML and a plain text version, optionally per
+// locale, see infrastructure/templates/email.
+const (
+	EmailActivation         = "activation"
+	EmailPasswordReset      = "password_reset"
+	EmailMagicLink          = "magic_link"
+	EmailAccountLocked      = "account_locked"
+	EmailChangeNotice       = "email_change_notice"
+	EmailChangeConfirmation = "email_change_confirm"
+	EmailExportReady        = "export_ready"
+	EmailDeletionScheduled  = "deletion_scheduled"
+	EmailNotification       = "notification"
+	EmailDigest             = "digest"
+	EmailNewsletterConfirm  = "newsletter_confirm"
+)
+
+var ErrEmailTemplateNotFound = errors.New("email template not found")
+
+// EmailMessage is a templated email. Locale picks a translated variant of the
+// template when there is one. Headers are added to the message as given, for
+// things like List-Unsubscribe.
+type EmailMessage struct {
+	To       []string
+	Template string
+	Locale   string
+	Data     map[string]interface{}
+	Headers  map[string]string
+}
+
+// RenderedEmail is a template rendered for one recipient.
+type RenderedEmail struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmailChangeRequest is a pending change of a user's email address. Each user
+// has at most one; asking again replaces it. Only the SHA-256 of the token
+// mailed to the new address is stored.
+type EmailChangeRequest struct {
+	gorm.Model
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64     `gorm:"uniqueIndex" json:"user_id"`                             // Foreign key column
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"` // GORM relation
+	NewEmail  string    `gorm:"type:varchar(500)" json:"new_email"`
+	TokenHash string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt time.Time `json:"updated_at"` // auto set on update
+}
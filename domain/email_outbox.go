@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+var ErrOutboxEmailNotRetryable = errors.New("only failed emails can be retried")
+
+// EmailOutboxConfig controls background delivery. Attempt n is retried after
+// RetryBase * 2^(n-1), capped at RetryMax, and the email is dead-lettered once
+// MaxAttempts have failed. A claimed email whose worker died becomes
+// claimable again after Lease.
+type EmailOutboxConfig struct {
+	Workers      int
+	BatchSize    int
+	MaxAttempts  int
+	RetryBase    time.Duration
+	RetryMax     time.Duration
+	Lease        time.Duration
+	PollInterval time.Duration
+	Retention    time.Duration // how long sent emails are kept
+}
+
+// OutboxEmail is an email waiting for, or done with, background delivery.
+// It is either templated or, when Template is empty, a plain Subject and
+// Body. Data, Headers and Body can hold sign-in or unsubscribe links, so they
+// are never serialized.
+type OutboxEmail struct {
+	gorm.Model
+	ID            int64                  `gorm:"primaryKey;autoIncrement" json:"id"`
+	To            []string               `gorm:"column:recipients;type:text;serializer:json" json:"to"`
+	Template      string                 `gorm:"type:varchar(100)" json:"template,omitempty"`
+	Locale        string                 `gorm:"type:varchar(16)" json:"locale,omitempty"`
+	Data          map[string]interface{} `gorm:"type:text;serializer:json" json:"-"`
+	Headers       map[string]string      `gorm:"type:text;serializer:json" json:"-"`
+	Subject       string                 `gorm:"type:varchar(255)" json:"subject,omitempty"`
+	Body          string                 `gorm:"type:text" json:"-"`
+	Status        string                 `gorm:"type:varchar(20);index:idx_outbox_emails_due,priority:1" json:"status"`
+	Attempts      int                    `json:"attempts"`
+	NextAttemptAt time.Time              `gorm:"index:idx_outbox_emails_due,priority:2" json:"next_attempt_at"`
+	LockedUntil   *time.Time             `json:"locked_until,omitempty"`
+	LastError     string                 `gorm:"type:varchar(1000)" json:"last_error,omitempty"`
+	SentAt        *time.Time             `json:"sent_at,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"` // auto set on insert
+	UpdatedAt     time.Time              `json:"updated_at"` // auto set on update
+}
+
+// NewOutboxEmail queues message for delivery right away.
+func NewOutboxEmail(message EmailMessage) OutboxEmail {
+	return OutboxEmail{
+		To:            message.To,
+		Template:      message.Template,
+		Locale:        message.Locale,
+		Data:          message.Data,
+		Headers:       message.Headers,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+}
+
+// Message returns the templated message the email was queued with.
+func (e OutboxEmail) Message() EmailMessage {
+	return EmailMessage{To: e.To, Template: e.Template, Locale: e.Locale, Data: e.Data, Headers: e.Headers}
+}
+
+// OutboxFilter narrows down the outbox. A zero Status matches every email.
+type OutboxFilter struct {
+	Status string
+	Page   int
+	Limit  int
+}
+
+type OutboxEmailPage struct {
+	Items []OutboxEmail `json:"items"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Total int64         `json:"total"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LinkedIdentity ties an account at an external OpenID Connect provider to a
+// local user. Provider and Subject together identify the external account.
+type LinkedIdentity struct {
+	gorm.Model
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64     `gorm:"index" json:"user_id"`                                   // Foreign key column
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"` // GORM relation
+	Provider  string    `gorm:"type:varchar(100);uniqueIndex:idx_linked_identity_subject" json:"provider"`
+	Subject   string    `gorm:"type:varchar(255);uniqueIndex:idx_linked_identity_subject" json:"subject"`
+	Email     string    `gorm:"type:varchar(500)" json:"email"`
+	CreatedAt time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt time.Time `json:"updated_at"` // auto set on update
+}
+
+// OIDCAuthRequest remembers what was sent to the provider when a login
+// started, so the callback can be matched to it exactly once.
+type OIDCAuthRequest struct {
+	gorm.Model
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	State        string    `gorm:"type:varchar(255);uniqueIndex" json:"-"`
+	Provider     string    `gorm:"type:varchar(100)" json:"provider"`
+	Nonce        string    `gorm:"type:varchar(255)" json:"-"`
+	CodeVerifier string    `gorm:"type:varchar(255)" json:"-"`
+	ExpiresAt    time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt    time.Time `json:"updated_at"` // auto set on update
+}
+
+// TableName keeps GORM from splitting the acronym into "o_id_c".
+func (OIDCAuthRequest) TableName() string {
+	return "oidc_auth_requests"
+}
+
+// OIDCIdentity holds the claims of an ID token after its signature, issuer,
+// audience, expiry and nonce have been verified.
+type OIDCIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
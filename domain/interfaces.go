@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type IBlogRepository interface {
@@ -12,68 +13,455 @@ type IBlogRepository interface {
 	FetchAll(ctx context.Context) ([]*Blog, error)
 }
 
+// IOwnershipRepository returns the id of the user who owns a resource, or
+// ErrResourceNotFound.
+type IOwnershipRepository interface {
+	BlogOwner(ctx context.Context, blogID int64) (int64, error)
+	CommentOwner(ctx context.Context, commentID int64) (int64, error)
+}
+
 type IBlogUsecase interface {
 	CreateBlog(ctx context.Context, blog *Blog, tags []string) error
 	FetchBlogByID(ctx context.Context, id int64) (*Blog, error)
 	FetchAllBlogs(ctx context.Context) ([]*Blog, error)
 }
 
+// IReactionRepository stores one reaction per user and post and keeps the
+// post's totals in step. Both methods return the kind the user had before,
+// or "" when they had not reacted.
+type IReactionRepository interface {
+	React(userID int64, blogID int64, kind string) (string, error)
+	Unreact(userID int64, blogID int64) (string, error)
+}
+
+type ICommentRepository interface {
+	Create(comment *Comment) error
+}
+
+type ICommentUsecase interface {
+	Create(userID string, blogID string, content string) (Comment, error)
+}
+
+type IReactionUsecase interface {
+	React(userID string, blogID string, kind string) error
+	Unreact(userID string, blogID string) error
+}
+
+// IAIInfrastructure completes text with a language model provider.
+type IAIInfrastructure interface {
+	Complete(ctx context.Context, request AIRequest) (string, error)
+}
+
+type IAIUsecase interface {
+	GenerateDraft(ctx context.Context, userID string, prompt string) (AIDraft, error)
+	ExpandOutline(ctx context.Context, userID string, outline string) (AIDraft, error)
+	ImproveParagraph(ctx context.Context, userID string, blogID int64, paragraph string, goal string) (string, error)
+}
+
+type ITagRepository interface {
+	// FetchByNames returns the existing tags named like one of names,
+	// ignoring case, most used first.
+	FetchByNames(ctx context.Context, names []string) ([]TagUsage, error)
+	// MatchTags returns existing tags whose name occurs as whole words in
+	// words, most used first. words is lowercase, with every run of
+	// characters other than letters, digits, '+' and '#' turned into a
+	// single space.
+	MatchTags(ctx context.Context, words string, limit int) ([]TagUsage, error)
+}
+
+// IKeywordExtractor picks the terms a post is about, best first.
+type IKeywordExtractor interface {
+	Keywords(title string, content string, limit int) []Keyword
+}
+
+type ITagUsecase interface {
+	SuggestTags(ctx context.Context, userID string, title string, content string, limit int) ([]TagSuggestion, error)
+}
+
 type IJWTInfrastructure interface {
 	GenerateAccessToken(userID string, userRole string) (string, error)
 	GenerateRefreshToken(userID string, userRole string) (string, error)
 	ValidateAccessToken(authHeader string) (*TokenClaims, error)
 	ValidateRefreshToken(token string) (*TokenClaims, error)
+	JWKS() JSONWebKeySet
+	RevokeToken(claims *TokenClaims) error
+	// ConsumeToken revokes a single-use token and returns ErrTokenUsed when
+	// it was revoked already, so only one of concurrent redemptions wins.
+	ConsumeToken(claims *TokenClaims) error
+	GenerateChallengeToken(userID string, userRole string) (string, error)
+	ValidateChallengeToken(token string) (*TokenClaims, error)
+	GenerateMagicLinkToken(userID string, userRole string) (string, error)
+	ValidateMagicLinkToken(token string) (*TokenClaims, error)
+	GenerateImpersonationToken(userID string, userRole string, impersonatorID string) (string, error)
+}
+
+type ITokenDenylist interface {
+	IsRevoked(jti string) (bool, error)
+	Revoke(jti string, expiresAt time.Time) error
+	// Consume is Revoke that reports whether jti was added by this call.
+	Consume(jti string, expiresAt time.Time) (bool, error)
 }
 
 type ITokenRepository interface {
 	FetchByContent(content string) (Token, error)
 	Save(token *Token) error
+	// SaveWithEmail stores token and queues message in one transaction.
+	SaveWithEmail(token *Token, message EmailMessage) error
 }
 
 type IPasswordInfrastructure interface {
 	HashPassword(password string) (string, error)
 	ComparePassword(correctPassword []byte, inputPassword []byte) error
+	NeedsRehash(hashedPassword string) bool
+}
+
+// IPasswordPolicy judges a candidate password. Reuse of earlier passwords is
+// checked separately because it needs the account's password history.
+type IPasswordPolicy interface {
+	Check(password string, pc PasswordContext) PasswordFeedback
+}
+
+type IPasswordHistoryRepository interface {
+	Recent(userID int64, limit int) ([]PasswordHistory, error)
+	Add(entry *PasswordHistory, keep int) error
+}
+
+type ITOTPInfrastructure interface {
+	GenerateSecret() (string, error)
+	ProvisioningURI(secret string, accountName string) string
+	Verify(secret string, code string, lastUsedStep int64) (int64, error)
+}
+
+type IOIDCInfrastructure interface {
+	AuthCodeURL(provider string, state string, nonce string, codeChallenge string) (string, error)
+	Exchange(provider string, code string, codeVerifier string, nonce string) (OIDCIdentity, error)
 }
 
 type IEmailInfrastructure interface {
 	SendEmail(to []string, subject string, body string) error
+	SendTemplate(message EmailMessage) error
+}
+
+type IEmailRenderer interface {
+	Templates() []string
+	Render(template string, locale string, data map[string]interface{}) (RenderedEmail, error)
+	SampleData(template string) (map[string]interface{}, error)
+}
+
+type IEmailTemplateUsecase interface {
+	List() []string
+	Preview(template string, locale string) (RenderedEmail, error)
+}
+
+type IEmailOutboxRepository interface {
+	Enqueue(email *OutboxEmail) error
+	Claim(now time.Time, lease time.Duration, limit int) ([]OutboxEmail, error)
+	MarkSent(id int64, at time.Time) error
+	MarkFailed(id int64, lastError string, nextAttemptAt time.Time, dead bool) error
+	Search(filter OutboxFilter) ([]OutboxEmail, int64, error)
+	Fetch(id int64) (OutboxEmail, error)
+	Retry(id int64, at time.Time) error
+	PurgeSent(before time.Time) (int64, error)
+}
+
+type IEmailOutboxUsecase interface {
+	Search(filter OutboxFilter) (OutboxEmailPage, error)
+	Fetch(id int64) (OutboxEmail, error)
+	Retry(id int64) (OutboxEmail, error)
+}
+
+type INotificationRepository interface {
+	Create(notification *Notification) error
+	Search(filter NotificationFilter) ([]Notification, int64, error)
+	CountUnread(userID int64) (int64, error)
+	MarkRead(userID int64, id int64, at time.Time) error
+	MarkAllRead(userID int64, at time.Time) (int64, error)
+	FetchPreferences(userID int64) ([]NotificationPreference, error)
+	SavePreferences(preferences []NotificationPreference) error
+	FetchPendingDigests() ([]Notification, error)
+	MarkDigested(ids []int64) error
+}
+
+// INotifier tells a user about an event on the channels they have chosen.
+type INotifier interface {
+	Notify(event NotificationEvent) error
+}
+
+type INotificationUsecase interface {
+	INotifier
+	List(userID string, unreadOnly bool, page int, limit int) (NotificationPage, error)
+	MarkRead(userID string, id string) error
+	MarkAllRead(userID string) (int64, error)
+	Preferences(userID string) ([]NotificationPreference, error)
+	UpdatePreferences(userID string, preferences []NotificationPreference) ([]NotificationPreference, error)
+	SendDigests() error
+}
+
+// IBounceParser turns a bounce report from source into per-recipient
+// events, or returns ErrUnknownBounceSource.
+type IBounceParser interface {
+	Parse(source string, payload []byte) ([]BounceEvent, error)
+}
+
+// IEmailSuppressionList returns the addresses among addresses that must not
+// be mailed any more.
+type IEmailSuppressionList interface {
+	Suppressed(addresses []string) ([]string, error)
+}
+
+type IDeliverabilityRepository interface {
+	IEmailSuppressionList
+	// MarkAddress suppresses email, records status on the users with it and
+	// stops any newsletter subscription to it. It returns how many users it
+	// marked.
+	MarkAddress(email string, status string, reason string, at time.Time) (int64, error)
+}
+
+type IBounceUsecase interface {
+	Ingest(source string, payload []byte) (BounceReport, error)
+}
+
+type ISubscriptionRepository interface {
+	FetchByUserID(userID int64) (Subscription, error)
+	FetchByEmail(email string) (Subscription, error)
+	Save(subscription *Subscription) error
+	Confirm(tokenHash string, at time.Time) error
+	Unsubscribe(tokenHash string) error
+	FetchDue(before time.Time, afterID int64, limit int) ([]Subscription, error)
+	MarkSent(id int64, at time.Time, unsubscribeTokenHash string) error
+	// TopPosts ranks posts published since since by the authors userID
+	// follows or tagged with one of tags, and across the whole blog when
+	// there is neither.
+	TopPosts(userID *int64, tags []string, since time.Time, limit int) ([]DigestPost, error)
+}
+
+type IDigestUsecase interface {
+	Subscription(userID string) (Subscription, error)
+	Subscribe(userID string, tags []string) (Subscription, error)
+	Unsubscribe(userID string) error
+	SubscribeEmail(email string, tags []string) error
+	Confirm(token string) error
+	UnsubscribeByToken(token string) error
+	SendDigests() error
 }
 
 type IUserUsecase interface {
 	Register(user *User) (User, error)
 	ActivateAccount(id string) error
-	Login(identifier string, password string) (string, string, error)
-	GetUserProfile(userID int64) (*User, error)
-	Promote(id string) error
-	Demote(id string) error
-	UpdateUserProfile(userID int64, updates map[string]interface{}) error
+	Login(identifier string, password string, client ClientInfo) (string, string, error)
+	Logout(authHeader string, refreshToken string, client ClientInfo) error
+	UnlockAccount(actor Principal, id string) error
+	GetUserProfile(userID int64) (*UserView, error)
+	AssignRole(actor Principal, id string, role string) error
+	UpdateUserProfile(userID int64, update ProfileUpdate) error
+	ConfirmEmailChange(token string) error
 	RefreshToken(authHeader string) (string, string, error)
-	ResetPassword(userID string, oldPassword string, newPassword string) error
-	ForgotPassword(email string) error
-	UpdatePasswordDirect(userID string, newPassword string, token string) error
+	ResetPassword(userID string, oldPassword string, newPassword string, client ClientInfo) error
+	ForgotPassword(email string, client ClientInfo) error
+	UpdatePasswordDirect(userID string, newPassword string, token string, client ClientInfo) error
+	CheckPassword(password string, pc PasswordContext) PasswordFeedback
+}
+
+type IOIDCRepository interface {
+	SaveAuthRequest(request *OIDCAuthRequest) error
+	ConsumeAuthRequest(state string) (OIDCAuthRequest, error)
+	FetchIdentity(provider string, subject string) (LinkedIdentity, error)
+	FetchIdentitiesByUserID(userID int64) ([]LinkedIdentity, error)
+	CreateIdentity(identity *LinkedIdentity) error
+	DeleteIdentity(userID int64, id int64) error
+}
+
+type IOIDCUsecase interface {
+	Begin(provider string) (string, string, error)
+	Complete(provider string, state string, browserState string, code string, client ClientInfo) (string, string, error)
+	ListIdentities(userID string) ([]LinkedIdentity, error)
+	Unlink(userID string, identityID string) error
+}
+
+type IPersonalAccessTokenRepository interface {
+	Create(token *PersonalAccessToken) error
+	FetchByHash(hash string) (PersonalAccessToken, error)
+	FetchByUserID(userID int64) ([]PersonalAccessToken, error)
+	Revoke(userID int64, id int64, at time.Time) error
+	TouchLastUsed(id int64, at time.Time) error
+}
+
+// IPersonalAccessTokenAuthenticator is the part of the usecase AuthMiddleware
+// needs to accept personal access tokens.
+type IPersonalAccessTokenAuthenticator interface {
+	Authenticate(token string) (*TokenClaims, error)
+}
+
+type IPersonalAccessTokenUsecase interface {
+	IPersonalAccessTokenAuthenticator
+	Create(userID string, name string, scopes []string, expiresAt *time.Time, client ClientInfo) (string, PersonalAccessToken, error)
+	List(userID string) ([]PersonalAccessToken, error)
+	Revoke(userID string, id string, client ClientInfo) error
 }
 
 type IUserRepository interface {
 	Register(user *User) (User, error)
+	// RegisterWithEmail stores user and queues the email message builds for
+	// the stored user in one transaction.
+	RegisterWithEmail(user *User, message func(User) EmailMessage) (User, error)
 	FetchByUsername(username string) (User, error)
 	FetchByEmail(email string) (User, error)
 	ActivateAccount(idStr string) error
 	Fetch(idStr string) (User, error)
 	GetUserProfile(userID int64) (*User, error)
-	Promote(idStr string) error
-	Demote(idStr string) error
-	UpdateUserProfile(userID int64, updates map[string]interface{}) error
+	ChangeRole(idStr string, role string) error
+	UpdateUserProfile(userID int64, update ProfileUpdate) error
+	UpdateEmail(userID int64, email string) error
 	ResetPassword(idStr string, newPassword string) error
 }
 
+type IAccountRepository interface {
+	CollectPersonalData(userID int64) (PersonalData, error)
+	CreateExport(export *DataExport) error
+	SaveExport(export *DataExport) error
+	LatestExport(userID int64) (DataExport, error)
+	ClaimExports(now time.Time, lease time.Duration, limit int) ([]DataExport, error)
+	PurgeExpiredExports(now time.Time) (int64, error)
+	FetchExportByTokenHash(tokenHash string) (DataExport, error)
+	ScheduleDeletion(deletion *AccountDeletion) error
+	CancelDeletion(userID int64) error
+	FetchDueDeletions(now time.Time) ([]AccountDeletion, error)
+	RevokeSessions(userID int64, at time.Time) ([]Token, error)
+	AnonymizeUser(userID int64) error
+	PurgeUser(userID int64) error
+}
+
+type IAccountUsecase interface {
+	RequestExport(userID string) error
+	ProcessPendingExports() error
+	PurgeExpiredExports() error
+	DownloadExport(token string) ([]byte, error)
+	RequestDeletion(userID string, password string) (AccountDeletion, error)
+	CancelDeletion(userID string) error
+	ProcessDueDeletions() error
+}
+
+type IAdminRepository interface {
+	SearchUsers(filter UserFilter) ([]User, int64, error)
+	FetchSessions(userID int64, limit int) ([]Token, error)
+	RecentPosts(userID int64, limit int) ([]Blog, error)
+	RecentComments(userID int64, limit int) ([]Comment, error)
+	SetStatus(userID int64, status string, suspension Suspension) error
+	LiftExpiredSuspensions(now time.Time) (int64, error)
+	ClearPassword(userID int64) error
+	ClearEmailStatus(userID int64) error
+}
+
+type IAdminUsecase interface {
+	ListUsers(filter UserFilter) (AdminUserPage, error)
+	Sessions(id string) ([]SessionView, error)
+	Activity(id string) (UserActivity, error)
+	Suspend(actor Principal, id string, suspension Suspension) error
+	Reinstate(actor Principal, id string) error
+	ForcePasswordReset(actor Principal, id string) error
+	Impersonate(actor Principal, id string, reason string) (string, error)
+	HardDelete(actor Principal, id string) error
+	ClearEmailStatus(actor Principal, id string) error
+	LiftExpiredSuspensions() error
+}
+
+// IAuditRecorder appends to the audit log. Record fills in CreatedAt and the
+// hash chain fields.
+type IAuditRecorder interface {
+	Record(event *AuditEvent) error
+}
+
+type IAuditRepository interface {
+	IAuditRecorder
+	FetchByUser(userID int64, limit int) ([]AuditEvent, error)
+	Search(filter AuditFilter) ([]AuditEvent, int64, error)
+	FetchAfter(id int64, limit int) ([]AuditEvent, error)
+}
+
+// IAuditSink receives a copy of every audit event once it is stored.
+type IAuditSink interface {
+	Write(event AuditEvent) error
+}
+
+type IAuditUsecase interface {
+	Search(filter AuditFilter) (AuditEventPage, error)
+	Verify() (AuditVerification, error)
+}
+
+type IProfileRepository interface {
+	FetchPrivacy(userID int64) (ProfilePrivacy, error)
+	SavePrivacy(privacy *ProfilePrivacy) error
+	AuthorStats(userID int64) (AuthorStats, error)
+	FetchPostsByUser(userID int64, offset int, limit int) ([]Blog, error)
+	Follow(followerID int64, followeeID int64) (bool, error)
+	Unfollow(followerID int64, followeeID int64) error
+}
+
+type IProfileUsecase interface {
+	PublicProfile(username string, page int, limit int) (PublicProfile, error)
+	Privacy(userID string) (ProfilePrivacy, error)
+	UpdatePrivacy(userID string, privacy ProfilePrivacy) (ProfilePrivacy, error)
+	Follow(followerID string, username string) error
+	Unfollow(followerID string, username string) error
+}
+
+type IEmailChangeRepository interface {
+	Save(request *EmailChangeRequest) error
+	Consume(tokenHash string) (EmailChangeRequest, error)
+}
+
+type ITwoFactorRepository interface {
+	FetchByUserID(userID int64) (TwoFactor, error)
+	Save(twoFactor *TwoFactor) error
+	Delete(userID int64) error
+	ReplaceRecoveryCodes(userID int64, codeHashes []string) error
+	FetchUnusedRecoveryCodes(userID int64) ([]RecoveryCode, error)
+	MarkRecoveryCodeUsed(id int64) error
+}
+
+type IMagicLinkUsecase interface {
+	RequestLink(email string) error
+	Verify(token string, client ClientInfo) (string, string, error)
+}
+
+type ITwoFactorUsecase interface {
+	Enroll(userID string) (string, string, error)
+	Confirm(userID string, code string) ([]string, error)
+	Disable(userID string, code string) error
+	VerifyLogin(challengeToken string, code string, client ClientInfo) (string, string, error)
+	Reset(actor Principal, userID string) error
+}
+
+type ILoginAttemptStore interface {
+	Fetch(key string) (LoginAttempt, error)
+	RecordFailure(key string, at time.Time) (LoginAttempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+// IRateLimiter allows a fixed number of calls per key and window. Allow
+// returns how long the caller has to wait, zero if the call may proceed.
+type IRateLimiter interface {
+	Allow(key string) (time.Duration, error)
+}
+
+type ILoginThrottler interface {
+	Check(account string, clientIP string) (time.Duration, error)
+	RegisterFailure(account string, clientIP string) (bool, error)
+	RegisterSuccess(account string) error
+	Unlock(account string) error
+}
+
 type IUserController interface {
 	Register(ctx *context.Context)
 	ActivateAccount(ctx *context.Context)
 	Login(ctx *context.Context)
+	Logout(ctx *context.Context)
 	GetProfile(ctx *context.Context)
 	UpdateProfile(ctx *context.Context)
 	RefreshToken(ctx *context.Context)
 	ResetPassword(ctx *context.Context)
 	ForgotPassword(ctx *context.Context)
 	UpdatePasswordDirect(ctx *context.Context)
+	CheckPassword(ctx *context.Context)
 }
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttempt tracks consecutive failed logins for a throttling key, which is
+// either an account or a client IP.
+type LoginAttempt struct {
+	gorm.Model
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Key           string    `gorm:"type:varchar(255);uniqueIndex" json:"key"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+	CreatedAt     time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt     time.Time `json:"updated_at"` // auto set on update
+}
+
+// LoginAttemptAccountKey is the key failures against account are counted
+// under, account being a user id.
+func LoginAttemptAccountKey(account string) string { return "account:" + account }
+
+// LoginThrottledError is returned by Login while an account or IP has to wait
+// before trying again, either because of backoff or a temporary lockout.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry in %d seconds", int(e.RetryAfter.Seconds()+0.5))
+}
+
+// RateLimitedError is returned when a caller exceeded a request quota, for
+// example how many magic links may be sent to one address.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("too many requests, retry in %d seconds", int(e.RetryAfter.Seconds()+0.5))
+}
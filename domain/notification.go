@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification types, one per kind of event a user can be told about.
+const (
+	NotificationComment  = "comment"
+	NotificationFollow   = "follow"
+	NotificationReaction = "reaction"
+)
+
+var NotificationTypes = []string{NotificationComment, NotificationFollow, NotificationReaction}
+
+// How a notification reaches the user's inbox, if at all.
+const (
+	EmailDeliveryOff       = "off"
+	EmailDeliveryImmediate = "immediate"
+	EmailDeliveryDigest    = "digest"
+)
+
+// NotificationEvent is something ActorID did that UserID should hear about.
+// Link points at whatever the event is about.
+type NotificationEvent struct {
+	Type    string
+	UserID  int64
+	ActorID int64
+	Title   string
+	Message string
+	Link    string
+}
+
+// Notification is an event as stored for its recipient. InApp rows are
+// listed in the notification center, DigestPending rows wait for the next
+// digest email.
+type Notification struct {
+	gorm.Model
+	ID            int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        int64      `gorm:"index" json:"-"` // Foreign key column
+	ActorID       int64      `json:"actor_id"`
+	Type          string     `gorm:"type:varchar(20)" json:"type"`
+	Title         string     `gorm:"type:varchar(255)" json:"title"`
+	Message       string     `gorm:"type:text" json:"message"`
+	Link          string     `gorm:"type:varchar(500)" json:"link,omitempty"`
+	InApp         bool       `json:"-"`
+	DigestPending bool       `gorm:"index" json:"-"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"` // auto set on insert
+	UpdatedAt     time.Time  `json:"-"`          // auto set on update
+}
+
+// NotificationPreference picks the channels for one notification type. Users
+// without a row for a type get DefaultNotificationPreference.
+type NotificationPreference struct {
+	gorm.Model
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	UserID    int64     `gorm:"uniqueIndex:idx_notification_preference" json:"-"`
+	Type      string    `gorm:"type:varchar(20);uniqueIndex:idx_notification_preference" json:"type"`
+	InApp     bool      `json:"in_app"`
+	Email     string    `gorm:"type:varchar(20)" json:"email"`
+	CreatedAt time.Time `json:"-"` // auto set on insert
+	UpdatedAt time.Time `json:"-"` // auto set on update
+}
+
+// DefaultNotificationPreference shows everything in the app and gathers it
+// into the digest rather than sending an email per event.
+func DefaultNotificationPreference(userID int64, notificationType string) NotificationPreference {
+	return NotificationPreference{UserID: userID, Type: notificationType, InApp: true, Email: EmailDeliveryDigest}
+}
+
+// NotificationFilter selects a page of one user's notification center.
+type NotificationFilter struct {
+	UserID     int64
+	UnreadOnly bool
+	Page       int
+	Limit      int
+}
+
+type NotificationPage struct {
+	Items  []Notification `json:"items"`
+	Page   int            `json:"page"`
+	Limit  int            `json:"limit"`
+	Total  int64          `json:"total"`
+	Unread int64          `json:"unread"`
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PasswordRuleLength      = "length"
+	PasswordRuleComposition = "composition"
+	PasswordRuleBreached    = "breached"
+	PasswordRuleStrength    = "strength"
+	PasswordRuleReuse       = "reuse"
+)
+
+// PasswordContext is what is known about the account a password is chosen
+// for. Passwords built from it are scored as if those parts were free.
+type PasswordContext struct {
+	Username string
+	Email    string
+}
+
+// PasswordRuleResult is the outcome of one policy rule, in a form clients can
+// show next to the password field.
+type PasswordRuleResult struct {
+	Rule    string `json:"rule"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// PasswordFeedback is the full verdict of a password policy. Score runs from
+// 0 (trivially guessable) to 4 (very hard to guess).
+type PasswordFeedback struct {
+	Valid       bool                 `json:"valid"`
+	Score       int                  `json:"score"`
+	Rules       []PasswordRuleResult `json:"rules"`
+	Suggestions []string             `json:"suggestions,omitempty"`
+}
+
+// Add appends a rule result and keeps Valid in sync with it.
+func (f *PasswordFeedback) Add(result PasswordRuleResult) {
+	f.Rules = append(f.Rules, result)
+	if !result.Passed {
+		f.Valid = false
+	}
+}
+
+// PasswordPolicyError is returned when a new password is refused. It carries
+// the per-rule feedback so the client can explain what to change.
+type PasswordPolicyError struct {
+	Feedback PasswordFeedback
+}
+
+func (e *PasswordPolicyError) Error() string {
+	var messages []string
+	for _, rule := range e.Feedback.Rules {
+		if !rule.Passed {
+			messages = append(messages, rule.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return "password does not meet the password policy"
+	}
+	return strings.Join(messages, "; ")
+}
+
+// PasswordHistory keeps the hashes of previous passwords so they cannot be
+// chosen again.
+type PasswordHistory struct {
+	gorm.Model
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64     `gorm:"index" json:"user_id"`
+	Hash      string    `gorm:"type:varchar(255)" json:"-"`
+	CreatedAt time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt time.Time `json:"updated_at"` // auto set on update
+}
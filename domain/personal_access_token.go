@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix marks tokens that AuthMiddleware must not try to
+// parse as a JWT.
+const PersonalAccessTokenPrefix = "bpat_"
+
+// PersonalAccessTokenScopes lists every scope a personal access token can be
+// granted. Interactive sessions are not restricted by scopes.
+var PersonalAccessTokenScopes = []string{
+	"blogs:read",
+	"blogs:write",
+	"comments:write",
+	"comments:moderate",
+	"profile:read",
+	"profile:write",
+	"users:admin",
+}
+
+// PersonalAccessToken is a long lived credential for automation. Only the
+// SHA-256 of the secret is stored; Prefix is kept so users can tell their
+// tokens apart in listings.
+type PersonalAccessToken struct {
+	gorm.Model
+	ID         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int64      `gorm:"index" json:"user_id"`                                   // Foreign key column
+	User       User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"` // GORM relation
+	Name       string     `gorm:"type:varchar(100)" json:"name"`
+	Prefix     string     `gorm:"type:varchar(20)" json:"prefix"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"type:varchar(500)" json:"scopes"` // space separated, as in OAuth
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"` // auto set on insert
+	UpdatedAt  time.Time  `json:"updated_at"` // auto set on update
+}
+
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// Active reports whether the token may still be used at the given time.
+func (t *PersonalAccessToken) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
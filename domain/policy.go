@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrForbidden        = errors.New("unauthorized to access this route")
+	ErrResourceNotFound = errors.New("resource not found")
+)
+
+// Principal is the authenticated caller as AuthMiddleware recorded it.
+// Client is only filled in where the action is audited.
+type Principal struct {
+	UserID string
+	Role   string
+	Client ClientInfo
+}
+
+// Policy decides whether principal may act on the resource with the given id.
+// It returns nil to allow, ErrForbidden to deny and ErrResourceNotFound when
+// there is nothing to act on. Any other error means no decision was made.
+type Policy func(ctx context.Context, principal Principal, resourceID string) error
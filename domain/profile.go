@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProfilePrivacy records which parts of a public profile a user has hidden.
+// Everything is shown by default, so a user without a row and the zero value
+// mean the same thing.
+type ProfilePrivacy struct {
+	gorm.Model
+	ID                 int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	UserID             int64     `gorm:"uniqueIndex" json:"-"`                                   // Foreign key column
+	User               User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"` // GORM relation
+	HideBio            bool      `json:"hide_bio"`
+	HideProfilePicture bool      `json:"hide_profile_picture"`
+	HideJoinDate       bool      `json:"hide_join_date"`
+	HidePostCount      bool      `json:"hide_post_count"`
+	HideFollowerCount  bool      `json:"hide_follower_count"`
+	HideReactions      bool      `json:"hide_reactions"`
+	HidePosts          bool      `json:"hide_posts"`
+	CreatedAt          time.Time `json:"-"`          // auto set on insert
+	UpdatedAt          time.Time `json:"updated_at"` // auto set on update
+}
+
+// Follow records that FollowerID follows FolloweeID.
+type Follow struct {
+	gorm.Model
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	FollowerID int64     `gorm:"uniqueIndex:idx_follow_pair" json:"follower_id"`
+	FolloweeID int64     `gorm:"uniqueIndex:idx_follow_pair;index" json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt  time.Time `json:"updated_at"` // auto set on update
+}
+
+type AuthorStats struct {
+	PostCount         int64
+	FollowerCount     int64
+	ReactionsReceived int64
+}
+
+// PublicProfile is what anyone may see of a user. Fields the user has hidden
+// are left nil and dropped from the JSON.
+type PublicProfile struct {
+	Username          string          `json:"username"`
+	Bio               *string         `json:"bio,omitempty"`
+	ProfilePicture    *string         `json:"profile_picture,omitempty"`
+	JoinedAt          *time.Time      `json:"joined_at,omitempty"`
+	PostCount         *int64          `json:"post_count,omitempty"`
+	FollowerCount     *int64          `json:"follower_count,omitempty"`
+	ReactionsReceived *int64          `json:"reactions_received,omitempty"`
+	Posts             *PublicPostPage `json:"posts,omitempty"`
+}
+
+type PublicPost struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	ViewCount int       `json:"view_count"`
+	Likes     int       `json:"likes"`
+	Dislikes  int       `json:"dislikes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PublicPostPage reports HasMore instead of a total so that a hidden post
+// count cannot be read off the pagination.
+type PublicPostPage struct {
+	Items   []PublicPost `json:"items"`
+	Page    int          `json:"page"`
+	Limit   int          `json:"limit"`
+	HasMore bool         `json:"has_more"`
+}
@@ -0,0 +1,126 @@
+package domain
+
+import "errors"
+
+const (
+	RoleReader     = "reader"
+	RoleAuthor     = "author"
+	RoleEditor     = "editor"
+	RoleModerator  = "moderator"
+	RoleAdmin      = "admin"
+	RoleSuperadmin = "superadmin"
+
+	// DefaultRole is given to every new account.
+	DefaultRole = RoleAuthor
+)
+
+const (
+	PermissionBlogsRead        = "blogs:read"
+	PermissionBlogsWrite       = "blogs:write"
+	PermissionBlogsEditAny     = "blogs:edit_any"
+	PermissionBlogsDeleteAny   = "blogs:delete_any"
+	PermissionCommentsWrite    = "comments:write"
+	PermissionCommentsModerate = "comments:moderate"
+	PermissionUsersRead        = "users:read"
+	PermissionUsersManage      = "users:manage"
+	PermissionRolesAssign      = "roles:assign"
+	// PermissionRolesAssignAdmin is needed on top of PermissionRolesAssign to
+	// hand out or take away the admin and superadmin roles.
+	PermissionRolesAssignAdmin = "roles:assign_admin"
+)
+
+// ErrRoleNotAllowed is returned when the acting user may not hand out or take
+// away the role in question.
+var ErrRoleNotAllowed = errors.New("not allowed to assign this role")
+
+// ErrLastSuperadmin is returned when a role change would leave the platform
+// without a superadmin.
+var ErrLastSuperadmin = errors.New("cannot demote the last superadmin")
+
+// Roles lists every role from least to most privileged.
+var Roles = []string{RoleReader, RoleAuthor, RoleEditor, RoleModerator, RoleAdmin, RoleSuperadmin}
+
+// RolePermissions holds the complete permission set of each role. Roles do
+// not inherit from each other, so every grant is spelled out here.
+var RolePermissions = map[string][]string{
+	RoleReader: {
+		PermissionBlogsRead,
+		PermissionCommentsWrite,
+	},
+	RoleAuthor: {
+		PermissionBlogsRead,
+		PermissionCommentsWrite,
+		PermissionBlogsWrite,
+	},
+	RoleEditor: {
+		PermissionBlogsRead,
+		PermissionCommentsWrite,
+		PermissionBlogsWrite,
+		PermissionBlogsEditAny,
+		PermissionBlogsDeleteAny,
+	},
+	RoleModerator: {
+		PermissionBlogsRead,
+		PermissionCommentsWrite,
+		PermissionBlogsWrite,
+		PermissionCommentsModerate,
+	},
+	RoleAdmin: {
+		PermissionBlogsRead,
+		PermissionCommentsWrite,
+		PermissionBlogsWrite,
+		PermissionBlogsEditAny,
+		PermissionBlogsDeleteAny,
+		PermissionCommentsModerate,
+		PermissionUsersRead,
+		PermissionUsersManage,
+		PermissionRolesAssign,
+	},
+	RoleSuperadmin: {
+		PermissionBlogsRead,
+		PermissionCommentsWrite,
+		PermissionBlogsWrite,
+		PermissionBlogsEditAny,
+		PermissionBlogsDeleteAny,
+		PermissionCommentsModerate,
+		PermissionUsersRead,
+		PermissionUsersManage,
+		PermissionRolesAssign,
+		PermissionRolesAssignAdmin,
+	},
+}
+
+// legacyRoles maps the values stored before roles were introduced.
+var legacyRoles = map[string]string{
+	"user": RoleAuthor,
+}
+
+// NormalizeRole resolves legacy role names. Unknown roles come back as is and
+// carry no permissions.
+func NormalizeRole(role string) string {
+	if mapped, ok := legacyRoles[role]; ok {
+		return mapped
+	}
+	return role
+}
+
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+func RoleHasPermission(role string, permission string) bool {
+	for _, granted := range RolePermissions[NormalizeRole(role)] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PrivilegedRole reports whether assigning or removing the role requires
+// PermissionRolesAssignAdmin.
+func PrivilegedRole(role string) bool {
+	role = NormalizeRole(role)
+	return role == RoleAdmin || role == RoleSuperadmin
+}
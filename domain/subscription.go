@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SubscriptionStatusPending      = "pending"
+	SubscriptionStatusActive       = "active"
+	SubscriptionStatusUnsubscribed = "unsubscribed"
+)
+
+var ErrSubscriptionTokenInvalid = errors.New("invalid or expired link")
+
+// DigestConfig controls the weekly digest. Subscribers are due once Period
+// has passed since their last digest, and a digest lists at most MaxPosts.
+type DigestConfig struct {
+	Period     time.Duration
+	MaxPosts   int
+	ConfirmTTL time.Duration // how long a newsletter confirmation link works
+	// UnsubscribeSecret keys the HMAC unsubscribe tokens are derived with.
+	UnsubscribeSecret []byte
+}
+
+// Subscription is a digest subscriber. Signed in users subscribe with their
+// account and get posts from the authors they follow. Anyone else subscribes
+// by email alone and has to confirm the address first. Either kind can
+// follow Tags.
+//
+// The unsubscribe token is derived from the subscription id, so every
+// digest carries the same link. Only its hash is kept, to look the
+// subscription up by; it is filled in when the first digest goes out.
+type Subscription struct {
+	gorm.Model
+	ID                   int64      `gorm:"primaryKey;autoIncrement" json:"-"`
+	UserID               *int64     `gorm:"uniqueIndex" json:"-"`                                 // Foreign key column, nil for email-only subscribers
+	Email                *string    `gorm:"type:varchar(255);uniqueIndex" json:"email,omitempty"` // nil for account subscribers, who get mail at their current address
+	Tags                 []string   `gorm:"type:text;serializer:json" json:"tags"`
+	Status               string     `gorm:"type:varchar(20);index" json:"status"`
+	ConfirmTokenHash     *string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	ConfirmExpiresAt     *time.Time `json:"-"`
+	UnsubscribeTokenHash *string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	ConfirmedAt          *time.Time `json:"confirmed_at,omitempty"`
+	LastSentAt           *time.Time `json:"last_sent_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"` // auto set on insert
+	UpdatedAt            time.Time  `json:"-"`          // auto set on update
+}
+
+// DigestPost is a post as it is listed in a digest.
+type DigestPost struct {
+	ID        int64
+	Title     string
+	Content   string
+	Author    string
+	Likes     int
+	ViewCount int
+}
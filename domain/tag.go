@@ -25,3 +25,25 @@ type Tag_Blog struct {
 	CreatedAt time.Time `json:"created_at"`                                     // auto set on insert
 	UpdatedAt time.Time `json:"updated_at"`                                     // auto set on update
 }
+
+// TagUsage is an existing tag and how many posts carry it.
+type TagUsage struct {
+	ID    int64
+	Name  string
+	Posts int64
+}
+
+// Keyword is a term that stands out in a text, scored from 0 to 1.
+type Keyword struct {
+	Term  string
+	Score float64
+}
+
+// TagSuggestion is a tag proposed for a draft. Existing tags are ranked
+// above new ones so the same topic keeps the same tag.
+type TagSuggestion struct {
+	Name     string  `json:"name"`
+	Existing bool    `json:"existing"`
+	Posts    int64   `json:"posts"`
+	Score    float64 `json:"score"`
+}
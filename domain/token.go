@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -22,5 +23,38 @@ type Token struct {
 type TokenClaims struct {
 	UserID string `json:"user_id"`
 	UserRole string `json:"user_role"`
+	TokenType string `json:"token_type,omitempty"`
+	Scopes []string `json:"scopes,omitempty"` // only set for personal access tokens
+	ImpersonatorID string `json:"impersonator_id,omitempty"` // admin acting as the user, see GenerateImpersonationToken
 	jwt.RegisteredClaims
-}
\ No newline at end of file
+}
+
+// RevokedToken is an entry of the jti denylist. Rows can be purged once
+// ExpiresAt has passed since the token would be rejected anyway.
+type RevokedToken struct {
+	gorm.Model
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	JTI       string    `gorm:"type:varchar(255);uniqueIndex" json:"jti"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"` // auto set on insert
+	UpdatedAt time.Time `json:"updated_at"` // auto set on update
+}
+
+// ErrTokenUsed is returned when a single-use token was already redeemed.
+var ErrTokenUsed = errors.New("token already used")
+
+type JSONWebKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // EC / OKP curve
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
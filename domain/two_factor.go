@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactor struct {
+	gorm.Model
+	ID           int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       int64      `gorm:"uniqueIndex" json:"user_id"`                    // Foreign key column
+	User         User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // GORM relation
+	Secret       string     `gorm:"type:varchar(255)" json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"` // last accepted TOTP time step, prevents code replay
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at"` // auto set on insert
+	UpdatedAt    time.Time  `json:"updated_at"` // auto set on update
+}
+
+type RecoveryCode struct {
+	gorm.Model
+	ID        int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64      `gorm:"index" json:"user_id"`                          // Foreign key column
+	User      User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // GORM relation
+	CodeHash  string     `gorm:"type:varchar(255)" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"` // auto set on insert
+	UpdatedAt time.Time  `json:"updated_at"` // auto set on update
+}
+
+// MFARequiredError is returned by Login when the password was correct but the
+// account has two-factor authentication enabled. The challenge token must be
+// exchanged together with a TOTP or recovery code for the real token pair.
+type MFARequiredError struct {
+	ChallengeToken string
+}
+
+func (e *MFARequiredError) Error() string {
+	return "two-factor authentication required"
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,15 +9,99 @@ import (
 
 type User struct {
 	gorm.Model
-	ID             int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	Username       string    `gorm:"type:varchar(255)" json:"username"`
-	Email          string    `gorm:"type:varchar(500)" json:"email"`
-	Password       string    `gorm:"type:varchar(255)" json:"_"`
-	Role           string    `gorm:"type:varchar(255)" json:"role"`
-	Bio            string    `json:"bio"`
-	ProfilePicture string    `gorm:"type:varchar(500)" json:"profile_picture"`
-	Phone          string    `gorm:"type:varchar(255)" json:"phone"`
-	Status         string    `gorm:"type:varchar(255)" json:"status"`
-	CreatedAt      time.Time `json:"created_at"` // auto set on insert
-	UpdatedAt      time.Time `json:"updated_at"` // auto set on update
+	ID             int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username       string `gorm:"type:varchar(255)" json:"username"`
+	Email          string `gorm:"type:varchar(500)" json:"email"`
+	Password       string `gorm:"type:varchar(255)" json:"-"`
+	Role           string `gorm:"type:varchar(255)" json:"role"`
+	Bio            string `json:"bio"`
+	ProfilePicture string `gorm:"type:varchar(500)" json:"profile_picture"`
+	Phone          string `gorm:"type:varchar(255)" json:"phone"`
+	Status         string `gorm:"type:varchar(255)" json:"status"`
+	Locale         string `gorm:"type:varchar(16)" json:"locale"` // picks translated emails, empty for the default
+	// SuspensionReason and SuspendedUntil describe the current suspension or
+	// ban; a ban has no end date.
+	SuspensionReason string     `gorm:"type:varchar(500)" json:"suspension_reason"`
+	SuspendedUntil   *time.Time `json:"suspended_until"`
+	// EmailStatus is set once the address hard-bounces or the user marks
+	// our mail as spam, and nothing is sent to it until it is cleared.
+	EmailStatus       string     `gorm:"type:varchar(20);index" json:"-"`
+	EmailStatusReason string     `gorm:"type:varchar(500)" json:"-"`
+	EmailStatusAt     *time.Time `json:"-"`
+	CreatedAt         time.Time  `json:"created_at"` // auto set on insert
+	UpdatedAt         time.Time  `json:"updated_at"` // auto set on update
+}
+
+// UserView is a user as shown by GET /users/:id, without the password hash
+// and the email deliverability fields.
+type UserView struct {
+	ID               int64      `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	Bio              string     `json:"bio"`
+	ProfilePicture   string     `json:"profile_picture"`
+	Phone            string     `json:"phone"`
+	Status           string     `json:"status"`
+	Locale           string     `json:"locale"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func NewUserView(u User) UserView {
+	return UserView{
+		ID:               u.ID,
+		Username:         u.Username,
+		Email:            u.Email,
+		Role:             NormalizeRole(u.Role),
+		Bio:              u.Bio,
+		ProfilePicture:   u.ProfilePicture,
+		Phone:            u.Phone,
+		Status:           u.Status,
+		Locale:           u.Locale,
+		SuspensionReason: u.SuspensionReason,
+		SuspendedUntil:   u.SuspendedUntil,
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
+	}
+}
+
+// ErrAccountSuspended is returned when a suspended or banned user tries to
+// sign in.
+var ErrAccountSuspended = errors.New("this account is suspended")
+
+// Suspended reports whether the user is banned, or suspended and the
+// suspension has not run out yet.
+func (u User) Suspended(now time.Time) bool {
+	switch u.Status {
+	case "banned":
+		return true
+	case "suspended":
+		return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+	}
+	return false
+}
+
+// ProfileUpdate holds the fields users may change on their own profile. Nil
+// fields are left as they are. Email is never written directly; a change
+// has to be confirmed from the new address first.
+type ProfileUpdate struct {
+	Username       *string
+	Email          *string
+	Bio            *string
+	ProfilePicture *string
+	Phone          *string
+	Locale         *string
+}
+
+// ValidationError reports which field of a request was rejected and why.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
 }
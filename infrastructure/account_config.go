@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+func DefaultAccountDeletionConfig() domain.AccountDeletionConfig {
+	return domain.AccountDeletionConfig{
+		Policy:      domain.DeletionPolicyAnonymize,
+		GracePeriod: 14 * 24 * time.Hour,
+	}
+}
+
+func LoadAccountDeletionConfigFromEnv() (domain.AccountDeletionConfig, error) {
+	config := DefaultAccountDeletionConfig()
+
+	switch policy := os.Getenv("ACCOUNT_DELETION_POLICY"); policy {
+	case "":
+	case domain.DeletionPolicyAnonymize, domain.DeletionPolicyDelete:
+		config.Policy = policy
+	default:
+		return domain.AccountDeletionConfig{}, errors.New("invalid value for ACCOUNT_DELETION_POLICY")
+	}
+
+	if value := os.Getenv("ACCOUNT_DELETION_GRACE"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return domain.AccountDeletionConfig{}, errors.New("invalid duration for ACCOUNT_DELETION_GRACE")
+		}
+		config.GracePeriod = d
+	}
+
+	return config, nil
+}
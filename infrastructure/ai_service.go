@@ -1,5 +1,289 @@
 package infrastructure
 
-func ConnectClient() {
-	
-}
\ No newline at end of file
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	// AIProviderOpenAI talks to the OpenAI chat completions API, or to any
+	// server speaking it, such as a local Ollama or vLLM.
+	AIProviderOpenAI = "openai"
+	// AIProviderFake answers locally and deterministically, for development
+	// and tests.
+	AIProviderFake = "fake"
+)
+
+const maxAIResponseSize = 1 << 20
+
+func DefaultAIConfig() domain.AIConfig {
+	return domain.AIConfig{
+		BaseURL:     "https://api.openai.com/v1",
+		Model:       "gpt-4o-mini",
+		Timeout:     30 * time.Second,
+		MaxRetries:  2,
+		RetryBase:   time.Second,
+		MaxTokens:   1024,
+		Temperature: 0.7,
+	}
+}
+
+func LoadAIConfigFromEnv() (domain.AIConfig, error) {
+	config := DefaultAIConfig()
+
+	config.Provider = os.Getenv("AI_PROVIDER")
+	switch config.Provider {
+	case "", AIProviderOpenAI, AIProviderFake:
+	default:
+		return domain.AIConfig{}, errors.New("invalid value for AI_PROVIDER")
+	}
+	config.APIKey = os.Getenv("AI_API_KEY")
+	if value := os.Getenv("AI_BASE_URL"); value != "" {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return domain.AIConfig{}, errors.New("invalid value for AI_BASE_URL")
+		}
+		config.BaseURL = strings.TrimSuffix(value, "/")
+	}
+	if value := os.Getenv("AI_MODEL"); value != "" {
+		config.Model = value
+	}
+
+	if value := os.Getenv("AI_MAX_RETRIES"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return domain.AIConfig{}, errors.New("invalid value for AI_MAX_RETRIES")
+		}
+		config.MaxRetries = n
+	}
+	if value := os.Getenv("AI_MAX_TOKENS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return domain.AIConfig{}, errors.New("invalid value for AI_MAX_TOKENS")
+		}
+		config.MaxTokens = n
+	}
+	if value := os.Getenv("AI_TEMPERATURE"); value != "" {
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || t < 0 || t > 2 {
+			return domain.AIConfig{}, errors.New("invalid value for AI_TEMPERATURE")
+		}
+		config.Temperature = t
+	}
+
+	durations := map[string]*time.Duration{
+		"AI_TIMEOUT":    &config.Timeout,
+		"AI_RETRY_BASE": &config.RetryBase,
+	}
+	for envVar, target := range durations {
+		if value := os.Getenv(envVar); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return domain.AIConfig{}, errors.New("invalid duration for " + envVar)
+			}
+			*target = d
+		}
+	}
+
+	return config, nil
+}
+
+// NewAIInfrastructure returns the provider config names, or nil when the
+// assistant is turned off.
+func NewAIInfrastructure(config domain.AIConfig) domain.IAIInfrastructure {
+	switch config.Provider {
+	case AIProviderOpenAI:
+		return NewOpenAIClient(config)
+	case AIProviderFake:
+		return NewFakeAIClient()
+	default:
+		return nil
+	}
+}
+
+// OpenAIClient completes text through a chat completions endpoint. Each
+// attempt gets Timeout; timeouts, 429s and server errors are retried with
+// exponential backoff, or after the Retry-After the server asked for when
+// that is longer.
+type OpenAIClient struct {
+	config domain.AIConfig
+	client *http.Client
+}
+
+func NewOpenAIClient(config domain.AIConfig) *OpenAIClient {
+	return &OpenAIClient{
+		config: config,
+		client: &http.Client{},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// aiProviderError is a failed attempt. Retryable failures may succeed when
+// sent again, after RetryAfter if the provider named a delay.
+type aiProviderError struct {
+	message    string
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *aiProviderError) Error() string {
+	return e.message
+}
+
+func (c *OpenAIClient) Complete(ctx context.Context, request domain.AIRequest) (string, error) {
+	maxTokens := request.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = c.config.MaxTokens
+	}
+	payload, err := json.Marshal(chatCompletionRequest{
+		Model: c.config.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: request.Instructions},
+			{Role: "user", Content: request.Input},
+		},
+		MaxTokens:   maxTokens,
+		Temperature: c.config.Temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; ; attempt++ {
+		text, err := c.attempt(ctx, payload)
+		var providerErr *aiProviderError
+		if err == nil || !errors.As(err, &providerErr) || !providerErr.retryable || attempt >= c.config.MaxRetries {
+			return text, err
+		}
+		// an author is waiting on the answer, so a provider asking for a
+		// longer break than an attempt may take is not waited for
+		if providerErr.retryAfter > c.config.Timeout {
+			return "", err
+		}
+
+		delay := c.config.RetryBase << attempt
+		if providerErr.retryAfter > delay {
+			delay = providerErr.retryAfter
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *OpenAIClient) attempt(ctx context.Context, payload []byte) (string, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, c.config.BaseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// the caller giving up is final, a timeout or a dropped connection
+		// is worth another try
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if attemptCtx.Err() != nil {
+			return "", &aiProviderError{message: "ai provider timed out", retryable: true}
+		}
+		return "", &aiProviderError{message: "unable to reach ai provider: " + err.Error(), retryable: true}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAIResponseSize))
+	if err != nil {
+		return "", &aiProviderError{message: "unable to read ai provider response", retryable: true}
+	}
+	var completion chatCompletionResponse
+	decodeErr := json.Unmarshal(body, &completion)
+
+	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("ai provider answered %d", resp.StatusCode)
+		if decodeErr == nil && completion.Error != nil && completion.Error.Message != "" {
+			message += ": " + completion.Error.Message
+		}
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return "", &aiProviderError{message: message, retryable: retryable, retryAfter: retryAfter}
+	}
+	if decodeErr != nil || len(completion.Choices) == 0 {
+		return "", errors.New("invalid ai provider response")
+	}
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
+
+// FakeAIClient answers every request with its input, so local setups and
+// tests get stable output without a provider. It keeps the requests it
+// was sent.
+type FakeAIClient struct {
+	mu       sync.Mutex
+	requests []domain.AIRequest
+}
+
+func NewFakeAIClient() *FakeAIClient {
+	return &FakeAIClient{}
+}
+
+func (c *FakeAIClient) Complete(ctx context.Context, request domain.AIRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, request)
+	return strings.TrimSpace(request.Input), nil
+}
+
+// Requests returns the requests received so far, oldest first.
+func (c *FakeAIClient) Requests() []domain.AIRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]domain.AIRequest(nil), c.requests...)
+}
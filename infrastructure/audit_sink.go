@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/blog-platform/domain"
+)
+
+// FileAuditSink appends audit events to a file as JSON lines, for shipping to
+// a log pipeline or keeping an off-database copy of the chain.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+func (s *FileAuditSink) Write(event domain.AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *FileAuditSink) Close() error {
+	return s.file.Close()
+}
@@ -1,7 +1,11 @@
 package infrastructure
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/blog-platform/domain"
 	"github.com/gin-gonic/gin"
@@ -9,6 +13,12 @@ import (
 
 type Middleware struct {
 	tokenInfra domain.IJWTInfrastructure
+	// PersonalAccessTokens, when set, lets AuthMiddleware accept personal
+	// access tokens in addition to access JWTs.
+	PersonalAccessTokens domain.IPersonalAccessTokenAuthenticator
+	// Audit, when set, records denied requests and every request made while
+	// impersonating a user.
+	Audit domain.IAuditRecorder
 }
 
 func NewMiddleware(tokenInfra domain.IJWTInfrastructure) *Middleware {
@@ -19,7 +29,13 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 
-		claims, err := m.tokenInfra.ValidateAccessToken(authHeader)
+		var claims *domain.TokenClaims
+		var err error
+		if secret, ok := personalAccessToken(authHeader); ok && m.PersonalAccessTokens != nil {
+			claims, err = m.PersonalAccessTokens.Authenticate(secret)
+		} else {
+			claims, err = m.tokenInfra.ValidateAccessToken(authHeader)
+		}
 		if err != nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			ctx.Abort()
@@ -31,16 +47,91 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 
 		ctx.Set("user_id", userID)
 		ctx.Set("role", role)
+		if claims.TokenType == "personal_access" {
+			ctx.Set("scopes", claims.Scopes)
+		}
+		if claims.ImpersonatorID != "" {
+			ctx.Set("impersonator_id", claims.ImpersonatorID)
+			ctx.Header("X-Impersonated-By", claims.ImpersonatorID)
+			targetID, _ := strconv.ParseInt(userID, 10, 64)
+			m.audit(ctx, claims.ImpersonatorID, "impersonation.request", domain.AuditOutcomeSuccess, targetID, nil)
+		}
 
 		ctx.Next()
 	}
 }
 
+// ScopeMiddleware restricts personal access tokens to routes covered by one
+// of their scopes. Requests authenticated with an interactive login pass.
+func (m *Middleware) ScopeMiddleware(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, ok := ctx.Get("scopes")
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		scopes, _ := value.([]string)
+		for _, granted := range scopes {
+			if granted == scope {
+				ctx.Next()
+				return
+			}
+		}
+
+		m.denied(ctx, "missing scope "+scope)
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "token is missing the " + scope + " scope"})
+		ctx.Abort()
+	}
+}
+
+// SessionOnlyMiddleware keeps personal access tokens away from account
+// security settings, so a leaked CI token cannot be used to mint more tokens
+// or lock the owner out. Admins impersonating a user are kept out as well.
+func (m *Middleware) SessionOnlyMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := ctx.Get("scopes"); ok {
+			m.denied(ctx, "personal access token")
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "this action requires an interactive login"})
+			ctx.Abort()
+			return
+		}
+		if ctx.GetString("impersonator_id") != "" {
+			m.denied(ctx, "impersonation")
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "this action is not available while impersonating"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func personalAccessToken(authHeader string) (string, bool) {
+	secret, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || !strings.HasPrefix(secret, domain.PersonalAccessTokenPrefix) {
+		return "", false
+	}
+	return secret, true
+}
+
+// AdminMiddleware admits any role that can manage users.
 func (m *Middleware) AdminMiddleware() gin.HandlerFunc {
+	return m.RequirePermission(domain.PermissionUsersManage)
+}
+
+// RequirePermission admits requests whose role grants permission. It expects
+// AuthMiddleware to have run first and answers 401 if it has not.
+func (m *Middleware) RequirePermission(permission string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		role, ok := ctx.Get("role")
-		if !ok || role != "admin" {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "unauthorized to access this route"})
+		principal, ok := authenticatedPrincipal(ctx)
+		if !ok {
+			abortUnauthenticated(ctx)
+			return
+		}
+		if !domain.RoleHasPermission(principal.Role, permission) {
+			m.denied(ctx, "missing permission "+permission)
+			ctx.JSON(http.StatusForbidden, gin.H{"error": domain.ErrForbidden.Error()})
 			ctx.Abort()
 			return
 		}
@@ -49,17 +140,84 @@ func (m *Middleware) AdminMiddleware() gin.HandlerFunc {
 	}
 }
 
+// AccountOwnerMiddleware admits users acting on their own account only.
 func (m *Middleware) AccountOwnerMiddleware() gin.HandlerFunc {
+	return m.Authorize(UserPolicy(""))
+}
+
+// Authorize checks policy against the :id route parameter. A request that did
+// not pass AuthMiddleware gets a 401 instead of being judged as anonymous, so
+// a route that forgot AuthMiddleware fails closed.
+func (m *Middleware) Authorize(policy domain.Policy) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		id := ctx.Param("id")
-		userID, ok := ctx.Get("user_id")
+		principal, ok := authenticatedPrincipal(ctx)
+		if !ok {
+			abortUnauthenticated(ctx)
+			return
+		}
 
-		if !ok || userID != id {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "unauthorized to access this route"})
-			ctx.Abort()
+		err := policy(ctx.Request.Context(), principal, ctx.Param("id"))
+		switch {
+		case err == nil:
+			ctx.Next()
 			return
+		case errors.Is(err, domain.ErrForbidden):
+			m.denied(ctx, "policy")
+			ctx.JSON(http.StatusForbidden, gin.H{"error": domain.ErrForbidden.Error()})
+		case errors.Is(err, domain.ErrResourceNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": domain.ErrResourceNotFound.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "unable to authorize request"})
 		}
+		ctx.Abort()
+	}
+}
 
-		ctx.Next()
+func authenticatedPrincipal(ctx *gin.Context) (domain.Principal, bool) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		return domain.Principal{}, false
+	}
+	return domain.Principal{UserID: userID, Role: ctx.GetString("role")}, true
+}
+
+func abortUnauthenticated(ctx *gin.Context) {
+	ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+	ctx.Abort()
+}
+
+// denied records a 403. The target is the :id route parameter when the route
+// has one.
+func (m *Middleware) denied(ctx *gin.Context, reason string) {
+	targetID, _ := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	m.audit(ctx, ctx.GetString("user_id"), "access.denied", domain.AuditOutcomeDenied, targetID, map[string]interface{}{"reason": reason})
+}
+
+// audit is best effort, a request is never failed because it could not be
+// recorded.
+func (m *Middleware) audit(ctx *gin.Context, actorID string, action string, outcome string, targetID int64, metadata map[string]interface{}) {
+	if m.Audit == nil {
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["method"] = ctx.Request.Method
+	metadata["path"] = ctx.Request.URL.Path
+	encoded, _ := json.Marshal(metadata)
+
+	userAgent := ctx.Request.UserAgent()
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
 	}
+	actor, _ := strconv.ParseInt(actorID, 10, 64)
+	_ = m.Audit.Record(&domain.AuditEvent{
+		ActorID:   actor,
+		Action:    action,
+		TargetID:  targetID,
+		IP:        ctx.ClientIP(),
+		UserAgent: userAgent,
+		Outcome:   outcome,
+		Metadata:  string(encoded),
+	})
 }
@@ -0,0 +1,332 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/blog-platform/domain"
+)
+
+var errInvalidBounceReport = errors.New("invalid bounce report")
+
+// BounceParser reads delivery status notifications (RFC 3464), abuse
+// reports (RFC 5965) and the bounce webhooks of Amazon SES, SendGrid,
+// Mailgun and Postmark.
+type BounceParser struct{}
+
+func NewBounceParser() *BounceParser {
+	return &BounceParser{}
+}
+
+func (p *BounceParser) Parse(source string, payload []byte) ([]domain.BounceEvent, error) {
+	switch source {
+	case domain.BounceSourceDSN:
+		return parseReportMessage(payload)
+	case domain.BounceSourceSES:
+		return parseSESNotification(payload)
+	case domain.BounceSourceSendGrid:
+		return parseSendGridEvents(payload)
+	case domain.BounceSourceMailgun:
+		return parseMailgunEvent(payload)
+	case domain.BounceSourcePostmark:
+		return parsePostmarkEvent(payload)
+	default:
+		return nil, domain.ErrUnknownBounceSource
+	}
+}
+
+// parseReportMessage reads a multipart/report message, either a delivery
+// status notification or an ARF abuse report.
+func parseReportMessage(payload []byte) ([]domain.BounceEvent, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(payload))
+	if err != nil {
+		return nil, errInvalidBounceReport
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || params["boundary"] == "" {
+		return nil, errors.New("bounce report is not a multipart/report message")
+	}
+
+	var events []domain.BounceEvent
+	var feedback textproto.MIMEHeader
+	var originalTo string
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errInvalidBounceReport
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, errInvalidBounceReport
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			groups := headerGroups(content)
+			if len(groups) < 2 {
+				return nil, errInvalidBounceReport
+			}
+			// the first group describes the message, the rest one recipient each
+			for _, fields := range groups[1:] {
+				if event, ok := dsnRecipientEvent(fields); ok {
+					events = append(events, event)
+				}
+			}
+		case "message/feedback-report":
+			groups := headerGroups(content)
+			if len(groups) == 0 {
+				return nil, errInvalidBounceReport
+			}
+			feedback = groups[0]
+		case "message/rfc822", "text/rfc822-headers":
+			if original, err := mail.ReadMessage(bytes.NewReader(append(content, '\n', '\n'))); err == nil {
+				originalTo = original.Header.Get("To")
+			}
+		}
+	}
+
+	if feedback != nil {
+		recipient := feedback.Get("Original-Rcpt-To")
+		if recipient == "" {
+			recipient = originalTo
+		}
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, errors.New("abuse report does not name the recipient")
+		}
+		events = append(events, domain.BounceEvent{Email: address.Address, Type: domain.BounceComplaint, Reason: "feedback-type " + feedback.Get("Feedback-Type")})
+	}
+	return events, nil
+}
+
+// headerGroups parses blank line separated blocks of header fields.
+func headerGroups(content []byte) []textproto.MIMEHeader {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	var groups []textproto.MIMEHeader
+	for _, block := range strings.Split(string(content), "\n\n") {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		reader := textproto.NewReader(bufio.NewReader(strings.NewReader(strings.TrimLeft(block, "\n") + "\n\n")))
+		fields, err := reader.ReadMIMEHeader()
+		if err != nil && len(fields) == 0 {
+			continue
+		}
+		groups = append(groups, fields)
+	}
+	return groups
+}
+
+// dsnRecipientEvent classifies a per-recipient block. Only failed
+// deliveries with a permanent (5.x.x) status are hard bounces.
+func dsnRecipientEvent(fields textproto.MIMEHeader) (domain.BounceEvent, bool) {
+	recipient := fields.Get("Final-Recipient")
+	if recipient == "" {
+		recipient = fields.Get("Original-Recipient")
+	}
+	// "rfc822; jane@example.com"
+	if _, address, found := strings.Cut(recipient, ";"); found {
+		recipient = address
+	}
+	recipient = strings.Trim(strings.TrimSpace(recipient), "<>")
+	if recipient == "" {
+		return domain.BounceEvent{}, false
+	}
+
+	status := strings.TrimSpace(fields.Get("Status"))
+	reason := strings.TrimSpace(fields.Get("Diagnostic-Code"))
+	if reason == "" {
+		reason = "status " + status
+	}
+	switch strings.ToLower(strings.TrimSpace(fields.Get("Action"))) {
+	case "failed":
+		if strings.HasPrefix(status, "5.") {
+			return domain.BounceEvent{Email: recipient, Type: domain.BounceHard, Reason: reason}, true
+		}
+		return domain.BounceEvent{Email: recipient, Type: domain.BounceSoft, Reason: reason}, true
+	case "delayed":
+		return domain.BounceEvent{Email: recipient, Type: domain.BounceSoft, Reason: reason}, true
+	default:
+		return domain.BounceEvent{}, false
+	}
+}
+
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			Status         string `json:"status"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+// parseSESNotification accepts SES notifications as delivered by SNS, or
+// on their own when raw message delivery is turned on.
+func parseSESNotification(payload []byte) ([]domain.BounceEvent, error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, errInvalidBounceReport
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		// Visiting the link is left to an operator rather than fetching
+		// whatever URL the request names.
+		log.Printf("bounces: confirm the SNS subscription at %s", envelope.SubscribeURL)
+		return nil, nil
+	case "Notification":
+		payload = []byte(envelope.Message)
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return nil, errInvalidBounceReport
+	}
+	kind := notification.NotificationType
+	if kind == "" {
+		kind = notification.EventType
+	}
+
+	var events []domain.BounceEvent
+	switch kind {
+	case "Bounce":
+		bounceType := domain.BounceSoft
+		if notification.Bounce.BounceType == "Permanent" {
+			bounceType = domain.BounceHard
+		}
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			reason := recipient.DiagnosticCode
+			if reason == "" {
+				reason = notification.Bounce.BounceType + " bounce"
+			}
+			events = append(events, domain.BounceEvent{Email: recipient.EmailAddress, Type: bounceType, Reason: reason})
+		}
+	case "Complaint":
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			events = append(events, domain.BounceEvent{Email: recipient.EmailAddress, Type: domain.BounceComplaint, Reason: "complaint " + notification.Complaint.ComplaintFeedbackType})
+		}
+	}
+	return events, nil
+}
+
+type sendGridEvent struct {
+	Email  string `json:"email"`
+	Event  string `json:"event"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// parseSendGridEvents reads a batch from the SendGrid event webhook.
+// Blocked messages are rejections of the sender, not of the address.
+func parseSendGridEvents(payload []byte) ([]domain.BounceEvent, error) {
+	var batch []sendGridEvent
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return nil, errInvalidBounceReport
+	}
+
+	var events []domain.BounceEvent
+	for _, event := range batch {
+		switch {
+		case event.Event == "bounce" && event.Type != "blocked":
+			events = append(events, domain.BounceEvent{Email: event.Email, Type: domain.BounceHard, Reason: event.Reason})
+		case event.Event == "bounce", event.Event == "deferred":
+			events = append(events, domain.BounceEvent{Email: event.Email, Type: domain.BounceSoft, Reason: event.Reason})
+		case event.Event == "spamreport":
+			events = append(events, domain.BounceEvent{Email: event.Email, Type: domain.BounceComplaint, Reason: "spam report"})
+		}
+	}
+	return events, nil
+}
+
+type mailgunEvent struct {
+	EventData struct {
+		Event          string `json:"event"`
+		Severity       string `json:"severity"`
+		Recipient      string `json:"recipient"`
+		Reason         string `json:"reason"`
+		DeliveryStatus struct {
+			Description string `json:"description"`
+			Message     string `json:"message"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+func parseMailgunEvent(payload []byte) ([]domain.BounceEvent, error) {
+	var event mailgunEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, errInvalidBounceReport
+	}
+
+	data := event.EventData
+	switch data.Event {
+	case "failed":
+		reason := data.DeliveryStatus.Description
+		if reason == "" {
+			reason = data.DeliveryStatus.Message
+		}
+		if reason == "" {
+			reason = data.Reason
+		}
+		bounceType := domain.BounceSoft
+		if data.Severity == "permanent" {
+			bounceType = domain.BounceHard
+		}
+		return []domain.BounceEvent{{Email: data.Recipient, Type: bounceType, Reason: reason}}, nil
+	case "complained":
+		return []domain.BounceEvent{{Email: data.Recipient, Type: domain.BounceComplaint, Reason: "complaint"}}, nil
+	}
+	return nil, nil
+}
+
+type postmarkEvent struct {
+	RecordType  string `json:"RecordType"`
+	Type        string `json:"Type"`
+	Email       string `json:"Email"`
+	Description string `json:"Description"`
+}
+
+func parsePostmarkEvent(payload []byte) ([]domain.BounceEvent, error) {
+	var event postmarkEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, errInvalidBounceReport
+	}
+
+	switch {
+	case event.RecordType == "SpamComplaint" || event.Type == "SpamComplaint":
+		return []domain.BounceEvent{{Email: event.Email, Type: domain.BounceComplaint, Reason: "spam complaint"}}, nil
+	case event.RecordType == "Bounce" && (event.Type == "HardBounce" || event.Type == "BadEmailAddress"):
+		return []domain.BounceEvent{{Email: event.Email, Type: domain.BounceHard, Reason: event.Description}}, nil
+	case event.RecordType == "Bounce":
+		return []domain.BounceEvent{{Email: event.Email, Type: domain.BounceSoft, Reason: event.Description}}, nil
+	}
+	return nil, nil
+}
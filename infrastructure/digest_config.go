@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+func DefaultDigestConfig() domain.DigestConfig {
+	return domain.DigestConfig{
+		Period:     7 * 24 * time.Hour,
+		MaxPosts:   10,
+		ConfirmTTL: 48 * time.Hour,
+	}
+}
+
+func LoadDigestConfigFromEnv() (domain.DigestConfig, error) {
+	config := DefaultDigestConfig()
+
+	if value := os.Getenv("DIGEST_MAX_POSTS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return domain.DigestConfig{}, errors.New("invalid value for DIGEST_MAX_POSTS")
+		}
+		config.MaxPosts = n
+	}
+
+	durations := map[string]*time.Duration{
+		"DIGEST_PERIOD":          &config.Period,
+		"NEWSLETTER_CONFIRM_TTL": &config.ConfirmTTL,
+	}
+	for envVar, target := range durations {
+		if value := os.Getenv(envVar); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return domain.DigestConfig{}, errors.New("invalid duration for " + envVar)
+			}
+			*target = d
+		}
+	}
+
+	config.UnsubscribeSecret = []byte(os.Getenv("DIGEST_UNSUBSCRIBE_SECRET"))
+	if len(config.UnsubscribeSecret) == 0 {
+		return domain.DigestConfig{}, errors.New("DIGEST_UNSUBSCRIBE_SECRET must be set")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"os"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// dkimSignedHeaders are signed whenever the message has them. From is
+// required by RFC 6376, the rest keep the visible parts of the message from
+// being swapped in transit.
+var dkimSignedHeaders = []string{"From", "To", "Cc", "Reply-To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "List-Unsubscribe", "List-Unsubscribe-Post"}
+
+// DKIMSigner adds a DKIM-Signature header (RFC 6376) with relaxed/relaxed
+// canonicalization, using rsa-sha256 or, for Ed25519 keys, ed25519-sha256
+// (RFC 8463).
+type DKIMSigner struct {
+	Domain   string
+	Selector string
+
+	key crypto.Signer
+}
+
+func NewDKIMSigner(domain string, selector string, key crypto.Signer) (*DKIMSigner, error) {
+	if domain == "" || selector == "" {
+		return nil, errors.New("dkim domain and selector are required")
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		// RFC 8301 forbids verifiers from accepting anything shorter.
+		if k.N.BitLen() < 1024 {
+			return nil, errors.New("dkim rsa keys must be at least 1024 bits")
+		}
+	case ed25519.PrivateKey:
+	default:
+		return nil, errors.New("dkim keys must be rsa or ed25519")
+	}
+	return &DKIMSigner{Domain: domain, Selector: selector, key: key}, nil
+}
+
+// LoadDKIMSignerFromEnv returns nil when DKIM_DOMAIN, DKIM_SELECTOR and
+// DKIM_PRIVATE_KEY_FILE are all unset, so signing stays optional.
+func LoadDKIMSignerFromEnv() (*DKIMSigner, error) {
+	domain, selector, keyFile := os.Getenv("DKIM_DOMAIN"), os.Getenv("DKIM_SELECTOR"), os.Getenv("DKIM_PRIVATE_KEY_FILE")
+	if domain == "" && selector == "" && keyFile == "" {
+		return nil, nil
+	}
+	if domain == "" || selector == "" || keyFile == "" {
+		return nil, errors.New("DKIM_DOMAIN, DKIM_SELECTOR and DKIM_PRIVATE_KEY_FILE must be set together")
+	}
+	key, err := LoadDKIMKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewDKIMSigner(domain, selector, key)
+}
+
+// LoadDKIMKey reads a PEM encoded PKCS #8 RSA or Ed25519 key, or a PKCS #1
+// RSA key as written by older tools.
+func LoadDKIMKey(path string) (crypto.Signer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("dkim key file is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported dkim key type")
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in dkim key file", block.Type)
+	}
+}
+
+// DNSRecord returns the TXT record to publish at <selector>._domainkey.<domain>.
+func (s *DKIMSigner) DNSRecord() string {
+	switch key := s.key.Public().(type) {
+	case ed25519.PublicKey:
+		return "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(key)
+	default:
+		der, _ := x509.MarshalPKIXPublicKey(key)
+		return "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der)
+	}
+}
+
+// Sign returns msg with a DKIM-Signature header prepended. Line endings are
+// normalized to CRLF first, since that is what receivers hash.
+func (s *DKIMSigner) Sign(msg []byte) ([]byte, error) {
+	msg = normalizeCRLF(msg)
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, name := range dkimSignedHeaders {
+		// One entry per occurrence, so repeated fields are all covered.
+		for range parsed.Header[textproto.CanonicalMIMEHeaderKey(name)] {
+			keys = append(keys, name)
+		}
+	}
+	if len(keys) == 0 || keys[0] != "From" {
+		return nil, errors.New("message has no From header to sign")
+	}
+
+	var out bytes.Buffer
+	err = dkim.Sign(&out, bytes.NewReader(msg), &dkim.SignOptions{
+		Domain:                 s.Domain,
+		Selector:               s.Selector,
+		Signer:                 s.key,
+		HeaderCanonicalization: dkim.CanonicalizationRelaxed,
+		BodyCanonicalization:   dkim.CanonicalizationRelaxed,
+		HeaderKeys:             keys,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func normalizeCRLF(msg []byte) []byte {
+	msg = bytes.ReplaceAll(msg, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(msg, []byte("\n"), []byte("\r\n"))
+}
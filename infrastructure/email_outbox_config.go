@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+func DefaultEmailOutboxConfig() domain.EmailOutboxConfig {
+	return domain.EmailOutboxConfig{
+		Workers:      4,
+		BatchSize:    50,
+		MaxAttempts:  8,
+		RetryBase:    30 * time.Second,
+		RetryMax:     6 * time.Hour,
+		Lease:        5 * time.Minute,
+		PollInterval: 10 * time.Second,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+func LoadEmailOutboxConfigFromEnv() (domain.EmailOutboxConfig, error) {
+	config := DefaultEmailOutboxConfig()
+
+	counts := map[string]*int{
+		"EMAIL_WORKERS":      &config.Workers,
+		"EMAIL_BATCH_SIZE":   &config.BatchSize,
+		"EMAIL_MAX_ATTEMPTS": &config.MaxAttempts,
+	}
+	for envVar, target := range counts {
+		if value := os.Getenv(envVar); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return domain.EmailOutboxConfig{}, errors.New("invalid value for " + envVar)
+			}
+			*target = n
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"EMAIL_RETRY_BASE":       &config.RetryBase,
+		"EMAIL_RETRY_MAX":        &config.RetryMax,
+		"EMAIL_SEND_LEASE":       &config.Lease,
+		"EMAIL_POLL_INTERVAL":    &config.PollInterval,
+		"EMAIL_OUTBOX_RETENTION": &config.Retention,
+	}
+	for envVar, target := range durations {
+		if value := os.Getenv(envVar); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return domain.EmailOutboxConfig{}, errors.New("invalid duration for " + envVar)
+			}
+			*target = d
+		}
+	}
+	if config.RetryMax < config.RetryBase {
+		return domain.EmailOutboxConfig{}, errors.New("invalid duration for EMAIL_RETRY_MAX")
+	}
+
+	return config, nil
+}
@@ -1,14 +1,32 @@
 package infrastructure
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/blog-platform/domain"
 )
 
 type SendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
 
+// SMTPEmailService renders and composes emails, signs them when DKIM is set
+// and hands them to Transport. Without a Transport it sends through
+// SendMailFn with the SMTP settings.
 type SMTPEmailService struct {
 	Host       string
 	Port       string
@@ -16,6 +34,10 @@ type SMTPEmailService struct {
 	Password   string
 	From       string
 	SendMailFn SendMailFunc
+	Transport  EmailTransport
+	DKIM       *DKIMSigner
+	Templates  domain.IEmailRenderer
+	Now        func() time.Time
 }
 
 func NewSMTPEmailService() *SMTPEmailService {
@@ -26,17 +48,185 @@ func NewSMTPEmailService() *SMTPEmailService {
 		Password:   os.Getenv("SMTP_PASSWORD"),
 		From:       os.Getenv("SMTP_FROM"),
 		SendMailFn: smtp.SendMail,
+		Templates:  DefaultEmailTemplates(),
+		Now:        time.Now,
 	}
 }
 
+// SendEmail sends a plain text body, with an HTML alternative derived from
+// it, for messages that have no template.
 func (s *SMTPEmailService) SendEmail(to []string, subject string, body string) error {
-	auth := smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	return s.send(to, domain.RenderedEmail{Subject: subject, Text: body, HTML: textToHTML(body)}, nil)
+}
 
-	msg := []byte("To: " + strings.Join(to, ",") + "\r\n" +
-		"Subject: " + subject + "\r\n" +
-		"MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n" +
-		body)
+func (s *SMTPEmailService) SendTemplate(message domain.EmailMessage) error {
+	if s.Templates == nil {
+		return errors.New("email templates are not configured")
+	}
+	rendered, err := s.Templates.Render(message.Template, message.Locale, message.Data)
+	if err != nil {
+		return err
+	}
+	return s.send(message.To, rendered, message.Headers)
+}
+
+func (s *SMTPEmailService) send(to []string, email domain.RenderedEmail, extra map[string]string) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	msg, envelopeFrom, err := composeMessage(s.From, to, email, extra, now())
+	if err != nil {
+		return err
+	}
+
+	if s.DKIM != nil {
+		if msg, err = s.DKIM.Sign(msg); err != nil {
+			return err
+		}
+	}
 
+	if s.Transport != nil {
+		return s.Transport.Send(envelopeFrom, to, msg)
+	}
+	auth := smtp.PlainAuth("", s.Username, s.Password, s.Host)
 	addr := fmt.Sprintf("%s:%s", s.Host, s.Port)
-	return s.SendMailFn(addr, auth, s.From, to, msg)
-}
\ No newline at end of file
+	return s.SendMailFn(addr, auth, envelopeFrom, to, msg)
+}
+
+// composeMessage builds a multipart/alternative message and returns it with
+// the bare sender address for the SMTP envelope. extra headers may not
+// replace the ones composeMessage sets itself.
+func composeMessage(from string, to []string, email domain.RenderedEmail, extra map[string]string, now time.Time) ([]byte, string, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, "", errors.New("invalid sender address")
+	}
+	recipients := make([]string, 0, len(to))
+	for _, address := range to {
+		recipient, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid recipient address %q", address)
+		}
+		recipients = append(recipients, recipient.String())
+	}
+
+	messageID, err := newMessageID(sender.Address)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", email.Text},
+		{"text/html; charset=utf-8", email.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, "", err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, "", err
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", sender.String()},
+		{"To", strings.Join(recipients, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", email.Subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+	}
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		canonical := textproto.CanonicalMIMEHeaderKey(name)
+		if !validHeaderName(name) || reservedHeaders[canonical] {
+			return nil, "", fmt.Errorf("invalid header %q", name)
+		}
+		if strings.ContainsAny(extra[name], "\r\n") {
+			return nil, "", fmt.Errorf("invalid value for header %q", name)
+		}
+		headers = append(headers, [2]string{canonical, extra[name]})
+	}
+	headers = append(headers,
+		[2]string{"MIME-Version", "1.0"},
+		[2]string{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()})},
+	)
+	for _, header := range headers {
+		msg.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), sender.Address, nil
+}
+
+var reservedHeaders = map[string]bool{
+	"From":                      true,
+	"To":                        true,
+	"Subject":                   true,
+	"Date":                      true,
+	"Message-Id":                true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r > '~' || r == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+func newMessageID(sender string) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	host := "localhost"
+	if at := strings.LastIndex(sender, "@"); at >= 0 {
+		host = sender[at+1:]
+	}
+	return "<" + hex.EncodeToString(id) + "@" + host + ">", nil
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<]+`)
+
+// textToHTML escapes body, turns blank-line separated blocks into paragraphs
+// and makes links clickable.
+func textToHTML(body string) string {
+	var out strings.Builder
+	for _, paragraph := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		escaped := html.EscapeString(paragraph)
+		escaped = urlPattern.ReplaceAllStringFunc(escaped, func(link string) string {
+			return `<a href="` + link + `">` + link + `</a>`
+		})
+		out.WriteString("<p>" + strings.ReplaceAll(escaped, "\n", "<br>") + "</p>\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,204 @@
+package infrastructure
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/blog-platform/domain"
+)
+
+//go:embed templates/email
+var embeddedEmailTemplates embed.FS
+
+// EmailTemplates renders the emails in a template directory laid out as
+//
+//	layout.html, layout.txt      wrap every message, call "content" and "footer"
+//	partials/*.html, *.txt       shared blocks such as "button" and "footer"
+//	<name>.html, <name>.txt      one message; the text version defines "subject"
+//	<name>.<locale>.html/.txt    a translation, may redefine partials
+//	<name>.json                  sample data for previews
+type EmailTemplates struct {
+	html    map[string]*htmltemplate.Template
+	text    map[string]*texttemplate.Template
+	samples map[string][]byte
+	names   []string
+}
+
+var emailTemplateFuncs = map[string]interface{}{
+	// dict builds the argument for partials that need more than one value,
+	// e.g. {{template "button" (dict "Link" .Link "Label" "Sign in")}}.
+	"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+		if len(pairs)%2 != 0 {
+			return nil, errors.New("dict needs key value pairs")
+		}
+		values := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, ok := pairs[i].(string)
+			if !ok {
+				return nil, errors.New("dict keys must be strings")
+			}
+			values[key] = pairs[i+1]
+		}
+		return values, nil
+	},
+}
+
+// LoadEmailTemplates parses the templates in dir, or the bundled ones when
+// dir is empty.
+func LoadEmailTemplates(dir string) (*EmailTemplates, error) {
+	if dir == "" {
+		return DefaultEmailTemplates(), nil
+	}
+	return NewEmailTemplates(os.DirFS(dir))
+}
+
+// DefaultEmailTemplates returns the bundled templates.
+func DefaultEmailTemplates() *EmailTemplates {
+	fsys, err := fs.Sub(embeddedEmailTemplates, "templates/email")
+	if err != nil {
+		panic(err)
+	}
+	templates, err := NewEmailTemplates(fsys)
+	if err != nil {
+		panic(fmt.Sprintf("bundled email templates: %v", err))
+	}
+	return templates
+}
+
+func NewEmailTemplates(fsys fs.FS) (*EmailTemplates, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	textPartials, _ := fs.Glob(fsys, "partials/*.txt")
+	htmlPartials, _ := fs.Glob(fsys, "partials/*.html")
+
+	templates := &EmailTemplates{
+		html:    map[string]*htmltemplate.Template{},
+		text:    map[string]*texttemplate.Template{},
+		samples: map[string][]byte{},
+	}
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(file, "layout.") {
+			continue
+		}
+		ext := path.Ext(file)
+		key := strings.ToLower(strings.TrimSuffix(file, ext))
+
+		switch ext {
+		case ".txt":
+			files := append(append([]string{"layout.txt"}, textPartials...), file)
+			t, err := texttemplate.New("layout.txt").Funcs(emailTemplateFuncs).ParseFS(fsys, files...)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			if t.Lookup("subject") == nil {
+				return nil, fmt.Errorf("%s: missing subject", file)
+			}
+			templates.text[key] = t
+		case ".html":
+			files := append(append([]string{"layout.html"}, htmlPartials...), file)
+			t, err := htmltemplate.New("layout.html").Funcs(emailTemplateFuncs).ParseFS(fsys, files...)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			templates.html[key] = t
+		case ".json":
+			sample, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, err
+			}
+			templates.samples[key] = sample
+		}
+	}
+
+	for key := range templates.text {
+		if _, ok := templates.html[key]; !ok {
+			return nil, fmt.Errorf("%s.txt has no html version", key)
+		}
+		if !strings.Contains(key, ".") {
+			templates.names = append(templates.names, key)
+		}
+	}
+	for key := range templates.html {
+		if _, ok := templates.text[key]; !ok {
+			return nil, fmt.Errorf("%s.html has no text version", key)
+		}
+	}
+	sort.Strings(templates.names)
+	return templates, nil
+}
+
+func (t *EmailTemplates) Templates() []string {
+	return append([]string(nil), t.names...)
+}
+
+// Render falls back from a regional locale such as pt-BR to its language and
+// then to the untranslated template.
+func (t *EmailTemplates) Render(template string, locale string, data map[string]interface{}) (domain.RenderedEmail, error) {
+	key, ok := t.resolve(template, locale)
+	if !ok {
+		return domain.RenderedEmail{}, domain.ErrEmailTemplateNotFound
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.text[key].ExecuteTemplate(&subject, "subject", data); err != nil {
+		return domain.RenderedEmail{}, err
+	}
+	if err := t.text[key].Execute(&text, data); err != nil {
+		return domain.RenderedEmail{}, err
+	}
+	if err := t.html[key].Execute(&html, data); err != nil {
+		return domain.RenderedEmail{}, err
+	}
+
+	return domain.RenderedEmail{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+func (t *EmailTemplates) SampleData(template string) (map[string]interface{}, error) {
+	if _, ok := t.text[template]; !ok {
+		return nil, domain.ErrEmailTemplateNotFound
+	}
+	data := map[string]interface{}{}
+	if sample, ok := t.samples[template]; ok {
+		if err := json.Unmarshal(sample, &data); err != nil {
+			return nil, fmt.Errorf("%s.json: %w", template, err)
+		}
+	}
+	return data, nil
+}
+
+func (t *EmailTemplates) resolve(template string, locale string) (string, bool) {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	candidates := []string{}
+	if locale != "" {
+		candidates = append(candidates, template+"."+locale)
+		if language, _, found := strings.Cut(locale, "-"); found {
+			candidates = append(candidates, template+"."+language)
+		}
+	}
+	candidates = append(candidates, template)
+
+	for _, key := range candidates {
+		if _, ok := t.text[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
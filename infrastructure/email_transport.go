@@ -0,0 +1,424 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EmailTransport delivers a composed message to the envelope recipients.
+// from is the bare envelope sender, msg the full RFC 5322 message.
+type EmailTransport interface {
+	Send(from string, to []string, msg []byte) error
+}
+
+const (
+	EmailTransportSMTP    = "smtp"
+	EmailTransportFile    = "file"
+	EmailTransportLog     = "log"
+	EmailTransportCapture = "capture"
+)
+
+const (
+	// SMTPSecuritySTARTTLS upgrades a plain connection and refuses servers
+	// that do not offer STARTTLS.
+	SMTPSecuritySTARTTLS = "starttls"
+	// SMTPSecurityTLS connects over TLS from the start, usually on port 465.
+	SMTPSecurityTLS = "tls"
+	// SMTPSecurityNone sends in the clear, for local catch-all servers only.
+	SMTPSecurityNone = "none"
+)
+
+// LoadEmailTransportFromEnv builds the transport EMAIL_TRANSPORT names,
+// SMTP when unset.
+func LoadEmailTransportFromEnv() (EmailTransport, error) {
+	switch name := os.Getenv("EMAIL_TRANSPORT"); name {
+	case "", EmailTransportSMTP:
+		transport, err := LoadSMTPTransportFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return transport, nil
+	case EmailTransportFile:
+		dir := os.Getenv("EMAIL_FILE_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		transport, err := NewFileTransport(dir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create EMAIL_FILE_DIR: %w", err)
+		}
+		return transport, nil
+	case EmailTransportLog:
+		transport := NewLogTransport(log.Default())
+		if value := os.Getenv("EMAIL_LOG_BODY"); value != "" {
+			body, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.New("invalid value for EMAIL_LOG_BODY")
+			}
+			transport.Body = body
+		}
+		return transport, nil
+	case EmailTransportCapture:
+		return NewCaptureTransport(), nil
+	default:
+		return nil, errors.New("invalid value for EMAIL_TRANSPORT")
+	}
+}
+
+// SMTPTransport sends through an SMTP server and keeps up to MaxIdle
+// connections open between messages, so a busy outbox does not pay for a
+// handshake per email.
+type SMTPTransport struct {
+	Host        string
+	Port        string
+	Username    string
+	Password    string
+	Security    string
+	TLSConfig   *tls.Config // nil verifies the server against Host
+	Timeout     time.Duration // for the dial and for each send
+	MaxIdle     int
+	IdleTimeout time.Duration
+
+	mu   sync.Mutex
+	idle []*smtpConnection
+}
+
+type smtpConnection struct {
+	client   *smtp.Client
+	conn     net.Conn
+	lastUsed time.Time
+}
+
+// deadline bounds the next exchange with the server by Timeout, so a server
+// that stops answering cannot hold a worker forever.
+func (c *smtpConnection) deadline(timeout time.Duration) {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+}
+
+func NewSMTPTransport(host string, port string) *SMTPTransport {
+	return &SMTPTransport{
+		Host:        host,
+		Port:        port,
+		Security:    SMTPSecuritySTARTTLS,
+		Timeout:     10 * time.Second,
+		MaxIdle:     2,
+		IdleTimeout: 30 * time.Second,
+	}
+}
+
+func LoadSMTPTransportFromEnv() (*SMTPTransport, error) {
+	transport := NewSMTPTransport(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"))
+	transport.Username = os.Getenv("SMTP_USERNAME")
+	transport.Password = os.Getenv("SMTP_PASSWORD")
+
+	switch security := os.Getenv("SMTP_SECURITY"); security {
+	case "":
+		if transport.Port == "465" {
+			transport.Security = SMTPSecurityTLS
+		}
+	case SMTPSecuritySTARTTLS, SMTPSecurityTLS, SMTPSecurityNone:
+		transport.Security = security
+	default:
+		return nil, errors.New("invalid value for SMTP_SECURITY")
+	}
+
+	for envVar, target := range map[string]*time.Duration{
+		"SMTP_TIMEOUT":      &transport.Timeout,
+		"SMTP_IDLE_TIMEOUT": &transport.IdleTimeout,
+	} {
+		if value := os.Getenv(envVar); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return nil, errors.New("invalid duration for " + envVar)
+			}
+			*target = d
+		}
+	}
+
+	if value := os.Getenv("SMTP_MAX_IDLE"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, errors.New("invalid value for SMTP_MAX_IDLE")
+		}
+		transport.MaxIdle = n
+	}
+	return transport, nil
+}
+
+func (t *SMTPTransport) Send(from string, to []string, msg []byte) error {
+	conn, err := t.connection()
+	if err != nil {
+		return err
+	}
+	conn.deadline(t.Timeout)
+	if err := t.deliver(conn.client, from, to, msg); err != nil {
+		conn.client.Close()
+		return err
+	}
+	t.release(conn)
+	return nil
+}
+
+func (t *SMTPTransport) deliver(client *smtp.Client, from string, to []string, msg []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient address %q", recipient)
+		}
+		if err := client.Rcpt(address.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// connection hands out an idle connection that still answers, or dials a
+// new one.
+func (t *SMTPTransport) connection() (*smtpConnection, error) {
+	for {
+		t.mu.Lock()
+		if len(t.idle) == 0 {
+			t.mu.Unlock()
+			break
+		}
+		conn := t.idle[len(t.idle)-1]
+		t.idle = t.idle[:len(t.idle)-1]
+		t.mu.Unlock()
+
+		if time.Since(conn.lastUsed) < t.IdleTimeout {
+			conn.deadline(t.Timeout)
+			if conn.client.Reset() == nil {
+				return conn, nil
+			}
+		}
+		conn.client.Close()
+	}
+
+	return t.dial()
+}
+
+func (t *SMTPTransport) release(conn *smtpConnection) {
+	conn.lastUsed = time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.idle) < t.MaxIdle {
+		t.idle = append(t.idle, conn)
+		return
+	}
+	conn.deadline(t.Timeout)
+	go conn.client.Quit()
+}
+
+func (t *SMTPTransport) dial() (*smtpConnection, error) {
+	addr := net.JoinHostPort(t.Host, t.Port)
+	dialer := &net.Dialer{Timeout: t.Timeout}
+	tlsConfig := t.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: t.Host, MinVersion: tls.VersionTLS12}
+	}
+
+	var conn net.Conn
+	var err error
+	if t.Security == SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the greeting, STARTTLS and AUTH share one deadline
+	conn.SetDeadline(time.Now().Add(t.Timeout))
+	client, err := smtp.NewClient(conn, t.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if t.Security == SMTPSecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	if t.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", t.Username, t.Password, t.Host)); err != nil {
+				client.Close()
+				return nil, err
+			}
+		}
+	}
+	return &smtpConnection{client: client, conn: conn}, nil
+}
+
+// Close quits the idle connections.
+func (t *SMTPTransport) Close() error {
+	t.mu.Lock()
+	idle := t.idle
+	t.idle = nil
+	t.mu.Unlock()
+	for _, conn := range idle {
+		conn.deadline(t.Timeout)
+		conn.client.Quit()
+	}
+	return nil
+}
+
+// FileTransport drops every message into a maildir, for local development.
+// Mail clients such as mutt open the directory directly.
+type FileTransport struct {
+	Dir string
+}
+
+func NewFileTransport(dir string) (*FileTransport, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return &FileTransport{Dir: dir}, nil
+}
+
+// Send writes to tmp and renames into new, as maildir readers expect. The
+// envelope is recorded in Return-Path and X-Envelope-To headers, since Bcc
+// recipients appear nowhere else.
+func (t *FileTransport) Send(from string, to []string, msg []byte) error {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	hostname, _ := os.Hostname()
+	name := fmt.Sprintf("%d.%s.%s", time.Now().UnixNano(), hex.EncodeToString(id), strings.ReplaceAll(hostname, "/", "_"))
+
+	var content bytes.Buffer
+	content.WriteString("Return-Path: <" + from + ">\r\n")
+	content.WriteString("X-Envelope-To: " + strings.Join(to, ", ") + "\r\n")
+	content.Write(msg)
+
+	tmp := filepath.Join(t.Dir, "tmp", name)
+	if err := os.WriteFile(tmp, content.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(t.Dir, "new", name))
+}
+
+// LogTransport logs messages instead of sending them. Bodies carry sign-in
+// and reset links, so only the headers are logged unless Body is set.
+type LogTransport struct {
+	Logger *log.Logger
+	Body   bool
+}
+
+func NewLogTransport(logger *log.Logger) *LogTransport {
+	return &LogTransport{Logger: logger}
+}
+
+func (t *LogTransport) Send(from string, to []string, msg []byte) error {
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		subject = parsed.Header.Get("Subject")
+	}
+	t.Logger.Printf("email: from=%s to=%s subject=%q message-id=%s size=%d",
+		from, strings.Join(to, ","), subject, parsed.Header.Get("Message-ID"), len(msg))
+	if t.Body {
+		t.Logger.Printf("email: %s", msg)
+	}
+	return nil
+}
+
+// CapturedEmail is a message kept by a CaptureTransport.
+type CapturedEmail struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Message parses the captured message.
+func (e CapturedEmail) Message() (*mail.Message, error) {
+	return mail.ReadMessage(bytes.NewReader(e.Data))
+}
+
+// CaptureTransport keeps sent messages in memory so integration tests can
+// assert on them and follow the links they contain.
+type CaptureTransport struct {
+	mu       sync.Mutex
+	messages []CapturedEmail
+}
+
+func NewCaptureTransport() *CaptureTransport {
+	return &CaptureTransport{}
+}
+
+func (t *CaptureTransport) Send(from string, to []string, msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, CapturedEmail{
+		From: from,
+		To:   append([]string(nil), to...),
+		Data: append([]byte(nil), msg...),
+	})
+	return nil
+}
+
+// Messages returns the captured messages, oldest first.
+func (t *CaptureTransport) Messages() []CapturedEmail {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]CapturedEmail(nil), t.messages...)
+}
+
+// Last returns the most recent message sent to recipient.
+func (t *CaptureTransport) Last(recipient string) (CapturedEmail, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.messages) - 1; i >= 0; i-- {
+		for _, to := range t.messages[i].To {
+			if address, err := mail.ParseAddress(to); err == nil && strings.EqualFold(address.Address, recipient) {
+				return t.messages[i], true
+			}
+		}
+	}
+	return CapturedEmail{}, false
+}
+
+func (t *CaptureTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = nil
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+type JWTConfig struct {
+	AccessTTL    time.Duration
+	RefreshTTL   time.Duration
+	ChallengeTTL time.Duration
+	MagicLinkTTL time.Duration
+	// ImpersonationTTL bounds admin impersonation sessions, which cannot be
+	// refreshed.
+	ImpersonationTTL time.Duration
+	Issuer           string
+	Audience         []string
+	Leeway           time.Duration
+	// LegacySecretUntil is when tokens without a kid stop being verified
+	// with the legacy HMAC secrets once key rings are loaded. Zero rejects
+	// them right away.
+	LegacySecretUntil time.Time
+}
+
+func DefaultJWTConfig() JWTConfig {
+	return JWTConfig{
+		AccessTTL:        60 * time.Minute,
+		RefreshTTL:       7 * 24 * time.Hour,
+		ChallengeTTL:     5 * time.Minute,
+		MagicLinkTTL:     15 * time.Minute,
+		ImpersonationTTL: 15 * time.Minute,
+	}
+}
+
+// LoadJWTConfigFromEnv starts from DefaultJWTConfig and overrides whatever is
+// set. Durations use time.ParseDuration syntax, e.g. JWT_ACCESS_TTL=15m.
+func LoadJWTConfigFromEnv() (JWTConfig, error) {
+	config := DefaultJWTConfig()
+
+	durations := map[string]*time.Duration{
+		"JWT_ACCESS_TTL":        &config.AccessTTL,
+		"JWT_REFRESH_TTL":       &config.RefreshTTL,
+		"JWT_CHALLENGE_TTL":     &config.ChallengeTTL,
+		"JWT_MAGIC_LINK_TTL":    &config.MagicLinkTTL,
+		"JWT_IMPERSONATION_TTL": &config.ImpersonationTTL,
+		"JWT_LEEWAY":            &config.Leeway,
+	}
+	for envVar, target := range durations {
+		value := os.Getenv(envVar)
+		if value == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return JWTConfig{}, errors.New("invalid duration for " + envVar)
+		}
+		*target = d
+	}
+
+	if value := os.Getenv("JWT_LEGACY_SECRET_UNTIL"); value != "" {
+		until, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return JWTConfig{}, errors.New("invalid value for JWT_LEGACY_SECRET_UNTIL")
+		}
+		config.LegacySecretUntil = until
+	}
+
+	config.Issuer = os.Getenv("JWT_ISSUER")
+	for _, aud := range strings.Split(os.Getenv("JWT_AUDIENCE"), ",") {
+		if aud = strings.TrimSpace(aud); aud != "" {
+			config.Audience = append(config.Audience, aud)
+		}
+	}
+
+	return config, nil
+}
+
+// withDefaults fills zero TTLs so a JWTInfrastructure built as a struct
+// literal keeps the historical lifetimes.
+func (c JWTConfig) withDefaults() JWTConfig {
+	defaults := DefaultJWTConfig()
+	if c.AccessTTL == 0 {
+		c.AccessTTL = defaults.AccessTTL
+	}
+	if c.RefreshTTL == 0 {
+		c.RefreshTTL = defaults.RefreshTTL
+	}
+	if c.ChallengeTTL == 0 {
+		c.ChallengeTTL = defaults.ChallengeTTL
+	}
+	if c.MagicLinkTTL == 0 {
+		c.MagicLinkTTL = defaults.MagicLinkTTL
+	}
+	if c.ImpersonationTTL == 0 {
+		c.ImpersonationTTL = defaults.ImpersonationTTL
+	}
+	return c
+}
@@ -0,0 +1,349 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a single entry of a KeyRing. A key signs new tokens from
+// ActiveFrom onwards (until a newer key takes over) and keeps verifying
+// tokens until ExpiresAt. A zero ExpiresAt means the key never expires.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+	ActiveFrom time.Time
+	ExpiresAt  time.Time
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+func (k *SigningKey) isHMAC() bool {
+	_, ok := k.method().(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+func (k *SigningKey) verificationKey() interface{} {
+	if k.isHMAC() {
+		return k.PrivateKey
+	}
+	return k.PublicKey
+}
+
+func (k *SigningKey) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+type KeyRing struct {
+	mu   sync.RWMutex
+	keys map[string]*SigningKey
+	now  func() time.Time
+}
+
+func NewKeyRing(keys ...*SigningKey) (*KeyRing, error) {
+	kr := &KeyRing{keys: make(map[string]*SigningKey), now: time.Now}
+	for _, key := range keys {
+		if err := kr.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	return kr, nil
+}
+
+// NewHMACKeyRing wraps a single shared secret, which is how the service was
+// configured before key rings existed.
+func NewHMACKeyRing(kid string, secret []byte) (*KeyRing, error) {
+	return NewKeyRing(&SigningKey{ID: kid, Algorithm: jwt.SigningMethodHS256.Alg(), PrivateKey: secret})
+}
+
+func (kr *KeyRing) Add(key *SigningKey) error {
+	if key == nil || key.ID == "" {
+		return errors.New("signing key must have a kid")
+	}
+
+	if err := validateSigningKey(key); err != nil {
+		return err
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if _, exists := kr.keys[key.ID]; exists {
+		return errors.New("duplicate kid " + key.ID)
+	}
+	kr.keys[key.ID] = key
+	return nil
+}
+
+// Rotate schedules next to take over signing at next.ActiveFrom. The key that
+// is currently signing keeps verifying for grace after the switch, which
+// should be at least the lifetime of the longest-lived token it signed.
+func (kr *KeyRing) Rotate(next *SigningKey, grace time.Duration) error {
+	if next != nil && next.ActiveFrom.IsZero() {
+		next.ActiveFrom = kr.now()
+	}
+
+	current, signerErr := kr.Signer()
+	if err := kr.Add(next); err != nil {
+		return err
+	}
+
+	if signerErr == nil {
+		kr.mu.Lock()
+		current.ExpiresAt = next.ActiveFrom.Add(grace)
+		kr.mu.Unlock()
+	}
+	return nil
+}
+
+// Signer returns the key that should sign tokens right now: the most recently
+// activated key that has not expired.
+func (kr *KeyRing) Signer() (*SigningKey, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	now := kr.now()
+	var signer *SigningKey
+	for _, key := range kr.keys {
+		if key.ActiveFrom.After(now) || key.expired(now) {
+			continue
+		}
+		if signer == nil || key.ActiveFrom.After(signer.ActiveFrom) ||
+			(key.ActiveFrom.Equal(signer.ActiveFrom) && key.ID > signer.ID) {
+			signer = key
+		}
+	}
+
+	if signer == nil {
+		return nil, errors.New("no active signing key")
+	}
+	return signer, nil
+}
+
+// Lookup returns the key identified by kid if it may still verify tokens.
+func (kr *KeyRing) Lookup(kid string) (*SigningKey, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	key, ok := kr.keys[kid]
+	if !ok || key.expired(kr.now()) {
+		return nil, errors.New("unknown signing key")
+	}
+	return key, nil
+}
+
+// JWKS publishes the public halves of every asymmetric key that may still
+// verify tokens. HMAC secrets are never published.
+func (kr *KeyRing) JWKS() domain.JSONWebKeySet {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	now := kr.now()
+	set := domain.JSONWebKeySet{Keys: []domain.JSONWebKey{}}
+	for _, key := range kr.keys {
+		if key.isHMAC() || key.expired(now) {
+			continue
+		}
+		jwk, err := toJSONWebKey(key)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+func validateSigningKey(key *SigningKey) error {
+	switch key.method().(type) {
+	case *jwt.SigningMethodHMAC:
+		secret, ok := key.PrivateKey.([]byte)
+		if !ok || len(secret) == 0 {
+			return errors.New("HMAC key " + key.ID + " needs a non-empty secret")
+		}
+	case *jwt.SigningMethodRSA:
+		private, ok := key.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return errors.New("RSA key " + key.ID + " needs an RSA private key")
+		}
+		key.PublicKey = &private.PublicKey
+	case *jwt.SigningMethodECDSA:
+		private, ok := key.PrivateKey.(*ecdsa.PrivateKey)
+		if !ok || private.Curve != elliptic.P256() {
+			return errors.New("ECDSA key " + key.ID + " needs a P-256 private key")
+		}
+		key.PublicKey = &private.PublicKey
+	case *jwt.SigningMethodEd25519:
+		private, ok := key.PrivateKey.(ed25519.PrivateKey)
+		if !ok {
+			return errors.New("EdDSA key " + key.ID + " needs an Ed25519 private key")
+		}
+		key.PublicKey = private.Public()
+	default:
+		return errors.New("unsupported signing algorithm " + key.Algorithm)
+	}
+	return nil
+}
+
+func toJSONWebKey(key *SigningKey) (domain.JSONWebKey, error) {
+	jwk := domain.JSONWebKey{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch public := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encode(public.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = public.Curve.Params().Name
+		jwk.X = encode(public.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(public.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encode(public)
+	default:
+		return domain.JSONWebKey{}, errors.New("unsupported public key type")
+	}
+	return jwk, nil
+}
+
+// fromJSONWebKey is the inverse of toJSONWebKey for the public key types a
+// remote JWKS may publish.
+func fromJSONWebKey(jwk domain.JSONWebKey) (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, errors.New("invalid RSA modulus")
+		}
+		e, err := decode(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("unsupported curve " + jwk.Curve)
+		}
+		x, errX := decode(jwk.X)
+		y, errY := decode(jwk.Y)
+		if errX != nil || errY != nil {
+			return nil, errors.New("invalid EC point")
+		}
+		public := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(public.X, public.Y) {
+			return nil, errors.New("invalid EC point")
+		}
+		return public, nil
+	case "OKP":
+		x, err := decode(jwk.X)
+		if err != nil || jwk.Curve != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.New("unsupported key type " + jwk.KeyType)
+	}
+}
+
+type keyRingFileEntry struct {
+	KeyID          string    `json:"kid"`
+	Algorithm      string    `json:"alg"`
+	Secret         string    `json:"secret"`
+	PrivateKeyPath string    `json:"private_key_path"`
+	ActiveFrom     time.Time `json:"active_from"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// LoadKeyRingFile reads a JSON array of keys. HMAC keys carry their secret
+// inline; asymmetric keys point at a PEM encoded private key.
+func LoadKeyRingFile(path string) (*KeyRing, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []keyRingFileEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, errors.New("invalid key ring file: " + err.Error())
+	}
+
+	keys := make([]*SigningKey, 0, len(entries))
+	for _, entry := range entries {
+		key := &SigningKey{
+			ID:         entry.KeyID,
+			Algorithm:  entry.Algorithm,
+			ActiveFrom: entry.ActiveFrom,
+			ExpiresAt:  entry.ExpiresAt,
+		}
+
+		if entry.Secret != "" {
+			key.PrivateKey = []byte(entry.Secret)
+		} else {
+			key.PrivateKey, err = loadPrivateKey(entry.Algorithm, entry.PrivateKeyPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyRing(keys...)
+}
+
+func loadPrivateKey(alg string, path string) (crypto.PrivateKey, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch jwt.GetSigningMethod(alg).(type) {
+	case *jwt.SigningMethodRSA:
+		return jwt.ParseRSAPrivateKeyFromPEM(pem)
+	case *jwt.SigningMethodECDSA:
+		return jwt.ParseECPrivateKeyFromPEM(pem)
+	case *jwt.SigningMethodEd25519:
+		return jwt.ParseEdPrivateKeyFromPEM(pem)
+	}
+	return nil, errors.New("unsupported signing algorithm " + alg)
+}
+
+// LoadKeyRingFromEnv loads the key ring file named by envVar. It returns a nil
+// ring when the variable is unset so the plain shared secret keeps working.
+func LoadKeyRingFromEnv(envVar string) (*KeyRing, error) {
+	path := os.Getenv(envVar)
+	if path == "" {
+		return nil, nil
+	}
+	return LoadKeyRingFile(path)
+}
@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"strings"
 	"time"
@@ -10,56 +12,194 @@ import (
 )
 
 type JWTInfrastructure struct {
-	AccessSecret []byte
+	AccessSecret  []byte
 	RefreshSecret []byte
-	TokenRepo domain.ITokenRepository
+	AccessKeys    *KeyRing
+	RefreshKeys   *KeyRing
+	Config        JWTConfig
+	Denylist      domain.ITokenDenylist
+	TokenRepo     domain.ITokenRepository
 }
 
 func NewJWTInfrastructure(accessSecret, refreshSecret []byte, tokenRepo domain.ITokenRepository) *JWTInfrastructure {
 	return &JWTInfrastructure{
 		AccessSecret:  accessSecret,
 		RefreshSecret: refreshSecret,
-		TokenRepo: tokenRepo,
+		TokenRepo:     tokenRepo,
 	}
 }
 
+// NewJWTInfrastructureWithKeyRings signs with the active key of each ring and
+// stamps its kid in the token header. accessSecret and refreshSecret sign
+// when their ring is nil; otherwise they only verify tokens issued before
+// key rings, which carry no kid, until Config.LegacySecretUntil. A secret
+// may be left empty when its ring is loaded and no such tokens remain.
+func NewJWTInfrastructureWithKeyRings(accessKeys, refreshKeys *KeyRing, accessSecret, refreshSecret []byte, tokenRepo domain.ITokenRepository) (*JWTInfrastructure, error) {
+	if err := validateLegacySecret("access", accessKeys, accessSecret); err != nil {
+		return nil, err
+	}
+	if err := validateLegacySecret("refresh", refreshKeys, refreshSecret); err != nil {
+		return nil, err
+	}
+	return &JWTInfrastructure{
+		AccessSecret:  accessSecret,
+		RefreshSecret: refreshSecret,
+		AccessKeys:    accessKeys,
+		RefreshKeys:   refreshKeys,
+		TokenRepo:     tokenRepo,
+	}, nil
+}
+
+func validateLegacySecret(name string, keys *KeyRing, secret []byte) error {
+	if keys != nil && len(secret) == 0 {
+		return nil
+	}
+	return validateSigningKey(&SigningKey{ID: "legacy " + name, Algorithm: "HS256", PrivateKey: secret})
+}
+
 func (infra *JWTInfrastructure) GenerateAccessToken(userID string, userRole string) (string, error) {
+	config := infra.Config.withDefaults()
+	return infra.generate(userID, userRole, "access", config.AccessTTL, infra.AccessKeys, infra.AccessSecret)
+}
+
+func (infra *JWTInfrastructure) GenerateRefreshToken(userID string, userRole string) (string, error) {
+	config := infra.Config.withDefaults()
+	return infra.generate(userID, userRole, "refresh", config.RefreshTTL, infra.RefreshKeys, infra.RefreshSecret)
+}
+
+// GenerateChallengeToken issues the short-lived token handed out between the
+// password and the second factor step of a login. It is signed with the
+// access keys but can never pass ValidateAccessToken because of its type.
+func (infra *JWTInfrastructure) GenerateChallengeToken(userID string, userRole string) (string, error) {
+	config := infra.Config.withDefaults()
+	return infra.generate(userID, userRole, "mfa_challenge", config.ChallengeTTL, infra.AccessKeys, infra.AccessSecret)
+}
+
+// GenerateMagicLinkToken issues the token embedded in a sign-in email. Like
+// the challenge token it is signed with the access keys but has its own type.
+func (infra *JWTInfrastructure) GenerateMagicLinkToken(userID string, userRole string) (string, error) {
+	config := infra.Config.withDefaults()
+	return infra.generate(userID, userRole, "magic_link", config.MagicLinkTTL, infra.AccessKeys, infra.AccessSecret)
+}
+
+// GenerateImpersonationToken issues an access token for userID that names
+// the admin behind it in the impersonator_id claim, so clients can show a
+// banner and the session can be told apart from the user's own.
+func (infra *JWTInfrastructure) GenerateImpersonationToken(userID string, userRole string, impersonatorID string) (string, error) {
+	if impersonatorID == "" {
+		return "", errors.New("impersonatorID cannot be empty")
+	}
+	config := infra.Config.withDefaults()
+	claims, err := infra.newClaims(userID, userRole, "access", config.ImpersonationTTL)
+	if err != nil {
+		return "", err
+	}
+	claims.ImpersonatorID = impersonatorID
+	return infra.sign(claims, infra.AccessKeys, infra.AccessSecret)
+}
+
+func (infra *JWTInfrastructure) generate(userID string, userRole string, tokenType string, ttl time.Duration, keys *KeyRing, secret []byte) (string, error) {
+	claims, err := infra.newClaims(userID, userRole, tokenType, ttl)
+	if err != nil {
+		return "", err
+	}
+	return infra.sign(claims, keys, secret)
+}
+
+func (infra *JWTInfrastructure) newClaims(userID string, userRole string, tokenType string, ttl time.Duration) (domain.TokenClaims, error) {
 	if userID == "" || userRole == "" {
-		return "", errors.New("userID and userRole cannot be empty")
+		return domain.TokenClaims{}, errors.New("userID and userRole cannot be empty")
+	}
+
+	jti, err := newTokenID()
+	if err != nil {
+		return domain.TokenClaims{}, errors.New("unable to generate token id")
 	}
 
+	now := time.Now()
 	claims := domain.TokenClaims{
 		UserID:    userID,
 		UserRole:  userRole,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(60 * time.Minute)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			Subject:   userID,
+			Issuer:    infra.Config.Issuer,
+			Audience:  infra.Config.Audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(infra.AccessSecret)
+	return claims, nil
 }
 
-func (infra *JWTInfrastructure) GenerateRefreshToken(userID string, userRole string) (string, error) {
-	if userID == "" || userRole == "" {
-		return "", errors.New("userID and userRole cannot be empty")
+func (infra *JWTInfrastructure) sign(claims domain.TokenClaims, keys *KeyRing, secret []byte) (string, error) {
+	if keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(secret)
 	}
 
-	claims := domain.TokenClaims{
-		UserID: userID,
-		UserRole: userRole,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	key, err := keys.Signer()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(infra.RefreshSecret)
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// keyFunc resolves the verification key from the kid header. The algorithm
+// must match the one the key was registered with, so a public RSA key can
+// never be used as an HMAC secret. Tokens without a kid fall back to the
+// legacy secret only while Config.LegacySecretUntil has not passed, and
+// never to an empty one.
+func (infra *JWTInfrastructure) keyFunc(keys *KeyRing, secret []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" || keys == nil {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			if len(secret) == 0 {
+				return nil, errors.New("no key to verify the token")
+			}
+			if keys != nil && !time.Now().Before(infra.Config.LegacySecretUntil) {
+				return nil, errors.New("token has no kid")
+			}
+			return secret, nil
+		}
+
+		key, err := keys.Lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verificationKey(), nil
+	}
 }
 
-func (infra *JWTInfrastructure) validateToken(authHeader string, secret []byte) (*domain.TokenClaims, error) {
+func (infra *JWTInfrastructure) parserOptions() []jwt.ParserOption {
+	options := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(infra.Config.Leeway),
+	}
+	if infra.Config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(infra.Config.Issuer))
+	}
+	if len(infra.Config.Audience) > 0 {
+		options = append(options, jwt.WithAudience(infra.Config.Audience...))
+	}
+	return options
+}
+
+// validateToken checks the signature and registered claims. When stateful is
+// set the token must also be present and unblocked in the token store;
+// otherwise only the jti denylist is consulted.
+func (infra *JWTInfrastructure) validateToken(authHeader string, tokenType string, stateful bool, keys *KeyRing, secret []byte) (*domain.TokenClaims, error) {
 	if authHeader == "" {
 		return &domain.TokenClaims{}, errors.New("log in inorder to access this route")
 	}
@@ -71,21 +211,18 @@ func (infra *JWTInfrastructure) validateToken(authHeader string, secret []byte)
 
 	tokenString := authParts[1]
 
-	tokenObj, err := infra.TokenRepo.FetchByContent(tokenString)
-	if err != nil {
-		return &domain.TokenClaims{}, errors.New("invalid token")
-	}
+	if stateful {
+		tokenObj, err := infra.TokenRepo.FetchByContent(tokenString)
+		if err != nil {
+			return &domain.TokenClaims{}, errors.New("invalid token")
+		}
 
-	if tokenObj.Status == "blocked" {
-		return &domain.TokenClaims{}, errors.New("blocked token")
+		if tokenObj.Status == "blocked" {
+			return &domain.TokenClaims{}, errors.New("blocked token")
+		}
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &domain.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return secret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &domain.TokenClaims{}, infra.keyFunc(keys, secret), infra.parserOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -95,22 +232,104 @@ func (infra *JWTInfrastructure) validateToken(authHeader string, secret []byte)
 		return nil, errors.New("invalid token")
 	}
 
-	exp, err := claims.GetExpirationTime() 
-	if err != nil {
-		return nil, errors.New("token validation failed")
+	// tokens issued before the token_type claim existed carry none
+	if claims.TokenType != "" && claims.TokenType != tokenType {
+		return nil, errors.New("invalid token type")
 	}
 
-	if exp.Time.Before(time.Now()) {
-		return nil, errors.New("token is expired")
+	if infra.Denylist != nil && claims.ID != "" {
+		revoked, err := infra.Denylist.IsRevoked(claims.ID)
+		if err != nil {
+			return nil, errors.New("token validation failed")
+		}
+		if revoked {
+			return nil, errors.New("revoked token")
+		}
 	}
 
 	return claims, nil
 }
 
+// ValidateAccessToken is stateless when a Denylist is configured: only the
+// signature, registered claims and the jti denylist are checked.
 func (infra *JWTInfrastructure) ValidateAccessToken(authHeader string) (*domain.TokenClaims, error) {
-	return infra.validateToken(authHeader, infra.AccessSecret)
+	return infra.validateToken(authHeader, "access", infra.Denylist == nil, infra.AccessKeys, infra.AccessSecret)
 }
 
 func (infra *JWTInfrastructure) ValidateRefreshToken(authHeader string) (*domain.TokenClaims, error) {
-	return infra.validateToken(authHeader, infra.RefreshSecret)
-}
\ No newline at end of file
+	return infra.validateToken(authHeader, "refresh", true, infra.RefreshKeys, infra.RefreshSecret)
+}
+
+func (infra *JWTInfrastructure) ValidateChallengeToken(token string) (*domain.TokenClaims, error) {
+	return infra.validateTypedToken(token, "mfa_challenge")
+}
+
+func (infra *JWTInfrastructure) ValidateMagicLinkToken(token string) (*domain.TokenClaims, error) {
+	return infra.validateTypedToken(token, "magic_link")
+}
+
+// validateTypedToken is for token types that never existed without the
+// token_type claim, so a missing type is rejected instead of tolerated.
+func (infra *JWTInfrastructure) validateTypedToken(token string, tokenType string) (*domain.TokenClaims, error) {
+	claims, err := infra.validateToken("Bearer "+token, tokenType, false, infra.AccessKeys, infra.AccessSecret)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, errors.New("invalid token type")
+	}
+	return claims, nil
+}
+
+func (infra *JWTInfrastructure) RevokeToken(claims *domain.TokenClaims) error {
+	if infra.Denylist == nil {
+		return errors.New("token revocation is not configured")
+	}
+	if claims == nil || claims.ID == "" {
+		return errors.New("token has no id")
+	}
+
+	return infra.Denylist.Revoke(claims.ID, infra.denylistUntil(claims))
+}
+
+func (infra *JWTInfrastructure) ConsumeToken(claims *domain.TokenClaims) error {
+	if infra.Denylist == nil {
+		return errors.New("token revocation is not configured")
+	}
+	if claims == nil || claims.ID == "" {
+		return errors.New("token has no id")
+	}
+
+	added, err := infra.Denylist.Consume(claims.ID, infra.denylistUntil(claims))
+	if err != nil {
+		return err
+	}
+	if !added {
+		return domain.ErrTokenUsed
+	}
+	return nil
+}
+
+// denylistUntil is when a revoked token would be rejected anyway.
+func (infra *JWTInfrastructure) denylistUntil(claims *domain.TokenClaims) time.Time {
+	if claims.ExpiresAt != nil {
+		return claims.ExpiresAt.Time.Add(infra.Config.Leeway)
+	}
+	return time.Now().Add(infra.Config.withDefaults().RefreshTTL)
+}
+
+// JWKS lists the public keys other services need to verify our access tokens.
+func (infra *JWTInfrastructure) JWKS() domain.JSONWebKeySet {
+	if infra.AccessKeys == nil {
+		return domain.JSONWebKeySet{Keys: []domain.JSONWebKey{}}
+	}
+	return infra.AccessKeys.JWKS()
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
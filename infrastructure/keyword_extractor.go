@@ -0,0 +1,109 @@
+package infrastructure
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	// a word in the title counts as much as this many in the body
+	keywordTitleWeight = 3
+	minKeywordLength   = 3
+)
+
+var keywordStopwords = toSet(strings.Fields(`
+	a about above after again against all also am an and any are as at be because been before being below
+	between both but by can could did do does doing done down during each even ever every few for from
+	further get gets getting got had has have having he her here hers herself him himself his how however
+	i if in into is it its itself just let like made make makes making many may me might more most much
+	must my myself need new no nor not now of off often on once one only or other our ours ourselves out
+	over own really same see she should since so some still such take than that the their theirs them
+	themselves then there these they thing things this those through thus to too two under until up upon
+	us use used uses using very want was way we well were what when where which while who whom why will
+	with within without would yet you your yours yourself yourselves
+`))
+
+// KeywordExtractor finds keywords by counting words and recurring two word
+// phrases, leaving out common English words. It needs no provider, so tag
+// suggestions work with the writing assistant turned off.
+type KeywordExtractor struct{}
+
+func NewKeywordExtractor() *KeywordExtractor {
+	return &KeywordExtractor{}
+}
+
+func (e *KeywordExtractor) Keywords(title string, content string, limit int) []domain.Keyword {
+	counts := make(map[string]float64)
+	phrases := make(map[string]int)
+	count := func(text string, weight float64) {
+		words := keywordTokens(text)
+		for i, word := range words {
+			if word == "" {
+				continue
+			}
+			counts[word] += weight
+			if i+1 < len(words) && words[i+1] != "" {
+				phrase := word + " " + words[i+1]
+				counts[phrase] += weight
+				phrases[phrase]++
+			}
+		}
+	}
+	count(title, keywordTitleWeight)
+	count(content, 1)
+
+	var best float64
+	keywords := make([]domain.Keyword, 0, len(counts))
+	for term, score := range counts {
+		// a phrase has to recur to mean more than its words
+		if strings.Contains(term, " ") && phrases[term] < 2 {
+			continue
+		}
+		keywords = append(keywords, domain.Keyword{Term: term, Score: score})
+		best = max(best, score)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		// a phrase as frequent as its words is the more specific keyword
+		if wi, wj := strings.Count(keywords[i].Term, " "), strings.Count(keywords[j].Term, " "); wi != wj {
+			return wi > wj
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+	if len(keywords) > limit {
+		keywords = keywords[:limit]
+	}
+	for i := range keywords {
+		keywords[i].Score /= best
+	}
+	return keywords
+}
+
+// keywordTokens lowercases text and splits it into words. Stopwords, short
+// words and numbers become empty strings so phrases never span them.
+func keywordTokens(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '+' && r != '#'
+	})
+	for i, word := range words {
+		word = strings.Trim(word, "-")
+		if len([]rune(word)) < minKeywordLength || keywordStopwords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			word = ""
+		}
+		words[i] = word
+	}
+	return words
+}
+
+func toSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,245 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+type LoginThrottleConfig struct {
+	FreeAttempts       int           // failures allowed before any backoff applies
+	BaseDelay          time.Duration // delay after the first failure past FreeAttempts, doubled for each further one
+	MaxDelay           time.Duration
+	LockoutThreshold   int // failures per account before a temporary lockout
+	IPLockoutThreshold int // failures per client IP before a temporary lockout
+	LockoutDuration    time.Duration
+	ResetAfter         time.Duration // quiet period after which the failure count starts over
+}
+
+func DefaultLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		FreeAttempts:       3,
+		BaseDelay:          time.Second,
+		MaxDelay:           time.Minute,
+		LockoutThreshold:   10,
+		IPLockoutThreshold: 50,
+		LockoutDuration:    15 * time.Minute,
+		ResetAfter:         time.Hour,
+	}
+}
+
+func LoadLoginThrottleConfigFromEnv() (LoginThrottleConfig, error) {
+	config := DefaultLoginThrottleConfig()
+
+	counts := map[string]*int{
+		"LOGIN_FREE_ATTEMPTS":        &config.FreeAttempts,
+		"LOGIN_LOCKOUT_THRESHOLD":    &config.LockoutThreshold,
+		"LOGIN_IP_LOCKOUT_THRESHOLD": &config.IPLockoutThreshold,
+	}
+	for envVar, target := range counts {
+		if value := os.Getenv(envVar); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return LoginThrottleConfig{}, errors.New("invalid value for " + envVar)
+			}
+			*target = n
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"LOGIN_BACKOFF_BASE":     &config.BaseDelay,
+		"LOGIN_BACKOFF_MAX":      &config.MaxDelay,
+		"LOGIN_LOCKOUT_DURATION": &config.LockoutDuration,
+		"LOGIN_FAILURE_RESET":    &config.ResetAfter,
+	}
+	for envVar, target := range durations {
+		if value := os.Getenv(envVar); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return LoginThrottleConfig{}, errors.New("invalid duration for " + envVar)
+			}
+			*target = d
+		}
+	}
+
+	return config, nil
+}
+
+// LoginThrottler applies exponential backoff and temporary lockouts on top of
+// an ILoginAttemptStore. Accounts and client IPs are tracked independently so
+// password spraying from one address is slowed down as well.
+type LoginThrottler struct {
+	store  domain.ILoginAttemptStore
+	config LoginThrottleConfig
+	Now    func() time.Time
+}
+
+func NewLoginThrottler(store domain.ILoginAttemptStore, config LoginThrottleConfig) *LoginThrottler {
+	return &LoginThrottler{
+		store:  store,
+		config: config,
+		Now:    time.Now,
+	}
+}
+
+func accountKey(account string) string { return domain.LoginAttemptAccountKey(account) }
+func ipKey(clientIP string) string     { return "ip:" + clientIP }
+
+// Check returns how long the caller has to wait before the next attempt is
+// allowed, zero if it may proceed right away.
+func (t *LoginThrottler) Check(account string, clientIP string) (time.Duration, error) {
+	now := t.Now()
+	var wait time.Duration
+
+	for _, key := range t.keys(account, clientIP) {
+		attempt, err := t.store.Fetch(key)
+		if err != nil {
+			return 0, err
+		}
+		if w := t.waitFor(attempt, now); w > wait {
+			wait = w
+		}
+	}
+	return wait, nil
+}
+
+// RegisterFailure records a failed attempt and reports whether it caused the
+// account to be locked.
+func (t *LoginThrottler) RegisterFailure(account string, clientIP string) (bool, error) {
+	now := t.Now()
+	accountLocked := false
+
+	for _, key := range t.keys(account, clientIP) {
+		threshold := t.config.LockoutThreshold
+		if key != accountKey(account) {
+			threshold = t.config.IPLockoutThreshold
+		}
+
+		previous, err := t.store.Fetch(key)
+		if err != nil {
+			return false, err
+		}
+		// a served lockout starts the count over, otherwise the next single
+		// failure would lock the key again
+		lockoutServed := !previous.LockedUntil.IsZero() && !previous.LockedUntil.After(now)
+		if previous.Failures > 0 && (lockoutServed || now.Sub(previous.LastFailureAt) > t.config.ResetAfter) {
+			if err := t.store.Reset(key); err != nil {
+				return false, err
+			}
+		}
+
+		attempt, err := t.store.RecordFailure(key, now)
+		if err != nil {
+			return false, err
+		}
+
+		if attempt.Failures >= threshold && !attempt.LockedUntil.After(now) {
+			if err := t.store.Lock(key, now.Add(t.config.LockoutDuration)); err != nil {
+				return false, err
+			}
+			if key == accountKey(account) {
+				accountLocked = true
+			}
+		}
+	}
+
+	return accountLocked, nil
+}
+
+// RegisterSuccess clears the account's failures. The IP counter is left alone
+// so one valid login does not hide failures against other accounts.
+func (t *LoginThrottler) RegisterSuccess(account string) error {
+	return t.store.Reset(accountKey(account))
+}
+
+func (t *LoginThrottler) Unlock(account string) error {
+	return t.store.Reset(accountKey(account))
+}
+
+func (t *LoginThrottler) keys(account string, clientIP string) []string {
+	keys := []string{accountKey(account)}
+	if clientIP != "" {
+		keys = append(keys, ipKey(clientIP))
+	}
+	return keys
+}
+
+func (t *LoginThrottler) waitFor(attempt domain.LoginAttempt, now time.Time) time.Duration {
+	if attempt.LockedUntil.After(now) {
+		return attempt.LockedUntil.Sub(now)
+	}
+	if attempt.Failures == 0 || now.Sub(attempt.LastFailureAt) > t.config.ResetAfter {
+		return 0
+	}
+
+	excess := attempt.Failures - t.config.FreeAttempts
+	if excess <= 0 {
+		return 0
+	}
+
+	delay := t.config.MaxDelay
+	if excess <= 30 {
+		delay = t.config.BaseDelay << (excess - 1)
+	}
+	if delay > t.config.MaxDelay {
+		delay = t.config.MaxDelay
+	}
+
+	if ready := attempt.LastFailureAt.Add(delay); ready.After(now) {
+		return ready.Sub(now)
+	}
+	return 0
+}
+
+// InMemoryLoginAttemptStore keeps counters in process memory. It is suitable
+// for a single instance; replicas must share the database backed store.
+type InMemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempt
+}
+
+func NewInMemoryLoginAttemptStore() *InMemoryLoginAttemptStore {
+	return &InMemoryLoginAttemptStore{attempts: make(map[string]domain.LoginAttempt)}
+}
+
+func (s *InMemoryLoginAttemptStore) Fetch(key string) (domain.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *InMemoryLoginAttemptStore) RecordFailure(key string, at time.Time) (domain.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt := s.attempts[key]
+	attempt.Key = key
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	s.attempts[key] = attempt
+	return attempt, nil
+}
+
+func (s *InMemoryLoginAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt := s.attempts[key]
+	attempt.Key = key
+	attempt.LockedUntil = until
+	s.attempts[key] = attempt
+	return nil
+}
+
+func (s *InMemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,331 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcMaxResponseSize = 1 << 20
+	oidcJWKSRefetchWait = time.Minute
+)
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// LoadOIDCProvidersFromEnv reads the comma separated OIDC_PROVIDERS list and,
+// for each name, OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL
+// and the optional space separated _SCOPES.
+func LoadOIDCProvidersFromEnv() ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return nil, errors.New(prefix + "ISSUER and " + prefix + "CLIENT_ID are required")
+		}
+		if provider.RedirectURL == "" {
+			provider.RedirectURL = fmt.Sprintf("%v://%v:%v/oidc/%v/callback", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), name)
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcProvider struct {
+	config OIDCProviderConfig
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// OIDCInfrastructure is a relying party for any number of OpenID Connect
+// providers using the authorization code flow with PKCE. Provider metadata is
+// discovered lazily and signing keys are refetched when an unknown kid shows
+// up, so key rotation at the provider needs no restart.
+type OIDCInfrastructure struct {
+	HTTPClient *http.Client
+	Now        func() time.Time
+	providers  map[string]*oidcProvider
+}
+
+func NewOIDCInfrastructure(providers ...OIDCProviderConfig) *OIDCInfrastructure {
+	oi := &OIDCInfrastructure{
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Now:        time.Now,
+		providers:  make(map[string]*oidcProvider),
+	}
+	for _, config := range providers {
+		if len(config.Scopes) == 0 {
+			config.Scopes = []string{"openid", "email", "profile"}
+		}
+		oi.providers[config.Name] = &oidcProvider{config: config}
+	}
+	return oi
+}
+
+func (oi *OIDCInfrastructure) AuthCodeURL(provider string, state string, nonce string, codeChallenge string) (string, error) {
+	p, err := oi.provider(provider)
+	if err != nil {
+		return "", err
+	}
+	discovery, err := oi.discover(p)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token.
+// The nonce must be the one passed to AuthCodeURL for the same login.
+func (oi *OIDCInfrastructure) Exchange(provider string, code string, codeVerifier string, nonce string) (domain.OIDCIdentity, error) {
+	p, err := oi.provider(provider)
+	if err != nil {
+		return domain.OIDCIdentity{}, err
+	}
+	discovery, err := oi.discover(p)
+	if err != nil {
+		return domain.OIDCIdentity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.config.ClientID},
+	}
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return domain.OIDCIdentity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := oi.doJSON(req, &tokenResponse); err != nil {
+		return domain.OIDCIdentity{}, errors.New("token exchange failed: " + err.Error())
+	}
+	if tokenResponse.IDToken == "" {
+		return domain.OIDCIdentity{}, errors.New("token response has no id_token")
+	}
+
+	return oi.verifyIDToken(p, discovery, tokenResponse.IDToken, nonce)
+}
+
+type idTokenClaims struct {
+	Nonce             string          `json:"nonce"`
+	AuthorizedParty   string          `json:"azp"`
+	Email             string          `json:"email"`
+	EmailVerified     json.RawMessage `json:"email_verified"`
+	Name              string          `json:"name"`
+	PreferredUsername string          `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+func (oi *OIDCInfrastructure) verifyIDToken(p *oidcProvider, discovery *oidcDiscovery, rawToken string, nonce string) (domain.OIDCIdentity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return oi.signingKey(p, discovery, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(oi.Now),
+	)
+	if err != nil {
+		return domain.OIDCIdentity{}, errors.New("invalid id_token: " + err.Error())
+	}
+
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return domain.OIDCIdentity{}, errors.New("invalid id_token: nonce mismatch")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return domain.OIDCIdentity{}, errors.New("invalid id_token: unexpected authorized party")
+	}
+	if claims.Subject == "" {
+		return domain.OIDCIdentity{}, errors.New("invalid id_token: missing subject")
+	}
+
+	// some providers send email_verified as the string "true"
+	verified := string(claims.EmailVerified) == "true" || string(claims.EmailVerified) == `"true"`
+
+	return domain.OIDCIdentity{
+		Provider:          p.config.Name,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     verified,
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+func (oi *OIDCInfrastructure) provider(name string) (*oidcProvider, error) {
+	p, ok := oi.providers[name]
+	if !ok {
+		return nil, errors.New("unknown identity provider")
+	}
+	return p, nil
+}
+
+func (oi *OIDCInfrastructure) discover(p *oidcProvider) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var discovery oidcDiscovery
+	if err := oi.doJSON(req, &discovery); err != nil {
+		return nil, errors.New("provider discovery failed: " + err.Error())
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.config.Issuer {
+		return nil, errors.New("provider discovery failed: issuer mismatch")
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("provider discovery failed: incomplete metadata")
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (oi *OIDCInfrastructure) signingKey(p *oidcProvider, discovery *oidcDiscovery, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := lookupOIDCKey(p.keys, kid); ok {
+		return key, nil
+	}
+	if !p.keysFetchedAt.IsZero() && oi.Now().Sub(p.keysFetchedAt) < oidcJWKSRefetchWait {
+		return nil, errors.New("unknown signing key")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set domain.JSONWebKeySet
+	if err := oi.doJSON(req, &set); err != nil {
+		return nil, errors.New("unable to fetch provider keys: " + err.Error())
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// keys of unsupported types are skipped rather than failing the set
+		if public, err := fromJSONWebKey(jwk); err == nil {
+			keys[jwk.KeyID] = public
+		}
+	}
+	p.keys = keys
+	p.keysFetchedAt = oi.Now()
+
+	if key, ok := lookupOIDCKey(p.keys, kid); ok {
+		return key, nil
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+// lookupOIDCKey accepts a token without kid only when the provider publishes
+// a single key, as the spec allows.
+func lookupOIDCKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+func (oi *OIDCInfrastructure) doJSON(req *http.Request, target interface{}) error {
+	resp, err := oi.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, oidcMaxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, target)
+}
@@ -0,0 +1,260 @@
+package infrastructure
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blog-platform/domain"
+)
+
+//go:embed passwords/common.txt
+var commonPasswords string
+
+type PasswordPolicyConfig struct {
+	MinLength      int
+	MaxLength      int
+	RequireClasses bool   // upper, lower, digit and symbol, the rule passwords had before scoring
+	MinScore       int    // 0-4, see scoreFromBits
+	HistoryDepth   int    // previous passwords that may not be reused, 0 disables the check
+	BreachListFile string // extra SHA-1 hashes, one per line, optionally followed by :count
+}
+
+func DefaultPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength:      8,
+		MaxLength:      128,
+		RequireClasses: true,
+		MinScore:       3,
+		HistoryDepth:   5,
+	}
+}
+
+func LoadPasswordPolicyConfigFromEnv() (PasswordPolicyConfig, error) {
+	config := DefaultPasswordPolicyConfig()
+
+	counts := map[string]*int{
+		"PASSWORD_MIN_LENGTH": &config.MinLength,
+		"PASSWORD_MAX_LENGTH": &config.MaxLength,
+		"PASSWORD_MIN_SCORE":  &config.MinScore,
+		"PASSWORD_HISTORY":    &config.HistoryDepth,
+	}
+	for envVar, target := range counts {
+		if value := os.Getenv(envVar); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return PasswordPolicyConfig{}, errors.New("invalid value for " + envVar)
+			}
+			*target = n
+		}
+	}
+	if config.MinScore > 4 {
+		return PasswordPolicyConfig{}, errors.New("invalid value for PASSWORD_MIN_SCORE")
+	}
+	if config.MaxLength < config.MinLength {
+		return PasswordPolicyConfig{}, errors.New("invalid value for PASSWORD_MAX_LENGTH")
+	}
+
+	if value := os.Getenv("PASSWORD_REQUIRE_CLASSES"); value != "" {
+		required, err := strconv.ParseBool(value)
+		if err != nil {
+			return PasswordPolicyConfig{}, errors.New("invalid value for PASSWORD_REQUIRE_CLASSES")
+		}
+		config.RequireClasses = required
+	}
+
+	config.BreachListFile = os.Getenv("PASSWORD_BREACH_LIST")
+	return config, nil
+}
+
+// BreachedPasswordSource answers range queries the way the Pwned Passwords
+// API does: given the first five hex characters of a password's SHA-1 it
+// returns the remaining 35 of every known breached hash with that prefix, so
+// the password itself never has to leave the caller.
+type BreachedPasswordSource interface {
+	Range(prefix string) ([]string, error)
+}
+
+// BreachList is an in-memory BreachedPasswordSource.
+type BreachList struct {
+	ranges map[string]map[string]struct{}
+}
+
+// NewBreachList returns a list holding the bundled common passwords.
+func NewBreachList() *BreachList {
+	list := &BreachList{ranges: map[string]map[string]struct{}{}}
+	for _, password := range rankedCommonPasswords() {
+		list.Add(password)
+	}
+	return list
+}
+
+// Add adds a plain text password.
+func (b *BreachList) Add(password string) {
+	sum := sha1.Sum([]byte(password))
+	b.AddHash(hex.EncodeToString(sum[:]))
+}
+
+// AddHash adds a hex encoded SHA-1 hash.
+func (b *BreachList) AddHash(hash string) {
+	hash = strings.ToUpper(hash)
+	prefix, suffix := hash[:5], hash[5:]
+	if b.ranges[prefix] == nil {
+		b.ranges[prefix] = map[string]struct{}{}
+	}
+	b.ranges[prefix][suffix] = struct{}{}
+}
+
+// LoadFile adds the hashes in path, in the format of the downloadable Pwned
+// Passwords list.
+func (b *BreachList) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" {
+			continue
+		}
+		hash, _, _ := strings.Cut(entry, ":")
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 40 {
+			return fmt.Errorf("invalid SHA-1 hash on line %d", line)
+		}
+		b.AddHash(hash)
+	}
+	return scanner.Err()
+}
+
+func (b *BreachList) Range(prefix string) ([]string, error) {
+	suffixes := make([]string, 0, len(b.ranges[strings.ToUpper(prefix)]))
+	for suffix := range b.ranges[strings.ToUpper(prefix)] {
+		suffixes = append(suffixes, suffix)
+	}
+	return suffixes, nil
+}
+
+// PasswordPolicy is the default IPasswordPolicy. Breached passwords are
+// looked up through Breaches so a remote range API can replace the bundled
+// list.
+type PasswordPolicy struct {
+	config     PasswordPolicyConfig
+	dictionary map[string]int
+	Breaches   BreachedPasswordSource
+}
+
+func NewPasswordPolicy(config PasswordPolicyConfig) (*PasswordPolicy, error) {
+	breaches := NewBreachList()
+	if config.BreachListFile != "" {
+		if err := breaches.LoadFile(config.BreachListFile); err != nil {
+			return nil, fmt.Errorf("unable to load breached password list: %w", err)
+		}
+	}
+
+	dictionary := map[string]int{}
+	for i, password := range rankedCommonPasswords() {
+		word := strings.ToLower(password)
+		if _, ok := dictionary[word]; !ok {
+			dictionary[word] = i + 1
+		}
+	}
+
+	return &PasswordPolicy{config: config, dictionary: dictionary, Breaches: breaches}, nil
+}
+
+func (p *PasswordPolicy) Check(password string, pc domain.PasswordContext) domain.PasswordFeedback {
+	feedback := domain.PasswordFeedback{Valid: true}
+
+	length := utf8.RuneCountInString(password)
+	feedback.Add(passwordRule(domain.PasswordRuleLength, length >= p.config.MinLength && length <= p.config.MaxLength,
+		fmt.Sprintf("password must be between %d and %d characters long", p.config.MinLength, p.config.MaxLength)))
+
+	if p.config.RequireClasses {
+		feedback.Add(passwordRule(domain.PasswordRuleComposition, hasAllCharacterClasses(password),
+			"password must contain an uppercase letter, a lowercase letter, a number and a symbol"))
+	}
+
+	breached, err := p.breached(password)
+	if err != nil {
+		// The other rules still apply, a lookup outage should not stop
+		// people from changing their password.
+		log.Printf("password policy: breached password lookup failed: %v", err)
+	}
+	feedback.Add(passwordRule(domain.PasswordRuleBreached, !breached,
+		"this password has appeared in a data breach and cannot be used"))
+
+	estimate := estimateStrength(password, p.dictionary, userInputWords(pc.Username, pc.Email))
+	feedback.Score = estimate.score
+	feedback.Suggestions = estimate.suggestions
+	feedback.Add(passwordRule(domain.PasswordRuleStrength, estimate.score >= p.config.MinScore,
+		"password is too easy to guess"))
+
+	return feedback
+}
+
+func (p *PasswordPolicy) breached(password string) (bool, error) {
+	if p.Breaches == nil {
+		return false, nil
+	}
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	suffixes, err := p.Breaches.Range(hash[:5])
+	if err != nil {
+		return false, err
+	}
+	for _, suffix := range suffixes {
+		if strings.EqualFold(suffix, hash[5:]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func passwordRule(name string, passed bool, message string) domain.PasswordRuleResult {
+	result := domain.PasswordRuleResult{Rule: name, Passed: passed}
+	if !passed {
+		result.Message = message
+	}
+	return result
+}
+
+func hasAllCharacterClasses(password string) bool {
+	var hasUpper, hasLower, hasNumber, hasSpecial bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsNumber(c):
+			hasNumber = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c):
+			hasSpecial = true
+		}
+	}
+	return hasUpper && hasLower && hasNumber && hasSpecial
+}
+
+func rankedCommonPasswords() []string {
+	var passwords []string
+	for _, line := range strings.Split(commonPasswords, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+	return passwords
+}
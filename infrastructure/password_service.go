@@ -1,28 +1,179 @@
 package infrastructure
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-type PasswordInfrastructure struct {}
+// PasswordHashConfig holds the argon2id cost parameters. Hashes record the
+// parameters they were made with, so raising them only affects new hashes and
+// the ones rehashed at login.
+type PasswordHashConfig struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultPasswordHashConfig follows the OWASP recommendation for argon2id.
+func DefaultPasswordHashConfig() PasswordHashConfig {
+	return PasswordHashConfig{
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func LoadPasswordHashConfigFromEnv() (PasswordHashConfig, error) {
+	config := DefaultPasswordHashConfig()
+
+	params := map[string]struct {
+		target *uint32
+		max    uint64
+	}{
+		"PASSWORD_ARGON2_MEMORY":     {&config.Memory, 4 * 1024 * 1024},
+		"PASSWORD_ARGON2_ITERATIONS": {&config.Iterations, 100},
+	}
+	for envVar, param := range params {
+		if value := os.Getenv(envVar); value != "" {
+			n, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || n < 1 || n > param.max {
+				return PasswordHashConfig{}, errors.New("invalid value for " + envVar)
+			}
+			*param.target = uint32(n)
+		}
+	}
+
+	if value := os.Getenv("PASSWORD_ARGON2_PARALLELISM"); value != "" {
+		n, err := strconv.ParseUint(value, 10, 8)
+		if err != nil || n < 1 {
+			return PasswordHashConfig{}, errors.New("invalid value for PASSWORD_ARGON2_PARALLELISM")
+		}
+		config.Parallelism = uint8(n)
+	}
+
+	if config.Memory < 8*uint32(config.Parallelism) {
+		return PasswordHashConfig{}, errors.New("invalid value for PASSWORD_ARGON2_MEMORY")
+	}
+	return config, nil
+}
+
+// PasswordInfrastructure hashes with argon2id and still verifies the bcrypt
+// hashes stored before it was introduced.
+type PasswordInfrastructure struct {
+	Config PasswordHashConfig
+}
 
 func NewPasswordInfrastructure() *PasswordInfrastructure {
-	return &PasswordInfrastructure{}
+	return &PasswordInfrastructure{Config: DefaultPasswordHashConfig()}
+}
+
+func (infra *PasswordInfrastructure) config() PasswordHashConfig {
+	if infra.Config == (PasswordHashConfig{}) {
+		return DefaultPasswordHashConfig()
+	}
+	return infra.Config
 }
 
+// HashPassword returns a PHC formatted argon2id hash, for example
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>.
 func (infra *PasswordInfrastructure) HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+	config := infra.config()
+	salt := make([]byte, config.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", errors.New("unable to hash password")
 	}
-	return string(hashedPassword), nil
+
+	key := argon2.IDKey([]byte(password), salt, config.Iterations, config.Memory, config.Parallelism, config.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, config.Memory, config.Iterations, config.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
 func (infra *PasswordInfrastructure) ComparePassword(correctPassword []byte, inputPassword []byte) error {
-	if bcrypt.CompareHashAndPassword(correctPassword, inputPassword) != nil {
+	if isBcryptHash(correctPassword) {
+		if bcrypt.CompareHashAndPassword(correctPassword, inputPassword) != nil {
+			return errors.New("invalid credentials")
+		}
+		return nil
+	}
+
+	hash, err := parseArgon2idHash(string(correctPassword))
+	if err != nil {
+		return errors.New("invalid credentials")
+	}
+	key := argon2.IDKey(inputPassword, hash.salt, hash.config.Iterations, hash.config.Memory, hash.config.Parallelism, uint32(len(hash.key)))
+	if subtle.ConstantTimeCompare(key, hash.key) != 1 {
 		return errors.New("invalid credentials")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// NeedsRehash reports whether a stored hash was made with another algorithm
+// or other parameters than the ones currently configured.
+func (infra *PasswordInfrastructure) NeedsRehash(hashedPassword string) bool {
+	hash, err := parseArgon2idHash(hashedPassword)
+	if err != nil {
+		return true
+	}
+	config := infra.config()
+	return hash.config.Memory != config.Memory ||
+		hash.config.Iterations != config.Iterations ||
+		hash.config.Parallelism != config.Parallelism ||
+		uint32(len(hash.salt)) != config.SaltLength ||
+		uint32(len(hash.key)) != config.KeyLength
+}
+
+type argon2idHash struct {
+	config PasswordHashConfig
+	salt   []byte
+	key    []byte
+}
+
+func parseArgon2idHash(encoded string) (argon2idHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return argon2idHash{}, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2idHash{}, errors.New("unsupported argon2 version")
+	}
+
+	var hash argon2idHash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.config.Memory, &hash.config.Iterations, &hash.config.Parallelism); err != nil {
+		return argon2idHash{}, errors.New("invalid argon2id parameters")
+	}
+	if hash.config.Iterations < 1 || hash.config.Parallelism < 1 {
+		return argon2idHash{}, errors.New("invalid argon2id parameters")
+	}
+
+	var err error
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return argon2idHash{}, errors.New("invalid argon2id salt")
+	}
+	if hash.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(hash.key) == 0 {
+		return argon2idHash{}, errors.New("invalid argon2id key")
+	}
+	hash.config.SaltLength = uint32(len(hash.salt))
+	hash.config.KeyLength = uint32(len(hash.key))
+	return hash, nil
+}
+
+func isBcryptHash(hash []byte) bool {
+	prefix := string(hash)
+	return strings.HasPrefix(prefix, "$2a$") || strings.HasPrefix(prefix, "$2b$") || strings.HasPrefix(prefix, "$2y$")
+}
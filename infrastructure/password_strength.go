@@ -0,0 +1,314 @@
+package infrastructure
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The estimator follows the idea behind zxcvbn: a password is split into the
+// cheapest sequence of guessable patterns (common passwords, the user's own
+// name or email, sequences, repeats, keyboard walks, years) and whatever is
+// left is brute forced. The score is derived from the resulting number of
+// guesses, using the same thresholds as zxcvbn.
+
+const (
+	matchDictionary = "dictionary"
+	matchUserInput  = "user_input"
+	matchSequence   = "sequence"
+	matchRepeat     = "repeat"
+	matchKeyboard   = "keyboard"
+	matchYear       = "year"
+)
+
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm", "qwertzuiop", "azertyuiop", "yxcvbnm"}
+
+var leetSubstitutions = map[rune]rune{
+	'@': 'a', '4': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't',
+}
+
+var matchSuggestions = map[string]string{
+	matchDictionary: "avoid common passwords and predictable substitutions like @ for a",
+	matchUserInput:  "avoid using your username or email address",
+	matchSequence:   "avoid sequences like abc or 123",
+	matchRepeat:     "avoid repeated characters",
+	matchKeyboard:   "avoid keyboard patterns like qwerty",
+	matchYear:       "avoid years and dates that are associated with you",
+}
+
+type strengthMatch struct {
+	start, end int // rune offsets, end exclusive
+	bits       float64
+	kind       string
+}
+
+type strengthEstimate struct {
+	bits        float64
+	score       int
+	suggestions []string
+}
+
+// estimateStrength returns the estimated entropy of password given a ranked
+// dictionary (rank 1 is the most common) and words tied to the account.
+func estimateStrength(password string, dictionary map[string]int, userInputs []string) strengthEstimate {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return strengthEstimate{suggestions: []string{"use a few words, avoid common phrases"}}
+	}
+
+	matches := findMatches(runes, dictionary, userInputs)
+
+	// best[i] is the cheapest way to guess the first i runes.
+	best := make([]float64, len(runes)+1)
+	via := make([]*strengthMatch, len(runes)+1)
+	for i := 1; i <= len(runes); i++ {
+		best[i] = best[i-1] + bruteForceBits(runes[i-1])
+		via[i] = nil
+		for j := range matches {
+			m := &matches[j]
+			if m.end == i && best[m.start]+m.bits < best[i] {
+				best[i] = best[m.start] + m.bits
+				via[i] = m
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	var suggestions []string
+	for i := len(runes); i > 0; {
+		m := via[i]
+		if m == nil {
+			i--
+			continue
+		}
+		if !seen[m.kind] {
+			seen[m.kind] = true
+			suggestions = append(suggestions, matchSuggestions[m.kind])
+		}
+		i = m.start
+	}
+	sort.Strings(suggestions)
+
+	bits := best[len(runes)]
+	score := scoreFromBits(bits)
+	if score < 3 {
+		suggestions = append(suggestions, "add another word or two; uncommon words are better")
+	}
+	return strengthEstimate{bits: bits, score: score, suggestions: suggestions}
+}
+
+// scoreFromBits maps entropy onto zxcvbn's 0-4 scale, whose thresholds are
+// 10^3, 10^6, 10^8 and 10^10 guesses.
+func scoreFromBits(bits float64) int {
+	guesses := bits * math.Log10(2)
+	switch {
+	case guesses < 3:
+		return 0
+	case guesses < 6:
+		return 1
+	case guesses < 8:
+		return 2
+	case guesses < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func bruteForceBits(r rune) float64 {
+	switch {
+	case r >= '0' && r <= '9':
+		return math.Log2(10)
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return math.Log2(26)
+	case r < unicode.MaxASCII:
+		return math.Log2(33)
+	default:
+		return math.Log2(100)
+	}
+}
+
+func findMatches(runes []rune, dictionary map[string]int, userInputs []string) []strengthMatch {
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		lower = runes
+	}
+	unleet := make([]rune, len(lower))
+	leeted := false
+	for i, r := range lower {
+		if plain, ok := leetSubstitutions[r]; ok {
+			unleet[i] = plain
+			leeted = true
+		} else {
+			unleet[i] = r
+		}
+	}
+
+	inputs := map[string]bool{}
+	for _, input := range userInputs {
+		if len([]rune(input)) >= 3 {
+			inputs[input] = true
+		}
+	}
+
+	var matches []strengthMatch
+	for i := 0; i < len(runes); i++ {
+		for j := i + 3; j <= len(runes); j++ {
+			candidates := []string{string(lower[i:j])}
+			if leeted {
+				if word := string(unleet[i:j]); word != candidates[0] {
+					candidates = append(candidates, word)
+				}
+			}
+			for k, word := range candidates {
+				extra := caseBits(runes[i:j])
+				if k > 0 {
+					extra++
+				}
+				if inputs[word] {
+					matches = append(matches, strengthMatch{start: i, end: j, bits: 1 + extra, kind: matchUserInput})
+				}
+				if rank, ok := dictionary[word]; ok && j-i >= 4 {
+					matches = append(matches, strengthMatch{start: i, end: j, bits: math.Log2(float64(rank)) + 1 + extra, kind: matchDictionary})
+				}
+			}
+		}
+	}
+
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, repeatMatches(lower)...)
+	matches = append(matches, keyboardMatches(lower)...)
+	matches = append(matches, yearMatches(lower)...)
+	return matches
+}
+
+// caseBits is the extra work to guess where capitals are, free for all lower
+// case and cheap for the usual first-letter or all-caps variants.
+func caseBits(word []rune) float64 {
+	upper := 0
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	switch {
+	case upper == 0:
+		return 0
+	case upper == letters, upper == 1 && unicode.IsUpper(word[0]):
+		return 1
+	default:
+		return float64(upper)
+	}
+}
+
+func sequenceMatches(lower []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i+2 < len(lower); {
+		delta := lower[i+1] - lower[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(lower) && lower[j+1]-lower[j] == delta {
+			j++
+		}
+		if j-i+1 >= 3 {
+			bits := 2 + math.Log2(float64(j-i+1))
+			if delta < 0 {
+				bits++
+			}
+			matches = append(matches, strengthMatch{start: i, end: j + 1, bits: bits, kind: matchSequence})
+		}
+		i = j
+	}
+	return matches
+}
+
+func repeatMatches(lower []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i < len(lower); {
+		j := i
+		for j+1 < len(lower) && lower[j+1] == lower[i] {
+			j++
+		}
+		if j-i+1 >= 3 {
+			bits := bruteForceBits(lower[i]) + math.Log2(float64(j-i+1))
+			matches = append(matches, strengthMatch{start: i, end: j + 1, bits: bits, kind: matchRepeat})
+		}
+		i = j + 1
+	}
+	return matches
+}
+
+func keyboardMatches(lower []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := range lower {
+		for j := i + 4; j <= len(lower); j++ {
+			part := string(lower[i:j])
+			for _, row := range keyboardRows {
+				if strings.Contains(row, part) {
+					matches = append(matches, strengthMatch{start: i, end: j, bits: 3 + math.Log2(float64(j-i)), kind: matchKeyboard})
+					break
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func yearMatches(lower []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i+4 <= len(lower); i++ {
+		year := string(lower[i : i+4])
+		if (strings.HasPrefix(year, "19") || strings.HasPrefix(year, "20")) && isDigits(year) {
+			matches = append(matches, strengthMatch{start: i, end: i + 4, bits: math.Log2(200), kind: matchYear})
+		}
+	}
+	return matches
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// userInputWords splits the username and email into the fragments people
+// tend to reuse in their passwords.
+func userInputWords(username string, email string) []string {
+	var words []string
+	add := func(value string) {
+		value = strings.ToLower(value)
+		if value != "" {
+			words = append(words, value)
+		}
+		for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if part != value {
+				words = append(words, part)
+			}
+		}
+	}
+	add(username)
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		add(email[:at])
+		domain := email[at+1:]
+		if dot := strings.Index(domain, "."); dot > 0 {
+			domain = domain[:dot]
+		}
+		add(domain)
+	} else {
+		add(email)
+	}
+	return words
+}
@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+)
+
+// UserPolicy lets users act on their own account. Roles granting bypass may
+// act on any account; an empty bypass makes the route owner-only.
+func UserPolicy(bypass string) domain.Policy {
+	return func(ctx context.Context, principal domain.Principal, resourceID string) error {
+		if principal.UserID != "" && principal.UserID == resourceID {
+			return nil
+		}
+		return bypassOrForbid(principal, bypass)
+	}
+}
+
+// BlogPolicy lets authors act on their own posts, and roles granting bypass
+// on everyone's.
+func BlogPolicy(owners domain.IOwnershipRepository, bypass string) domain.Policy {
+	return ownedBy(owners.BlogOwner, bypass)
+}
+
+// CommentPolicy lets users act on their own comments, and roles granting
+// bypass on everyone's.
+func CommentPolicy(owners domain.IOwnershipRepository, bypass string) domain.Policy {
+	return ownedBy(owners.CommentOwner, bypass)
+}
+
+func ownedBy(owner func(context.Context, int64) (int64, error), bypass string) domain.Policy {
+	return func(ctx context.Context, principal domain.Principal, resourceID string) error {
+		id, err := strconv.ParseInt(resourceID, 10, 64)
+		if err != nil {
+			return domain.ErrResourceNotFound
+		}
+
+		ownerID, err := owner(ctx, id)
+		if err != nil {
+			return err
+		}
+		if principal.UserID != "" && principal.UserID == strconv.FormatInt(ownerID, 10) {
+			return nil
+		}
+		return bypassOrForbid(principal, bypass)
+	}
+}
+
+func bypassOrForbid(principal domain.Principal, bypass string) error {
+	if bypass != "" && domain.RoleHasPermission(principal.Role, bypass) {
+		return nil
+	}
+	return domain.ErrForbidden
+}
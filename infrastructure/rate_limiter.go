@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+// RateLimiter is a fixed window limiter on top of an ILoginAttemptStore: the
+// failure counter counts calls and LockedUntil marks the end of the window.
+type RateLimiter struct {
+	store  domain.ILoginAttemptStore
+	limit  int
+	window time.Duration
+	prefix string
+	Now    func() time.Time
+}
+
+// NewRateLimiter namespaces its keys with prefix so it can share a store with
+// the login throttler.
+func NewRateLimiter(store domain.ILoginAttemptStore, prefix string, limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		store:  store,
+		limit:  limit,
+		window: window,
+		prefix: prefix,
+		Now:    time.Now,
+	}
+}
+
+func (rl *RateLimiter) Allow(key string) (time.Duration, error) {
+	key = rl.prefix + key
+	now := rl.Now()
+
+	attempt, err := rl.store.Fetch(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if !attempt.LockedUntil.After(now) {
+		if attempt.Failures > 0 {
+			if err := rl.store.Reset(key); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := rl.store.RecordFailure(key, now); err != nil {
+			return 0, err
+		}
+		return 0, rl.store.Lock(key, now.Add(rl.window))
+	}
+
+	if attempt.Failures >= rl.limit {
+		return attempt.LockedUntil.Sub(now), nil
+	}
+	_, err = rl.store.RecordFailure(key, now)
+	return 0, err
+}
+
+// LoadRateLimitFromEnv reads a call count and a window duration, falling back
+// to the given defaults for unset variables.
+func LoadRateLimitFromEnv(limitVar string, windowVar string, limit int, window time.Duration) (int, time.Duration, error) {
+	if value := os.Getenv(limitVar); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("invalid value for " + limitVar)
+		}
+		limit = n
+	}
+	if value := os.Getenv(windowVar); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return 0, 0, errors.New("invalid duration for " + windowVar)
+		}
+		window = d
+	}
+	return limit, window, nil
+}
@@ -0,0 +1,20 @@
+package infrastructure
+
+import (
+	"log"
+	"time"
+)
+
+// Every runs job in the background once per interval until the process exits.
+// Errors are logged; a failed run is simply retried on the next tick.
+func Every(interval time.Duration, name string, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := job(); err != nil {
+				log.Printf("%v: %v", name, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// InMemoryTokenDenylist is enough for a single instance. Deployments with
+// several replicas should use the database backed denylist instead.
+type InMemoryTokenDenylist struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+func NewInMemoryTokenDenylist() *InMemoryTokenDenylist {
+	return &InMemoryTokenDenylist{revoked: make(map[string]time.Time)}
+}
+
+func (d *InMemoryTokenDenylist) IsRevoked(jti string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.revoked[jti]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(expiresAt) {
+		delete(d.revoked, jti)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (d *InMemoryTokenDenylist) Revoke(jti string, expiresAt time.Time) error {
+	_, err := d.Consume(jti, expiresAt)
+	return err
+}
+
+func (d *InMemoryTokenDenylist) Consume(jti string, expiresAt time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range d.revoked {
+		if now.After(exp) {
+			delete(d.revoked, id)
+		}
+	}
+	if _, ok := d.revoked[jti]; ok {
+		return false, nil
+	}
+	d.revoked[jti] = expiresAt
+	return true, nil
+}
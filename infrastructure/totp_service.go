@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTPInfrastructure implements RFC 6238 with the parameters every
+// authenticator app understands: HMAC-SHA1, 6 digits and a 30 second period.
+type TOTPInfrastructure struct {
+	Issuer string
+	Period time.Duration
+	Digits int
+	Skew   int // number of periods accepted either side of now
+	Now    func() time.Time
+}
+
+func NewTOTPInfrastructure(issuer string) *TOTPInfrastructure {
+	return &TOTPInfrastructure{
+		Issuer: issuer,
+		Period: 30 * time.Second,
+		Digits: 6,
+		Skew:   1,
+		Now:    time.Now,
+	}
+}
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func (infra *TOTPInfrastructure) GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.New("unable to generate secret")
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+func (infra *TOTPInfrastructure) ProvisioningURI(secret string, accountName string) string {
+	label := url.PathEscape(infra.Issuer + ":" + accountName)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", infra.Issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(infra.Digits))
+	params.Set("period", fmt.Sprint(int(infra.Period.Seconds())))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// GenerateCode returns the code for the time step containing t.
+func (infra *TOTPInfrastructure) GenerateCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", errors.New("invalid secret")
+	}
+	return infra.codeForStep(key, infra.step(t)), nil
+}
+
+// Verify checks code against the steps around now and returns the matching
+// step. Steps at or before lastUsedStep are rejected so a code cannot be
+// replayed within its validity window.
+func (infra *TOTPInfrastructure) Verify(secret string, code string, lastUsedStep int64) (int64, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, errors.New("invalid secret")
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != infra.Digits {
+		return 0, errors.New("invalid code")
+	}
+
+	current := infra.step(infra.Now())
+	for offset := -infra.Skew; offset <= infra.Skew; offset++ {
+		step := current + int64(offset)
+		if step <= lastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(infra.codeForStep(key, step)), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, errors.New("invalid code")
+}
+
+func (infra *TOTPInfrastructure) step(t time.Time) int64 {
+	return t.Unix() / int64(infra.Period.Seconds())
+}
+
+func (infra *TOTPInfrastructure) codeForStep(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < infra.Digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", infra.Digits, value%modulo)
+}
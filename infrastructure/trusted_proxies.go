@@ -0,0 +1,20 @@
+package infrastructure
+
+import (
+	"os"
+	"strings"
+)
+
+// LoadTrustedProxiesFromEnv reads TRUSTED_PROXIES, a comma separated list of
+// IPs or CIDRs. Only requests arriving from one of them may set the client IP
+// through X-Forwarded-For; with none configured the peer address is used, so
+// clients cannot pick the IP the login throttler counts against.
+func LoadTrustedProxiesFromEnv() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountRepository struct {
+	DB *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{
+		DB: db,
+	}
+}
+
+func (repo *AccountRepository) CollectPersonalData(userID int64) (domain.PersonalData, error) {
+	var data domain.PersonalData
+	if err := repo.DB.First(&data.User, userID).Error; err != nil {
+		return domain.PersonalData{}, err
+	}
+
+	queries := []struct {
+		dest  interface{}
+		query string
+	}{
+		{&data.Posts, "user_id = ?"},
+		{&data.Comments, "user_id = ?"},
+		{&data.Reactions, "user_id = ?"},
+		{&data.Sessions, "user_id = ?"},
+		{&data.PersonalAccessTokens, "user_id = ?"},
+		{&data.LinkedIdentities, "user_id = ?"},
+		{&data.Following, "follower_id = ?"},
+		{&data.Followers, "followee_id = ?"},
+	}
+	for _, q := range queries {
+		if err := repo.DB.Where(q.query, userID).Order("id").Find(q.dest).Error; err != nil {
+			return domain.PersonalData{}, err
+		}
+	}
+	return data, nil
+}
+
+func (repo *AccountRepository) CreateExport(export *domain.DataExport) error {
+	return repo.DB.Create(export).Error
+}
+
+func (repo *AccountRepository) SaveExport(export *domain.DataExport) error {
+	return repo.DB.Save(export).Error
+}
+
+// LatestExport returns a zero DataExport when the user never asked for one.
+func (repo *AccountRepository) LatestExport(userID int64) (domain.DataExport, error) {
+	var export domain.DataExport
+	result := repo.DB.Omit("archive").Where("user_id = ?", userID).Order("created_at DESC").First(&export)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.DataExport{}, nil
+	}
+	return export, result.Error
+}
+
+// ClaimExports marks up to limit queued exports as building until now+lease
+// and returns them. Exports other replicas are claiming are skipped, and a
+// build stuck past its lease is claimed again.
+func (repo *AccountRepository) ClaimExports(now time.Time, lease time.Duration, limit int) ([]domain.DataExport, error) {
+	var exports []domain.DataExport
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND locked_until <= ?)", "pending", "building", now).
+			Order("id").Limit(limit).Find(&exports).Error
+		if err != nil || len(exports) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(exports))
+		for i := range exports {
+			ids[i] = exports[i].ID
+		}
+		lockedUntil := now.Add(lease)
+		err = tx.Model(&domain.DataExport{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       "building",
+			"locked_until": lockedUntil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		for i := range exports {
+			exports[i].Status = "building"
+			exports[i].LockedUntil = &lockedUntil
+		}
+		return nil
+	})
+	return exports, err
+}
+
+// PurgeExpiredExports drops the archive and link of every ready export past
+// its expiry. The row stays behind so the request cooldown still applies.
+func (repo *AccountRepository) PurgeExpiredExports(now time.Time) (int64, error) {
+	result := repo.DB.Model(&domain.DataExport{}).
+		Where("status = ? AND expires_at < ?", "ready", now).
+		Updates(map[string]interface{}{"status": "expired", "archive": nil, "token_hash": nil})
+	return result.RowsAffected, result.Error
+}
+
+func (repo *AccountRepository) FetchExportByTokenHash(tokenHash string) (domain.DataExport, error) {
+	var export domain.DataExport
+	err := repo.DB.Where("token_hash = ?", tokenHash).First(&export).Error
+	return export, err
+}
+
+// ScheduleDeletion keeps an already scheduled deletion as it is and loads it
+// into deletion, so asking twice does not push the date out.
+func (repo *AccountRepository) ScheduleDeletion(deletion *domain.AccountDeletion) error {
+	if err := repo.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(deletion).Error; err != nil {
+		return err
+	}
+	return repo.DB.Where("user_id = ?", deletion.UserID).First(deletion).Error
+}
+
+func (repo *AccountRepository) CancelDeletion(userID int64) error {
+	result := repo.DB.Unscoped().Where("user_id = ?", userID).Delete(&domain.AccountDeletion{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (repo *AccountRepository) FetchDueDeletions(now time.Time) ([]domain.AccountDeletion, error) {
+	var deletions []domain.AccountDeletion
+	err := repo.DB.Where("scheduled_for <= ?", now).Order("scheduled_for").Find(&deletions).Error
+	return deletions, err
+}
+
+// RevokeSessions blocks every stored token of the user and revokes their
+// personal access tokens. It returns the tokens that were still active so the
+// caller can also denylist stateless access tokens.
+func (repo *AccountRepository) RevokeSessions(userID int64, at time.Time) ([]domain.Token, error) {
+	var active []domain.Token
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND status <> ?", userID, "blocked").Find(&active).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Token{}).Where("user_id = ?", userID).Update("status", "blocked").Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.PersonalAccessToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", at).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return active, nil
+}
+
+// AnonymizeUser scrubs the account but leaves its posts and comments in place
+// under a placeholder name.
+func (repo *AccountRepository) AnonymizeUser(userID int64) error {
+	placeholder := fmt.Sprintf("deleted-%d", userID)
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":        placeholder,
+			"email":           placeholder + "@deleted.invalid",
+			"password":        "",
+			"role":            domain.RoleReader,
+			"bio":             "",
+			"profile_picture": "",
+			"phone":           "",
+			"status":          "deleted",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return removeAccountData(tx, userID)
+	})
+}
+
+// PurgeUser removes the account, its posts with everything attached to them,
+// and its comments and reactions.
+func (repo *AccountRepository) PurgeUser(userID int64) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		posts := tx.Unscoped().Model(&domain.Blog{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Unscoped().Where("user_id = ? OR blog_id IN (?)", userID, posts).Delete(&domain.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ? OR blog_id IN (?)", userID, posts).Delete(&domain.Reaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("blog_id IN (?)", posts).Delete(&domain.Tag_Blog{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&domain.Blog{}).Error; err != nil {
+			return err
+		}
+		if err := removeAccountData(tx, userID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&domain.User{}, userID).Error
+	})
+}
+
+// removeAccountData deletes everything tied to the account apart from the
+// user row and authored content.
+func removeAccountData(tx *gorm.DB, userID int64) error {
+	owned := []interface{}{
+		&domain.Token{},
+		&domain.PersonalAccessToken{},
+		&domain.RecoveryCode{},
+		&domain.TwoFactor{},
+		&domain.LinkedIdentity{},
+		&domain.EmailChangeRequest{},
+		&domain.DataExport{},
+		&domain.ProfilePrivacy{},
+		&domain.AccountDeletion{},
+		&domain.PasswordHistory{},
+		&domain.Notification{},
+		&domain.NotificationPreference{},
+		&domain.Subscription{},
+	}
+	for _, model := range owned {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Unscoped().Where("follower_id = ? OR followee_id = ?", userID, userID).Delete(&domain.Follow{}).Error; err != nil {
+		return err
+	}
+	// Notifications about the user's actions name them in the message.
+	if err := tx.Unscoped().Where("actor_id = ?", userID).Delete(&domain.Notification{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("key = ?", domain.LoginAttemptAccountKey(strconv.FormatInt(userID, 10))).Delete(&domain.LoginAttempt{}).Error
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type AdminRepository struct {
+	DB *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{
+		DB: db,
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers returns one page of users matching filter, oldest first, along
+// with the number of matches on all pages.
+func (repo *AdminRepository) SearchUsers(filter domain.UserFilter) ([]domain.User, int64, error) {
+	query := repo.DB.Model(&domain.User{})
+	if filter.Query != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EmailStatus != "" {
+		query = query.Where("email_status = ?", filter.EmailStatus)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	err := query.Order("id").Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).Find(&users).Error
+	return users, total, err
+}
+
+func (repo *AdminRepository) FetchSessions(userID int64, limit int) ([]domain.Token, error) {
+	var tokens []domain.Token
+	err := repo.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&tokens).Error
+	return tokens, err
+}
+
+func (repo *AdminRepository) RecentPosts(userID int64, limit int) ([]domain.Blog, error) {
+	var blogs []domain.Blog
+	err := repo.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&blogs).Error
+	return blogs, err
+}
+
+func (repo *AdminRepository) RecentComments(userID int64, limit int) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	err := repo.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+func (repo *AdminRepository) SetStatus(userID int64, status string, suspension domain.Suspension) error {
+	result := repo.DB.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"status":            status,
+		"suspension_reason": suspension.Reason,
+		"suspended_until":   suspension.Until,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// LiftExpiredSuspensions reactivates users whose suspension has run out and
+// returns how many there were. Bans never expire.
+func (repo *AdminRepository) LiftExpiredSuspensions(now time.Time) (int64, error) {
+	result := repo.DB.Model(&domain.User{}).
+		Where("status = ? AND suspended_until <= ?", "suspended", now).
+		Updates(map[string]interface{}{
+			"status":            "active",
+			"suspension_reason": "",
+			"suspended_until":   nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ClearPassword makes every password fail to match until the user sets a new
+// one through a reset link.
+func (repo *AdminRepository) ClearPassword(userID int64) error {
+	return repo.DB.Model(&domain.User{}).Where("id = ?", userID).Update("password", "").Error
+}
+
+// ClearEmailStatus lets mail reach the user's address again, taking it off
+// the suppression list as well.
+func (repo *AdminRepository) ClearEmailStatus(userID int64) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"email_status":        "",
+			"email_status_reason": "",
+			"email_status_at":     nil,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		email := tx.Model(&domain.User{}).Select("lower(email)").Where("id = ?", userID)
+		return tx.Unscoped().Where("email = (?)", email).Delete(&domain.SuppressedAddress{}).Error
+	})
+}
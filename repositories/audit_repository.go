@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+// auditChainLock is the advisory lock key that serializes appends, so two
+// concurrent events can never claim the same predecessor.
+const auditChainLock = 7261001
+
+type AuditRepository struct {
+	DB *gorm.DB
+	// Sink, when set, receives a copy of every event after it is stored.
+	Sink domain.IAuditSink
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		DB: db,
+	}
+}
+
+// Record appends event to the hash chain. CreatedAt, PrevHash and Hash are
+// overwritten.
+func (repo *AuditRepository) Record(event *domain.AuditEvent) error {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLock).Error; err != nil {
+			return err
+		}
+
+		var last domain.AuditEvent
+		err := tx.Unscoped().Select("hash").Order("id DESC").Take(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		event.PrevHash = last.Hash
+		event.Hash = event.ComputeHash()
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if repo.Sink != nil {
+		// the database is the record of truth, a failing sink only gets logged
+		if err := repo.Sink.Write(*event); err != nil {
+			log.Printf("audit sink: %v", err)
+		}
+	}
+	return nil
+}
+
+// FetchByUser returns the latest events the user performed or was the target
+// of, newest first.
+func (repo *AuditRepository) FetchByUser(userID int64, limit int) ([]domain.AuditEvent, error) {
+	var events []domain.AuditEvent
+	err := repo.DB.Where("actor_id = ? OR target_id = ?", userID, userID).Order("id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// Search returns one page of matching events, newest first, along with the
+// number of matches on all pages.
+func (repo *AuditRepository) Search(filter domain.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	query := repo.DB.Model(&domain.AuditEvent{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []domain.AuditEvent
+	err := query.Order("id DESC").Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).Find(&events).Error
+	return events, total, err
+}
+
+// FetchAfter returns up to limit events with an id above id in chain order.
+// Soft deleted rows are included, since hiding them would hide a gap.
+func (repo *AuditRepository) FetchAfter(id int64, limit int) ([]domain.AuditEvent, error) {
+	var events []domain.AuditEvent
+	err := repo.DB.Unscoped().Where("id > ?", id).Order("id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// EnsureAuditAppendOnly installs a trigger that rejects every UPDATE and
+// DELETE on audit_events, so the log stays append-only even for code that
+// does not go through AuditRepository.
+func EnsureAuditAppendOnly(db *gorm.DB) error {
+	return db.Exec(`
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();`).Error
+}
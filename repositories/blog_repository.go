@@ -45,14 +45,14 @@ func (r *BlogRepository) LinkTagToBlog(ctx context.Context, blogID int64, tagID
 
 func (r *BlogRepository) FetchByID(ctx context.Context, id int64) (*domain.Blog, error) {
 	var blog domain.Blog
-	if err := r.db.WithContext(ctx).Preload("User").Preload("Tags").First(&blog, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("User").First(&blog, id).Error; err != nil {
 		return nil, err
 	}
 	return &blog, nil
 }
 func (r *BlogRepository) FetchAll(ctx context.Context) ([]*domain.Blog, error) {
 	var blogs []*domain.Blog
-	if err := r.db.WithContext(ctx).Preload("User").Preload("Tags").
+	if err := r.db.WithContext(ctx).Preload("User").
 		Find(&blogs).Error; err != nil {
 		return nil, err
 	}
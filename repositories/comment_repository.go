@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type CommentRepository struct {
+	DB *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{
+		DB: db,
+	}
+}
+
+func (repo *CommentRepository) Create(comment *domain.Comment) error {
+	return repo.DB.Omit("User", "Blog").Create(comment).Error
+}
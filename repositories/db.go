@@ -22,8 +22,19 @@ func ConnectDB() {
 
     DB = db
 
-	err = DB.AutoMigrate(&domain.User{}, &domain.Blog{}, &domain.Comment{}, &domain.Tag{}, &domain.Tag_Blog{}, &domain.Token{})
+	err = DB.AutoMigrate(&domain.User{}, &domain.Blog{}, &domain.Comment{}, &domain.Tag{}, &domain.Tag_Blog{}, &domain.Token{}, &domain.RevokedToken{}, &domain.TwoFactor{}, &domain.RecoveryCode{}, &domain.LoginAttempt{}, &domain.LinkedIdentity{}, &domain.OIDCAuthRequest{}, &domain.PersonalAccessToken{}, &domain.EmailChangeRequest{}, &domain.ProfilePrivacy{}, &domain.Follow{}, &domain.DataExport{}, &domain.AccountDeletion{}, &domain.Reaction{}, &domain.AuditEvent{}, &domain.PasswordHistory{}, &domain.OutboxEmail{}, &domain.Notification{}, &domain.NotificationPreference{}, &domain.Subscription{}, &domain.SuppressedAddress{})
     if err != nil {
         log.Fatal("Failed to migrate database:", err)
     }
+    if err := EnsureAuditAppendOnly(DB); err != nil {
+        log.Fatal("Failed to protect audit log:", err)
+    }
+    if err := HashUnsubscribeTokens(DB); err != nil {
+        log.Fatal("Failed to hash unsubscribe tokens:", err)
+    }
+    if promoted, err := BootstrapSuperadmin(DB, os.Getenv("SUPERADMIN_EMAIL")); err != nil {
+        log.Fatal("Failed to bootstrap superadmin:", err)
+    } else if promoted {
+        log.Printf("Promoted %s to superadmin", os.Getenv("SUPERADMIN_EMAIL"))
+    }
 }
\ No newline at end of file
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeliverabilityRepository struct {
+	DB *gorm.DB
+}
+
+func NewDeliverabilityRepository(db *gorm.DB) *DeliverabilityRepository {
+	return &DeliverabilityRepository{
+		DB: db,
+	}
+}
+
+// Suppressed returns the addresses among addresses that are on the
+// suppression list or belong to a user whose email status is set, lowercased.
+func (repo *DeliverabilityRepository) Suppressed(addresses []string) ([]string, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	lowered := make([]string, len(addresses))
+	for i, address := range addresses {
+		lowered[i] = strings.ToLower(address)
+	}
+
+	var listed []string
+	err := repo.DB.Model(&domain.SuppressedAddress{}).
+		Where("email IN ?", lowered).
+		Pluck("email", &listed).Error
+	if err != nil {
+		return nil, err
+	}
+	var flagged []string
+	err = repo.DB.Model(&domain.User{}).
+		Where("lower(email) IN ? AND email_status <> ''", lowered).
+		Pluck("lower(email)", &flagged).Error
+	if err != nil {
+		return nil, err
+	}
+
+	suppressed := listed
+	for _, address := range flagged {
+		if !slices.Contains(suppressed, address) {
+			suppressed = append(suppressed, address)
+		}
+	}
+	return suppressed, nil
+}
+
+func (repo *DeliverabilityRepository) MarkAddress(email string, status string, reason string, at time.Time) (int64, error) {
+	email = strings.ToLower(email)
+	var marked int64
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		address := domain.SuppressedAddress{Email: email, Status: status, Reason: reason, SuppressedAt: at}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "email"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "reason", "suppressed_at", "updated_at", "deleted_at"}),
+		}).Create(&address).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&domain.User{}).Where("lower(email) = ?", email).Updates(map[string]interface{}{
+			"email_status":        status,
+			"email_status_reason": reason,
+			"email_status_at":     at,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		marked = result.RowsAffected
+
+		return tx.Model(&domain.Subscription{}).
+			Where("email = ? AND status <> ?", email, domain.SubscriptionStatusUnsubscribed).
+			Update("status", domain.SubscriptionStatusUnsubscribed).Error
+	})
+	return marked, err
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailChangeRepository struct {
+	DB *gorm.DB
+}
+
+func NewEmailChangeRepository(db *gorm.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{
+		DB: db,
+	}
+}
+
+// Save replaces any pending change of the same user, so only the most recent
+// confirmation link works.
+func (repo *EmailChangeRepository) Save(request *domain.EmailChangeRequest) error {
+	if err := repo.DB.Unscoped().Where("expires_at < ?", time.Now()).Delete(&domain.EmailChangeRequest{}).Error; err != nil {
+		return err
+	}
+	return repo.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"new_email", "token_hash", "expires_at", "updated_at"}),
+	}).Create(request).Error
+}
+
+// Consume deletes and returns the request in one statement, so a link can
+// never be redeemed twice.
+func (repo *EmailChangeRepository) Consume(tokenHash string) (domain.EmailChangeRequest, error) {
+	var requests []domain.EmailChangeRequest
+	result := repo.DB.Unscoped().Clauses(clause.Returning{}).Where("token_hash = ?", tokenHash).Delete(&requests)
+	if result.Error != nil {
+		return domain.EmailChangeRequest{}, result.Error
+	}
+	if len(requests) == 0 {
+		return domain.EmailChangeRequest{}, gorm.ErrRecordNotFound
+	}
+	return requests[0], nil
+}
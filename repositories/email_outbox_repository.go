@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailOutboxRepository struct {
+	DB *gorm.DB
+}
+
+func NewEmailOutboxRepository(db *gorm.DB) *EmailOutboxRepository {
+	return &EmailOutboxRepository{
+		DB: db,
+	}
+}
+
+func (repo *EmailOutboxRepository) Enqueue(email *domain.OutboxEmail) error {
+	return repo.DB.Create(email).Error
+}
+
+// Claim marks up to limit due emails as sending until now+lease and returns
+// them. Emails other workers are claiming at the same moment are skipped
+// rather than waited for, and an email stuck in sending past its lease is
+// due again.
+func (repo *EmailOutboxRepository) Claim(now time.Time, lease time.Duration, limit int) ([]domain.OutboxEmail, error) {
+	var emails []domain.OutboxEmail
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until <= ?)",
+				domain.OutboxStatusPending, now, domain.OutboxStatusSending, now).
+			Order("next_attempt_at").Limit(limit).Find(&emails).Error
+		if err != nil || len(emails) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(emails))
+		for i := range emails {
+			ids[i] = emails[i].ID
+		}
+		lockedUntil := now.Add(lease)
+		err = tx.Model(&domain.OutboxEmail{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       domain.OutboxStatusSending,
+			"locked_until": lockedUntil,
+			"attempts":     gorm.Expr("attempts + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		for i := range emails {
+			emails[i].Status = domain.OutboxStatusSending
+			emails[i].LockedUntil = &lockedUntil
+			emails[i].Attempts++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// MarkSent records the delivery and drops what the email was rendered from.
+// Template data, headers and bodies carry live links (password resets,
+// magic links, unsubscribe tokens) that must not outlive the send; the
+// template, recipients and subject stay for the admin view.
+func (repo *EmailOutboxRepository) MarkSent(id int64, at time.Time) error {
+	return repo.DB.Model(&domain.OutboxEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       domain.OutboxStatusSent,
+		"sent_at":      at,
+		"locked_until": nil,
+		"last_error":   "",
+		"data":         gorm.Expr("NULL"),
+		"headers":      gorm.Expr("NULL"),
+		"body":         "",
+	}).Error
+}
+
+// MarkFailed schedules another attempt at nextAttemptAt, or dead-letters the
+// email when dead is set.
+func (repo *EmailOutboxRepository) MarkFailed(id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := domain.OutboxStatusPending
+	if dead {
+		status = domain.OutboxStatusDead
+	}
+	return repo.DB.Model(&domain.OutboxEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"next_attempt_at": nextAttemptAt,
+		"locked_until":    nil,
+		"last_error":      lastError,
+	}).Error
+}
+
+// Search returns one page of matching emails, newest first, along with the
+// number of matches on all pages.
+func (repo *EmailOutboxRepository) Search(filter domain.OutboxFilter) ([]domain.OutboxEmail, int64, error) {
+	query := repo.DB.Model(&domain.OutboxEmail{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emails []domain.OutboxEmail
+	err := query.Order("id DESC").Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).Find(&emails).Error
+	return emails, total, err
+}
+
+func (repo *EmailOutboxRepository) Fetch(id int64) (domain.OutboxEmail, error) {
+	var email domain.OutboxEmail
+	if err := repo.DB.First(&email, id).Error; err != nil {
+		return domain.OutboxEmail{}, err
+	}
+	return email, nil
+}
+
+// Retry makes a dead email, or a pending one waiting out its backoff, due at
+// at with a fresh set of attempts.
+func (repo *EmailOutboxRepository) Retry(id int64, at time.Time) error {
+	result := repo.DB.Model(&domain.OutboxEmail{}).
+		Where("id = ? AND status IN ?", id, []string{domain.OutboxStatusDead, domain.OutboxStatusPending}).
+		Updates(map[string]interface{}{
+			"status":          domain.OutboxStatusPending,
+			"next_attempt_at": at,
+			"attempts":        0,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrOutboxEmailNotRetryable
+	}
+	return nil
+}
+
+// PurgeSent removes emails delivered before before. Failed ones are kept
+// for inspection.
+func (repo *EmailOutboxRepository) PurgeSent(before time.Time) (int64, error) {
+	result := repo.DB.Unscoped().Where("status = ? AND sent_at < ?", domain.OutboxStatusSent, before).Delete(&domain.OutboxEmail{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository struct {
+	DB *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		DB: db,
+	}
+}
+
+func (repo *LoginAttemptRepository) Fetch(key string) (domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	result := repo.DB.Where("key = ?", key).First(&attempt)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.LoginAttempt{}, nil
+	}
+	if result.Error != nil {
+		return domain.LoginAttempt{}, result.Error
+	}
+	return attempt, nil
+}
+
+// RecordFailure increments the counter with a single upsert so concurrent
+// replicas never lose a failure.
+func (repo *LoginAttemptRepository) RecordFailure(key string, at time.Time) (domain.LoginAttempt, error) {
+	attempt := domain.LoginAttempt{Key: key, Failures: 1, LastFailureAt: at}
+	result := repo.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr(`"login_attempts"."failures" + 1`),
+			"last_failure_at": at,
+			"updated_at":      at,
+		}),
+	}).Create(&attempt)
+	if result.Error != nil {
+		return domain.LoginAttempt{}, result.Error
+	}
+	return repo.Fetch(key)
+}
+
+func (repo *LoginAttemptRepository) Lock(key string, until time.Time) error {
+	return repo.DB.Model(&domain.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+func (repo *LoginAttemptRepository) Reset(key string) error {
+	return repo.DB.Unscoped().Where("key = ?", key).Delete(&domain.LoginAttempt{}).Error
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	DB *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{
+		DB: db,
+	}
+}
+
+func (repo *NotificationRepository) Create(notification *domain.Notification) error {
+	return repo.DB.Create(notification).Error
+}
+
+// Search returns one page of the user's notification center, newest first,
+// along with the number of matches on all pages.
+func (repo *NotificationRepository) Search(filter domain.NotificationFilter) ([]domain.Notification, int64, error) {
+	query := repo.DB.Model(&domain.Notification{}).Where("user_id = ? AND in_app = ?", filter.UserID, true)
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []domain.Notification
+	err := query.Order("id DESC").Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (repo *NotificationRepository) CountUnread(userID int64) (int64, error) {
+	var count int64
+	err := repo.DB.Model(&domain.Notification{}).Where("user_id = ? AND in_app = ? AND read_at IS NULL", userID, true).Count(&count).Error
+	return count, err
+}
+
+// MarkRead returns ErrResourceNotFound when the notification does not belong
+// to the user. Marking a read notification again keeps the first ReadAt.
+func (repo *NotificationRepository) MarkRead(userID int64, id int64, at time.Time) error {
+	var notification domain.Notification
+	if err := repo.DB.Where("id = ? AND user_id = ? AND in_app = ?", id, userID, true).First(&notification).Error; err != nil {
+		return domain.ErrResourceNotFound
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return repo.DB.Model(&notification).Update("read_at", at).Error
+}
+
+func (repo *NotificationRepository) MarkAllRead(userID int64, at time.Time) (int64, error) {
+	result := repo.DB.Model(&domain.Notification{}).
+		Where("user_id = ? AND in_app = ? AND read_at IS NULL", userID, true).
+		Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// FetchPreferences returns only the types the user has changed.
+func (repo *NotificationRepository) FetchPreferences(userID int64) ([]domain.NotificationPreference, error) {
+	var preferences []domain.NotificationPreference
+	err := repo.DB.Where("user_id = ?", userID).Order("type").Find(&preferences).Error
+	return preferences, err
+}
+
+func (repo *NotificationRepository) SavePreferences(preferences []domain.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return repo.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "updated_at"}),
+	}).Create(&preferences).Error
+}
+
+// FetchPendingDigests returns everything waiting for a digest, grouped by
+// user and oldest first within each user.
+func (repo *NotificationRepository) FetchPendingDigests() ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	err := repo.DB.Where("digest_pending = ?", true).Order("user_id, id").Find(&notifications).Error
+	return notifications, err
+}
+
+func (repo *NotificationRepository) MarkDigested(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return repo.DB.Model(&domain.Notification{}).Where("id IN ?", ids).Update("digest_pending", false).Error
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OIDCRepository struct {
+	DB *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) *OIDCRepository {
+	return &OIDCRepository{
+		DB: db,
+	}
+}
+
+// SaveAuthRequest also drops requests nobody came back for, which keeps the
+// table at roughly the number of logins in flight.
+func (repo *OIDCRepository) SaveAuthRequest(request *domain.OIDCAuthRequest) error {
+	if err := repo.DB.Unscoped().Where("expires_at < ?", time.Now()).Delete(&domain.OIDCAuthRequest{}).Error; err != nil {
+		return err
+	}
+	return repo.DB.Create(request).Error
+}
+
+// ConsumeAuthRequest deletes and returns the request in one statement, so a
+// state value can never be redeemed twice.
+func (repo *OIDCRepository) ConsumeAuthRequest(state string) (domain.OIDCAuthRequest, error) {
+	var requests []domain.OIDCAuthRequest
+	result := repo.DB.Unscoped().Clauses(clause.Returning{}).Where("state = ?", state).Delete(&requests)
+	if result.Error != nil {
+		return domain.OIDCAuthRequest{}, result.Error
+	}
+	if len(requests) == 0 {
+		return domain.OIDCAuthRequest{}, gorm.ErrRecordNotFound
+	}
+	return requests[0], nil
+}
+
+// FetchIdentity returns a zero LinkedIdentity without error when the external
+// account was never linked.
+func (repo *OIDCRepository) FetchIdentity(provider string, subject string) (domain.LinkedIdentity, error) {
+	var identity domain.LinkedIdentity
+	result := repo.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.LinkedIdentity{}, nil
+	}
+	if result.Error != nil {
+		return domain.LinkedIdentity{}, result.Error
+	}
+	return identity, nil
+}
+
+func (repo *OIDCRepository) FetchIdentitiesByUserID(userID int64) ([]domain.LinkedIdentity, error) {
+	var identities []domain.LinkedIdentity
+	result := repo.DB.Where("user_id = ?", userID).Order("id").Find(&identities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return identities, nil
+}
+
+func (repo *OIDCRepository) CreateIdentity(identity *domain.LinkedIdentity) error {
+	return repo.DB.Create(identity).Error
+}
+
+func (repo *OIDCRepository) DeleteIdentity(userID int64, id int64) error {
+	result := repo.DB.Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&domain.LinkedIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
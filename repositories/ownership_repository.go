@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type OwnershipRepository struct {
+	DB *gorm.DB
+}
+
+func NewOwnershipRepository(db *gorm.DB) *OwnershipRepository {
+	return &OwnershipRepository{
+		DB: db,
+	}
+}
+
+func (repo *OwnershipRepository) BlogOwner(ctx context.Context, blogID int64) (int64, error) {
+	return repo.owner(ctx, &domain.Blog{}, blogID)
+}
+
+func (repo *OwnershipRepository) CommentOwner(ctx context.Context, commentID int64) (int64, error) {
+	return repo.owner(ctx, &domain.Comment{}, commentID)
+}
+
+func (repo *OwnershipRepository) owner(ctx context.Context, model interface{}, id int64) (int64, error) {
+	var owners []int64
+	err := repo.DB.WithContext(ctx).Model(model).Where("id = ?", id).Limit(1).Pluck("user_id", &owners).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(owners) == 0 {
+		return 0, domain.ErrResourceNotFound
+	}
+	return owners[0], nil
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository struct {
+	DB *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{
+		DB: db,
+	}
+}
+
+// Recent returns the newest password hashes of a user, newest first.
+func (repo *PasswordHistoryRepository) Recent(userID int64, limit int) ([]domain.PasswordHistory, error) {
+	var entries []domain.PasswordHistory
+	err := repo.DB.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// Add stores a new hash and drops everything but the newest keep entries,
+// there is no reason to hold on to old password hashes.
+func (repo *PasswordHistoryRepository) Add(entry *domain.PasswordHistory, keep int) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		newest := tx.Model(&domain.PasswordHistory{}).Select("id").Where("user_id = ?", entry.UserID).Order("id DESC").Limit(keep)
+		return tx.Unscoped().Where("user_id = ? AND id NOT IN (?)", entry.UserID, newest).Delete(&domain.PasswordHistory{}).Error
+	})
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		DB: db,
+	}
+}
+
+func (repo *PersonalAccessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	return repo.DB.Create(token).Error
+}
+
+func (repo *PersonalAccessTokenRepository) FetchByHash(hash string) (domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	result := repo.DB.Where("token_hash = ?", hash).First(&token)
+	if result.Error != nil {
+		return domain.PersonalAccessToken{}, result.Error
+	}
+	return token, nil
+}
+
+func (repo *PersonalAccessTokenRepository) FetchByUserID(userID int64) ([]domain.PersonalAccessToken, error) {
+	var tokens []domain.PersonalAccessToken
+	result := repo.DB.Where("user_id = ?", userID).Order("id").Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+// Revoke keeps the row so the token still shows up, marked revoked, in the
+// owner's listing.
+func (repo *PersonalAccessTokenRepository) Revoke(userID int64, id int64, at time.Time) error {
+	result := repo.DB.Model(&domain.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (repo *PersonalAccessTokenRepository) TouchLastUsed(id int64, at time.Time) error {
+	return repo.DB.Model(&domain.PersonalAccessToken{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProfileRepository struct {
+	DB *gorm.DB
+}
+
+func NewProfileRepository(db *gorm.DB) *ProfileRepository {
+	return &ProfileRepository{
+		DB: db,
+	}
+}
+
+// FetchPrivacy returns the zero ProfilePrivacy, everything public, for users
+// who never changed their settings.
+func (repo *ProfileRepository) FetchPrivacy(userID int64) (domain.ProfilePrivacy, error) {
+	var privacy domain.ProfilePrivacy
+	result := repo.DB.Where("user_id = ?", userID).First(&privacy)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.ProfilePrivacy{UserID: userID}, nil
+	}
+	return privacy, result.Error
+}
+
+func (repo *ProfileRepository) SavePrivacy(privacy *domain.ProfilePrivacy) error {
+	return repo.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"hide_bio",
+			"hide_profile_picture",
+			"hide_join_date",
+			"hide_post_count",
+			"hide_follower_count",
+			"hide_reactions",
+			"hide_posts",
+			"updated_at",
+		}),
+	}).Create(privacy).Error
+}
+
+func (repo *ProfileRepository) AuthorStats(userID int64) (domain.AuthorStats, error) {
+	var stats domain.AuthorStats
+	if err := repo.DB.Model(&domain.Blog{}).Where("user_id = ?", userID).Count(&stats.PostCount).Error; err != nil {
+		return domain.AuthorStats{}, err
+	}
+	if err := repo.DB.Model(&domain.Blog{}).Where("user_id = ?", userID).Select("COALESCE(SUM(likes + dislikes), 0)").Scan(&stats.ReactionsReceived).Error; err != nil {
+		return domain.AuthorStats{}, err
+	}
+	if err := repo.DB.Model(&domain.Follow{}).Where("followee_id = ?", userID).Count(&stats.FollowerCount).Error; err != nil {
+		return domain.AuthorStats{}, err
+	}
+	return stats, nil
+}
+
+func (repo *ProfileRepository) FetchPostsByUser(userID int64, offset int, limit int) ([]domain.Blog, error) {
+	var blogs []domain.Blog
+	err := repo.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&blogs).Error
+	return blogs, err
+}
+
+// Follow does nothing if the follow already exists, and reports whether it
+// created one.
+func (repo *ProfileRepository) Follow(followerID int64, followeeID int64) (bool, error) {
+	follow := domain.Follow{FollowerID: followerID, FolloweeID: followeeID}
+	result := repo.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Unfollow deletes the row for good, otherwise the unique index would block
+// following the same user again.
+func (repo *ProfileRepository) Unfollow(followerID int64, followeeID int64) error {
+	return repo.DB.Unscoped().Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&domain.Follow{}).Error
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionRepository struct {
+	DB *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) *ReactionRepository {
+	return &ReactionRepository{
+		DB: db,
+	}
+}
+
+// React leaves or changes userID's reaction to blogID and keeps the post's
+// totals in step. It returns the kind the user had before, or "" when this is
+// their first reaction to the post.
+func (repo *ReactionRepository) React(userID int64, blogID int64, kind string) (string, error) {
+	var previous string
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		var existing domain.Reaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND blog_id = ?", userID, blogID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			reaction := domain.Reaction{UserID: userID, BlogID: blogID, Kind: kind}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction)
+			if result.Error != nil || result.RowsAffected == 0 {
+				// a concurrent request for the same pair got there first
+				previous = kind
+				return result.Error
+			}
+			return adjustReactionTotals(tx, blogID, kind, 1)
+		}
+		if err != nil {
+			return err
+		}
+
+		previous = existing.Kind
+		if existing.Kind == kind {
+			return nil
+		}
+		if err := tx.Model(&existing).Update("kind", kind).Error; err != nil {
+			return err
+		}
+		if err := adjustReactionTotals(tx, blogID, previous, -1); err != nil {
+			return err
+		}
+		return adjustReactionTotals(tx, blogID, kind, 1)
+	})
+	return previous, err
+}
+
+// Unreact removes userID's reaction to blogID and returns its kind, or ""
+// when there was none.
+func (repo *ReactionRepository) Unreact(userID int64, blogID int64) (string, error) {
+	var previous string
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		var existing domain.Reaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND blog_id = ?", userID, blogID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// deleted for good, otherwise the unique index would block reacting
+		// to the same post again
+		if err := tx.Unscoped().Delete(&existing).Error; err != nil {
+			return err
+		}
+		previous = existing.Kind
+		return adjustReactionTotals(tx, blogID, existing.Kind, -1)
+	})
+	return previous, err
+}
+
+// adjustReactionTotals moves Blog.Likes or Blog.Dislikes by delta.
+func adjustReactionTotals(tx *gorm.DB, blogID int64, kind string, delta int) error {
+	column := "likes"
+	if kind == domain.ReactionDislike {
+		column = "dislikes"
+	}
+	return tx.Model(&domain.Blog{}).Where("id = ?", blogID).
+		UpdateColumn(column, gorm.Expr(column+" + ?", delta)).Error
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type SubscriptionRepository struct {
+	DB *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		DB: db,
+	}
+}
+
+func (repo *SubscriptionRepository) FetchByUserID(userID int64) (domain.Subscription, error) {
+	var subscription domain.Subscription
+	if err := repo.DB.Where("user_id = ?", userID).First(&subscription).Error; err != nil {
+		return domain.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+func (repo *SubscriptionRepository) FetchByEmail(email string) (domain.Subscription, error) {
+	var subscription domain.Subscription
+	if err := repo.DB.Where("email = ?", email).First(&subscription).Error; err != nil {
+		return domain.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+func (repo *SubscriptionRepository) Save(subscription *domain.Subscription) error {
+	return repo.DB.Save(subscription).Error
+}
+
+// Confirm activates the pending subscription the token was sent for and
+// returns ErrSubscriptionTokenInvalid when there is none or the link expired.
+func (repo *SubscriptionRepository) Confirm(tokenHash string, at time.Time) error {
+	result := repo.DB.Model(&domain.Subscription{}).
+		Where("confirm_token_hash = ? AND status = ? AND confirm_expires_at > ?", tokenHash, domain.SubscriptionStatusPending, at).
+		Updates(map[string]interface{}{
+			"status":             domain.SubscriptionStatusActive,
+			"confirmed_at":       at,
+			"confirm_token_hash": nil,
+			"confirm_expires_at": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSubscriptionTokenInvalid
+	}
+	return nil
+}
+
+// Unsubscribe succeeds again for a subscription that is already off, since
+// mail clients may send the one-click request more than once.
+func (repo *SubscriptionRepository) Unsubscribe(tokenHash string) error {
+	result := repo.DB.Model(&domain.Subscription{}).
+		Where("unsubscribe_token_hash = ?", tokenHash).
+		Update("status", domain.SubscriptionStatusUnsubscribed)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSubscriptionTokenInvalid
+	}
+	return nil
+}
+
+// FetchDue pages through active subscriptions that had no digest since
+// before, in id order.
+func (repo *SubscriptionRepository) FetchDue(before time.Time, afterID int64, limit int) ([]domain.Subscription, error) {
+	var subscriptions []domain.Subscription
+	err := repo.DB.
+		Where("status = ? AND (last_sent_at IS NULL OR last_sent_at <= ?) AND id > ?", domain.SubscriptionStatusActive, before, afterID).
+		Order("id").Limit(limit).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// MarkSent also stores the hash of the unsubscribe token the digest carried.
+func (repo *SubscriptionRepository) MarkSent(id int64, at time.Time, unsubscribeTokenHash string) error {
+	return repo.DB.Model(&domain.Subscription{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_sent_at":           at,
+		"unsubscribe_token_hash": unsubscribeTokenHash,
+	}).Error
+}
+
+// HashUnsubscribeTokens moves unsubscribe tokens stored in the clear by
+// earlier versions to unsubscribe_token_hash, so links in digests already
+// sent keep working, and drops the old column.
+func HashUnsubscribeTokens(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&domain.Subscription{}, "unsubscribe_token") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE subscriptions SET unsubscribe_token_hash = encode(sha256(convert_to(unsubscribe_token, 'UTF8')), 'hex') WHERE unsubscribe_token <> ''`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&domain.Subscription{}, "unsubscribe_token")
+	})
+}
+
+func (repo *SubscriptionRepository) TopPosts(userID *int64, tags []string, since time.Time, limit int) ([]domain.DigestPost, error) {
+	query := repo.DB.Model(&domain.Blog{}).
+		Select("blogs.id, blogs.title, blogs.content, users.username AS author, blogs.likes, blogs.view_count").
+		Joins("LEFT JOIN users ON users.id = blogs.user_id").
+		Where("blogs.created_at >= ?", since)
+
+	followed := repo.DB.Model(&domain.Follow{}).Select("followee_id").Where("follower_id = ?", userID)
+	tagged := repo.DB.Model(&domain.Tag_Blog{}).Select("tag_blogs.blog_id").
+		Joins("JOIN tags ON tags.id = tag_blogs.tag_id").
+		Where("tags.name IN ?", tags)
+	switch {
+	case userID != nil && len(tags) > 0:
+		query = query.Where(repo.DB.Where("blogs.user_id IN (?)", followed).Or("blogs.id IN (?)", tagged))
+	case userID != nil:
+		query = query.Where("blogs.user_id IN (?)", followed)
+	case len(tags) > 0:
+		query = query.Where("blogs.id IN (?)", tagged)
+	}
+
+	var posts []domain.DigestPost
+	err := query.Order("blogs.likes - blogs.dislikes DESC, blogs.view_count DESC, blogs.id DESC").Limit(limit).Scan(&posts).Error
+	return posts, err
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type TagRepository struct {
+	DB *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{
+		DB: db,
+	}
+}
+
+// tagWords reduces a tag name to lowercase words separated by single spaces,
+// so "Machine-Learning" and "machine learning" compare equal.
+const tagWords = `btrim(regexp_replace(lower(tags.name), '[^[:alnum:]+#]+', ' ', 'g'))`
+
+// FetchByNames looks names up one to one. Names are compared with tag names
+// reduced to words as tagWords does, so they must be given in that form.
+func (repo *TagRepository) FetchByNames(ctx context.Context, names []string) ([]domain.TagUsage, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+
+	var tags []domain.TagUsage
+	err := repo.usage(ctx).Where(tagWords+" IN ?", lowered).Scan(&tags).Error
+	return tags, err
+}
+
+// MatchTags compares whole words only, so "go" is found in "go tooling"
+// but not in "good ideas". Tag names are reduced to words the same way
+// words was, and the limit applies to what is left.
+func (repo *TagRepository) MatchTags(ctx context.Context, words string, limit int) ([]domain.TagUsage, error) {
+	var tags []domain.TagUsage
+	err := repo.usage(ctx).
+		Where(`strpos(?, ' ' || `+tagWords+` || ' ') > 0`, " "+words+" ").
+		Limit(limit).
+		Scan(&tags).Error
+	return tags, err
+}
+
+// usage lists tags with the number of posts using them, most used first.
+func (repo *TagRepository) usage(ctx context.Context) *gorm.DB {
+	return repo.DB.WithContext(ctx).Model(&domain.Tag{}).
+		Select("tags.id, tags.name, count(tag_blogs.id) AS posts").
+		Joins("LEFT JOIN tag_blogs ON tag_blogs.tag_id = tags.id AND tag_blogs.deleted_at IS NULL").
+		Group("tags.id").
+		Order("posts DESC, tags.id")
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenDenylistRepository struct {
+	DB *gorm.DB
+}
+
+func NewTokenDenylistRepository(db *gorm.DB) *TokenDenylistRepository {
+	return &TokenDenylistRepository{
+		DB: db,
+	}
+}
+
+func (repo *TokenDenylistRepository) IsRevoked(jti string) (bool, error) {
+	var count int64
+	result := repo.DB.Model(&domain.RevokedToken{}).Where("jti = ? AND expires_at > ?", jti, time.Now()).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (repo *TokenDenylistRepository) Revoke(jti string, expiresAt time.Time) error {
+	_, err := repo.Consume(jti, expiresAt)
+	return err
+}
+
+// Consume relies on the unique index on jti: of two concurrent calls only
+// one inserts a row.
+func (repo *TokenDenylistRepository) Consume(jti string, expiresAt time.Time) (bool, error) {
+	revoked := domain.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+	result := repo.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&revoked)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
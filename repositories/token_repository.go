@@ -31,4 +31,13 @@ func (repo *TokenRepository) Save(token *domain.Token) error {
 		return result.Error
 	}
 	return nil
-}
\ No newline at end of file
+}
+func (repo *TokenRepository) SaveWithEmail(token *domain.Token, message domain.EmailMessage) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(token).Error; err != nil {
+			return err
+		}
+		email := domain.NewOutboxEmail(message)
+		return tx.Create(&email).Error
+	})
+}
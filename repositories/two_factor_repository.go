@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct {
+	DB *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		DB: db,
+	}
+}
+
+// FetchByUserID returns a zero TwoFactor without error when the user never
+// enrolled, so callers can tell "not enrolled" apart from a database failure.
+func (repo *TwoFactorRepository) FetchByUserID(userID int64) (domain.TwoFactor, error) {
+	var twoFactor domain.TwoFactor
+	result := repo.DB.Where("user_id = ?", userID).First(&twoFactor)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.TwoFactor{}, nil
+	}
+	if result.Error != nil {
+		return domain.TwoFactor{}, result.Error
+	}
+	return twoFactor, nil
+}
+
+func (repo *TwoFactorRepository) Save(twoFactor *domain.TwoFactor) error {
+	return repo.DB.Save(twoFactor).Error
+}
+
+// Delete removes the TOTP secret together with every recovery code, leaving
+// the account as if two-factor authentication had never been set up.
+func (repo *TwoFactorRepository) Delete(userID int64) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&domain.TwoFactor{}).Error
+	})
+}
+
+func (repo *TwoFactorRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]domain.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, domain.RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (repo *TwoFactorRepository) FetchUnusedRecoveryCodes(userID int64) ([]domain.RecoveryCode, error) {
+	var codes []domain.RecoveryCode
+	result := repo.DB.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return codes, nil
+}
+
+func (repo *TwoFactorRepository) MarkRecoveryCodeUsed(id int64) error {
+	result := repo.DB.Model(&domain.RecoveryCode{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/blog-platform/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -26,6 +27,20 @@ func (ur *UserRepository) Register(user *domain.User) (domain.User, error) {
 	return *user, nil
 }
 
+func (ur *UserRepository) RegisterWithEmail(user *domain.User, message func(domain.User) domain.EmailMessage) (domain.User, error) {
+	err := ur.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		email := domain.NewOutboxEmail(message(*user))
+		return tx.Create(&email).Error
+	})
+	if err != nil {
+		return domain.User{}, errors.New(err.Error())
+	}
+	return *user, nil
+}
+
 func (ur *UserRepository) FetchByEmail(email string) (domain.User, error) {
 	var user domain.User
 	err := ur.DB.Where("email = ?", email).First(&user).Error
@@ -84,53 +99,97 @@ func (ur *UserRepository) GetUserProfile(userId int64) (*domain.User, error) {
 	return &user, nil
 }
 
-func (ur *UserRepository) Promote(idStr string) error {
-	id, err := strconv.Atoi(idStr)
+// ChangeRole locks the superadmin rows before changing a role, so two
+// concurrent demotions cannot each see the other superadmin and leave none.
+func (ur *UserRepository) ChangeRole(idStr string, role string) error {
+	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
 		return errors.New("invalid id")
 	}
 
-	result := ur.DB.Model(&domain.User{}).Where("id = ?", id).Update("role", "admin")
-	if result.Error != nil || result.RowsAffected == 0 {
-		return errors.New("failed to promote user")
-	}
+	return ur.DB.Transaction(func(tx *gorm.DB) error {
+		var superadmins []domain.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("role = ?", domain.RoleSuperadmin).Find(&superadmins).Error
+		if err != nil {
+			return errors.New("failed to change role")
+		}
+		if role != domain.RoleSuperadmin && len(superadmins) == 1 && superadmins[0].ID == id {
+			return domain.ErrLastSuperadmin
+		}
 
-	return nil
+		result := tx.Model(&domain.User{}).Where("id = ?", id).Update("role", role)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return errors.New("failed to change role")
+		}
+		return nil
+	})
 }
 
-func (ur *UserRepository) Demote(idStr string) error {
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		return errors.New("invalid id")
-	}
-
-	result := ur.DB.Model(&domain.User{}).Where("id = ?", id).Update("role", "user")
-	if result.Error != nil || result.RowsAffected == 0 {
-		return errors.New("failed to demote user")
-	}
+// BootstrapSuperadmin makes the account registered with email a superadmin,
+// but only while the platform has none, so the first superadmin can be set up
+// from the environment and leaving the variable set changes nothing later on.
+// It reports whether an account was promoted.
+func BootstrapSuperadmin(db *gorm.DB, email string) (bool, error) {
+	if email == "" {
+		return false, nil
+	}
+
+	promoted := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var superadmins []domain.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("role = ?", domain.RoleSuperadmin).Find(&superadmins).Error
+		if err != nil || len(superadmins) > 0 {
+			return err
+		}
 
-	return nil
+		result := tx.Model(&domain.User{}).Where("lower(email) = lower(?)", email).Update("role", domain.RoleSuperadmin)
+		promoted = result.RowsAffected > 0
+		return result.Error
+	})
+	return promoted, err
 }
 
-func (ur *UserRepository) UpdateUserProfile(userID int64, updates map[string]interface{}) error {
-	allowedFields := map[string]bool{
-		"Username":       true,
-		"Email":          true,
-		"Bio":            true,
-		"ProfilePicture": true,
-		"Phone":          true,
-		"Status":         true,
-	}
-	filteredUpdates := make(map[string]interface{})
-	for k, v := range updates {
-		if allowedFields[k] {
-			filteredUpdates[k] = v
-		}
+// UpdateUserProfile writes the non-nil fields of update. Email is ignored; it
+// only changes through UpdateEmail once the new address has been confirmed.
+func (ur *UserRepository) UpdateUserProfile(userID int64, update domain.ProfileUpdate) error {
+	fields := make(map[string]interface{})
+	if update.Username != nil {
+		fields["username"] = *update.Username
 	}
-	if len(filteredUpdates) == 0 {
+	if update.Bio != nil {
+		fields["bio"] = *update.Bio
+	}
+	if update.ProfilePicture != nil {
+		fields["profile_picture"] = *update.ProfilePicture
+	}
+	if update.Phone != nil {
+		fields["phone"] = *update.Phone
+	}
+	if update.Locale != nil {
+		fields["locale"] = *update.Locale
+	}
+	if len(fields) == 0 {
 		return nil
 	}
-	return ur.DB.Model(&domain.User{}).Where("id = ?", userID).Updates(filteredUpdates).Error
+	return ur.DB.Model(&domain.User{}).Where("id = ?", userID).Updates(fields).Error
+}
+
+// UpdateEmail also forgets whether the previous address bounced, since the
+// new one has just received the confirmation link.
+func (ur *UserRepository) UpdateEmail(userID int64, email string) error {
+	result := ur.DB.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email":               email,
+		"email_status":        "",
+		"email_status_reason": "",
+		"email_status_at":     nil,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (ur *UserRepository) ResetPassword(idStr string, newPassword string) error {
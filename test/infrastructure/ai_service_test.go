@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type AIServiceTestSuite struct {
+	suite.Suite
+	calls  atomic.Int32
+	config domain.AIConfig
+}
+
+func (suite *AIServiceTestSuite) SetupTest() {
+	suite.calls.Store(0)
+	suite.config = infrastructure.DefaultAIConfig()
+	suite.config.Provider = infrastructure.AIProviderOpenAI
+	suite.config.APIKey = "sk-test"
+	suite.config.Timeout = time.Second
+	suite.config.RetryBase = time.Millisecond
+}
+
+// server answers with the handlers in turn, the last one from then on.
+func (suite *AIServiceTestSuite) server(handlers ...http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(suite.calls.Add(1))
+		handlers[min(n, len(handlers))-1](w, r)
+	}))
+	suite.T().Cleanup(server.Close)
+	suite.config.BaseURL = server.URL
+	return server
+}
+
+func completion(text string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": text}}},
+		})
+	}
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+		w.Write([]byte(`{"error":{"message":"try later"}}`))
+	}
+}
+
+func (suite *AIServiceTestSuite) TestComplete_SendsChatCompletion() {
+	var got map[string]interface{}
+	suite.server(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("/chat/completions", r.URL.Path)
+		suite.Equal("Bearer sk-test", r.Header.Get("Authorization"))
+		suite.NoError(json.NewDecoder(r.Body).Decode(&got))
+		completion("  Hello there  ")(w, r)
+	})
+
+	text, err := infrastructure.NewOpenAIClient(suite.config).Complete(context.Background(), domain.AIRequest{Instructions: "Be brief.", Input: "Say hello"})
+	suite.NoError(err)
+	suite.Equal("Hello there", text)
+	suite.Equal("gpt-4o-mini", got["model"])
+	suite.Equal(float64(1024), got["max_tokens"])
+	messages := got["messages"].([]interface{})
+	suite.Equal("Be brief.", messages[0].(map[string]interface{})["content"])
+	suite.Equal("Say hello", messages[1].(map[string]interface{})["content"])
+}
+
+func (suite *AIServiceTestSuite) TestComplete_RetriesServerErrorsAndRateLimits() {
+	suite.server(status(http.StatusServiceUnavailable), status(http.StatusTooManyRequests), completion("done"))
+
+	text, err := infrastructure.NewOpenAIClient(suite.config).Complete(context.Background(), domain.AIRequest{Input: "x"})
+	suite.NoError(err)
+	suite.Equal("done", text)
+	suite.Equal(int32(3), suite.calls.Load())
+}
+
+func (suite *AIServiceTestSuite) TestComplete_GivesUpAfterMaxRetries() {
+	suite.server(status(http.StatusBadGateway))
+
+	_, err := infrastructure.NewOpenAIClient(suite.config).Complete(context.Background(), domain.AIRequest{Input: "x"})
+	suite.EqualError(err, "ai provider answered 502: try later")
+	suite.Equal(int32(3), suite.calls.Load())
+}
+
+func (suite *AIServiceTestSuite) TestComplete_ClientErrorsAreNotRetried() {
+	suite.server(status(http.StatusUnauthorized))
+
+	_, err := infrastructure.NewOpenAIClient(suite.config).Complete(context.Background(), domain.AIRequest{Input: "x"})
+	suite.Error(err)
+	suite.Equal(int32(1), suite.calls.Load())
+}
+
+func (suite *AIServiceTestSuite) TestComplete_RetriesTimeouts() {
+	suite.config.Timeout = 50 * time.Millisecond
+	suite.server(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}, completion("late but fine"))
+
+	text, err := infrastructure.NewOpenAIClient(suite.config).Complete(context.Background(), domain.AIRequest{Input: "x"})
+	suite.NoError(err)
+	suite.Equal("late but fine", text)
+}
+
+func (suite *AIServiceTestSuite) TestFakeAIClient_EchoesInput() {
+	client := infrastructure.NewAIInfrastructure(domain.AIConfig{Provider: infrastructure.AIProviderFake})
+	text, err := client.Complete(context.Background(), domain.AIRequest{Instructions: "Rewrite.", Input: " A paragraph. "})
+	suite.NoError(err)
+	suite.Equal("A paragraph.", text)
+	suite.Len(client.(*infrastructure.FakeAIClient).Requests(), 1)
+}
+
+func (suite *AIServiceTestSuite) TestNewAIInfrastructure_OffWithoutProvider() {
+	suite.Nil(infrastructure.NewAIInfrastructure(domain.AIConfig{}))
+}
+
+func (suite *AIServiceTestSuite) TestLoadConfig() {
+	suite.T().Setenv("AI_PROVIDER", "openai")
+	suite.T().Setenv("AI_BASE_URL", "http://localhost:11434/v1/")
+	suite.T().Setenv("AI_TIMEOUT", "5s")
+	config, err := infrastructure.LoadAIConfigFromEnv()
+	suite.NoError(err)
+	suite.Equal("http://localhost:11434/v1", config.BaseURL)
+	suite.Equal(5*time.Second, config.Timeout)
+	suite.Equal(2, config.MaxRetries)
+}
+
+func (suite *AIServiceTestSuite) TestLoadConfig_InvalidValues() {
+	suite.T().Setenv("AI_PROVIDER", "skynet")
+	_, err := infrastructure.LoadAIConfigFromEnv()
+	suite.EqualError(err, "invalid value for AI_PROVIDER")
+
+	suite.T().Setenv("AI_PROVIDER", "")
+	suite.T().Setenv("AI_TEMPERATURE", "3")
+	_, err = infrastructure.LoadAIConfigFromEnv()
+	suite.EqualError(err, "invalid value for AI_TEMPERATURE")
+
+	suite.T().Setenv("AI_TEMPERATURE", "")
+	suite.T().Setenv("AI_RETRY_BASE", "soon")
+	_, err = infrastructure.LoadAIConfigFromEnv()
+	suite.EqualError(err, "invalid duration for AI_RETRY_BASE")
+}
+
+func TestAIServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AIServiceTestSuite))
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileAuditSinkTestSuite struct {
+	suite.Suite
+}
+
+func (suite *FileAuditSinkTestSuite) TestWrite_AppendsJSONLines() {
+	path := filepath.Join(suite.T().TempDir(), "audit.jsonl")
+	sink, err := infrastructure.NewFileAuditSink(path)
+	suite.Require().NoError(err)
+
+	suite.NoError(sink.Write(domain.AuditEvent{ID: 1, Action: "auth.login", Hash: "h1"}))
+	suite.NoError(sink.Write(domain.AuditEvent{ID: 2, Action: "token.revoke", PrevHash: "h1", Hash: "h2"}))
+	suite.NoError(sink.Close())
+
+	content, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	suite.Require().Len(lines, 2)
+
+	var event domain.AuditEvent
+	suite.NoError(json.Unmarshal([]byte(lines[1]), &event))
+	suite.Equal("token.revoke", event.Action)
+	suite.Equal("h1", event.PrevHash)
+}
+
+func TestFileAuditSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(FileAuditSinkTestSuite))
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+const sampleDSN = `From: MAILER-DAEMON@mx.example.org
+To: bounces@blog.example.com
+Subject: Undelivered Mail Returned to Sender
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="BOUNDARY"
+
+--BOUNDARY
+Content-Type: text/plain
+
+Your message could not be delivered.
+
+--BOUNDARY
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.org
+Arrival-Date: Mon, 19 Oct 2026 10:00:00 +0000
+
+Final-Recipient: rfc822; gone@example.org
+Action: failed
+Status: 5.1.1
+Diagnostic-Code: smtp; 550 5.1.1 user unknown
+
+Final-Recipient: rfc822; full@example.org
+Action: failed
+Status: 4.2.2
+Diagnostic-Code: smtp; 452 4.2.2 mailbox full
+
+Final-Recipient: rfc822; fine@example.org
+Action: delivered
+Status: 2.0.0
+
+--BOUNDARY
+Content-Type: text/rfc822-headers
+
+From: no-reply@blog.example.com
+To: gone@example.org
+Subject: Weekly digest
+
+--BOUNDARY--
+`
+
+const sampleARF = `From: abuse@isp.example.net
+To: bounces@blog.example.com
+Subject: Abuse report
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=feedback-report; boundary="ARF"
+
+--ARF
+Content-Type: text/plain
+
+This is an email abuse report.
+
+--ARF
+Content-Type: message/feedback-report
+
+Feedback-Type: abuse
+User-Agent: SomeISP/1.0
+Version: 1
+
+--ARF
+Content-Type: message/rfc822
+
+From: no-reply@blog.example.com
+To: Jane <jane@example.net>
+Subject: Weekly digest
+
+Hello
+--ARF--
+`
+
+type BounceParserTestSuite struct {
+	suite.Suite
+	parser *infrastructure.BounceParser
+}
+
+func (suite *BounceParserTestSuite) SetupTest() {
+	suite.parser = infrastructure.NewBounceParser()
+}
+
+func (suite *BounceParserTestSuite) TestDSN() {
+	events, err := suite.parser.Parse(domain.BounceSourceDSN, []byte(strings.ReplaceAll(sampleDSN, "\n", "\r\n")))
+	suite.NoError(err)
+	suite.Equal([]domain.BounceEvent{
+		{Email: "gone@example.org", Type: domain.BounceHard, Reason: "smtp; 550 5.1.1 user unknown"},
+		{Email: "full@example.org", Type: domain.BounceSoft, Reason: "smtp; 452 4.2.2 mailbox full"},
+	}, events)
+}
+
+func (suite *BounceParserTestSuite) TestARFComplaint() {
+	events, err := suite.parser.Parse(domain.BounceSourceDSN, []byte(sampleARF))
+	suite.NoError(err)
+	suite.Equal([]domain.BounceEvent{{Email: "jane@example.net", Type: domain.BounceComplaint, Reason: "feedback-type abuse"}}, events)
+}
+
+func (suite *BounceParserTestSuite) TestDSN_NotAReport() {
+	_, err := suite.parser.Parse(domain.BounceSourceDSN, []byte("Subject: hi\r\nContent-Type: text/plain\r\n\r\nhello"))
+	suite.EqualError(err, "bounce report is not a multipart/report message")
+}
+
+func (suite *BounceParserTestSuite) TestSES() {
+	payload := `{"Type":"Notification","Message":"{\"notificationType\":\"Bounce\",\"bounce\":{\"bounceType\":\"Permanent\",\"bouncedRecipients\":[{\"emailAddress\":\"gone@example.org\",\"diagnosticCode\":\"smtp; 550 user unknown\"}]}}"}`
+	events, err := suite.parser.Parse(domain.BounceSourceSES, []byte(payload))
+	suite.NoError(err)
+	suite.Equal([]domain.BounceEvent{{Email: "gone@example.org", Type: domain.BounceHard, Reason: "smtp; 550 user unknown"}}, events)
+
+	payload = `{"notificationType":"Complaint","complaint":{"complaintFeedbackType":"abuse","complainedRecipients":[{"emailAddress":"jane@example.net"}]}}`
+	events, err = suite.parser.Parse(domain.BounceSourceSES, []byte(payload))
+	suite.NoError(err)
+	suite.Equal([]domain.BounceEvent{{Email: "jane@example.net", Type: domain.BounceComplaint, Reason: "complaint abuse"}}, events)
+}
+
+func (suite *BounceParserTestSuite) TestSES_SubscriptionConfirmation() {
+	events, err := suite.parser.Parse(domain.BounceSourceSES, []byte(`{"Type":"SubscriptionConfirmation","SubscribeURL":"https://sns.example.com/confirm"}`))
+	suite.NoError(err)
+	suite.Empty(events)
+}
+
+func (suite *BounceParserTestSuite) TestSendGrid() {
+	payload := `[
+		{"email":"gone@example.org","event":"bounce","type":"bounce","reason":"550 user unknown"},
+		{"email":"blocked@example.org","event":"bounce","type":"blocked","reason":"550 spam"},
+		{"email":"jane@example.net","event":"spamreport"},
+		{"email":"fine@example.org","event":"delivered"}
+	]`
+	events, err := suite.parser.Parse(domain.BounceSourceSendGrid, []byte(payload))
+	suite.NoError(err)
+	suite.Equal([]domain.BounceEvent{
+		{Email: "gone@example.org", Type: domain.BounceHard, Reason: "550 user unknown"},
+		{Email: "blocked@example.org", Type: domain.BounceSoft, Reason: "550 spam"},
+		{Email: "jane@example.net", Type: domain.BounceComplaint, Reason: "spam report"},
+	}, events)
+}
+
+func (suite *BounceParserTestSuite) TestMailgun() {
+	payload := `{"event-data":{"event":"failed","severity":"permanent","recipient":"gone@example.org","delivery-status":{"description":"No such user"}}}`
+	events, err := suite.parser.Parse(domain.BounceSourceMailgun, []byte(payload))
+	suite.NoError(err)
+	suite.Equal([]domain.BounceEvent{{Email: "gone@example.org", Type: domain.BounceHard, Reason: "No such user"}}, events)
+}
+
+func (suite *BounceParserTestSuite) TestPostmark() {
+	events, err := suite.parser.Parse(domain.BounceSourcePostmark, []byte(`{"RecordType":"Bounce","Type":"HardBounce","Email":"gone@example.org","Description":"The server was unable to deliver your message"}`))
+	suite.NoError(err)
+	suite.Equal([]domain.BounceEvent{{Email: "gone@example.org", Type: domain.BounceHard, Reason: "The server was unable to deliver your message"}}, events)
+
+	events, err = suite.parser.Parse(domain.BounceSourcePostmark, []byte(`{"RecordType":"SpamComplaint","Type":"SpamComplaint","Email":"jane@example.net"}`))
+	suite.NoError(err)
+	suite.Equal(domain.BounceComplaint, events[0].Type)
+}
+
+func (suite *BounceParserTestSuite) TestUnknownSourceAndMalformedPayload() {
+	_, err := suite.parser.Parse("carrier-pigeon", nil)
+	suite.ErrorIs(err, domain.ErrUnknownBounceSource)
+
+	_, err = suite.parser.Parse(domain.BounceSourceSendGrid, []byte("not json"))
+	suite.EqualError(err, "invalid bounce report")
+}
+
+func TestBounceParserTestSuite(t *testing.T) {
+	suite.Run(t, new(BounceParserTestSuite))
+}
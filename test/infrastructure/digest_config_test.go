@@ -0,0 +1,55 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type DigestConfigTestSuite struct {
+	suite.Suite
+}
+
+func (suite *DigestConfigTestSuite) SetupTest() {
+	suite.T().Setenv("DIGEST_UNSUBSCRIBE_SECRET", "secret")
+}
+
+func (suite *DigestConfigTestSuite) TestDefaults() {
+	config, err := infrastructure.LoadDigestConfigFromEnv()
+	suite.NoError(err)
+	expected := infrastructure.DefaultDigestConfig()
+	expected.UnsubscribeSecret = []byte("secret")
+	suite.Equal(expected, config)
+}
+
+func (suite *DigestConfigTestSuite) TestRequiresUnsubscribeSecret() {
+	suite.T().Setenv("DIGEST_UNSUBSCRIBE_SECRET", "")
+	_, err := infrastructure.LoadDigestConfigFromEnv()
+	suite.EqualError(err, "DIGEST_UNSUBSCRIBE_SECRET must be set")
+}
+
+func (suite *DigestConfigTestSuite) TestOverrides() {
+	suite.T().Setenv("DIGEST_PERIOD", "24h")
+	suite.T().Setenv("DIGEST_MAX_POSTS", "5")
+	config, err := infrastructure.LoadDigestConfigFromEnv()
+	suite.NoError(err)
+	suite.Equal(24*time.Hour, config.Period)
+	suite.Equal(5, config.MaxPosts)
+}
+
+func (suite *DigestConfigTestSuite) TestInvalidValues() {
+	suite.T().Setenv("DIGEST_MAX_POSTS", "none")
+	_, err := infrastructure.LoadDigestConfigFromEnv()
+	suite.EqualError(err, "invalid value for DIGEST_MAX_POSTS")
+
+	suite.T().Setenv("DIGEST_MAX_POSTS", "")
+	suite.T().Setenv("NEWSLETTER_CONFIRM_TTL", "-1h")
+	_, err = infrastructure.LoadDigestConfigFromEnv()
+	suite.EqualError(err, "invalid duration for NEWSLETTER_CONFIRM_TTL")
+}
+
+func TestDigestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(DigestConfigTestSuite))
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/emersion/go-msgauth/dkim"
+	"github.com/stretchr/testify/suite"
+)
+
+type DKIMTestSuite struct {
+	suite.Suite
+	rsaKey     *rsa.PrivateKey
+	ed25519Key ed25519.PrivateKey
+}
+
+func (suite *DKIMTestSuite) SetupSuite() {
+	var err error
+	suite.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+	_, suite.ed25519Key, err = ed25519.GenerateKey(rand.Reader)
+	suite.Require().NoError(err)
+}
+
+// send signs a message through the email service and returns what the
+// transport received.
+func (suite *DKIMTestSuite) send(key crypto.Signer, subject string, body string) ([]byte, *infrastructure.DKIMSigner) {
+	signer, err := infrastructure.NewDKIMSigner("example.com", "mail2024", key)
+	suite.Require().NoError(err)
+	capture := infrastructure.NewCaptureTransport()
+	service := &infrastructure.SMTPEmailService{From: "Blog Platform <noreply@example.com>", Transport: capture, DKIM: signer}
+
+	suite.Require().NoError(service.SendEmail([]string{"Jane Doe <jane@example.com>"}, subject, body))
+	messages := capture.Messages()
+	suite.Require().Len(messages, 1)
+	return messages[0].Data, signer
+}
+
+// verify checks msg with go-msgauth, answering the key lookup with the
+// record the signer says to publish.
+func (suite *DKIMTestSuite) verify(msg []byte, signer *infrastructure.DKIMSigner) *dkim.Verification {
+	verifications, err := dkim.VerifyWithOptions(bytes.NewReader(msg), &dkim.VerifyOptions{
+		LookupTXT: func(domain string) ([]string, error) {
+			suite.Equal("mail2024._domainkey.example.com", domain)
+			return []string{signer.DNSRecord()}, nil
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(verifications, 1)
+	return verifications[0]
+}
+
+func (suite *DKIMTestSuite) TestRSASignatureVerifies() {
+	msg, signer := suite.send(suite.rsaKey, "Activate your account", "Hello Jane,\n\nFollow https://example.com/activate to finish.\n")
+
+	for _, tag := range []string{"a=rsa-sha256;", "c=relaxed/relaxed;", "d=example.com;", "s=mail2024;"} {
+		suite.Contains(string(msg), tag)
+	}
+	verification := suite.verify(msg, signer)
+	suite.NoError(verification.Err)
+	suite.Equal("example.com", verification.Domain)
+	suite.Subset(verification.HeaderKeys, []string{"From", "To", "Subject", "Date", "Message-ID", "Content-Type"})
+}
+
+func (suite *DKIMTestSuite) TestEd25519SignatureVerifies() {
+	msg, signer := suite.send(suite.ed25519Key, "Réinitialisez votre mot de passe", "Bonjour,\n")
+
+	suite.Contains(string(msg), "a=ed25519-sha256;")
+	suite.True(strings.HasPrefix(signer.DNSRecord(), "v=DKIM1; k=ed25519; p="))
+	suite.NoError(suite.verify(msg, signer).Err)
+}
+
+func (suite *DKIMTestSuite) TestTamperedHeaderFails() {
+	msg, signer := suite.send(suite.rsaKey, "Activate your account", "Hello\n")
+
+	tampered := bytes.Replace(msg, []byte("Subject: Activate your account"), []byte("Subject: Claim your prize"), 1)
+	suite.Error(suite.verify(tampered, signer).Err)
+}
+
+func (suite *DKIMTestSuite) TestTamperedBodyFails() {
+	msg, signer := suite.send(suite.ed25519Key, "Reset", "Follow https://example.com/reset to reset.\n")
+
+	tampered := bytes.Replace(msg, []byte("example.com/reset"), []byte("evil.example/reset"), 1)
+	suite.Error(suite.verify(tampered, signer).Err)
+}
+
+func (suite *DKIMTestSuite) TestRelaxedCanonicalizationToleratesWhitespace() {
+	msg, signer := suite.send(suite.rsaKey, "Activate   your account", "Hello\n")
+
+	// Relays may refold headers, pad lines and append blank lines.
+	relayed := bytes.Replace(msg, []byte("Subject: Activate   your account"), []byte("Subject:  Activate your\r\n\taccount "), 1)
+	relayed = append(relayed, []byte("\r\n\r\n")...)
+	suite.NoError(suite.verify(relayed, signer).Err)
+}
+
+func (suite *DKIMTestSuite) TestSignRequiresFrom() {
+	signer, err := infrastructure.NewDKIMSigner("example.com", "mail2024", suite.ed25519Key)
+	suite.Require().NoError(err)
+
+	_, err = signer.Sign([]byte("To: jane@example.com\r\nSubject: Hi\r\n\r\nHello\r\n"))
+	suite.EqualError(err, "message has no From header to sign")
+}
+
+func (suite *DKIMTestSuite) TestNewDKIMSigner_RejectsShortRSAKeys() {
+	// Go refuses to make such keys unless told otherwise.
+	suite.T().Setenv("GODEBUG", "rsa1024min=0")
+	short, err := rsa.GenerateKey(rand.Reader, 512)
+	suite.Require().NoError(err)
+
+	_, err = infrastructure.NewDKIMSigner("example.com", "mail2024", short)
+	suite.EqualError(err, "dkim rsa keys must be at least 1024 bits")
+}
+
+func (suite *DKIMTestSuite) TestLoadDKIMKey() {
+	dir := suite.T().TempDir()
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(suite.ed25519Key)
+	suite.Require().NoError(err)
+	files := map[string]*pem.Block{
+		"ed25519.pem": {Type: "PRIVATE KEY", Bytes: pkcs8},
+		"rsa.pem":     {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(suite.rsaKey)},
+		"cert.pem":    {Type: "CERTIFICATE", Bytes: []byte{1}},
+	}
+	for name, block := range files {
+		suite.Require().NoError(os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0o600))
+	}
+
+	key, err := infrastructure.LoadDKIMKey(filepath.Join(dir, "ed25519.pem"))
+	suite.NoError(err)
+	suite.Equal(suite.ed25519Key, key)
+
+	key, err = infrastructure.LoadDKIMKey(filepath.Join(dir, "rsa.pem"))
+	suite.NoError(err)
+	suite.True(suite.rsaKey.Equal(key))
+
+	_, err = infrastructure.LoadDKIMKey(filepath.Join(dir, "cert.pem"))
+	suite.EqualError(err, `unsupported PEM block "CERTIFICATE" in dkim key file`)
+}
+
+func (suite *DKIMTestSuite) TestLoadDKIMSignerFromEnv() {
+	signer, err := infrastructure.LoadDKIMSignerFromEnv()
+	suite.NoError(err)
+	suite.Nil(signer)
+
+	suite.T().Setenv("DKIM_DOMAIN", "example.com")
+	_, err = infrastructure.LoadDKIMSignerFromEnv()
+	suite.EqualError(err, "DKIM_DOMAIN, DKIM_SELECTOR and DKIM_PRIVATE_KEY_FILE must be set together")
+
+	path := filepath.Join(suite.T().TempDir(), "dkim.pem")
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(suite.rsaKey)
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0o600))
+	suite.T().Setenv("DKIM_SELECTOR", "mail2024")
+	suite.T().Setenv("DKIM_PRIVATE_KEY_FILE", path)
+	signer, err = infrastructure.LoadDKIMSignerFromEnv()
+	suite.NoError(err)
+	suite.True(strings.HasPrefix(signer.DNSRecord(), "v=DKIM1; k=rsa; p="))
+}
+
+func TestDKIMTestSuite(t *testing.T) {
+	suite.Run(t, new(DKIMTestSuite))
+}
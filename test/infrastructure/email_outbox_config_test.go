@@ -0,0 +1,43 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmailOutboxConfigTestSuite struct {
+	suite.Suite
+}
+
+func (suite *EmailOutboxConfigTestSuite) TestDefaults() {
+	config, err := infrastructure.LoadEmailOutboxConfigFromEnv()
+	suite.NoError(err)
+	suite.Equal(infrastructure.DefaultEmailOutboxConfig(), config)
+}
+
+func (suite *EmailOutboxConfigTestSuite) TestOverrides() {
+	suite.T().Setenv("EMAIL_WORKERS", "8")
+	suite.T().Setenv("EMAIL_RETRY_BASE", "1m")
+	config, err := infrastructure.LoadEmailOutboxConfigFromEnv()
+	suite.NoError(err)
+	suite.Equal(8, config.Workers)
+	suite.Equal(time.Minute, config.RetryBase)
+}
+
+func (suite *EmailOutboxConfigTestSuite) TestInvalidValues() {
+	suite.T().Setenv("EMAIL_MAX_ATTEMPTS", "0")
+	_, err := infrastructure.LoadEmailOutboxConfigFromEnv()
+	suite.EqualError(err, "invalid value for EMAIL_MAX_ATTEMPTS")
+
+	suite.T().Setenv("EMAIL_MAX_ATTEMPTS", "")
+	suite.T().Setenv("EMAIL_RETRY_MAX", "10s")
+	_, err = infrastructure.LoadEmailOutboxConfigFromEnv()
+	suite.EqualError(err, "invalid duration for EMAIL_RETRY_MAX")
+}
+
+func TestEmailOutboxConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailOutboxConfigTestSuite))
+}
@@ -2,9 +2,16 @@ package test
 
 import (
 	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
 	"net/smtp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/blog-platform/domain"
 	"github.com/blog-platform/infrastructure"
 	"github.com/stretchr/testify/suite"
 )
@@ -43,6 +50,112 @@ func (suite *EmailServiceTestSuite) TestSendEmail_Failure() {
 	suite.Equal("failed to send email", err.Error())
 }
 
+// capture sends through the service and returns the parsed message together
+// with its decoded parts keyed by content type.
+func (suite *EmailServiceTestSuite) capture(send func() error) (string, *mail.Message, map[string]string) {
+	var envelopeFrom string
+	var raw []byte
+	suite.emailService.SendMailFn = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		envelopeFrom = from
+		raw = msg
+		return nil
+	}
+	suite.Require().NoError(send())
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	suite.Require().NoError(err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	suite.Require().NoError(err)
+	suite.Equal("multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		suite.Require().NoError(err)
+		content, err := io.ReadAll(part)
+		suite.Require().NoError(err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+	return envelopeFrom, msg, parts
+}
+
+func (suite *EmailServiceTestSuite) TestSendEmail_MultipartWithHeaders() {
+	suite.emailService.From = "Blog Platform <from@example.com>"
+	suite.emailService.Now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	from, msg, parts := suite.capture(func() error {
+		return suite.emailService.SendEmail([]string{"to@example.com"}, "Grüße", "Hello,\n\nopen https://example.com/a?b=1&c=2 now")
+	})
+
+	suite.Equal("from@example.com", from)
+	suite.Equal(`"Blog Platform" <from@example.com>`, msg.Header.Get("From"))
+	suite.Equal("<to@example.com>", msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	suite.NoError(err)
+	suite.Equal("Grüße", subject)
+	suite.Equal("Tue, 02 Jan 2024 03:04:05 +0000", msg.Header.Get("Date"))
+	suite.Regexp(`^<[0-9a-f]{32}@example\.com>$`, msg.Header.Get("Message-ID"))
+	suite.Equal("1.0", msg.Header.Get("MIME-Version"))
+
+	suite.Equal("Hello,\r\n\r\nopen https://example.com/a?b=1&c=2 now", parts["text/plain"])
+	suite.Contains(parts["text/html"], `<a href="https://example.com/a?b=1&amp;c=2">`)
+}
+
+func (suite *EmailServiceTestSuite) TestSendEmail_RejectsHeaderInjection() {
+	suite.emailService.SendMailFn = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		suite.NotContains(string(msg), "\r\nBcc:")
+		return nil
+	}
+	suite.Error(suite.emailService.SendEmail([]string{"to@example.com\r\nBcc: evil@example.com"}, "Hi", "body"))
+	suite.NoError(suite.emailService.SendEmail([]string{"to@example.com"}, "Hi\r\nBcc: evil@example.com", "body"))
+}
+
+func (suite *EmailServiceTestSuite) TestSendTemplate_RendersLocale() {
+	suite.emailService.Templates = infrastructure.DefaultEmailTemplates()
+
+	_, msg, parts := suite.capture(func() error {
+		return suite.emailService.SendTemplate(domain.EmailMessage{
+			To:       []string{"to@example.com"},
+			Template: domain.EmailPasswordReset,
+			Locale:   "fr",
+			Data:     map[string]interface{}{"Username": "jeanne", "Link": "https://example.com/reset"},
+		})
+	})
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	suite.NoError(err)
+	suite.Equal("Réinitialisez votre mot de passe", subject)
+	suite.Contains(parts["text/plain"], "https://example.com/reset")
+	suite.Contains(parts["text/html"], "Choisir un nouveau mot de passe")
+}
+
+func (suite *EmailServiceTestSuite) TestSendTemplate_AddsHeaders() {
+	suite.emailService.Templates = infrastructure.DefaultEmailTemplates()
+	message := domain.EmailMessage{
+		To:       []string{"to@example.com"},
+		Template: domain.EmailNewsletterConfirm,
+		Data:     map[string]interface{}{"Link": "https://example.com/newsletter/confirm?token=abc"},
+		Headers: map[string]string{
+			"list-unsubscribe":      "<https://example.com/newsletter/unsubscribe?token=xyz>",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}
+
+	_, msg, _ := suite.capture(func() error { return suite.emailService.SendTemplate(message) })
+	suite.Equal("<https://example.com/newsletter/unsubscribe?token=xyz>", msg.Header.Get("List-Unsubscribe"))
+	suite.Equal("List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+
+	message.Headers = map[string]string{"List-Unsubscribe": "<https://example.com>\r\nBcc: evil@example.com"}
+	suite.EqualError(suite.emailService.SendTemplate(message), `invalid value for header "List-Unsubscribe"`)
+	message.Headers = map[string]string{"subject": "Claim your prize"}
+	suite.EqualError(suite.emailService.SendTemplate(message), `invalid header "subject"`)
+}
+
 func TestEmailServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(EmailServiceTestSuite))
 }
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmailTemplatesTestSuite struct {
+	suite.Suite
+	templates *infrastructure.EmailTemplates
+}
+
+func (suite *EmailTemplatesTestSuite) SetupTest() {
+	suite.templates = infrastructure.DefaultEmailTemplates()
+}
+
+func (suite *EmailTemplatesTestSuite) TestBundledTemplatesRenderWithSampleData() {
+	suite.Contains(suite.templates.Templates(), domain.EmailActivation)
+	suite.Contains(suite.templates.Templates(), domain.EmailDigest)
+	for _, name := range suite.templates.Templates() {
+		data, err := suite.templates.SampleData(name)
+		suite.Require().NoError(err, name)
+
+		email, err := suite.templates.Render(name, "", data)
+		suite.Require().NoError(err, name)
+		suite.NotEmpty(email.Subject, name)
+		suite.NotContains(email.Text, "<no value>", name)
+		suite.Contains(email.HTML, "</html>", name)
+	}
+}
+
+func (suite *EmailTemplatesTestSuite) TestRender_EscapesHTMLButNotText() {
+	email, err := suite.templates.Render(domain.EmailActivation, "", map[string]interface{}{"Username": "<b>jane</b>", "Link": "http://localhost/activate"})
+	suite.NoError(err)
+	suite.Equal("Activate your account", email.Subject)
+	suite.Contains(email.HTML, "&lt;b&gt;jane&lt;/b&gt;")
+	suite.Contains(email.HTML, `href="http://localhost/activate"`)
+	suite.Contains(email.Text, "Hi <b>jane</b>,")
+	suite.Contains(email.Text, "http://localhost/activate")
+}
+
+func (suite *EmailTemplatesTestSuite) TestRender_LocaleFallback() {
+	data := map[string]interface{}{"Username": "jeanne", "Link": "http://localhost/activate"}
+
+	french, err := suite.templates.Render(domain.EmailActivation, "fr-CA", data)
+	suite.NoError(err)
+	suite.Equal("Activez votre compte", french.Subject)
+	suite.Contains(french.Text, "Vous recevez cet e-mail")
+	suite.Contains(french.HTML, "Activer le compte")
+
+	fallback, err := suite.templates.Render(domain.EmailMagicLink, "fr", data)
+	suite.NoError(err)
+	suite.Equal("Your sign-in link", fallback.Subject)
+}
+
+func (suite *EmailTemplatesTestSuite) TestRender_UnknownTemplate() {
+	_, err := suite.templates.Render("missing", "", nil)
+	suite.ErrorIs(err, domain.ErrEmailTemplateNotFound)
+	_, err = suite.templates.SampleData("missing")
+	suite.ErrorIs(err, domain.ErrEmailTemplateNotFound)
+}
+
+func (suite *EmailTemplatesTestSuite) TestNewEmailTemplates_RequiresBothParts() {
+	fsys := fstest.MapFS{
+		"layout.txt":  {Data: []byte(`{{template "content" .}}`)},
+		"layout.html": {Data: []byte(`{{template "content" .}}`)},
+		"hello.txt":   {Data: []byte(`{{define "subject"}}Hello{{end}}{{define "content"}}Hi{{end}}`)},
+	}
+	_, err := infrastructure.NewEmailTemplates(fsys)
+	suite.EqualError(err, "hello.txt has no html version")
+
+	fsys["hello.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}<p>Hi</p>{{end}}`)}
+	templates, err := infrastructure.NewEmailTemplates(fsys)
+	suite.Require().NoError(err)
+	email, err := templates.Render("hello", "", nil)
+	suite.NoError(err)
+	suite.Equal("<p>Hi</p>", strings.TrimSpace(email.HTML))
+}
+
+func TestEmailTemplatesTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailTemplatesTestSuite))
+}
@@ -0,0 +1,312 @@
+package test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeSMTPServer speaks just enough SMTP to accept messages.
+type fakeSMTPServer struct {
+	listener      net.Listener
+	tlsConfig     *tls.Config // STARTTLS is offered when set
+	closeAfterMsg bool
+	stallOn       string // the server stops answering at this verb
+
+	mu          sync.Mutex
+	connections int
+	usedTLS     bool
+	authed      bool
+	recipients  []string
+	messages    []string
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.connections++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	_, secure := conn.(*tls.Conn)
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		if strings.EqualFold(verb, s.stallOn) {
+			io.Copy(io.Discard, conn)
+			return
+		}
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			tp.PrintfLine("250-fake")
+			if s.tlsConfig != nil && !secure {
+				tp.PrintfLine("250-STARTTLS")
+			}
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			tp.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			tp = textproto.NewConn(conn)
+		case "AUTH":
+			s.mu.Lock()
+			s.authed = true
+			s.mu.Unlock()
+			tp.PrintfLine("235 ok")
+		case "RCPT":
+			s.mu.Lock()
+			s.recipients = append(s.recipients, arg)
+			s.mu.Unlock()
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(data))
+			s.usedTLS = s.usedTLS || secure
+			s.mu.Unlock()
+			tp.PrintfLine("250 queued")
+			if s.closeAfterMsg {
+				return
+			}
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+type EmailTransportTestSuite struct {
+	suite.Suite
+	certServer *httptest.Server
+}
+
+func (suite *EmailTransportTestSuite) SetupSuite() {
+	// httptest brings a certificate for 127.0.0.1 and a client that trusts it.
+	suite.certServer = httptest.NewUnstartedServer(http.NotFoundHandler())
+	suite.certServer.StartTLS()
+}
+
+func (suite *EmailTransportTestSuite) TearDownSuite() {
+	suite.certServer.Close()
+}
+
+func (suite *EmailTransportTestSuite) clientTLS() *tls.Config {
+	config := suite.certServer.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	config.ServerName = "127.0.0.1"
+	return config
+}
+
+func (suite *EmailTransportTestSuite) startServer(server *fakeSMTPServer, implicitTLS bool) *infrastructure.SMTPTransport {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	if implicitTLS {
+		listener = tls.NewListener(listener, suite.certServer.TLS)
+	}
+	server.listener = listener
+	suite.T().Cleanup(func() { listener.Close() })
+	go server.serve()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	transport := infrastructure.NewSMTPTransport(host, port)
+	transport.TLSConfig = suite.clientTLS()
+	suite.T().Cleanup(func() { transport.Close() })
+	return transport
+}
+
+const transportTestMessage = "From: from@example.com\r\nTo: to@example.com\r\nSubject: =?utf-8?q?Caf=C3=A9?=\r\nMessage-ID: <1@example.com>\r\n\r\nreset link https://example.com/reset?token=secret\r\n"
+
+func (suite *EmailTransportTestSuite) TestSMTP_ReusesConnection() {
+	server := &fakeSMTPServer{}
+	transport := suite.startServer(server, false)
+	transport.Security = infrastructure.SMTPSecurityNone
+
+	suite.NoError(transport.Send("from@example.com", []string{"Jane Doe <jane@example.com>"}, []byte(transportTestMessage)))
+	suite.NoError(transport.Send("from@example.com", []string{"joe@example.com"}, []byte(transportTestMessage)))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	suite.Equal(1, server.connections)
+	suite.Len(server.messages, 2)
+	suite.Equal([]string{"TO:<jane@example.com>", "TO:<joe@example.com>"}, server.recipients)
+	suite.False(server.usedTLS)
+}
+
+func (suite *EmailTransportTestSuite) TestSMTP_RedialsClosedConnection() {
+	server := &fakeSMTPServer{closeAfterMsg: true}
+	transport := suite.startServer(server, false)
+	transport.Security = infrastructure.SMTPSecurityNone
+
+	suite.NoError(transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage)))
+	suite.NoError(transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage)))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	suite.Equal(2, server.connections)
+	suite.Len(server.messages, 2)
+}
+
+func (suite *EmailTransportTestSuite) TestSMTP_TimesOutStalledServer() {
+	// EHLO stalls while dialing, to check for STARTTLS; DATA stalls mid-send
+	for verb, security := range map[string]string{"EHLO": infrastructure.SMTPSecuritySTARTTLS, "DATA": infrastructure.SMTPSecurityNone} {
+		server := &fakeSMTPServer{stallOn: verb}
+		transport := suite.startServer(server, false)
+		transport.Security = security
+		transport.Timeout = 100 * time.Millisecond
+
+		start := time.Now()
+		err := transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage))
+		suite.Error(err, verb)
+		suite.Less(time.Since(start), 5*time.Second, verb)
+	}
+}
+
+func (suite *EmailTransportTestSuite) TestSMTP_STARTTLSWithAuth() {
+	server := &fakeSMTPServer{tlsConfig: suite.certServer.TLS}
+	transport := suite.startServer(server, false)
+	transport.Username = "user"
+	transport.Password = "password"
+
+	suite.NoError(transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage)))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	suite.True(server.usedTLS)
+	suite.True(server.authed)
+}
+
+func (suite *EmailTransportTestSuite) TestSMTP_STARTTLSRequired() {
+	server := &fakeSMTPServer{}
+	transport := suite.startServer(server, false)
+
+	err := transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage))
+	suite.EqualError(err, "smtp server does not support STARTTLS")
+	suite.Empty(server.messages)
+}
+
+func (suite *EmailTransportTestSuite) TestSMTP_ImplicitTLS() {
+	server := &fakeSMTPServer{}
+	transport := suite.startServer(server, true)
+	transport.Security = infrastructure.SMTPSecurityTLS
+
+	suite.NoError(transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage)))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	suite.True(server.usedTLS)
+	suite.Len(server.messages, 1)
+}
+
+func (suite *EmailTransportTestSuite) TestFileTransport_WritesMaildir() {
+	dir := suite.T().TempDir()
+	transport, err := infrastructure.NewFileTransport(dir)
+	suite.Require().NoError(err)
+
+	suite.NoError(transport.Send("from@example.com", []string{"jane@example.com", "bcc@example.com"}, []byte(transportTestMessage)))
+
+	files, err := os.ReadDir(filepath.Join(dir, "new"))
+	suite.Require().NoError(err)
+	suite.Require().Len(files, 1)
+	content, err := os.ReadFile(filepath.Join(dir, "new", files[0].Name()))
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(string(content), "Return-Path: <from@example.com>\r\nX-Envelope-To: jane@example.com, bcc@example.com\r\nFrom: from@example.com\r\n"))
+
+	tmp, _ := os.ReadDir(filepath.Join(dir, "tmp"))
+	suite.Empty(tmp)
+}
+
+func (suite *EmailTransportTestSuite) TestLogTransport_LeavesOutBody() {
+	var out bytes.Buffer
+	transport := infrastructure.NewLogTransport(log.New(&out, "", 0))
+
+	suite.NoError(transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage)))
+	suite.Contains(out.String(), `subject="Café"`)
+	suite.Contains(out.String(), "to=jane@example.com")
+	suite.NotContains(out.String(), "token=secret")
+
+	out.Reset()
+	transport.Body = true
+	suite.NoError(transport.Send("from@example.com", []string{"jane@example.com"}, []byte(transportTestMessage)))
+	suite.Contains(out.String(), "token=secret")
+}
+
+func (suite *EmailTransportTestSuite) TestCaptureTransport_ThroughEmailService() {
+	capture := infrastructure.NewCaptureTransport()
+	service := &infrastructure.SMTPEmailService{From: "Blog <from@example.com>", Transport: capture}
+
+	suite.NoError(service.SendEmail([]string{"Jane <jane@example.com>"}, "Welcome", "Hello"))
+	suite.NoError(service.SendEmail([]string{"joe@example.com"}, "Other", "Hi"))
+
+	suite.Len(capture.Messages(), 2)
+	email, ok := capture.Last("JANE@example.com")
+	suite.Require().True(ok)
+	suite.Equal("from@example.com", email.From)
+	msg, err := email.Message()
+	suite.Require().NoError(err)
+	suite.Equal("Welcome", msg.Header.Get("Subject"))
+
+	capture.Reset()
+	suite.Empty(capture.Messages())
+	_, ok = capture.Last("jane@example.com")
+	suite.False(ok)
+}
+
+func (suite *EmailTransportTestSuite) TestLoadEmailTransportFromEnv() {
+	suite.T().Setenv("SMTP_PORT", "465")
+	transport, err := infrastructure.LoadEmailTransportFromEnv()
+	suite.Require().NoError(err)
+	suite.Equal(infrastructure.SMTPSecurityTLS, transport.(*infrastructure.SMTPTransport).Security)
+
+	suite.T().Setenv("SMTP_SECURITY", "ssl")
+	_, err = infrastructure.LoadEmailTransportFromEnv()
+	suite.EqualError(err, "invalid value for SMTP_SECURITY")
+
+	suite.T().Setenv("EMAIL_TRANSPORT", "file")
+	suite.T().Setenv("EMAIL_FILE_DIR", suite.T().TempDir())
+	transport, err = infrastructure.LoadEmailTransportFromEnv()
+	suite.NoError(err)
+	suite.IsType(&infrastructure.FileTransport{}, transport)
+
+	suite.T().Setenv("EMAIL_TRANSPORT", "pigeon")
+	_, err = infrastructure.LoadEmailTransportFromEnv()
+	suite.EqualError(err, "invalid value for EMAIL_TRANSPORT")
+}
+
+func TestEmailTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailTransportTestSuite))
+}
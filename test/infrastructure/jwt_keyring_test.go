@@ -0,0 +1,278 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/blog-platform/test/mocks"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyRingTestSuite struct {
+	suite.Suite
+	mockTokenRepo *mocks.MockTokenRepository
+	rsaKey        *rsa.PrivateKey
+	ecKey         *ecdsa.PrivateKey
+	edKey         ed25519.PrivateKey
+}
+
+func (suite *KeyRingTestSuite) SetupSuite() {
+	var err error
+	suite.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+	suite.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	_, suite.edKey, err = ed25519.GenerateKey(rand.Reader)
+	suite.Require().NoError(err)
+}
+
+func (suite *KeyRingTestSuite) SetupTest() {
+	suite.mockTokenRepo = new(mocks.MockTokenRepository)
+	suite.mockTokenRepo.On("FetchByContent", mock.Anything).Return(domain.Token{Status: "active"}, nil)
+}
+
+func (suite *KeyRingTestSuite) newInfra(accessKeys *infrastructure.KeyRing) *infrastructure.JWTInfrastructure {
+	infra, err := infrastructure.NewJWTInfrastructureWithKeyRings(accessKeys, nil, []byte("legacy_access"), []byte("legacy_refresh"), suite.mockTokenRepo)
+	suite.Require().NoError(err)
+	return infra
+}
+
+func (suite *KeyRingTestSuite) TestSignAndValidate_AllAlgorithms() {
+	keys := []*infrastructure.SigningKey{
+		{ID: "hmac", Algorithm: "HS256", PrivateKey: []byte("hmac_secret")},
+		{ID: "rsa", Algorithm: "RS256", PrivateKey: suite.rsaKey},
+		{ID: "ec", Algorithm: "ES256", PrivateKey: suite.ecKey},
+		{ID: "ed", Algorithm: "EdDSA", PrivateKey: suite.edKey},
+	}
+
+	for _, key := range keys {
+		ring, err := infrastructure.NewKeyRing(key)
+		suite.Require().NoError(err)
+		infra := suite.newInfra(ring)
+
+		tokenString, err := infra.GenerateAccessToken("42", "user")
+		suite.Require().NoError(err, key.Algorithm)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, &domain.TokenClaims{})
+		suite.Require().NoError(err)
+		suite.Equal(key.ID, parsed.Header["kid"])
+		suite.Equal(key.Algorithm, parsed.Method.Alg())
+
+		claims, err := infra.ValidateAccessToken("Bearer " + tokenString)
+		suite.NoError(err, key.Algorithm)
+		suite.Equal("42", claims.UserID)
+	}
+}
+
+func (suite *KeyRingTestSuite) TestLegacyTokenWithoutKidStillValidates() {
+	ring, err := infrastructure.NewKeyRing(&infrastructure.SigningKey{ID: "rsa", Algorithm: "RS256", PrivateKey: suite.rsaKey})
+	suite.Require().NoError(err)
+
+	legacy := infrastructure.NewJWTInfrastructure([]byte("legacy_access"), nil, suite.mockTokenRepo)
+	tokenString, err := legacy.GenerateAccessToken("7", "admin")
+	suite.Require().NoError(err)
+
+	infra := suite.newInfra(ring)
+	infra.Config.LegacySecretUntil = time.Now().Add(time.Hour)
+	claims, err := infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.NoError(err)
+	suite.Equal("7", claims.UserID)
+}
+
+func (suite *KeyRingTestSuite) TestLegacyTokenWithoutKidRejectedOutsideWindow() {
+	ring, err := infrastructure.NewKeyRing(&infrastructure.SigningKey{ID: "rsa", Algorithm: "RS256", PrivateKey: suite.rsaKey})
+	suite.Require().NoError(err)
+
+	legacy := infrastructure.NewJWTInfrastructure([]byte("legacy_access"), nil, suite.mockTokenRepo)
+	tokenString, err := legacy.GenerateAccessToken("7", "admin")
+	suite.Require().NoError(err)
+
+	infra := suite.newInfra(ring)
+	_, err = infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.ErrorContains(err, "token has no kid")
+
+	infra.Config.LegacySecretUntil = time.Now().Add(-time.Minute)
+	_, err = infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.Error(err)
+}
+
+func (suite *KeyRingTestSuite) TestEmptyLegacySecret() {
+	ring, err := infrastructure.NewKeyRing(&infrastructure.SigningKey{ID: "rsa", Algorithm: "RS256", PrivateKey: suite.rsaKey})
+	suite.Require().NoError(err)
+
+	_, err = infrastructure.NewJWTInfrastructureWithKeyRings(nil, ring, nil, []byte("legacy_refresh"), suite.mockTokenRepo)
+	suite.EqualError(err, "HMAC key legacy access needs a non-empty secret")
+
+	// with a ring loaded an empty secret retires the fallback, and a token
+	// forged with the empty key never verifies
+	infra, err := infrastructure.NewJWTInfrastructureWithKeyRings(ring, ring, nil, nil, suite.mockTokenRepo)
+	suite.Require().NoError(err)
+	infra.Config.LegacySecretUntil = time.Now().Add(time.Hour)
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, domain.TokenClaims{
+		UserID:           "1",
+		UserRole:         "admin",
+		TokenType:        "access",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte{})
+	suite.Require().NoError(err)
+
+	_, err = infra.ValidateAccessToken("Bearer " + forged)
+	suite.Error(err)
+}
+
+func (suite *KeyRingTestSuite) TestRotate_OldTokensValidateDuringGrace() {
+	ring, err := infrastructure.NewKeyRing(&infrastructure.SigningKey{ID: "old", Algorithm: "RS256", PrivateKey: suite.rsaKey, ActiveFrom: time.Now().Add(-time.Hour)})
+	suite.Require().NoError(err)
+	infra := suite.newInfra(ring)
+
+	oldToken, err := infra.GenerateAccessToken("1", "user")
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(ring.Rotate(&infrastructure.SigningKey{ID: "new", Algorithm: "ES256", PrivateKey: suite.ecKey}, time.Hour))
+
+	signer, err := ring.Signer()
+	suite.Require().NoError(err)
+	suite.Equal("new", signer.ID)
+
+	_, err = infra.ValidateAccessToken("Bearer " + oldToken)
+	suite.NoError(err)
+
+	newToken, err := infra.GenerateAccessToken("1", "user")
+	suite.Require().NoError(err)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &domain.TokenClaims{})
+	suite.Equal("new", parsed.Header["kid"])
+}
+
+func (suite *KeyRingTestSuite) TestScheduledKeyDoesNotSignBeforeActivation() {
+	ring, err := infrastructure.NewKeyRing(
+		&infrastructure.SigningKey{ID: "current", Algorithm: "RS256", PrivateKey: suite.rsaKey, ActiveFrom: time.Now().Add(-time.Hour)},
+		&infrastructure.SigningKey{ID: "next", Algorithm: "EdDSA", PrivateKey: suite.edKey, ActiveFrom: time.Now().Add(time.Hour)},
+	)
+	suite.Require().NoError(err)
+
+	signer, err := ring.Signer()
+	suite.Require().NoError(err)
+	suite.Equal("current", signer.ID)
+	suite.Len(ring.JWKS().Keys, 2, "scheduled keys are published ahead of activation")
+}
+
+func (suite *KeyRingTestSuite) TestExpiredKeyIsRejected() {
+	ring, err := infrastructure.NewKeyRing(
+		&infrastructure.SigningKey{ID: "retired", Algorithm: "RS256", PrivateKey: suite.rsaKey, ActiveFrom: time.Now().Add(-2 * time.Hour)},
+		&infrastructure.SigningKey{ID: "current", Algorithm: "EdDSA", PrivateKey: suite.edKey, ActiveFrom: time.Now().Add(-time.Hour)},
+	)
+	suite.Require().NoError(err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, domain.TokenClaims{
+		UserID:           "1",
+		UserRole:         "user",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	token.Header["kid"] = "retired"
+	tokenString, err := token.SignedString(suite.rsaKey)
+	suite.Require().NoError(err)
+
+	retired, err := ring.Lookup("retired")
+	suite.Require().NoError(err)
+	retired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	_, err = suite.newInfra(ring).ValidateAccessToken("Bearer " + tokenString)
+	suite.Error(err)
+	suite.Contains(err.Error(), "unknown signing key")
+}
+
+func (suite *KeyRingTestSuite) TestAlgorithmConfusionIsRejected() {
+	ring, err := infrastructure.NewKeyRing(&infrastructure.SigningKey{ID: "rsa", Algorithm: "RS256", PrivateKey: suite.rsaKey})
+	suite.Require().NoError(err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&suite.rsaKey.PublicKey)
+	suite.Require().NoError(err)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, domain.TokenClaims{
+		UserID:           "1",
+		UserRole:         "admin",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	token.Header["kid"] = "rsa"
+	tokenString, err := token.SignedString(publicDER)
+	suite.Require().NoError(err)
+
+	_, err = suite.newInfra(ring).ValidateAccessToken("Bearer " + tokenString)
+	suite.Error(err)
+	suite.Contains(err.Error(), "unexpected signing method")
+}
+
+func (suite *KeyRingTestSuite) TestJWKS_PublishesOnlyPublicKeys() {
+	ring, err := infrastructure.NewKeyRing(
+		&infrastructure.SigningKey{ID: "a-rsa", Algorithm: "RS256", PrivateKey: suite.rsaKey},
+		&infrastructure.SigningKey{ID: "b-ec", Algorithm: "ES256", PrivateKey: suite.ecKey},
+		&infrastructure.SigningKey{ID: "c-ed", Algorithm: "EdDSA", PrivateKey: suite.edKey},
+		&infrastructure.SigningKey{ID: "d-hmac", Algorithm: "HS256", PrivateKey: []byte("secret")},
+	)
+	suite.Require().NoError(err)
+
+	set := suite.newInfra(ring).JWKS()
+	suite.Require().Len(set.Keys, 3)
+	suite.Equal("RSA", set.Keys[0].KeyType)
+	suite.Equal("AQAB", set.Keys[0].E)
+	suite.Equal("EC", set.Keys[1].KeyType)
+	suite.Equal("P-256", set.Keys[1].Curve)
+	suite.Len(set.Keys[1].X, 43)
+	suite.Equal("OKP", set.Keys[2].KeyType)
+	suite.Equal("Ed25519", set.Keys[2].Curve)
+
+	raw, err := json.Marshal(set)
+	suite.NoError(err)
+	suite.NotContains(string(raw), "secret")
+}
+
+func (suite *KeyRingTestSuite) TestNewKeyRing_RejectsInvalidKeys() {
+	_, err := infrastructure.NewKeyRing(&infrastructure.SigningKey{ID: "x", Algorithm: "RS256", PrivateKey: suite.ecKey})
+	suite.Error(err)
+
+	_, err = infrastructure.NewKeyRing(&infrastructure.SigningKey{ID: "x", Algorithm: "none", PrivateKey: []byte("s")})
+	suite.Error(err)
+
+	_, err = infrastructure.NewKeyRing(
+		&infrastructure.SigningKey{ID: "dup", Algorithm: "HS256", PrivateKey: []byte("a")},
+		&infrastructure.SigningKey{ID: "dup", Algorithm: "HS256", PrivateKey: []byte("b")},
+	)
+	suite.Error(err)
+}
+
+func (suite *KeyRingTestSuite) TestLoadKeyRingFile() {
+	dir := suite.T().TempDir()
+	der, err := x509.MarshalPKCS8PrivateKey(suite.edKey)
+	suite.Require().NoError(err)
+	keyPath := filepath.Join(dir, "ed.pem")
+	suite.Require().NoError(os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	ringPath := filepath.Join(dir, "ring.json")
+	ringJSON := `[{"kid":"ed","alg":"EdDSA","private_key_path":"` + keyPath + `"},{"kid":"hs","alg":"HS256","secret":"s3cret"}]`
+	suite.Require().NoError(os.WriteFile(ringPath, []byte(ringJSON), 0600))
+
+	ring, err := infrastructure.LoadKeyRingFile(ringPath)
+	suite.Require().NoError(err)
+
+	_, err = ring.Lookup("ed")
+	suite.NoError(err)
+	_, err = ring.Lookup("hs")
+	suite.NoError(err)
+}
+
+func TestKeyRingTestSuite(t *testing.T) {
+	suite.Run(t, new(KeyRingTestSuite))
+}
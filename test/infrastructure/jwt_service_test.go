@@ -209,6 +209,178 @@ func (suite *JWTInfrastructureTestSuite) TestValidateRefreshToken_ExpiredToken()
 	suite.Contains(err.Error(), "token is expired")
 }
 
+func (suite *JWTInfrastructureTestSuite) TestGenerateAccessToken_RegisteredClaims() {
+	suite.infra.Config = infrastructure.JWTConfig{
+		AccessTTL: 15 * time.Minute,
+		Issuer:    "blog-platform",
+		Audience:  []string{"blog-api"},
+	}
+
+	tokenString, err := suite.infra.GenerateAccessToken("user-123", "user")
+	suite.NoError(err)
+
+	claims := &domain.TokenClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return suite.accessSecret, nil
+	})
+	suite.NoError(err)
+	suite.Equal("user-123", claims.Subject)
+	suite.Equal("blog-platform", claims.Issuer)
+	suite.Equal(jwt.ClaimStrings{"blog-api"}, claims.Audience)
+	suite.Equal("access", claims.TokenType)
+	suite.NotEmpty(claims.ID)
+	suite.NotNil(claims.NotBefore)
+	suite.WithinDuration(time.Now().Add(15*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+}
+
+func (suite *JWTInfrastructureTestSuite) TestGenerateToken_UniqueJTI() {
+	first, _ := suite.infra.GenerateAccessToken("user-123", "user")
+	second, _ := suite.infra.GenerateAccessToken("user-123", "user")
+
+	firstClaims, secondClaims := &domain.TokenClaims{}, &domain.TokenClaims{}
+	jwt.NewParser().ParseUnverified(first, firstClaims)
+	jwt.NewParser().ParseUnverified(second, secondClaims)
+	suite.NotEqual(firstClaims.ID, secondClaims.ID)
+}
+
+func (suite *JWTInfrastructureTestSuite) TestValidateAccessToken_WrongIssuer() {
+	suite.infra.Config = infrastructure.JWTConfig{Issuer: "someone-else"}
+	tokenString, _ := suite.infra.GenerateAccessToken("user-123", "user")
+
+	suite.infra.Config = infrastructure.JWTConfig{Issuer: "blog-platform"}
+	suite.mockTokenRepo.On("FetchByContent", tokenString).Return(domain.Token{Content: tokenString, Status: "active"}, nil)
+
+	_, err := suite.infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.Error(err)
+	suite.Contains(err.Error(), "invalid issuer")
+}
+
+func (suite *JWTInfrastructureTestSuite) TestValidateAccessToken_WrongAudience() {
+	suite.infra.Config = infrastructure.JWTConfig{Audience: []string{"other-api"}}
+	tokenString, _ := suite.infra.GenerateAccessToken("user-123", "user")
+
+	suite.infra.Config = infrastructure.JWTConfig{Audience: []string{"blog-api"}}
+	suite.mockTokenRepo.On("FetchByContent", tokenString).Return(domain.Token{Content: tokenString, Status: "active"}, nil)
+
+	_, err := suite.infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.Error(err)
+	suite.Contains(err.Error(), "invalid audience")
+}
+
+func (suite *JWTInfrastructureTestSuite) TestValidateAccessToken_ExpiredWithinLeeway() {
+	claims := domain.TokenClaims{
+		UserID:   "user-123",
+		UserRole: "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-10 * time.Second)),
+		},
+	}
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(suite.accessSecret)
+	suite.mockTokenRepo.On("FetchByContent", tokenString).Return(domain.Token{Content: tokenString, Status: "active"}, nil)
+
+	suite.infra.Config = infrastructure.JWTConfig{Leeway: 30 * time.Second}
+	_, err := suite.infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.NoError(err)
+}
+
+func (suite *JWTInfrastructureTestSuite) TestValidateAccessToken_RejectsRefreshToken() {
+	infra := &infrastructure.JWTInfrastructure{AccessSecret: suite.accessSecret, RefreshSecret: suite.accessSecret, TokenRepo: suite.mockTokenRepo}
+	tokenString, _ := infra.GenerateRefreshToken("user-123", "user")
+	suite.mockTokenRepo.On("FetchByContent", tokenString).Return(domain.Token{Content: tokenString, Status: "active"}, nil)
+
+	_, err := infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.EqualError(err, "invalid token type")
+}
+
+func (suite *JWTInfrastructureTestSuite) TestValidateAccessToken_StatelessWithDenylist() {
+	suite.infra.Denylist = infrastructure.NewInMemoryTokenDenylist()
+	tokenString, _ := suite.infra.GenerateAccessToken("user-123", "user")
+
+	claims, err := suite.infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.NoError(err)
+	suite.Equal("user-123", claims.UserID)
+	suite.mockTokenRepo.AssertNotCalled(suite.T(), "FetchByContent", tokenString)
+}
+
+func (suite *JWTInfrastructureTestSuite) TestRevokeToken_RejectsRevokedAccessToken() {
+	suite.infra.Denylist = infrastructure.NewInMemoryTokenDenylist()
+	tokenString, _ := suite.infra.GenerateAccessToken("user-123", "user")
+
+	claims, err := suite.infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.Require().NoError(err)
+	suite.NoError(suite.infra.RevokeToken(claims))
+
+	_, err = suite.infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.EqualError(err, "revoked token")
+}
+
+func (suite *JWTInfrastructureTestSuite) TestRevokeToken_NoDenylist() {
+	err := suite.infra.RevokeToken(&domain.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "abc"}})
+	suite.EqualError(err, "token revocation is not configured")
+}
+
+func (suite *JWTInfrastructureTestSuite) TestChallengeToken_OnlyValidAsChallenge() {
+	suite.infra.Denylist = infrastructure.NewInMemoryTokenDenylist()
+	challenge, err := suite.infra.GenerateChallengeToken("user-123", "user")
+	suite.Require().NoError(err)
+
+	claims, err := suite.infra.ValidateChallengeToken(challenge)
+	suite.NoError(err)
+	suite.Equal("user-123", claims.UserID)
+	suite.WithinDuration(time.Now().Add(5*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+	_, err = suite.infra.ValidateAccessToken("Bearer " + challenge)
+	suite.EqualError(err, "invalid token type")
+
+	access, _ := suite.infra.GenerateAccessToken("user-123", "user")
+	_, err = suite.infra.ValidateChallengeToken(access)
+	suite.EqualError(err, "invalid token type")
+}
+
+func (suite *JWTInfrastructureTestSuite) TestMagicLinkToken_SingleUseViaDenylist() {
+	suite.infra.Denylist = infrastructure.NewInMemoryTokenDenylist()
+	link, err := suite.infra.GenerateMagicLinkToken("user-123", "user")
+	suite.Require().NoError(err)
+
+	claims, err := suite.infra.ValidateMagicLinkToken(link)
+	suite.Require().NoError(err)
+	suite.WithinDuration(time.Now().Add(15*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+	_, err = suite.infra.ValidateChallengeToken(link)
+	suite.EqualError(err, "invalid token type")
+	_, err = suite.infra.ValidateAccessToken("Bearer " + link)
+	suite.EqualError(err, "invalid token type")
+
+	suite.NoError(suite.infra.RevokeToken(claims))
+	_, err = suite.infra.ValidateMagicLinkToken(link)
+	suite.EqualError(err, "revoked token")
+}
+
+func (suite *JWTInfrastructureTestSuite) TestConsumeToken_OnlyOnce() {
+	suite.infra.Denylist = infrastructure.NewInMemoryTokenDenylist()
+	link, _ := suite.infra.GenerateMagicLinkToken("user-123", "user")
+	claims, err := suite.infra.ValidateMagicLinkToken(link)
+	suite.Require().NoError(err)
+
+	suite.NoError(suite.infra.ConsumeToken(claims))
+	suite.ErrorIs(suite.infra.ConsumeToken(claims), domain.ErrTokenUsed)
+}
+
+func (suite *JWTInfrastructureTestSuite) TestGenerateImpersonationToken() {
+	tokenString, err := suite.infra.GenerateImpersonationToken("7", "author", "1")
+	suite.Require().NoError(err)
+
+	suite.mockTokenRepo.On("FetchByContent", tokenString).Return(domain.Token{Status: "active"}, nil)
+	claims, err := suite.infra.ValidateAccessToken("Bearer " + tokenString)
+	suite.Require().NoError(err)
+	suite.Equal("7", claims.UserID)
+	suite.Equal("1", claims.ImpersonatorID)
+	suite.WithinDuration(time.Now().Add(15*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+	_, err = suite.infra.GenerateImpersonationToken("7", "author", "")
+	suite.Error(err)
+}
+
 func TestJWTInfrastructureTestSuite(t *testing.T) {
 	suite.Run(t, new(JWTInfrastructureTestSuite))
 }
\ No newline at end of file
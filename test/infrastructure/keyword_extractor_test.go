@@ -0,0 +1,53 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type KeywordExtractorTestSuite struct {
+	suite.Suite
+	extractor *infrastructure.KeywordExtractor
+}
+
+func (suite *KeywordExtractorTestSuite) SetupTest() {
+	suite.extractor = infrastructure.NewKeywordExtractor()
+}
+
+func (suite *KeywordExtractorTestSuite) TestRanksTitleAndRecurringPhrases() {
+	keywords := suite.extractor.Keywords(
+		"Profiling Golang services",
+		"Profiling with pprof shows where time goes. The heap profile and the CPU profile answer different questions; "+
+			"a CPU profile is what you want when latency grows. Golang makes the heap profile cheap.",
+		5,
+	)
+
+	terms := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		terms[i] = keyword.Term
+	}
+	suite.Equal([]string{"golang", "profile", "profiling", "services", "cpu profile"}, terms)
+	suite.Equal(1.0, keywords[0].Score)
+}
+
+func (suite *KeywordExtractorTestSuite) TestKeepsRecurringPhrases() {
+	keywords := suite.extractor.Keywords("", "Machine learning is fun. Most machine learning is statistics.", 2)
+	suite.Equal("machine learning", keywords[0].Term)
+	suite.Equal("learning", keywords[1].Term)
+}
+
+func (suite *KeywordExtractorTestSuite) TestSkipsStopwordsShortWordsAndNumbers() {
+	keywords := suite.extractor.Keywords("", "It is what it is, 2024 in a nutshell.", 10)
+	suite.Len(keywords, 1)
+	suite.Equal("nutshell", keywords[0].Term)
+}
+
+func (suite *KeywordExtractorTestSuite) TestEmptyText() {
+	suite.Empty(suite.extractor.Keywords("", "", 5))
+}
+
+func TestKeywordExtractorTestSuite(t *testing.T) {
+	suite.Run(t, new(KeywordExtractorTestSuite))
+}
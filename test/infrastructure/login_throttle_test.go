@@ -0,0 +1,170 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoginThrottleTestSuite struct {
+	suite.Suite
+	now       time.Time
+	store     *infrastructure.InMemoryLoginAttemptStore
+	throttler *infrastructure.LoginThrottler
+}
+
+func (suite *LoginThrottleTestSuite) SetupTest() {
+	suite.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	config := infrastructure.LoginThrottleConfig{
+		FreeAttempts:       2,
+		BaseDelay:          time.Second,
+		MaxDelay:           8 * time.Second,
+		LockoutThreshold:   6,
+		IPLockoutThreshold: 20,
+		LockoutDuration:    15 * time.Minute,
+		ResetAfter:         time.Hour,
+	}
+	suite.store = infrastructure.NewInMemoryLoginAttemptStore()
+	suite.throttler = infrastructure.NewLoginThrottler(suite.store, config)
+	suite.throttler.Now = func() time.Time { return suite.now }
+}
+
+func (suite *LoginThrottleTestSuite) fail(times int, account string, clientIP string) bool {
+	locked := false
+	for i := 0; i < times; i++ {
+		var err error
+		locked, err = suite.throttler.RegisterFailure(account, clientIP)
+		suite.Require().NoError(err)
+	}
+	return locked
+}
+
+func (suite *LoginThrottleTestSuite) TestFreeAttempts_NoDelay() {
+	suite.fail(2, "1", "10.0.0.1")
+
+	wait, err := suite.throttler.Check("1", "10.0.0.1")
+	suite.NoError(err)
+	suite.Zero(wait)
+}
+
+// account deletion removes failures by this key, see removeAccountData
+func (suite *LoginThrottleTestSuite) TestAccountFailures_StoredUnderAccountKey() {
+	suite.fail(2, "1", "")
+
+	attempt, err := suite.store.Fetch(domain.LoginAttemptAccountKey("1"))
+	suite.NoError(err)
+	suite.Equal(2, attempt.Failures)
+}
+
+func (suite *LoginThrottleTestSuite) TestBackoff_DoublesAndCaps() {
+	suite.fail(3, "1", "")
+	wait, _ := suite.throttler.Check("1", "")
+	suite.Equal(time.Second, wait)
+
+	suite.fail(1, "1", "")
+	wait, _ = suite.throttler.Check("1", "")
+	suite.Equal(2*time.Second, wait)
+
+	suite.fail(1, "1", "")
+	suite.now = suite.now.Add(time.Second)
+	wait, _ = suite.throttler.Check("1", "")
+	suite.Equal(3*time.Second, wait)
+
+	suite.now = suite.now.Add(3 * time.Second)
+	wait, _ = suite.throttler.Check("1", "")
+	suite.Zero(wait)
+}
+
+func (suite *LoginThrottleTestSuite) TestLockout_AfterThreshold() {
+	suite.False(suite.fail(5, "1", ""))
+	suite.True(suite.fail(1, "1", ""))
+
+	wait, err := suite.throttler.Check("1", "")
+	suite.NoError(err)
+	suite.Equal(15*time.Minute, wait)
+
+	suite.now = suite.now.Add(15*time.Minute + time.Second)
+	wait, _ = suite.throttler.Check("1", "")
+	suite.Zero(wait)
+}
+
+func (suite *LoginThrottleTestSuite) TestLockout_CountStartsOverOnceServed() {
+	suite.True(suite.fail(6, "1", ""))
+	suite.now = suite.now.Add(15*time.Minute + time.Second)
+
+	suite.False(suite.fail(1, "1", ""))
+	wait, _ := suite.throttler.Check("1", "")
+	suite.Zero(wait)
+
+	suite.False(suite.fail(4, "1", ""))
+	suite.True(suite.fail(1, "1", ""))
+}
+
+func (suite *LoginThrottleTestSuite) TestUnlock_ClearsLockout() {
+	suite.fail(6, "1", "")
+
+	suite.NoError(suite.throttler.Unlock("1"))
+	wait, _ := suite.throttler.Check("1", "")
+	suite.Zero(wait)
+}
+
+func (suite *LoginThrottleTestSuite) TestSuccess_ResetsAccountButNotIP() {
+	suite.fail(4, "1", "10.0.0.1")
+	suite.NoError(suite.throttler.RegisterSuccess("1"))
+
+	wait, _ := suite.throttler.Check("2", "")
+	suite.Zero(wait)
+	wait, _ = suite.throttler.Check("1", "10.0.0.1")
+	suite.Equal(2*time.Second, wait)
+}
+
+func (suite *LoginThrottleTestSuite) TestIPCounter_SpansAccounts() {
+	for i := 0; i < 4; i++ {
+		suite.fail(1, string(rune('a'+i)), "10.0.0.1")
+	}
+
+	wait, _ := suite.throttler.Check("z", "10.0.0.1")
+	suite.Equal(2*time.Second, wait)
+	wait, _ = suite.throttler.Check("z", "10.0.0.2")
+	suite.Zero(wait)
+}
+
+func (suite *LoginThrottleTestSuite) TestFailures_ExpireAfterQuietPeriod() {
+	suite.fail(5, "1", "")
+	suite.now = suite.now.Add(2 * time.Hour)
+
+	wait, _ := suite.throttler.Check("1", "")
+	suite.Zero(wait)
+
+	suite.False(suite.fail(1, "1", ""))
+	wait, _ = suite.throttler.Check("1", "")
+	suite.Zero(wait)
+}
+
+func (suite *LoginThrottleTestSuite) TestTrustedProxies_OnlyTheyMaySetClientIP() {
+	suite.T().Setenv("TRUSTED_PROXIES", " 10.0.0.0/8, ")
+	suite.Equal([]string{"10.0.0.0/8"}, infrastructure.LoadTrustedProxiesFromEnv())
+
+	engine := gin.New()
+	suite.Require().NoError(engine.SetTrustedProxies(infrastructure.LoadTrustedProxiesFromEnv()))
+	var clientIP string
+	engine.GET("/", func(ctx *gin.Context) { clientIP = ctx.ClientIP() })
+
+	for peer, expected := range map[string]string{"10.1.2.3:4000": "203.0.113.9", "198.51.100.7:4000": "198.51.100.7"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = peer
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+		suite.Equal(expected, clientIP, peer)
+	}
+}
+
+func TestLoginThrottleTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginThrottleTestSuite))
+}
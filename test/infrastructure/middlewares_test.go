@@ -81,6 +81,7 @@ func (suite *MiddlewareTestSuite) TestAdminMiddleware_Success() {
 	w := httptest.NewRecorder()
 
 	suite.router.GET("/admin", func(c *gin.Context) {
+		c.Set("user_id", "1")
 		c.Set("role", "admin")
 		c.Next()
 	}, suite.middleware.AdminMiddleware(), func(c *gin.Context) {
@@ -96,6 +97,7 @@ func (suite *MiddlewareTestSuite) TestAdminMiddleware_Forbidden() {
 	w := httptest.NewRecorder()
 
 	suite.router.GET("/admin", func(c *gin.Context) {
+		c.Set("user_id", "1")
 		c.Set("role", "user")
 		c.Next()
 	}, suite.middleware.AdminMiddleware(), func(c *gin.Context) {
@@ -107,7 +109,7 @@ func (suite *MiddlewareTestSuite) TestAdminMiddleware_Forbidden() {
 	assert.JSONEq(suite.T(), `{"error":"unauthorized to access this route"}`, w.Body.String())
 }
 
-func (suite *MiddlewareTestSuite) TestAdminMiddleware_NoRole() {
+func (suite *MiddlewareTestSuite) TestAdminMiddleware_Unauthenticated() {
 	req, _ := http.NewRequest("GET", "/admin", nil)
 	w := httptest.NewRecorder()
 
@@ -116,8 +118,8 @@ func (suite *MiddlewareTestSuite) TestAdminMiddleware_NoRole() {
 	})
 	suite.router.ServeHTTP(w, req)
 
-	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
-	assert.JSONEq(suite.T(), `{"error":"unauthorized to access this route"}`, w.Body.String())
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+	assert.JSONEq(suite.T(), `{"error":"authentication required"}`, w.Body.String())
 }
 
 func (suite *MiddlewareTestSuite) TestAccountOwnerMiddleware_Success() {
@@ -160,8 +162,141 @@ func (suite *MiddlewareTestSuite) TestAccountOwnerMiddleware_NoUserID() {
 	})
 	suite.router.ServeHTTP(w, req)
 
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+	assert.JSONEq(suite.T(), `{"error":"authentication required"}`, w.Body.String())
+}
+
+func (suite *MiddlewareTestSuite) TestAuthMiddleware_PersonalAccessToken() {
+	pats := new(mocks.MockPersonalAccessTokenAuthenticator)
+	suite.middleware.PersonalAccessTokens = pats
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer bpat_secret")
+	w := httptest.NewRecorder()
+
+	claims := &domain.TokenClaims{UserID: "7", UserRole: "user", TokenType: "personal_access", Scopes: []string{"blogs:write"}}
+	pats.On("Authenticate", "bpat_secret").Return(claims, nil)
+
+	suite.router.GET("/test", suite.middleware.AuthMiddleware(), func(c *gin.Context) {
+		assert.Equal(suite.T(), "7", c.GetString("user_id"))
+		assert.Equal(suite.T(), []string{"blogs:write"}, c.GetStringSlice("scopes"))
+		c.Status(http.StatusOK)
+	})
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockJWTService.AssertNotCalled(suite.T(), "ValidateAccessToken", "Bearer bpat_secret")
+}
+
+func (suite *MiddlewareTestSuite) TestAuthMiddleware_InvalidPersonalAccessToken() {
+	pats := new(mocks.MockPersonalAccessTokenAuthenticator)
+	suite.middleware.PersonalAccessTokens = pats
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer bpat_revoked")
+	w := httptest.NewRecorder()
+
+	pats.On("Authenticate", "bpat_revoked").Return(nil, errors.New("invalid token"))
+
+	suite.router.GET("/test", suite.middleware.AuthMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+func (suite *MiddlewareTestSuite) TestScopeMiddleware() {
+	suite.router.GET("/scoped/:granted", func(c *gin.Context) {
+		if granted := c.Param("granted"); granted != "session" {
+			c.Set("scopes", []string{granted})
+		}
+		c.Next()
+	}, suite.middleware.ScopeMiddleware("blogs:write"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for granted, expected := range map[string]int{
+		"blogs:write": http.StatusOK,
+		"blogs:read":  http.StatusForbidden,
+		"session":     http.StatusOK,
+	} {
+		req, _ := http.NewRequest("GET", "/scoped/"+granted, nil)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), expected, w.Code, granted)
+	}
+}
+
+func (suite *MiddlewareTestSuite) TestSessionOnlyMiddleware_RejectsPersonalAccessToken() {
+	req, _ := http.NewRequest("POST", "/tokens", nil)
+	w := httptest.NewRecorder()
+
+	suite.router.POST("/tokens", func(c *gin.Context) {
+		c.Set("scopes", []string{"users:admin"})
+		c.Next()
+	}, suite.middleware.SessionOnlyMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	suite.router.ServeHTTP(w, req)
+
 	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
-	assert.JSONEq(suite.T(), `{"error":"unauthorized to access this route"}`, w.Body.String())
+}
+
+func (suite *MiddlewareTestSuite) TestSessionOnlyMiddleware_RejectsImpersonation() {
+	req, _ := http.NewRequest("POST", "/2fa/disable", nil)
+	req.Header.Set("Authorization", "Bearer impersonation_token")
+	w := httptest.NewRecorder()
+
+	claims := &domain.TokenClaims{UserID: "7", UserRole: "author", ImpersonatorID: "1"}
+	suite.mockJWTService.On("ValidateAccessToken", "Bearer impersonation_token").Return(claims, nil)
+
+	suite.router.POST("/2fa/disable", suite.middleware.AuthMiddleware(), suite.middleware.SessionOnlyMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	assert.Equal(suite.T(), "1", w.Header().Get("X-Impersonated-By"))
+}
+
+func (suite *MiddlewareTestSuite) TestRequirePermission() {
+	cases := map[string]int{
+		"superadmin": http.StatusOK,
+		"admin":      http.StatusOK,
+		"editor":     http.StatusForbidden,
+		"user":       http.StatusForbidden,
+		"":           http.StatusForbidden,
+	}
+	for role, expected := range cases {
+		router := gin.New()
+		router.PUT("/users/:id/role", func(c *gin.Context) {
+			c.Set("user_id", "1")
+			c.Set("role", role)
+			c.Next()
+		}, suite.middleware.RequirePermission("roles:assign"), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest("PUT", "/users/1/role", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(suite.T(), expected, w.Code, role)
+	}
+}
+
+func (suite *MiddlewareTestSuite) TestRequirePermission_LegacyUserRole() {
+	req, _ := http.NewRequest("POST", "/blogs", nil)
+	w := httptest.NewRecorder()
+
+	suite.router.POST("/blogs", func(c *gin.Context) {
+		c.Set("user_id", "1")
+		c.Set("role", "user")
+		c.Next()
+	}, suite.middleware.RequirePermission("blogs:write"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
 }
 
 func TestMiddlewareTestSuite(t *testing.T) {
@@ -0,0 +1,220 @@
+package test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+// mockOIDCProvider is a minimal OpenID provider: discovery, JWKS and a token
+// endpoint that enforces PKCE and returns whatever claims the test sets.
+type mockOIDCProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	kid       string
+	challenge string
+	claims    jwt.MapClaims
+	jwksHits  int
+}
+
+func newMockOIDCProvider(key *rsa.PrivateKey) *mockOIDCProvider {
+	p := &mockOIDCProvider{key: key, kid: "key-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.jwksHits++
+		public := &p.key.PublicKey
+		json.NewEncoder(w).Encode(domain.JSONWebKeySet{Keys: []domain.JSONWebKey{{
+			KeyID:     p.kid,
+			KeyType:   "RSA",
+			Algorithm: "RS256",
+			Use:       "sig",
+			N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString([]byte{1, 0, 1}),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		clientID, secret, _ := r.BasicAuth()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "good-code" || clientID != "client-1" || secret != "s3cret" ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims)
+		token.Header["kid"] = p.kid
+		signed, _ := token.SignedString(p.key)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": signed})
+	})
+
+	p.server = httptest.NewServer(mux)
+	return p
+}
+
+type OIDCServiceTestSuite struct {
+	suite.Suite
+	key      *rsa.PrivateKey
+	provider *mockOIDCProvider
+	service  *infrastructure.OIDCInfrastructure
+	now      time.Time
+}
+
+func (suite *OIDCServiceTestSuite) SetupSuite() {
+	var err error
+	suite.key, err = rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+}
+
+func (suite *OIDCServiceTestSuite) SetupTest() {
+	suite.now = time.Now()
+	suite.provider = newMockOIDCProvider(suite.key)
+	suite.provider.claims = jwt.MapClaims{
+		"iss":            suite.provider.server.URL,
+		"aud":            "client-1",
+		"sub":            "external-42",
+		"iat":            suite.now.Unix(),
+		"exp":            suite.now.Add(5 * time.Minute).Unix(),
+		"nonce":          "nonce-1",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+	}
+	suite.service = infrastructure.NewOIDCInfrastructure(infrastructure.OIDCProviderConfig{
+		Name:         "mock",
+		Issuer:       suite.provider.server.URL,
+		ClientID:     "client-1",
+		ClientSecret: "s3cret",
+		RedirectURL:  "http://localhost:8080/oidc/mock/callback",
+	})
+	suite.service.Now = func() time.Time { return suite.now }
+}
+
+func (suite *OIDCServiceTestSuite) TearDownTest() {
+	suite.provider.server.Close()
+}
+
+// begin runs AuthCodeURL the way the usecase does and hands the challenge to
+// the provider, as a browser redirect would.
+func (suite *OIDCServiceTestSuite) begin(verifier string) url.Values {
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	authURL, err := suite.service.AuthCodeURL("mock", "state-1", "nonce-1", challenge)
+	suite.Require().NoError(err)
+	parsed, err := url.Parse(authURL)
+	suite.Require().NoError(err)
+
+	suite.provider.challenge = parsed.Query().Get("code_challenge")
+	return parsed.Query()
+}
+
+func (suite *OIDCServiceTestSuite) TestAuthCodeURL_Parameters() {
+	query := suite.begin("verifier-1")
+
+	suite.Equal("code", query.Get("response_type"))
+	suite.Equal("client-1", query.Get("client_id"))
+	suite.Equal("state-1", query.Get("state"))
+	suite.Equal("nonce-1", query.Get("nonce"))
+	suite.Equal("S256", query.Get("code_challenge_method"))
+	suite.Equal("openid email profile", query.Get("scope"))
+	suite.Equal("http://localhost:8080/oidc/mock/callback", query.Get("redirect_uri"))
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_Success() {
+	suite.begin("verifier-1")
+
+	identity, err := suite.service.Exchange("mock", "good-code", "verifier-1", "nonce-1")
+	suite.Require().NoError(err)
+	suite.Equal("mock", identity.Provider)
+	suite.Equal("external-42", identity.Subject)
+	suite.Equal("jane@example.com", identity.Email)
+	suite.True(identity.EmailVerified)
+	suite.Equal("Jane Doe", identity.Name)
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_WrongVerifier() {
+	suite.begin("verifier-1")
+
+	_, err := suite.service.Exchange("mock", "good-code", "someone-elses-verifier", "nonce-1")
+	suite.Error(err)
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_NonceMismatch() {
+	suite.begin("verifier-1")
+
+	_, err := suite.service.Exchange("mock", "good-code", "verifier-1", "nonce-2")
+	suite.ErrorContains(err, "nonce")
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_WrongAudience() {
+	suite.begin("verifier-1")
+	suite.provider.claims["aud"] = "another-client"
+
+	_, err := suite.service.Exchange("mock", "good-code", "verifier-1", "nonce-1")
+	suite.Error(err)
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_Expired() {
+	suite.begin("verifier-1")
+	suite.provider.claims["exp"] = suite.now.Add(-10 * time.Minute).Unix()
+
+	_, err := suite.service.Exchange("mock", "good-code", "verifier-1", "nonce-1")
+	suite.Error(err)
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_UnverifiedEmailFlagged() {
+	suite.begin("verifier-1")
+	suite.provider.claims["email_verified"] = false
+
+	identity, err := suite.service.Exchange("mock", "good-code", "verifier-1", "nonce-1")
+	suite.NoError(err)
+	suite.False(identity.EmailVerified)
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_ProviderKeyRotation() {
+	suite.begin("verifier-1")
+	_, err := suite.service.Exchange("mock", "good-code", "verifier-1", "nonce-1")
+	suite.Require().NoError(err)
+
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+	suite.provider.key = rotated
+	suite.provider.kid = "key-2"
+	suite.now = suite.now.Add(2 * time.Minute)
+
+	_, err = suite.service.Exchange("mock", "good-code", "verifier-1", "nonce-1")
+	suite.NoError(err)
+	suite.Equal(2, suite.provider.jwksHits)
+}
+
+func (suite *OIDCServiceTestSuite) TestExchange_UnknownProvider() {
+	_, err := suite.service.Exchange("nope", "good-code", "verifier-1", "nonce-1")
+	suite.EqualError(err, "unknown identity provider")
+}
+
+func TestOIDCServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OIDCServiceTestSuite))
+}
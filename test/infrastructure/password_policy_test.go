@@ -0,0 +1,133 @@
+package test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type failingBreachSource struct{}
+
+func (failingBreachSource) Range(prefix string) ([]string, error) {
+	return nil, errors.New("unavailable")
+}
+
+type PasswordPolicyTestSuite struct {
+	suite.Suite
+	policy *infrastructure.PasswordPolicy
+}
+
+func (suite *PasswordPolicyTestSuite) SetupTest() {
+	policy, err := infrastructure.NewPasswordPolicy(infrastructure.DefaultPasswordPolicyConfig())
+	suite.Require().NoError(err)
+	suite.policy = policy
+}
+
+func failedRules(feedback domain.PasswordFeedback) []string {
+	var rules []string
+	for _, rule := range feedback.Rules {
+		if !rule.Passed {
+			rules = append(rules, rule.Rule)
+		}
+	}
+	return rules
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck_StrongPassword() {
+	feedback := suite.policy.Check("Zx8!vLq2#Rt", domain.PasswordContext{Username: "alice", Email: "alice@example.com"})
+	suite.True(feedback.Valid)
+	suite.Equal(4, feedback.Score)
+	suite.Empty(failedRules(feedback))
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck_BundledBreachedPassword() {
+	feedback := suite.policy.Check("P@ssw0rd!", domain.PasswordContext{})
+	suite.False(feedback.Valid)
+	suite.Contains(failedRules(feedback), domain.PasswordRuleBreached)
+	suite.Contains(failedRules(feedback), domain.PasswordRuleStrength)
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck_PenalisesUserContext() {
+	context := domain.PasswordContext{Username: "wonderland_alice", Email: "alice.liddell@example.com"}
+	feedback := suite.policy.Check("Liddell#Wonderland7", context)
+	suite.False(feedback.Valid)
+	suite.Equal([]string{domain.PasswordRuleStrength}, failedRules(feedback))
+	suite.Contains(feedback.Suggestions, "avoid using your username or email address")
+
+	suite.True(suite.policy.Check("Liddell#Wonderland7", domain.PasswordContext{}).Valid)
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck_PatternsScoreLow() {
+	for _, password := range []string{"Abcdefg1!", "Aaaaaaaa1!", "Qwertyui9!", "Summer1999!"} {
+		feedback := suite.policy.Check(password, domain.PasswordContext{})
+		suite.Less(feedback.Score, 3, password)
+		suite.NotEmpty(feedback.Suggestions, password)
+	}
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck_LengthAndComposition() {
+	feedback := suite.policy.Check("short", domain.PasswordContext{})
+	suite.Contains(failedRules(feedback), domain.PasswordRuleLength)
+	suite.Contains(failedRules(feedback), domain.PasswordRuleComposition)
+
+	config := infrastructure.DefaultPasswordPolicyConfig()
+	config.RequireClasses = false
+	relaxed, err := infrastructure.NewPasswordPolicy(config)
+	suite.Require().NoError(err)
+	suite.True(relaxed.Check("purple tractor mango lighthouse", domain.PasswordContext{}).Valid)
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck_BreachListFile() {
+	sum := sha1.Sum([]byte("Zx8!vLq2#Rt"))
+	path := filepath.Join(suite.T().TempDir(), "pwned.txt")
+	suite.Require().NoError(os.WriteFile(path, []byte(hex.EncodeToString(sum[:])+":42\n"), 0600))
+
+	config := infrastructure.DefaultPasswordPolicyConfig()
+	config.BreachListFile = path
+	policy, err := infrastructure.NewPasswordPolicy(config)
+	suite.Require().NoError(err)
+
+	suite.Equal([]string{domain.PasswordRuleBreached}, failedRules(policy.Check("Zx8!vLq2#Rt", domain.PasswordContext{})))
+}
+
+func (suite *PasswordPolicyTestSuite) TestCheck_BreachLookupFailureIsIgnored() {
+	suite.policy.Breaches = failingBreachSource{}
+	suite.True(suite.policy.Check("Zx8!vLq2#Rt", domain.PasswordContext{}).Valid)
+}
+
+func (suite *PasswordPolicyTestSuite) TestBreachList_RangeOnlyReturnsSuffixes() {
+	list := infrastructure.NewBreachList()
+	list.Add("hunter2")
+	sum := sha1.Sum([]byte("hunter2"))
+	hash := hex.EncodeToString(sum[:])
+
+	suffixes, err := list.Range(hash[:5])
+	suite.NoError(err)
+	suite.Contains(suffixes, "F3BBBD66A63D4BF1747940578EC3D0103530E21D"[5:])
+}
+
+func (suite *PasswordPolicyTestSuite) TestLoadPasswordPolicyConfigFromEnv() {
+	suite.T().Setenv("PASSWORD_MIN_SCORE", "2")
+	suite.T().Setenv("PASSWORD_HISTORY", "0")
+	suite.T().Setenv("PASSWORD_REQUIRE_CLASSES", "false")
+	config, err := infrastructure.LoadPasswordPolicyConfigFromEnv()
+	suite.NoError(err)
+	suite.Equal(2, config.MinScore)
+	suite.Equal(0, config.HistoryDepth)
+	suite.False(config.RequireClasses)
+
+	suite.T().Setenv("PASSWORD_MIN_SCORE", "5")
+	_, err = infrastructure.LoadPasswordPolicyConfigFromEnv()
+	suite.EqualError(err, "invalid value for PASSWORD_MIN_SCORE")
+}
+
+func TestPasswordPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordPolicyTestSuite))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"github.com/blog-platform/infrastructure"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type PasswordServiceTestSuite struct {
@@ -76,6 +77,54 @@ func (suite *PasswordServiceTestSuite) TestHash_EdgeCases() {
 	suite.NotEmpty(hSpecial)
 }
 
+func (suite *PasswordServiceTestSuite) TestHash_Argon2idPHCFormat() {
+	hash, err := suite.service.HashPassword("secret123")
+	suite.NoError(err)
+	suite.True(strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$"))
+	suite.False(suite.service.NeedsRehash(hash))
+}
+
+func (suite *PasswordServiceTestSuite) TestCompare_LegacyBcrypt() {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	suite.NoError(err)
+
+	suite.NoError(suite.service.ComparePassword(legacy, []byte("secret123")))
+	suite.Error(suite.service.ComparePassword(legacy, []byte("secret124")))
+	suite.True(suite.service.NeedsRehash(string(legacy)))
+}
+
+func (suite *PasswordServiceTestSuite) TestCompare_NoTruncationPast72Bytes() {
+	prefix := strings.Repeat("x", 72)
+	hash, err := suite.service.HashPassword(prefix + "a")
+	suite.NoError(err)
+
+	suite.NoError(suite.service.ComparePassword([]byte(hash), []byte(prefix+"a")))
+	suite.Error(suite.service.ComparePassword([]byte(hash), []byte(prefix+"b")))
+}
+
+func (suite *PasswordServiceTestSuite) TestNeedsRehash_OutdatedParameters() {
+	weak := &infrastructure.PasswordInfrastructure{Config: infrastructure.PasswordHashConfig{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}}
+	hash, err := weak.HashPassword("secret123")
+	suite.NoError(err)
+
+	suite.NoError(suite.service.ComparePassword([]byte(hash), []byte("secret123")))
+	suite.True(suite.service.NeedsRehash(hash))
+	suite.False(weak.NeedsRehash(hash))
+}
+
+func (suite *PasswordServiceTestSuite) TestLoadPasswordHashConfigFromEnv() {
+	suite.T().Setenv("PASSWORD_ARGON2_MEMORY", "65536")
+	suite.T().Setenv("PASSWORD_ARGON2_ITERATIONS", "3")
+	config, err := infrastructure.LoadPasswordHashConfigFromEnv()
+	suite.NoError(err)
+	suite.Equal(uint32(65536), config.Memory)
+	suite.Equal(uint32(3), config.Iterations)
+
+	suite.T().Setenv("PASSWORD_ARGON2_PARALLELISM", "0")
+	_, err = infrastructure.LoadPasswordHashConfigFromEnv()
+	suite.EqualError(err, "invalid value for PASSWORD_ARGON2_PARALLELISM")
+}
+
 func TestPasswordServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(PasswordServiceTestSuite))
 }
\ No newline at end of file
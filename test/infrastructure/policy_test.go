@@ -0,0 +1,119 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/blog-platform/test/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+// PolicyTestSuite drives the policies through AuthMiddleware and Authorize
+// the same way the routers mount them.
+type PolicyTestSuite struct {
+	suite.Suite
+	jwtService *mocks.MockJWTService
+	owners     *mocks.MockOwnershipRepository
+	router     *gin.Engine
+}
+
+func (s *PolicyTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.jwtService = new(mocks.MockJWTService)
+	s.owners = new(mocks.MockOwnershipRepository)
+	middleware := infrastructure.NewMiddleware(s.jwtService)
+
+	s.router = gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	s.router.GET("/users/:id", middleware.AuthMiddleware(), middleware.Authorize(infrastructure.UserPolicy(domain.PermissionUsersRead)), ok)
+	s.router.PATCH("/users/:id", middleware.AuthMiddleware(), middleware.AccountOwnerMiddleware(), ok)
+	s.router.PATCH("/blogs/:id", middleware.AuthMiddleware(), middleware.Authorize(infrastructure.BlogPolicy(s.owners, domain.PermissionBlogsEditAny)), ok)
+	s.router.DELETE("/comments/:id", middleware.AuthMiddleware(), middleware.Authorize(infrastructure.CommentPolicy(s.owners, domain.PermissionCommentsModerate)), ok)
+	// mounted without AuthMiddleware on purpose
+	s.router.GET("/unguarded/:id", middleware.AccountOwnerMiddleware(), ok)
+}
+
+func (s *PolicyTestSuite) as(userID string, role string) string {
+	header := "Bearer " + userID
+	s.jwtService.On("ValidateAccessToken", header).Return(&domain.TokenClaims{UserID: userID, UserRole: role, TokenType: "access"}, nil)
+	return header
+}
+
+func (s *PolicyTestSuite) serve(method string, path string, authHeader string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *PolicyTestSuite) TestMissingToken_Unauthorized() {
+	s.jwtService.On("ValidateAccessToken", "").Return(nil, errors.New("missing token"))
+
+	w := s.serve("GET", "/users/1", "")
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *PolicyTestSuite) TestWithoutAuthMiddleware_Unauthorized() {
+	w := s.serve("GET", "/unguarded/1", "")
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.JSONEq(`{"error":"authentication required"}`, w.Body.String())
+}
+
+func (s *PolicyTestSuite) TestUser_Owner() {
+	s.Equal(http.StatusOK, s.serve("PATCH", "/users/1", s.as("1", domain.RoleAuthor)).Code)
+}
+
+func (s *PolicyTestSuite) TestUser_OtherUserForbidden() {
+	w := s.serve("GET", "/users/2", s.as("1", domain.RoleAuthor))
+	s.Equal(http.StatusForbidden, w.Code)
+	s.JSONEq(`{"error":"unauthorized to access this route"}`, w.Body.String())
+}
+
+func (s *PolicyTestSuite) TestUser_AdminBypassOnlyWhereGranted() {
+	admin := s.as("9", domain.RoleAdmin)
+	s.Equal(http.StatusOK, s.serve("GET", "/users/2", admin).Code)
+	s.Equal(http.StatusForbidden, s.serve("PATCH", "/users/2", admin).Code)
+}
+
+func (s *PolicyTestSuite) TestBlog_Author() {
+	s.owners.On("BlogOwner", int64(5)).Return(int64(1), nil)
+	s.Equal(http.StatusOK, s.serve("PATCH", "/blogs/5", s.as("1", domain.RoleAuthor)).Code)
+}
+
+func (s *PolicyTestSuite) TestBlog_OtherAuthorForbidden() {
+	s.owners.On("BlogOwner", int64(5)).Return(int64(2), nil)
+	s.Equal(http.StatusForbidden, s.serve("PATCH", "/blogs/5", s.as("1", domain.RoleAuthor)).Code)
+}
+
+func (s *PolicyTestSuite) TestBlog_EditorBypass() {
+	s.owners.On("BlogOwner", int64(5)).Return(int64(2), nil)
+	s.Equal(http.StatusOK, s.serve("PATCH", "/blogs/5", s.as("3", domain.RoleEditor)).Code)
+}
+
+func (s *PolicyTestSuite) TestBlog_NotFound() {
+	s.owners.On("BlogOwner", int64(5)).Return(int64(0), domain.ErrResourceNotFound)
+	s.Equal(http.StatusNotFound, s.serve("PATCH", "/blogs/5", s.as("1", domain.RoleAuthor)).Code)
+}
+
+func (s *PolicyTestSuite) TestBlog_LookupFailure() {
+	s.owners.On("BlogOwner", int64(5)).Return(int64(0), errors.New("connection reset"))
+	s.Equal(http.StatusInternalServerError, s.serve("PATCH", "/blogs/5", s.as("1", domain.RoleEditor)).Code)
+}
+
+func (s *PolicyTestSuite) TestComment_ModeratorBypass() {
+	s.owners.On("CommentOwner", int64(8)).Return(int64(2), nil)
+	s.Equal(http.StatusOK, s.serve("DELETE", "/comments/8", s.as("4", domain.RoleModerator)).Code)
+	s.Equal(http.StatusForbidden, s.serve("DELETE", "/comments/8", s.as("3", domain.RoleEditor)).Code)
+}
+
+func TestPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PolicyTestSuite))
+}
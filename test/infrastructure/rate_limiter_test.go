@@ -0,0 +1,64 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimiterTestSuite struct {
+	suite.Suite
+	now     time.Time
+	limiter *infrastructure.RateLimiter
+}
+
+func (suite *RateLimiterTestSuite) SetupTest() {
+	suite.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	suite.limiter = infrastructure.NewRateLimiter(infrastructure.NewInMemoryLoginAttemptStore(), "test:", 3, 15*time.Minute)
+	suite.limiter.Now = func() time.Time { return suite.now }
+}
+
+func (suite *RateLimiterTestSuite) TestAllow_UpToLimitPerWindow() {
+	for i := 0; i < 3; i++ {
+		wait, err := suite.limiter.Allow("jane@example.com")
+		suite.Require().NoError(err)
+		suite.Zero(wait, i)
+		suite.now = suite.now.Add(time.Minute)
+	}
+
+	wait, err := suite.limiter.Allow("jane@example.com")
+	suite.NoError(err)
+	suite.Equal(12*time.Minute, wait)
+
+	wait, _ = suite.limiter.Allow("john@example.com")
+	suite.Zero(wait)
+}
+
+func (suite *RateLimiterTestSuite) TestAllow_NewWindowStartsOver() {
+	for i := 0; i < 4; i++ {
+		suite.limiter.Allow("jane@example.com")
+	}
+
+	suite.now = suite.now.Add(15 * time.Minute)
+	wait, err := suite.limiter.Allow("jane@example.com")
+	suite.NoError(err)
+	suite.Zero(wait)
+}
+
+func (suite *RateLimiterTestSuite) TestAllow_PrefixSeparatesLimiters() {
+	store := infrastructure.NewInMemoryLoginAttemptStore()
+	first := infrastructure.NewRateLimiter(store, "a:", 1, time.Hour)
+	second := infrastructure.NewRateLimiter(store, "b:", 1, time.Hour)
+
+	first.Allow("key")
+	wait, _ := second.Allow("key")
+	suite.Zero(wait)
+	wait, _ = first.Allow("key")
+	suite.Positive(wait)
+}
+
+func TestRateLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterTestSuite))
+}
@@ -0,0 +1,42 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenDenylistTestSuite struct {
+	suite.Suite
+	denylist *infrastructure.InMemoryTokenDenylist
+}
+
+func (suite *TokenDenylistTestSuite) SetupTest() {
+	suite.denylist = infrastructure.NewInMemoryTokenDenylist()
+}
+
+func (suite *TokenDenylistTestSuite) TestRevokeAndCheck() {
+	suite.NoError(suite.denylist.Revoke("jti-1", time.Now().Add(time.Hour)))
+
+	revoked, err := suite.denylist.IsRevoked("jti-1")
+	suite.NoError(err)
+	suite.True(revoked)
+
+	revoked, err = suite.denylist.IsRevoked("jti-2")
+	suite.NoError(err)
+	suite.False(revoked)
+}
+
+func (suite *TokenDenylistTestSuite) TestExpiredEntriesAreForgotten() {
+	suite.NoError(suite.denylist.Revoke("jti-1", time.Now().Add(-time.Second)))
+
+	revoked, err := suite.denylist.IsRevoked("jti-1")
+	suite.NoError(err)
+	suite.False(revoked)
+}
+
+func TestTokenDenylistTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenDenylistTestSuite))
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/infrastructure"
+	"github.com/stretchr/testify/suite"
+)
+
+// base32 of the RFC 6238 SHA1 test key "12345678901234567890"
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+type TOTPServiceTestSuite struct {
+	suite.Suite
+	service *infrastructure.TOTPInfrastructure
+	now     time.Time
+}
+
+func (suite *TOTPServiceTestSuite) SetupTest() {
+	suite.now = time.Unix(1111111109, 0)
+	suite.service = infrastructure.NewTOTPInfrastructure("Blog Platform")
+	suite.service.Now = func() time.Time { return suite.now }
+}
+
+func (suite *TOTPServiceTestSuite) TestGenerateCode_RFC6238Vectors() {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := suite.service.GenerateCode(rfcTOTPSecret, time.Unix(unix, 0))
+		suite.NoError(err)
+		suite.Equal(expected, code, unix)
+	}
+}
+
+func (suite *TOTPServiceTestSuite) TestVerify_AcceptsAdjacentSteps() {
+	previous, _ := suite.service.GenerateCode(rfcTOTPSecret, suite.now.Add(-30*time.Second))
+
+	step, err := suite.service.Verify(rfcTOTPSecret, previous, 0)
+	suite.NoError(err)
+	suite.Equal(suite.now.Unix()/30-1, step)
+}
+
+func (suite *TOTPServiceTestSuite) TestVerify_RejectsDistantSteps() {
+	old, _ := suite.service.GenerateCode(rfcTOTPSecret, suite.now.Add(-2*time.Minute))
+
+	_, err := suite.service.Verify(rfcTOTPSecret, old, 0)
+	suite.EqualError(err, "invalid code")
+}
+
+func (suite *TOTPServiceTestSuite) TestVerify_RejectsReplay() {
+	code, _ := suite.service.GenerateCode(rfcTOTPSecret, suite.now)
+
+	step, err := suite.service.Verify(rfcTOTPSecret, code, 0)
+	suite.Require().NoError(err)
+
+	_, err = suite.service.Verify(rfcTOTPSecret, code, step)
+	suite.EqualError(err, "invalid code")
+}
+
+func (suite *TOTPServiceTestSuite) TestVerify_InvalidInput() {
+	_, err := suite.service.Verify(rfcTOTPSecret, "12345", 0)
+	suite.Error(err)
+
+	_, err = suite.service.Verify("not base32!", "123456", 0)
+	suite.EqualError(err, "invalid secret")
+}
+
+func (suite *TOTPServiceTestSuite) TestGenerateSecret_RoundTrip() {
+	secret, err := suite.service.GenerateSecret()
+	suite.Require().NoError(err)
+	suite.Len(secret, 32)
+
+	code, err := suite.service.GenerateCode(secret, suite.now)
+	suite.Require().NoError(err)
+	_, err = suite.service.Verify(secret, code, 0)
+	suite.NoError(err)
+}
+
+func (suite *TOTPServiceTestSuite) TestProvisioningURI() {
+	uri, err := url.Parse(suite.service.ProvisioningURI(rfcTOTPSecret, "jane@example.com"))
+	suite.Require().NoError(err)
+
+	suite.Equal("otpauth", uri.Scheme)
+	suite.Equal("totp", uri.Host)
+	suite.Equal("/Blog Platform:jane@example.com", uri.Path)
+	suite.Equal(rfcTOTPSecret, uri.Query().Get("secret"))
+	suite.Equal("Blog Platform", uri.Query().Get("issuer"))
+	suite.Equal("6", uri.Query().Get("digits"))
+	suite.Equal("30", uri.Query().Get("period"))
+}
+
+func TestTOTPServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TOTPServiceTestSuite))
+}
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) CollectPersonalData(userID int64) (domain.PersonalData, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.PersonalData), args.Error(1)
+}
+
+func (m *MockAccountRepository) CreateExport(export *domain.DataExport) error {
+	args := m.Called(export)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) SaveExport(export *domain.DataExport) error {
+	args := m.Called(export)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) LatestExport(userID int64) (domain.DataExport, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.DataExport), args.Error(1)
+}
+
+func (m *MockAccountRepository) ClaimExports(now time.Time, lease time.Duration, limit int) ([]domain.DataExport, error) {
+	args := m.Called(now, lease, limit)
+	exports, _ := args.Get(0).([]domain.DataExport)
+	return exports, args.Error(1)
+}
+
+func (m *MockAccountRepository) PurgeExpiredExports(now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAccountRepository) FetchExportByTokenHash(tokenHash string) (domain.DataExport, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(domain.DataExport), args.Error(1)
+}
+
+func (m *MockAccountRepository) ScheduleDeletion(deletion *domain.AccountDeletion) error {
+	args := m.Called(deletion)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) CancelDeletion(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) FetchDueDeletions(now time.Time) ([]domain.AccountDeletion, error) {
+	args := m.Called(now)
+	deletions, _ := args.Get(0).([]domain.AccountDeletion)
+	return deletions, args.Error(1)
+}
+
+func (m *MockAccountRepository) RevokeSessions(userID int64, at time.Time) ([]domain.Token, error) {
+	args := m.Called(userID, at)
+	tokens, _ := args.Get(0).([]domain.Token)
+	return tokens, args.Error(1)
+}
+
+func (m *MockAccountRepository) AnonymizeUser(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) PurgeUser(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
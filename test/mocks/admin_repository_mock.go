@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAdminRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepository) SearchUsers(filter domain.UserFilter) ([]domain.User, int64, error) {
+	args := m.Called(filter)
+	users, _ := args.Get(0).([]domain.User)
+	return users, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAdminRepository) FetchSessions(userID int64, limit int) ([]domain.Token, error) {
+	args := m.Called(userID, limit)
+	tokens, _ := args.Get(0).([]domain.Token)
+	return tokens, args.Error(1)
+}
+
+func (m *MockAdminRepository) RecentPosts(userID int64, limit int) ([]domain.Blog, error) {
+	args := m.Called(userID, limit)
+	blogs, _ := args.Get(0).([]domain.Blog)
+	return blogs, args.Error(1)
+}
+
+func (m *MockAdminRepository) RecentComments(userID int64, limit int) ([]domain.Comment, error) {
+	args := m.Called(userID, limit)
+	comments, _ := args.Get(0).([]domain.Comment)
+	return comments, args.Error(1)
+}
+
+func (m *MockAdminRepository) SetStatus(userID int64, status string, suspension domain.Suspension) error {
+	args := m.Called(userID, status, suspension)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepository) LiftExpiredSuspensions(now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAdminRepository) ClearPassword(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepository) ClearEmailStatus(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAIInfrastructure struct {
+	mock.Mock
+}
+
+func (m *MockAIInfrastructure) Complete(ctx context.Context, request domain.AIRequest) (string, error) {
+	args := m.Called(ctx, request)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(event *domain.AuditEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) FetchByUser(userID int64, limit int) ([]domain.AuditEvent, error) {
+	args := m.Called(userID, limit)
+	events, _ := args.Get(0).([]domain.AuditEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAuditRepository) Search(filter domain.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	args := m.Called(filter)
+	events, _ := args.Get(0).([]domain.AuditEvent)
+	return events, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAuditRepository) FetchAfter(id int64, limit int) ([]domain.AuditEvent, error) {
+	args := m.Called(id, limit)
+	events, _ := args.Get(0).([]domain.AuditEvent)
+	return events, args.Error(1)
+}
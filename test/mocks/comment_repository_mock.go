@@ -0,0 +1,15 @@
+package mocks
+
+import (
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) Create(comment *domain.Comment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
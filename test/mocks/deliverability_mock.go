@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeliverabilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeliverabilityRepository) Suppressed(addresses []string) ([]string, error) {
+	args := m.Called(addresses)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDeliverabilityRepository) MarkAddress(email string, status string, reason string, at time.Time) (int64, error) {
+	args := m.Called(email, status, reason, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockBounceParser struct {
+	mock.Mock
+}
+
+func (m *MockBounceParser) Parse(source string, payload []byte) ([]domain.BounceEvent, error) {
+	args := m.Called(source, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BounceEvent), args.Error(1)
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmailChangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailChangeRepository) Save(request *domain.EmailChangeRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockEmailChangeRepository) Consume(tokenHash string) (domain.EmailChangeRequest, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(domain.EmailChangeRequest), args.Error(1)
+}
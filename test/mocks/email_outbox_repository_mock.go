@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmailOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailOutboxRepository) Enqueue(email *domain.OutboxEmail) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) Claim(now time.Time, lease time.Duration, limit int) ([]domain.OutboxEmail, error) {
+	args := m.Called(now, lease, limit)
+	emails, _ := args.Get(0).([]domain.OutboxEmail)
+	return emails, args.Error(1)
+}
+
+func (m *MockEmailOutboxRepository) MarkSent(id int64, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) MarkFailed(id int64, lastError string, nextAttemptAt time.Time, dead bool) error {
+	args := m.Called(id, lastError, nextAttemptAt, dead)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) Search(filter domain.OutboxFilter) ([]domain.OutboxEmail, int64, error) {
+	args := m.Called(filter)
+	emails, _ := args.Get(0).([]domain.OutboxEmail)
+	return emails, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockEmailOutboxRepository) Fetch(id int64) (domain.OutboxEmail, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.OutboxEmail), args.Error(1)
+}
+
+func (m *MockEmailOutboxRepository) Retry(id int64, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockEmailOutboxRepository) PurgeSent(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
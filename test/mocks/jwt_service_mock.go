@@ -34,3 +34,49 @@ func (m *MockJWTService) ValidateRefreshToken(authHeader string) (*domain.TokenC
 	}
 	return args.Get(0).(*domain.TokenClaims), args.Error(1)
 }
+
+func (m *MockJWTService) JWKS() domain.JSONWebKeySet {
+	args := m.Called()
+	return args.Get(0).(domain.JSONWebKeySet)
+}
+
+func (m *MockJWTService) RevokeToken(claims *domain.TokenClaims) error {
+	args := m.Called(claims)
+	return args.Error(0)
+}
+
+func (m *MockJWTService) ConsumeToken(claims *domain.TokenClaims) error {
+	args := m.Called(claims)
+	return args.Error(0)
+}
+
+func (m *MockJWTService) GenerateChallengeToken(userID string, userRole string) (string, error) {
+	args := m.Called(userID, userRole)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJWTService) ValidateChallengeToken(token string) (*domain.TokenClaims, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenClaims), args.Error(1)
+}
+
+func (m *MockJWTService) GenerateMagicLinkToken(userID string, userRole string) (string, error) {
+	args := m.Called(userID, userRole)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJWTService) ValidateMagicLinkToken(token string) (*domain.TokenClaims, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenClaims), args.Error(1)
+}
+
+func (m *MockJWTService) GenerateImpersonationToken(userID string, userRole string, impersonatorID string) (string, error) {
+	args := m.Called(userID, userRole, impersonatorID)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoginThrottler struct {
+	mock.Mock
+}
+
+func (m *MockLoginThrottler) Check(account string, clientIP string) (time.Duration, error) {
+	args := m.Called(account, clientIP)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockLoginThrottler) RegisterFailure(account string, clientIP string) (bool, error) {
+	args := m.Called(account, clientIP)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLoginThrottler) RegisterSuccess(account string) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottler) Unlock(account string) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(notification *domain.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) Search(filter domain.NotificationFilter) ([]domain.Notification, int64, error) {
+	args := m.Called(filter)
+	notifications, _ := args.Get(0).([]domain.Notification)
+	return notifications, args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockNotificationRepository) CountUnread(userID int64) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(userID int64, id int64, at time.Time) error {
+	args := m.Called(userID, id, at)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(userID int64, at time.Time) (int64, error) {
+	args := m.Called(userID, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) FetchPreferences(userID int64) ([]domain.NotificationPreference, error) {
+	args := m.Called(userID)
+	preferences, _ := args.Get(0).([]domain.NotificationPreference)
+	return preferences, args.Error(1)
+}
+
+func (m *MockNotificationRepository) SavePreferences(preferences []domain.NotificationPreference) error {
+	args := m.Called(preferences)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) FetchPendingDigests() ([]domain.Notification, error) {
+	args := m.Called()
+	notifications, _ := args.Get(0).([]domain.Notification)
+	return notifications, args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkDigested(ids []int64) error {
+	args := m.Called(ids)
+	return args.Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(event domain.NotificationEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
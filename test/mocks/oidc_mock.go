@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOIDCService struct {
+	mock.Mock
+}
+
+func (m *MockOIDCService) AuthCodeURL(provider string, state string, nonce string, codeChallenge string) (string, error) {
+	args := m.Called(provider, state, nonce, codeChallenge)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOIDCService) Exchange(provider string, code string, codeVerifier string, nonce string) (domain.OIDCIdentity, error) {
+	args := m.Called(provider, code, codeVerifier, nonce)
+	return args.Get(0).(domain.OIDCIdentity), args.Error(1)
+}
+
+type MockOIDCRepository struct {
+	mock.Mock
+}
+
+func (m *MockOIDCRepository) SaveAuthRequest(request *domain.OIDCAuthRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockOIDCRepository) ConsumeAuthRequest(state string) (domain.OIDCAuthRequest, error) {
+	args := m.Called(state)
+	return args.Get(0).(domain.OIDCAuthRequest), args.Error(1)
+}
+
+func (m *MockOIDCRepository) FetchIdentity(provider string, subject string) (domain.LinkedIdentity, error) {
+	args := m.Called(provider, subject)
+	return args.Get(0).(domain.LinkedIdentity), args.Error(1)
+}
+
+func (m *MockOIDCRepository) FetchIdentitiesByUserID(userID int64) ([]domain.LinkedIdentity, error) {
+	args := m.Called(userID)
+	identities, _ := args.Get(0).([]domain.LinkedIdentity)
+	return identities, args.Error(1)
+}
+
+func (m *MockOIDCRepository) CreateIdentity(identity *domain.LinkedIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+func (m *MockOIDCRepository) DeleteIdentity(userID int64, id int64) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOwnershipRepository struct {
+	mock.Mock
+}
+
+func (m *MockOwnershipRepository) BlogOwner(ctx context.Context, blogID int64) (int64, error) {
+	args := m.Called(blogID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOwnershipRepository) CommentOwner(ctx context.Context, commentID int64) (int64, error) {
+	args := m.Called(commentID)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPasswordPolicy struct {
+	mock.Mock
+}
+
+func (m *MockPasswordPolicy) Check(password string, pc domain.PasswordContext) domain.PasswordFeedback {
+	args := m.Called(password, pc)
+	return args.Get(0).(domain.PasswordFeedback)
+}
+
+type MockPasswordHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordHistoryRepository) Recent(userID int64, limit int) ([]domain.PasswordHistory, error) {
+	args := m.Called(userID, limit)
+	return args.Get(0).([]domain.PasswordHistory), args.Error(1)
+}
+
+func (m *MockPasswordHistoryRepository) Add(entry *domain.PasswordHistory, keep int) error {
+	args := m.Called(entry, keep)
+	return args.Error(0)
+}
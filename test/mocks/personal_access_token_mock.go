@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPersonalAccessTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockPersonalAccessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockPersonalAccessTokenRepository) FetchByHash(hash string) (domain.PersonalAccessToken, error) {
+	args := m.Called(hash)
+	return args.Get(0).(domain.PersonalAccessToken), args.Error(1)
+}
+
+func (m *MockPersonalAccessTokenRepository) FetchByUserID(userID int64) ([]domain.PersonalAccessToken, error) {
+	args := m.Called(userID)
+	tokens, _ := args.Get(0).([]domain.PersonalAccessToken)
+	return tokens, args.Error(1)
+}
+
+func (m *MockPersonalAccessTokenRepository) Revoke(userID int64, id int64, at time.Time) error {
+	args := m.Called(userID, id, at)
+	return args.Error(0)
+}
+
+func (m *MockPersonalAccessTokenRepository) TouchLastUsed(id int64, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+type MockPersonalAccessTokenAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockPersonalAccessTokenAuthenticator) Authenticate(token string) (*domain.TokenClaims, error) {
+	args := m.Called(token)
+	claims, _ := args.Get(0).(*domain.TokenClaims)
+	return claims, args.Error(1)
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileRepository struct {
+	mock.Mock
+}
+
+func (m *MockProfileRepository) FetchPrivacy(userID int64) (domain.ProfilePrivacy, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.ProfilePrivacy), args.Error(1)
+}
+
+func (m *MockProfileRepository) SavePrivacy(privacy *domain.ProfilePrivacy) error {
+	args := m.Called(privacy)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) AuthorStats(userID int64) (domain.AuthorStats, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.AuthorStats), args.Error(1)
+}
+
+func (m *MockProfileRepository) FetchPostsByUser(userID int64, offset int, limit int) ([]domain.Blog, error) {
+	args := m.Called(userID, offset, limit)
+	blogs, _ := args.Get(0).([]domain.Blog)
+	return blogs, args.Error(1)
+}
+
+func (m *MockProfileRepository) Follow(followerID int64, followeeID int64) (bool, error) {
+	args := m.Called(followerID, followeeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProfileRepository) Unfollow(followerID int64, followeeID int64) error {
+	args := m.Called(followerID, followeeID)
+	return args.Error(0)
+}
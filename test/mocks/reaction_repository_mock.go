@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockReactionRepository) React(userID int64, blogID int64, kind string) (string, error) {
+	args := m.Called(userID, blogID, kind)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockReactionRepository) Unreact(userID int64, blogID int64) (string, error) {
+	args := m.Called(userID, blogID)
+	return args.String(0), args.Error(1)
+}
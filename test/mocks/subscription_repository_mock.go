@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionRepository) FetchByUserID(userID int64) (domain.Subscription, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) FetchByEmail(email string) (domain.Subscription, error) {
+	args := m.Called(email)
+	return args.Get(0).(domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Save(subscription *domain.Subscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) Confirm(tokenHash string, at time.Time) error {
+	args := m.Called(tokenHash, at)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) Unsubscribe(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) FetchDue(before time.Time, afterID int64, limit int) ([]domain.Subscription, error) {
+	args := m.Called(before, afterID, limit)
+	subscriptions, _ := args.Get(0).([]domain.Subscription)
+	return subscriptions, args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) MarkSent(id int64, at time.Time, unsubscribeTokenHash string) error {
+	args := m.Called(id, at, unsubscribeTokenHash)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) TopPosts(userID *int64, tags []string, since time.Time, limit int) ([]domain.DigestPost, error) {
+	args := m.Called(userID, tags, since, limit)
+	posts, _ := args.Get(0).([]domain.DigestPost)
+	return posts, args.Error(1)
+}
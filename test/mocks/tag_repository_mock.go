@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) FetchByNames(ctx context.Context, names []string) ([]domain.TagUsage, error) {
+	args := m.Called(ctx, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TagUsage), args.Error(1)
+}
+
+func (m *MockTagRepository) MatchTags(ctx context.Context, words string, limit int) ([]domain.TagUsage, error) {
+	args := m.Called(ctx, words, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TagUsage), args.Error(1)
+}
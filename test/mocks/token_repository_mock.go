@@ -17,4 +17,8 @@ func (m *MockTokenRepository) FetchByContent(content string) (domain.Token, erro
 func (m *MockTokenRepository) Save(token *domain.Token) error {
 	args := m.Called(token)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+func (m *MockTokenRepository) SaveWithEmail(token *domain.Token, message domain.EmailMessage) error {
+	args := m.Called(token, message)
+	return args.Error(0)
+}
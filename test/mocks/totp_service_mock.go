@@ -0,0 +1,22 @@
+package mocks
+
+import "github.com/stretchr/testify/mock"
+
+type MockTOTPService struct {
+	mock.Mock
+}
+
+func (m *MockTOTPService) GenerateSecret() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTOTPService) ProvisioningURI(secret string, accountName string) string {
+	args := m.Called(secret, accountName)
+	return args.String(0)
+}
+
+func (m *MockTOTPService) Verify(secret string, code string, lastUsedStep int64) (int64, error) {
+	args := m.Called(secret, code, lastUsedStep)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"github.com/blog-platform/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) FetchByUserID(userID int64) (domain.TwoFactor, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.TwoFactor), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) Save(twoFactor *domain.TwoFactor) error {
+	args := m.Called(twoFactor)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Delete(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) FetchUnusedRecoveryCodes(userID int64) ([]domain.RecoveryCode, error) {
+	args := m.Called(userID)
+	codes, _ := args.Get(0).([]domain.RecoveryCode)
+	return codes, args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) MarkRecoveryCodeUsed(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
@@ -17,6 +17,14 @@ func (m *MockUserRepository) Register(user *domain.User) (domain.User, error) {
 	return args.Get(0).(domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) RegisterWithEmail(user *domain.User, message func(domain.User) domain.EmailMessage) (domain.User, error) {
+	args := m.Called(user, message)
+	if args.Get(0) == nil {
+		return domain.User{}, args.Error(1)
+	}
+	return args.Get(0).(domain.User), args.Error(1)
+}
+
 func (m *MockUserRepository) FetchByUsername(username string) (domain.User, error) {
 	args := m.Called(username)
 	if args.Get(0) == nil {
@@ -52,18 +60,18 @@ func (m *MockUserRepository) GetUserProfile(userID int64) (*domain.User, error)
 	return user, args.Error(1)
 }
 
-func (m *MockUserRepository) Promote(idStr string) error {
-	args := m.Called(idStr)
+func (m *MockUserRepository) ChangeRole(idStr string, role string) error {
+	args := m.Called(idStr, role)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) Demote(idStr string) error {
-	args := m.Called(idStr)
-  return args.Error(0)
+func (m *MockUserRepository) UpdateUserProfile(userID int64, update domain.ProfileUpdate) error {
+	args := m.Called(userID, update)
+	return args.Error(0)
 }
 
-func (m *MockUserRepository) UpdateUserProfile(userID int64, updates map[string]interface{}) error {
-	args := m.Called(userID, updates)
+func (m *MockUserRepository) UpdateEmail(userID int64, email string) error {
+	args := m.Called(userID, email)
 	return args.Error(0)
 }
 
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type AccountRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.AccountRepository
+}
+
+func (s *AccountRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewAccountRepository(gormDB)
+}
+
+func (s *AccountRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *AccountRepositoryTestSuite) TestCollectPersonalData_IncludesReactions() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+		WithArgs(int64(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "jane"))
+	for _, table := range []string{"blogs", "comments"} {
+		s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "` + table + `" WHERE user_id = $1`)).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "reactions" WHERE user_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "blog_id", "kind"}).AddRow(5, 1, 12, "like"))
+	for _, table := range []string{"tokens", "personal_access_tokens", "linked_identities"} {
+		s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "` + table + `" WHERE user_id = $1`)).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "follows" WHERE follower_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "follows" WHERE followee_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	data, err := s.repo.CollectPersonalData(1)
+	s.Require().NoError(err)
+	s.Equal([]domain.Reaction{{ID: 5, UserID: 1, BlogID: 12, Kind: domain.ReactionLike}}, data.Reactions)
+}
+
+func (s *AccountRepositoryTestSuite) TestLatestExport_None() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`FROM "data_exports" WHERE user_id = $1`)).
+		WithArgs(int64(1), 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	export, err := s.repo.LatestExport(1)
+	s.NoError(err)
+	s.Zero(export.ID)
+}
+
+func (s *AccountRepositoryTestSuite) TestClaimExports_LeasesQueuedExports() {
+	now := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "data_exports" WHERE (status = $1 OR (status = $2 AND locked_until <= $3)) AND "data_exports"."deleted_at" IS NULL ORDER BY id LIMIT $4 FOR UPDATE SKIP LOCKED`)).
+		WithArgs("pending", "building", now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).
+			AddRow(3, 1, "pending").
+			AddRow(4, 2, "building"))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "data_exports" SET "locked_until"=$1,"status"=$2,"updated_at"=$3 WHERE id IN ($4,$5) AND "data_exports"."deleted_at" IS NULL`)).
+		WithArgs(now.Add(time.Minute), "building", sqlmock.AnyArg(), int64(3), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	exports, err := s.repo.ClaimExports(now, time.Minute, 10)
+	s.NoError(err)
+	s.Len(exports, 2)
+	s.Equal("building", exports[0].Status)
+	s.Equal(now.Add(time.Minute), *exports[1].LockedUntil)
+}
+
+func (s *AccountRepositoryTestSuite) TestClaimExports_NothingQueued() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "data_exports"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectCommit()
+
+	exports, err := s.repo.ClaimExports(time.Now(), time.Minute, 10)
+	s.NoError(err)
+	s.Empty(exports)
+}
+
+func (s *AccountRepositoryTestSuite) TestPurgeExpiredExports_DropsArchives() {
+	now := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "data_exports" SET "archive"=$1,"status"=$2,"token_hash"=$3,"updated_at"=$4 WHERE (status = $5 AND expires_at < $6) AND "data_exports"."deleted_at" IS NULL`)).
+		WithArgs(nil, "expired", nil, sqlmock.AnyArg(), "ready", now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	purged, err := s.repo.PurgeExpiredExports(now)
+	s.NoError(err)
+	s.Equal(int64(2), purged)
+}
+
+func (s *AccountRepositoryTestSuite) TestCancelDeletion_NothingScheduled() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "account_deletions" WHERE user_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.ErrorIs(s.repo.CancelDeletion(1), gorm.ErrRecordNotFound)
+}
+
+func (s *AccountRepositoryTestSuite) TestRevokeSessions() {
+	at := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tokens" WHERE (user_id = $1 AND status <> $2)`)).
+		WithArgs(int64(1), "blocked").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "content", "status", "user_id"}).
+			AddRow(1, "access", "a", "active", 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tokens" SET "status"=$1`)).
+		WithArgs("blocked", sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "personal_access_tokens" SET "revoked_at"=$1`)).
+		WithArgs(at, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	tokens, err := s.repo.RevokeSessions(1, at)
+	s.NoError(err)
+	s.Len(tokens, 1)
+}
+
+func (s *AccountRepositoryTestSuite) TestAnonymizeUser_ScrubsAndRemovesAccountData() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, table := range []string{"tokens", "personal_access_tokens", "recovery_codes", "two_factors", "linked_identities", "email_change_requests", "data_exports", "profile_privacies", "account_deletions", "password_histories", "notifications", "notification_preferences", "subscriptions"} {
+		s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "` + table + `" WHERE user_id = $1`)).
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "follows" WHERE follower_id = $1 OR followee_id = $2`)).
+		WithArgs(int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "notifications" WHERE actor_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// the key the login throttler counts failures of user 1 under
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_attempts" WHERE key = $1`)).
+		WithArgs("account:1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.AnonymizeUser(1))
+}
+
+func TestAccountRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountRepositoryTestSuite))
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type AdminRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.AdminRepository
+}
+
+func (s *AdminRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewAdminRepository(gormDB)
+}
+
+func (s *AdminRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *AdminRepositoryTestSuite) TestSearchUsers_EscapesQueryAndPaginates() {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE (username ILIKE $1 OR email ILIKE $2) AND role = $3 AND created_at >= $4`)).
+		WithArgs(`%50\%%`, `%50\%%`, "admin", from).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE (username ILIKE $1 OR email ILIKE $2) AND role = $3 AND created_at >= $4 AND "users"."deleted_at" IS NULL ORDER BY id LIMIT $5 OFFSET $6`)).
+		WithArgs(`%50\%%`, `%50\%%`, "admin", from, 10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(11, "fifty"))
+
+	users, total, err := s.repo.SearchUsers(domain.UserFilter{Query: "50%", Role: "admin", CreatedFrom: &from, Page: 2, Limit: 10})
+	s.NoError(err)
+	s.Equal(int64(25), total)
+	s.Len(users, 1)
+}
+
+func (s *AdminRepositoryTestSuite) TestSetStatus_UnknownUser() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repo.SetStatus(9, "banned", domain.Suspension{Reason: "spam"})
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *AdminRepositoryTestSuite) TestLiftExpiredSuspensions() {
+	now := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "status"=$1,"suspended_until"=$2,"suspension_reason"=$3,"updated_at"=$4 WHERE (status = $5 AND suspended_until <= $6)`)).
+		WithArgs("active", nil, "", sqlmock.AnyArg(), "suspended", now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	lifted, err := s.repo.LiftExpiredSuspensions(now)
+	s.NoError(err)
+	s.Equal(int64(2), lifted)
+}
+
+func (s *AdminRepositoryTestSuite) TestClearEmailStatus() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "email_status"=$1,"email_status_at"=$2,"email_status_reason"=$3,"updated_at"=$4 WHERE id = $5`)).
+		WithArgs("", nil, "", sqlmock.AnyArg(), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "suppressed_addresses" WHERE email = (SELECT lower(email) FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL)`)).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.ClearEmailStatus(7))
+}
+
+func TestAdminRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminRepositoryTestSuite))
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type auditSinkMock struct {
+	mock.Mock
+}
+
+func (m *auditSinkMock) Write(event domain.AuditEvent) error {
+	return m.Called(event).Error(0)
+}
+
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.AuditRepository
+}
+
+func (s *AuditRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewAuditRepository(gormDB)
+}
+
+func (s *AuditRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *AuditRepositoryTestSuite) expectAppend(lastHash *string) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"hash"})
+	if lastHash != nil {
+		rows.AddRow(*lastHash)
+	}
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "hash" FROM "audit_events" ORDER BY id DESC LIMIT $1`)).
+		WithArgs(1).
+		WillReturnRows(rows)
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "audit_events"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	s.mock.ExpectCommit()
+}
+
+func (s *AuditRepositoryTestSuite) TestRecord_ChainsToPreviousEvent() {
+	last := "abc123"
+	s.expectAppend(&last)
+
+	event := &domain.AuditEvent{ActorID: 1, Action: "auth.login", Outcome: domain.AuditOutcomeSuccess, Metadata: "{}"}
+	s.NoError(s.repo.Record(event))
+	s.Equal("abc123", event.PrevHash)
+	s.Equal(event.ComputeHash(), event.Hash)
+	s.False(event.CreatedAt.IsZero())
+}
+
+func (s *AuditRepositoryTestSuite) TestRecord_FirstEventAndSink() {
+	sink := new(auditSinkMock)
+	sink.On("Write", mock.MatchedBy(func(e domain.AuditEvent) bool { return e.Action == "token.revoke" && e.Hash != "" })).Return(nil)
+	s.repo.Sink = sink
+	s.expectAppend(nil)
+
+	event := &domain.AuditEvent{Action: "token.revoke", Metadata: "{}"}
+	s.NoError(s.repo.Record(event))
+	s.Empty(event.PrevHash)
+	sink.AssertExpectations(s.T())
+}
+
+func (s *AuditRepositoryTestSuite) TestFetchAfter_IncludesSoftDeleted() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_events" WHERE id > $1 ORDER BY id LIMIT $2`)).
+		WithArgs(int64(10), 500).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+
+	events, err := s.repo.FetchAfter(10, 500)
+	s.NoError(err)
+	s.Len(events, 1)
+}
+
+func TestAuditRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuite))
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type CommentRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.CommentRepository
+}
+
+func (s *CommentRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewCommentRepository(gormDB)
+}
+
+func (s *CommentRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *CommentRepositoryTestSuite) TestCreate_LeavesUserAndPostAlone() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "comments" ("created_at","updated_at","deleted_at","content","user_id","blog_id") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "nice post", int64(1), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	s.mock.ExpectCommit()
+
+	comment := domain.Comment{UserID: 1, BlogID: 7, Content: "nice post"}
+	s.NoError(s.repo.Create(&comment))
+	s.Equal(int64(3), comment.ID)
+}
+
+func TestCommentRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentRepositoryTestSuite))
+}
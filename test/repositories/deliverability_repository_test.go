@@ -0,0 +1,92 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type DeliverabilityRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.DeliverabilityRepository
+}
+
+func (s *DeliverabilityRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewDeliverabilityRepository(gormDB)
+}
+
+func (s *DeliverabilityRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *DeliverabilityRepositoryTestSuite) TestSuppressed() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "email" FROM "suppressed_addresses" WHERE email IN ($1,$2) AND "suppressed_addresses"."deleted_at" IS NULL`)).
+		WithArgs("jane@example.com", "sam@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT lower(email) FROM "users" WHERE (lower(email) IN ($1,$2) AND email_status <> '') AND "users"."deleted_at" IS NULL`)).
+		WithArgs("jane@example.com", "sam@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"lower"}).AddRow("jane@example.com"))
+
+	suppressed, err := s.repo.Suppressed([]string{"Jane@Example.com", "sam@example.com"})
+	s.NoError(err)
+	s.Equal([]string{"jane@example.com"}, suppressed)
+}
+
+// addresses without an account are suppressed too, e.g. a newsletter
+// subscriber whose mailbox bounced
+func (s *DeliverabilityRepositoryTestSuite) TestSuppressed_ListedAddresses() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "email" FROM "suppressed_addresses"`)).
+		WithArgs("reader@example.com", "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("reader@example.com").AddRow("jane@example.com"))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT lower(email) FROM "users"`)).
+		WithArgs("reader@example.com", "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"lower"}).AddRow("jane@example.com"))
+
+	suppressed, err := s.repo.Suppressed([]string{"Reader@example.com", "jane@example.com"})
+	s.NoError(err)
+	s.Equal([]string{"reader@example.com", "jane@example.com"}, suppressed)
+}
+
+func (s *DeliverabilityRepositoryTestSuite) TestSuppressed_NoAddresses() {
+	suppressed, err := s.repo.Suppressed(nil)
+	s.NoError(err)
+	s.Empty(suppressed)
+}
+
+func (s *DeliverabilityRepositoryTestSuite) TestMarkAddress_StopsNewsletter() {
+	at := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "suppressed_addresses" ("created_at","updated_at","deleted_at","email","status","reason","suppressed_at") VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT ("email") DO UPDATE SET "status"="excluded"."status","reason"="excluded"."reason","suppressed_at"="excluded"."suppressed_at","updated_at"="excluded"."updated_at","deleted_at"="excluded"."deleted_at" RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "jane@example.com", domain.EmailStatusHardBounced, "550 no such user", at).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "email_status"=$1,"email_status_at"=$2,"email_status_reason"=$3,"updated_at"=$4 WHERE lower(email) = $5 AND "users"."deleted_at" IS NULL`)).
+		WithArgs(domain.EmailStatusHardBounced, at, "550 no such user", sqlmock.AnyArg(), "jane@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "subscriptions" SET "status"=$1,"updated_at"=$2 WHERE (email = $3 AND status <> $4)`)).
+		WithArgs(domain.SubscriptionStatusUnsubscribed, sqlmock.AnyArg(), "jane@example.com", domain.SubscriptionStatusUnsubscribed).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	marked, err := s.repo.MarkAddress("Jane@Example.com", domain.EmailStatusHardBounced, "550 no such user", at)
+	s.NoError(err)
+	s.Equal(int64(1), marked)
+}
+
+func TestDeliverabilityRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(DeliverabilityRepositoryTestSuite))
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type EmailChangeRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.EmailChangeRepository
+}
+
+func (s *EmailChangeRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewEmailChangeRepository(gormDB)
+}
+
+func (s *EmailChangeRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *EmailChangeRepositoryTestSuite) TestConsume_SingleUse() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "email_change_requests" WHERE token_hash = $1 RETURNING *`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "new_email"}).AddRow(1, 2, "new@example.com"))
+	s.mock.ExpectCommit()
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "email_change_requests" WHERE token_hash = $1 RETURNING *`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectCommit()
+
+	request, err := s.repo.Consume("hash")
+	s.NoError(err)
+	s.Equal("new@example.com", request.NewEmail)
+
+	_, err = s.repo.Consume("hash")
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func TestEmailChangeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailChangeRepositoryTestSuite))
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type EmailOutboxRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.EmailOutboxRepository
+}
+
+func (s *EmailOutboxRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewEmailOutboxRepository(gormDB)
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TestEnqueue_SerializesRecipientsAndData() {
+	email := domain.NewOutboxEmail(domain.EmailMessage{
+		To:       []string{"jane@example.com"},
+		Template: domain.EmailActivation,
+		Data:     map[string]interface{}{"Link": "https://example.com/activate"},
+	})
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox_emails"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, `["jane@example.com"]`, domain.EmailActivation, "",
+			`{"Link":"https://example.com/activate"}`, nil, "", "", domain.OutboxStatusPending, 0, sqlmock.AnyArg(), nil, "", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.Enqueue(&email))
+	s.Equal(int64(1), email.ID)
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TestClaim_LocksDueEmails() {
+	now := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox_emails" WHERE ((status = $1 AND next_attempt_at <= $2) OR (status = $3 AND locked_until <= $4)) AND "outbox_emails"."deleted_at" IS NULL ORDER BY next_attempt_at LIMIT $5 FOR UPDATE SKIP LOCKED`)).
+		WithArgs(domain.OutboxStatusPending, now, domain.OutboxStatusSending, now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "recipients", "status", "attempts"}).
+			AddRow(3, `["a@example.com"]`, domain.OutboxStatusPending, 0).
+			AddRow(4, `["b@example.com"]`, domain.OutboxStatusSending, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_emails" SET "attempts"=attempts + 1,"locked_until"=$1,"status"=$2,"updated_at"=$3 WHERE id IN ($4,$5)`)).
+		WithArgs(now.Add(time.Minute), domain.OutboxStatusSending, sqlmock.AnyArg(), int64(3), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	emails, err := s.repo.Claim(now, time.Minute, 10)
+	s.NoError(err)
+	s.Len(emails, 2)
+	s.Equal([]string{"a@example.com"}, emails[0].To)
+	s.Equal(1, emails[0].Attempts)
+	s.Equal(2, emails[1].Attempts)
+	s.Equal(domain.OutboxStatusSending, emails[1].Status)
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TestClaim_NothingDue() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox_emails"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectCommit()
+
+	emails, err := s.repo.Claim(time.Now(), time.Minute, 10)
+	s.NoError(err)
+	s.Empty(emails)
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TestMarkSent_DropsRenderedContent() {
+	at := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_emails" SET "body"=$1,"data"=NULL,"headers"=NULL,"last_error"=$2,"locked_until"=$3,"sent_at"=$4,"status"=$5,"updated_at"=$6 WHERE id = $7`)).
+		WithArgs("", "", nil, at, domain.OutboxStatusSent, sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.MarkSent(3, at))
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TestMarkFailed_DeadLetters() {
+	next := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_emails" SET "last_error"=$1,"locked_until"=$2,"next_attempt_at"=$3,"status"=$4,"updated_at"=$5 WHERE id = $6`)).
+		WithArgs("550 no such user", nil, next, domain.OutboxStatusDead, sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.MarkFailed(3, "550 no such user", next, true))
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TestRetry_OnlyFailedEmails() {
+	at := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_emails" SET "attempts"=$1,"next_attempt_at"=$2,"status"=$3,"updated_at"=$4 WHERE (id = $5 AND status IN ($6,$7))`)).
+		WithArgs(0, at, domain.OutboxStatusPending, sqlmock.AnyArg(), int64(3), domain.OutboxStatusDead, domain.OutboxStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.ErrorIs(s.repo.Retry(3, at), domain.ErrOutboxEmailNotRetryable)
+}
+
+func (s *EmailOutboxRepositoryTestSuite) TestPurgeSent() {
+	before := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox_emails" WHERE status = $1 AND sent_at < $2`)).
+		WithArgs(domain.OutboxStatusSent, before).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	s.mock.ExpectCommit()
+
+	purged, err := s.repo.PurgeSent(before)
+	s.NoError(err)
+	s.Equal(int64(4), purged)
+}
+
+func TestEmailOutboxRepository(t *testing.T) {
+	suite.Run(t, new(EmailOutboxRepositoryTestSuite))
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.LoginAttemptRepository
+}
+
+func (s *LoginAttemptRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewLoginAttemptRepository(gormDB)
+}
+
+func (s *LoginAttemptRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *LoginAttemptRepositoryTestSuite) TestFetch_NotFoundIsZero() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_attempts" WHERE key = $1`)).
+		WithArgs("account:1", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	attempt, err := s.repo.Fetch("account:1")
+	s.NoError(err)
+	s.Zero(attempt.Failures)
+}
+
+func (s *LoginAttemptRepositoryTestSuite) TestFetch_DBError() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_attempts"`)).
+		WillReturnError(errors.New("db error"))
+
+	_, err := s.repo.Fetch("account:1")
+	s.Error(err)
+}
+
+func (s *LoginAttemptRepositoryTestSuite) TestRecordFailure_Upserts() {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "login_attempts"`) + `.*` + regexp.QuoteMeta(`ON CONFLICT ("key") DO UPDATE SET`) + `.*` + regexp.QuoteMeta(`"login_attempts"."failures" + 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_attempts" WHERE key = $1`)).
+		WithArgs("ip:10.0.0.1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key", "failures", "last_failure_at"}).AddRow(1, "ip:10.0.0.1", 4, at))
+
+	attempt, err := s.repo.RecordFailure("ip:10.0.0.1", at)
+	s.NoError(err)
+	s.Equal(4, attempt.Failures)
+}
+
+func (s *LoginAttemptRepositoryTestSuite) TestReset_HardDeletes() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_attempts" WHERE key = $1`)).
+		WithArgs("account:1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.Reset("account:1"))
+}
+
+func TestLoginAttemptRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginAttemptRepositoryTestSuite))
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type NotificationRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.NotificationRepository
+}
+
+func (s *NotificationRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewNotificationRepository(gormDB)
+}
+
+func (s *NotificationRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *NotificationRepositoryTestSuite) TestSearch_UnreadOnly() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "notifications" WHERE (user_id = $1 AND in_app = $2) AND read_at IS NULL`)).
+		WithArgs(int64(1), true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "notifications" WHERE (user_id = $1 AND in_app = $2) AND read_at IS NULL AND "notifications"."deleted_at" IS NULL ORDER BY id DESC LIMIT $3 OFFSET $4`)).
+		WithArgs(int64(1), true, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "type"}).AddRow(5, 1, "follow"))
+
+	notifications, total, err := s.repo.Search(domain.NotificationFilter{UserID: 1, UnreadOnly: true, Page: 2, Limit: 2})
+	s.NoError(err)
+	s.Equal(int64(3), total)
+	s.Len(notifications, 1)
+}
+
+func (s *NotificationRepositoryTestSuite) TestMarkRead_OtherUsersNotification() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "notifications" WHERE (id = $1 AND user_id = $2 AND in_app = $3)`)).
+		WithArgs(int64(9), int64(1), true, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	s.ErrorIs(s.repo.MarkRead(1, 9, time.Now()), domain.ErrResourceNotFound)
+}
+
+func (s *NotificationRepositoryTestSuite) TestMarkRead_KeepsFirstReadAt() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "notifications" WHERE (id = $1 AND user_id = $2 AND in_app = $3)`)).
+		WithArgs(int64(9), int64(1), true, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "read_at"}).AddRow(9, 1, time.Now().Add(-time.Hour)))
+
+	s.NoError(s.repo.MarkRead(1, 9, time.Now()))
+}
+
+func (s *NotificationRepositoryTestSuite) TestMarkAllRead() {
+	at := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "notifications" SET "read_at"=$1,"updated_at"=$2 WHERE (user_id = $3 AND in_app = $4 AND read_at IS NULL)`)).
+		WithArgs(at, sqlmock.AnyArg(), int64(1), true).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	s.mock.ExpectCommit()
+
+	count, err := s.repo.MarkAllRead(1, at)
+	s.NoError(err)
+	s.Equal(int64(4), count)
+}
+
+func (s *NotificationRepositoryTestSuite) TestSavePreferences_Upserts() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`ON CONFLICT ("user_id","type") DO UPDATE SET "in_app"="excluded"."in_app","email"="excluded"."email","updated_at"="excluded"."updated_at"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.SavePreferences([]domain.NotificationPreference{{UserID: 1, Type: domain.NotificationFollow, Email: domain.EmailDeliveryOff}}))
+}
+
+func (s *NotificationRepositoryTestSuite) TestMarkDigested() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "notifications" SET "digest_pending"=$1,"updated_at"=$2 WHERE id IN ($3,$4)`)).
+		WithArgs(false, sqlmock.AnyArg(), int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.MarkDigested([]int64{1, 2}))
+	s.NoError(s.repo.MarkDigested(nil))
+}
+
+func TestNotificationRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationRepositoryTestSuite))
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type OIDCRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.OIDCRepository
+}
+
+func (s *OIDCRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewOIDCRepository(gormDB)
+}
+
+func (s *OIDCRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *OIDCRepositoryTestSuite) TestConsumeAuthRequest_DeletesAndReturns() {
+	expires := time.Now().Add(time.Minute)
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "oidc_auth_requests" WHERE state = $1 RETURNING *`)).
+		WithArgs("state-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state", "provider", "nonce", "code_verifier", "expires_at"}).
+			AddRow(1, "state-1", "google", "nonce-1", "verifier-1", expires))
+	s.mock.ExpectCommit()
+
+	request, err := s.repo.ConsumeAuthRequest("state-1")
+	s.NoError(err)
+	s.Equal("google", request.Provider)
+	s.Equal("verifier-1", request.CodeVerifier)
+}
+
+func (s *OIDCRepositoryTestSuite) TestConsumeAuthRequest_Unknown() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "oidc_auth_requests" WHERE state = $1 RETURNING *`)).
+		WithArgs("replayed").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectCommit()
+
+	_, err := s.repo.ConsumeAuthRequest("replayed")
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *OIDCRepositoryTestSuite) TestFetchIdentity_NotLinked() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "linked_identities" WHERE (provider = $1 AND subject = $2)`)).
+		WithArgs("google", "sub-1", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	identity, err := s.repo.FetchIdentity("google", "sub-1")
+	s.NoError(err)
+	s.Zero(identity.ID)
+}
+
+func (s *OIDCRepositoryTestSuite) TestDeleteIdentity_ScopedToUser() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "linked_identities" WHERE id = $1 AND user_id = $2`)).
+		WithArgs(int64(3), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.ErrorIs(s.repo.DeleteIdentity(2, 3), gorm.ErrRecordNotFound)
+}
+
+func TestOIDCRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(OIDCRepositoryTestSuite))
+}
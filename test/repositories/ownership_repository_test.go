@@ -0,0 +1,58 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type OwnershipRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.OwnershipRepository
+}
+
+func (s *OwnershipRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewOwnershipRepository(gormDB)
+}
+
+func (s *OwnershipRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *OwnershipRepositoryTestSuite) TestBlogOwner() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "user_id" FROM "blogs" WHERE id = $1 AND "blogs"."deleted_at" IS NULL LIMIT $2`)).
+		WithArgs(int64(5), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(3))
+
+	owner, err := s.repo.BlogOwner(context.Background(), 5)
+	s.NoError(err)
+	s.Equal(int64(3), owner)
+}
+
+func (s *OwnershipRepositoryTestSuite) TestCommentOwner_NotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "user_id" FROM "comments" WHERE id = $1 AND "comments"."deleted_at" IS NULL LIMIT $2`)).
+		WithArgs(int64(8), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	_, err := s.repo.CommentOwner(context.Background(), 8)
+	s.ErrorIs(err, domain.ErrResourceNotFound)
+}
+
+func TestOwnershipRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(OwnershipRepositoryTestSuite))
+}
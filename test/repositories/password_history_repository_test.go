@@ -0,0 +1,61 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.PasswordHistoryRepository
+}
+
+func (s *PasswordHistoryRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewPasswordHistoryRepository(gormDB)
+}
+
+func (s *PasswordHistoryRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *PasswordHistoryRepositoryTestSuite) TestRecent_NewestFirst() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "password_histories" WHERE user_id = $1 AND "password_histories"."deleted_at" IS NULL ORDER BY id DESC LIMIT $2`)).
+		WithArgs(int64(1), 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "hash"}).AddRow(2, 1, "h2").AddRow(1, 1, "h1"))
+
+	entries, err := s.repo.Recent(1, 5)
+	s.NoError(err)
+	s.Len(entries, 2)
+	s.Equal("h2", entries[0].Hash)
+}
+
+func (s *PasswordHistoryRepositoryTestSuite) TestAdd_PrunesOlderEntries() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "password_histories"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "password_histories" WHERE user_id = $1 AND id NOT IN (SELECT "id" FROM "password_histories" WHERE user_id = $2 AND "password_histories"."deleted_at" IS NULL ORDER BY id DESC LIMIT $3)`)).
+		WithArgs(int64(1), int64(1), 3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.Add(&domain.PasswordHistory{UserID: 1, Hash: "h7"}, 3))
+}
+
+func TestPasswordHistoryRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordHistoryRepositoryTestSuite))
+}
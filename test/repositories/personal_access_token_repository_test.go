@@ -0,0 +1,59 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.PersonalAccessTokenRepository
+}
+
+func (s *PersonalAccessTokenRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewPersonalAccessTokenRepository(gormDB)
+}
+
+func (s *PersonalAccessTokenRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *PersonalAccessTokenRepositoryTestSuite) TestFetchByHash() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "personal_access_tokens" WHERE token_hash = $1`)).
+		WithArgs("abc", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "scopes"}).AddRow(5, 1, "ci", "blogs:write"))
+
+	token, err := s.repo.FetchByHash("abc")
+	s.NoError(err)
+	s.Equal(int64(5), token.ID)
+	s.Equal([]string{"blogs:write"}, token.ScopeList())
+}
+
+func (s *PersonalAccessTokenRepositoryTestSuite) TestRevoke_OnlyOwnActiveToken() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "personal_access_tokens" SET "revoked_at"=$1,"updated_at"=$2 WHERE (id = $3 AND user_id = $4 AND revoked_at IS NULL)`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), int64(5), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.ErrorIs(s.repo.Revoke(2, 5, time.Now()), gorm.ErrRecordNotFound)
+}
+
+func TestPersonalAccessTokenRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PersonalAccessTokenRepositoryTestSuite))
+}
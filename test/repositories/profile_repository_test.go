@@ -0,0 +1,87 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type ProfileRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.ProfileRepository
+}
+
+func (s *ProfileRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewProfileRepository(gormDB)
+}
+
+func (s *ProfileRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *ProfileRepositoryTestSuite) TestFetchPrivacy_DefaultsToPublic() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "profile_privacies" WHERE user_id = $1`)).
+		WithArgs(int64(1), 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	privacy, err := s.repo.FetchPrivacy(1)
+	s.NoError(err)
+	s.Equal(int64(1), privacy.UserID)
+	s.False(privacy.HideBio)
+}
+
+func (s *ProfileRepositoryTestSuite) TestAuthorStats() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "blogs" WHERE user_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(likes + dislikes), 0) FROM "blogs" WHERE user_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(9))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "follows" WHERE followee_id = $1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	stats, err := s.repo.AuthorStats(1)
+	s.NoError(err)
+	s.Equal(int64(4), stats.PostCount)
+	s.Equal(int64(9), stats.ReactionsReceived)
+	s.Equal(int64(2), stats.FollowerCount)
+}
+
+func (s *ProfileRepositoryTestSuite) TestFollow_ReportsExisting() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "follows"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectCommit()
+
+	created, err := s.repo.Follow(2, 1)
+	s.NoError(err)
+	s.False(created)
+}
+
+func (s *ProfileRepositoryTestSuite) TestUnfollow_HardDeletes() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "follows" WHERE follower_id = $1 AND followee_id = $2`)).
+		WithArgs(int64(2), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.Unfollow(2, 1))
+}
+
+func TestProfileRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileRepositoryTestSuite))
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type ReactionRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.ReactionRepository
+}
+
+func (s *ReactionRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewReactionRepository(gormDB)
+}
+
+func (s *ReactionRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+const selectReaction = `SELECT * FROM "reactions" WHERE (user_id = $1 AND blog_id = $2) AND "reactions"."deleted_at" IS NULL ORDER BY "reactions"."id" LIMIT $3 FOR UPDATE`
+
+func (s *ReactionRepositoryTestSuite) TestReact_First() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectReaction)).
+		WithArgs(int64(1), int64(7), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "reactions"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "blogs" SET "likes"=likes + $1 WHERE id = $2 AND "blogs"."deleted_at" IS NULL`)).
+		WithArgs(1, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	previous, err := s.repo.React(1, 7, domain.ReactionLike)
+	s.NoError(err)
+	s.Empty(previous)
+}
+
+func (s *ReactionRepositoryTestSuite) TestReact_ChangesKind() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectReaction)).
+		WithArgs(int64(1), int64(7), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "blog_id", "kind"}).AddRow(5, 1, 7, domain.ReactionLike))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "reactions" SET "kind"=$1,"updated_at"=$2 WHERE "reactions"."deleted_at" IS NULL AND "id" = $3`)).
+		WithArgs(domain.ReactionDislike, sqlmock.AnyArg(), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "blogs" SET "likes"=likes + $1 WHERE id = $2`)).
+		WithArgs(-1, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "blogs" SET "dislikes"=dislikes + $1 WHERE id = $2`)).
+		WithArgs(1, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	previous, err := s.repo.React(1, 7, domain.ReactionDislike)
+	s.NoError(err)
+	s.Equal(domain.ReactionLike, previous)
+}
+
+func (s *ReactionRepositoryTestSuite) TestReact_SameKindChangesNothing() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectReaction)).
+		WithArgs(int64(1), int64(7), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "blog_id", "kind"}).AddRow(5, 1, 7, domain.ReactionLike))
+	s.mock.ExpectCommit()
+
+	previous, err := s.repo.React(1, 7, domain.ReactionLike)
+	s.NoError(err)
+	s.Equal(domain.ReactionLike, previous)
+}
+
+func (s *ReactionRepositoryTestSuite) TestUnreact() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectReaction)).
+		WithArgs(int64(1), int64(7), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "blog_id", "kind"}).AddRow(5, 1, 7, domain.ReactionDislike))
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "reactions" WHERE "reactions"."id" = $1`)).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "blogs" SET "dislikes"=dislikes + $1 WHERE id = $2`)).
+		WithArgs(-1, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	previous, err := s.repo.Unreact(1, 7)
+	s.NoError(err)
+	s.Equal(domain.ReactionDislike, previous)
+}
+
+func TestReactionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ReactionRepositoryTestSuite))
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type SubscriptionRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.SubscriptionRepository
+}
+
+func (s *SubscriptionRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewSubscriptionRepository(gormDB)
+}
+
+func (s *SubscriptionRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *SubscriptionRepositoryTestSuite) TestConfirm_ExpiredOrUnknownToken() {
+	at := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "subscriptions" SET "confirm_expires_at"=$1,"confirm_token_hash"=$2,"confirmed_at"=$3,"status"=$4,"updated_at"=$5 WHERE (confirm_token_hash = $6 AND status = $7 AND confirm_expires_at > $8)`)).
+		WithArgs(nil, nil, at, domain.SubscriptionStatusActive, sqlmock.AnyArg(), "hash", domain.SubscriptionStatusPending, at).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.ErrorIs(s.repo.Confirm("hash", at), domain.ErrSubscriptionTokenInvalid)
+}
+
+func (s *SubscriptionRepositoryTestSuite) TestUnsubscribe() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "subscriptions" SET "status"=$1,"updated_at"=$2 WHERE unsubscribe_token_hash = $3`)).
+		WithArgs(domain.SubscriptionStatusUnsubscribed, sqlmock.AnyArg(), "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.Unsubscribe("hash"))
+}
+
+func (s *SubscriptionRepositoryTestSuite) TestMarkSent_StoresTokenHash() {
+	at := time.Now()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "subscriptions" SET "last_sent_at"=$1,"unsubscribe_token_hash"=$2,"updated_at"=$3 WHERE id = $4`)).
+		WithArgs(at, "hash", sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.MarkSent(1, at, "hash"))
+}
+
+func (s *SubscriptionRepositoryTestSuite) TestFetchDue() {
+	before := time.Now()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "subscriptions" WHERE (status = $1 AND (last_sent_at IS NULL OR last_sent_at <= $2) AND id > $3) AND "subscriptions"."deleted_at" IS NULL ORDER BY id LIMIT $4`)).
+		WithArgs(domain.SubscriptionStatusActive, before, int64(7), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "status"}).AddRow(8, `["go"]`, domain.SubscriptionStatusActive))
+
+	subscriptions, err := s.repo.FetchDue(before, 7, 100)
+	s.NoError(err)
+	s.Require().Len(subscriptions, 1)
+	s.Equal([]string{"go"}, subscriptions[0].Tags)
+}
+
+func (s *SubscriptionRepositoryTestSuite) TestTopPosts_FollowedAuthorsAndTags() {
+	since := time.Now().Add(-7 * 24 * time.Hour)
+	userID := int64(1)
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT blogs.id, blogs.title, blogs.content, users.username AS author, blogs.likes, blogs.view_count FROM "blogs" LEFT JOIN users ON users.id = blogs.user_id WHERE blogs.created_at >= $1 AND (blogs.user_id IN (SELECT "followee_id" FROM "follows" WHERE follower_id = $2 AND "follows"."deleted_at" IS NULL) OR blogs.id IN (SELECT tag_blogs.blog_id FROM "tag_blogs" JOIN tags ON tags.id = tag_blogs.tag_id WHERE tags.name IN ($3,$4) AND "tag_blogs"."deleted_at" IS NULL)) AND "blogs"."deleted_at" IS NULL ORDER BY blogs.likes - blogs.dislikes DESC, blogs.view_count DESC, blogs.id DESC LIMIT $5`)).
+		WithArgs(since, userID, "go", "testing", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "likes"}).AddRow(3, "Testing in Go", "jane", 12))
+
+	posts, err := s.repo.TopPosts(&userID, []string{"go", "testing"}, since, 10)
+	s.NoError(err)
+	s.Equal([]domain.DigestPost{{ID: 3, Title: "Testing in Go", Author: "jane", Likes: 12}}, posts)
+}
+
+func (s *SubscriptionRepositoryTestSuite) TestTopPosts_WholeBlog() {
+	since := time.Now().Add(-7 * 24 * time.Hour)
+	s.mock.ExpectQuery(regexp.QuoteMeta(`FROM "blogs" LEFT JOIN users ON users.id = blogs.user_id WHERE blogs.created_at >= $1 AND "blogs"."deleted_at" IS NULL ORDER BY`)).
+		WithArgs(since, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	posts, err := s.repo.TopPosts(nil, nil, since, 10)
+	s.NoError(err)
+	s.Empty(posts)
+}
+
+func TestSubscriptionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(SubscriptionRepositoryTestSuite))
+}
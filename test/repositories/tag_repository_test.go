@@ -0,0 +1,75 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type TagRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.TagRepository
+}
+
+func (s *TagRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewTagRepository(gormDB)
+}
+
+func (s *TagRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TagRepositoryTestSuite) TestFetchByNames() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT tags.id, tags.name, count(tag_blogs.id) AS posts FROM "tags" LEFT JOIN tag_blogs ON tag_blogs.tag_id = tags.id AND tag_blogs.deleted_at IS NULL WHERE btrim(regexp_replace(lower(tags.name), '[^[:alnum:]+#]+', ' ', 'g')) IN ($1,$2) AND "tags"."deleted_at" IS NULL GROUP BY "tags"."id" ORDER BY posts DESC, tags.id`)).
+		WithArgs("pprof", "golang").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "posts"}).AddRow(8, "golang", 2))
+
+	tags, err := s.repo.FetchByNames(context.Background(), []string{"pprof", "Golang"})
+	s.NoError(err)
+	s.Equal([]domain.TagUsage{{ID: 8, Name: "golang", Posts: 2}}, tags)
+}
+
+func (s *TagRepositoryTestSuite) TestFetchByNames_HyphenatedTag() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`WHERE btrim(regexp_replace(lower(tags.name), '[^[:alnum:]+#]+', ' ', 'g')) IN ($1)`)).
+		WithArgs("machine learning").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "posts"}).AddRow(4, "Machine-Learning", 9))
+
+	tags, err := s.repo.FetchByNames(context.Background(), []string{"machine learning"})
+	s.NoError(err)
+	s.Equal([]domain.TagUsage{{ID: 4, Name: "Machine-Learning", Posts: 9}}, tags)
+}
+
+func (s *TagRepositoryTestSuite) TestFetchByNames_NoNames() {
+	tags, err := s.repo.FetchByNames(context.Background(), nil)
+	s.NoError(err)
+	s.Empty(tags)
+}
+
+func (s *TagRepositoryTestSuite) TestMatchTags_WholeWords() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT tags.id, tags.name, count(tag_blogs.id) AS posts FROM "tags" LEFT JOIN tag_blogs ON tag_blogs.tag_id = tags.id AND tag_blogs.deleted_at IS NULL WHERE strpos($1, ' ' || btrim(regexp_replace(lower(tags.name), '[^[:alnum:]+#]+', ' ', 'g')) || ' ') > 0 AND "tags"."deleted_at" IS NULL GROUP BY "tags"."id" ORDER BY posts DESC, tags.id LIMIT $2`)).
+		WithArgs(" profiling go ", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "posts"}).AddRow(3, "Go", 12))
+
+	tags, err := s.repo.MatchTags(context.Background(), "profiling go", 50)
+	s.NoError(err)
+	s.Equal([]domain.TagUsage{{ID: 3, Name: "Go", Posts: 12}}, tags)
+}
+
+func TestTagRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TagRepositoryTestSuite))
+}
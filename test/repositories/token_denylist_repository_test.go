@@ -0,0 +1,83 @@
+package test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type TokenDenylistRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.TokenDenylistRepository
+}
+
+func (s *TokenDenylistRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewTokenDenylistRepository(gormDB)
+}
+
+func (s *TokenDenylistRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TokenDenylistRepositoryTestSuite) TestIsRevoked_True() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "revoked_tokens" WHERE (jti = $1 AND expires_at > $2) AND "revoked_tokens"."deleted_at" IS NULL`)).
+		WithArgs("jti-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	revoked, err := s.repo.IsRevoked("jti-1")
+	s.NoError(err)
+	s.True(revoked)
+}
+
+func (s *TokenDenylistRepositoryTestSuite) TestIsRevoked_DBError() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "revoked_tokens"`)).
+		WillReturnError(errors.New("db error"))
+
+	_, err := s.repo.IsRevoked("jti-1")
+	s.Error(err)
+}
+
+func (s *TokenDenylistRepositoryTestSuite) TestRevoke_Success() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "revoked_tokens" ("created_at","updated_at","deleted_at","jti","expires_at") VALUES ($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "jti-1", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.Revoke("jti-1", expiresAt))
+}
+
+func (s *TokenDenylistRepositoryTestSuite) TestConsume_AlreadyRevoked() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "revoked_tokens" ("created_at","updated_at","deleted_at","jti","expires_at") VALUES ($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "jti-1", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectCommit()
+
+	added, err := s.repo.Consume("jti-1", expiresAt)
+	s.NoError(err)
+	s.False(added)
+}
+
+func TestTokenDenylistRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenDenylistRepositoryTestSuite))
+}
@@ -128,6 +128,34 @@ func (s *TokenRepositoryTestSuite) TestSave_DBError() {
 	s.Error(err)
 }
 
+func (s *TokenRepositoryTestSuite) TestSaveWithEmail() {
+	token := &domain.Token{Type: "access", Content: "reset_token", UserID: 1, Status: "active"}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tokens"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox_emails"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.SaveWithEmail(token, domain.EmailMessage{To: []string{"user@example.com"}, Template: domain.EmailPasswordReset})
+	s.NoError(err)
+}
+
+func (s *TokenRepositoryTestSuite) TestSaveWithEmail_QueueError() {
+	token := &domain.Token{Type: "access", Content: "reset_token", UserID: 1, Status: "active"}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tokens"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox_emails"`)).
+		WillReturnError(errors.New("some db error"))
+	s.mock.ExpectRollback()
+
+	err := s.repo.SaveWithEmail(token, domain.EmailMessage{To: []string{"user@example.com"}, Template: domain.EmailPasswordReset})
+	s.Error(err)
+}
+
 func TestTokenRepositoryTestSuite(t *testing.T) {
     suite.Run(t, new(TokenRepositoryTestSuite))
 }
\ No newline at end of file
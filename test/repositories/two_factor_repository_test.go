@@ -0,0 +1,82 @@
+package test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepositoryTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repositories.TwoFactorRepository
+}
+
+func (s *TwoFactorRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	s.Require().NoError(err)
+
+	s.mock = mock
+	s.repo = repositories.NewTwoFactorRepository(gormDB)
+}
+
+func (s *TwoFactorRepositoryTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TwoFactorRepositoryTestSuite) TestFetchByUserID_NotEnrolled() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "two_factors" WHERE user_id = $1 AND "two_factors"."deleted_at" IS NULL ORDER BY "two_factors"."id" LIMIT $2`)).
+		WithArgs(1, 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	twoFactor, err := s.repo.FetchByUserID(1)
+	s.NoError(err)
+	s.Equal(domain.TwoFactor{}, twoFactor)
+}
+
+func (s *TwoFactorRepositoryTestSuite) TestFetchByUserID_Found() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "two_factors" WHERE user_id = $1`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "secret", "enabled"}).AddRow(3, 1, "SECRET", true))
+
+	twoFactor, err := s.repo.FetchByUserID(1)
+	s.NoError(err)
+	s.True(twoFactor.Enabled)
+	s.Equal("SECRET", twoFactor.Secret)
+}
+
+func (s *TwoFactorRepositoryTestSuite) TestMarkRecoveryCodeUsed_AlreadyUsed() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "recovery_codes" SET "used_at"=$1,"updated_at"=$2 WHERE (id = $3 AND used_at IS NULL)`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.ErrorIs(s.repo.MarkRecoveryCodeUsed(7), gorm.ErrRecordNotFound)
+}
+
+func (s *TwoFactorRepositoryTestSuite) TestDelete_RemovesCodesAndSecret() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "recovery_codes" WHERE user_id = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "two_factors" WHERE user_id = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.Delete(1))
+}
+
+func TestTwoFactorRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorRepositoryTestSuite))
+}
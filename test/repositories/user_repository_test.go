@@ -2,6 +2,7 @@ package test
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"testing"
 
@@ -50,8 +51,8 @@ func (s *UserRepositoryTestSuite) TestRegister_Success() {
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("created_at","updated_at","deleted_at","username","email","password","role","bio","profile_picture","phone","status") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING "id"`)).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), user.Username, user.Email, user.Password, "", "", "", "", user.Status).
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("created_at","updated_at","deleted_at","username","email","password","role","bio","profile_picture","phone","status","locale","suspension_reason","suspended_until","email_status","email_status_reason","email_status_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), user.Username, user.Email, user.Password, "", "", "", "", user.Status, "", "", nil, "", "", nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	s.mock.ExpectCommit()
 
@@ -70,8 +71,8 @@ func (s *UserRepositoryTestSuite) TestRegister_Error() {
 	}
 
 	s.mock.ExpectBegin()
-	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("created_at","updated_at","deleted_at","username","email","password","role","bio","profile_picture","phone","status") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING "id"`)).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), user.Username, user.Email, user.Password, "", "", "", "", user.Status).
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("created_at","updated_at","deleted_at","username","email","password","role","bio","profile_picture","phone","status","locale","suspension_reason","suspended_until","email_status","email_status_reason","email_status_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17) RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), user.Username, user.Email, user.Password, "", "", "", "", user.Status, "", "", nil, "", "", nil).
 		WillReturnError(errors.New("db error"))
 	s.mock.ExpectRollback()
 
@@ -79,6 +80,46 @@ func (s *UserRepositoryTestSuite) TestRegister_Error() {
 	s.Error(err)
 }
 
+func (s *UserRepositoryTestSuite) TestRegisterWithEmail_QueuesEmailInSameTransaction() {
+	user := &domain.User{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password",
+		Status:   "inactive",
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox_emails"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, `["test@example.com"]`, domain.EmailActivation, "",
+			`{"Link":"/user/7/activate"}`, nil, "", "", domain.OutboxStatusPending, 0, sqlmock.AnyArg(), nil, "", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	createdUser, err := s.repo.RegisterWithEmail(user, func(u domain.User) domain.EmailMessage {
+		return domain.EmailMessage{To: []string{u.Email}, Template: domain.EmailActivation, Data: map[string]interface{}{"Link": fmt.Sprintf("/user/%d/activate", u.ID)}}
+	})
+	s.NoError(err)
+	s.Equal(int64(7), createdUser.ID)
+}
+
+func (s *UserRepositoryTestSuite) TestRegisterWithEmail_RollsBackUserWhenQueueingFails() {
+	user := &domain.User{Username: "testuser", Email: "test@example.com", Password: "password"}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox_emails"`)).
+		WillReturnError(errors.New("db error"))
+	s.mock.ExpectRollback()
+
+	_, err := s.repo.RegisterWithEmail(user, func(u domain.User) domain.EmailMessage {
+		return domain.EmailMessage{To: []string{u.Email}, Template: domain.EmailActivation}
+	})
+	s.Error(err)
+}
+
 func (s *UserRepositoryTestSuite) TestFetchByEmail_Success() {
 	email := "test@example.com"
 	user := domain.User{ID: 1, Email: email, Username: "testuser"}
@@ -224,30 +265,91 @@ func (s *UserRepositoryTestSuite) TestGetUserProfile_NotFound() {
 	s.Nil(user)
 }
 
+func (s *UserRepositoryTestSuite) TestChangeRole_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE role = $1 AND "users"."deleted_at" IS NULL FOR UPDATE`)).
+		WithArgs("superadmin").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "role"=$1,"updated_at"=$2 WHERE id = $3 AND "users"."deleted_at" IS NULL`)).
+		WithArgs("editor", sqlmock.AnyArg(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.NoError(s.repo.ChangeRole("2", "editor"))
+}
+
+func (s *UserRepositoryTestSuite) TestChangeRole_LastSuperadmin() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE role = $1 AND "users"."deleted_at" IS NULL FOR UPDATE`)).
+		WithArgs("superadmin").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectRollback()
+
+	s.ErrorIs(s.repo.ChangeRole("1", "admin"), domain.ErrLastSuperadmin)
+}
+
+func (s *UserRepositoryTestSuite) TestBootstrapSuperadmin_PromotesWhenNoneExists() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE role = $1 AND "users"."deleted_at" IS NULL FOR UPDATE`)).
+		WithArgs("superadmin").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "role"=$1,"updated_at"=$2 WHERE lower(email) = lower($3) AND "users"."deleted_at" IS NULL`)).
+		WithArgs("superadmin", sqlmock.AnyArg(), "Owner@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	promoted, err := repositories.BootstrapSuperadmin(s.DB, "Owner@example.com")
+	s.NoError(err)
+	s.True(promoted)
+}
+
+func (s *UserRepositoryTestSuite) TestBootstrapSuperadmin_KeepsExisting() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE role = $1 AND "users"."deleted_at" IS NULL FOR UPDATE`)).
+		WithArgs("superadmin").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	promoted, err := repositories.BootstrapSuperadmin(s.DB, "owner@example.com")
+	s.NoError(err)
+	s.False(promoted)
+}
+
+func (s *UserRepositoryTestSuite) TestChangeRole_InvalidID() {
+	s.EqualError(s.repo.ChangeRole("abc", "admin"), "invalid id")
+}
+
 func (s *UserRepositoryTestSuite) TestUpdateUserProfile_Success() {
 	userID := int64(1)
-	updates := map[string]interface{}{
-		"Username": "updateduser",
-		"Bio":      "updated bio",
-	}
+	username := "updateduser"
+	bio := "updated bio"
+	email := "ignored@example.com"
 	s.mock.ExpectBegin()
-	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "username"=$1,"bio"=$2 WHERE id = $3 AND "users"."deleted_at" IS NULL`)).
-		WithArgs("updateduser", "updated bio", userID).
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "bio"=$1,"username"=$2,"updated_at"=$3 WHERE id = $4 AND "users"."deleted_at" IS NULL`)).
+		WithArgs("updated bio", "updateduser", sqlmock.AnyArg(), userID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	s.mock.ExpectCommit()
 
-	err := s.repo.UpdateUserProfile(userID, updates)
+	err := s.repo.UpdateUserProfile(userID, domain.ProfileUpdate{Username: &username, Bio: &bio, Email: &email})
 	s.NoError(err)
 }
 
 func (s *UserRepositoryTestSuite) TestUpdateUserProfile_NoFields() {
 	userID := int64(1)
-	updates := map[string]interface{}{
-		"NotAllowed": "value",
-	}
-	err := s.repo.UpdateUserProfile(userID, updates)
+	err := s.repo.UpdateUserProfile(userID, domain.ProfileUpdate{})
 	s.NoError(err)
 }
+
+func (s *UserRepositoryTestSuite) TestUpdateEmail_NotFound() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "email"=$1,"email_status"=$2,"email_status_at"=$3,"email_status_reason"=$4,"updated_at"=$5 WHERE id = $6 AND "users"."deleted_at" IS NULL`)).
+		WithArgs("new@example.com", "", nil, "", sqlmock.AnyArg(), int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	s.ErrorIs(s.repo.UpdateEmail(9, "new@example.com"), gorm.ErrRecordNotFound)
+}
+
 func (s *UserRepositoryTestSuite) TestResetPassword_Success() {
 	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
 		WithArgs(1, 1).
@@ -0,0 +1,249 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountUsecaseTestSuite struct {
+	suite.Suite
+	userRepo        *mocks.MockUserRepository
+	accountRepo     *mocks.MockAccountRepository
+	emailService    *mocks.MockEmailService
+	passwordService *mocks.MockPasswordService
+	jwtService      *mocks.MockJWTService
+	config          domain.AccountDeletionConfig
+	usecase         domain.IAccountUsecase
+	user            domain.User
+}
+
+func (suite *AccountUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.accountRepo = new(mocks.MockAccountRepository)
+	suite.emailService = new(mocks.MockEmailService)
+	suite.passwordService = new(mocks.MockPasswordService)
+	suite.jwtService = new(mocks.MockJWTService)
+	suite.config = domain.AccountDeletionConfig{Policy: domain.DeletionPolicyAnonymize, GracePeriod: 14 * 24 * time.Hour}
+	suite.usecase = suite.newUsecase()
+	suite.user = domain.User{ID: 1, Username: "jane", Email: "jane@example.com", Password: "hashed", Role: domain.RoleAuthor, Status: "active"}
+}
+
+func (suite *AccountUsecaseTestSuite) newUsecase() domain.IAccountUsecase {
+	return usecases.NewAccountUsecase(suite.userRepo, suite.accountRepo, suite.emailService, suite.passwordService, suite.jwtService, suite.config)
+}
+
+func (suite *AccountUsecaseTestSuite) TestRequestExport_QueuesExport() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.accountRepo.On("LatestExport", int64(1)).Return(domain.DataExport{}, nil)
+	suite.accountRepo.On("CreateExport", mock.MatchedBy(func(e *domain.DataExport) bool {
+		return e.UserID == 1 && e.Status == "pending"
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.RequestExport("1"))
+	suite.accountRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AccountUsecaseTestSuite) TestRequestExport_Cooldown() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.accountRepo.On("LatestExport", int64(1)).Return(domain.DataExport{ID: 4, Status: "ready", CreatedAt: time.Now().Add(-time.Hour)}, nil)
+
+	suite.Error(suite.usecase.RequestExport("1"))
+	suite.accountRepo.AssertNotCalled(suite.T(), "CreateExport", mock.Anything)
+}
+
+func (suite *AccountUsecaseTestSuite) TestRequestExport_RetryAfterFailure() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.accountRepo.On("LatestExport", int64(1)).Return(domain.DataExport{ID: 4, Status: "failed", CreatedAt: time.Now().Add(-time.Hour)}, nil)
+	suite.accountRepo.On("CreateExport", mock.Anything).Return(nil)
+
+	suite.NoError(suite.usecase.RequestExport("1"))
+}
+
+func (suite *AccountUsecaseTestSuite) TestProcessPendingExports_BuildsArchiveAndMailsLink() {
+	data := domain.PersonalData{
+		User:      suite.user,
+		Posts:     []domain.Blog{{ID: 7, Title: "hello", Likes: 2}},
+		Comments:  []domain.Comment{{ID: 3, BlogID: 7, Content: "nice"}},
+		Reactions: []domain.Reaction{{ID: 5, UserID: 1, BlogID: 12, Kind: domain.ReactionDislike}},
+		Sessions:  []domain.Token{{Type: "refresh", Content: "secret-token", Status: "active"}},
+	}
+	var saved *domain.DataExport
+	var link string
+	suite.accountRepo.On("ClaimExports", mock.Anything, 15*time.Minute, 10).Return([]domain.DataExport{{ID: 9, UserID: 1, Status: "building"}}, nil)
+	suite.accountRepo.On("CollectPersonalData", int64(1)).Return(data, nil)
+	suite.accountRepo.On("SaveExport", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*domain.DataExport)
+	}).Return(nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.Template == domain.EmailExportReady && m.To[0] == "jane@example.com"
+	})).Run(func(args mock.Arguments) {
+		link = args.Get(0).(domain.EmailMessage).Data["Link"].(string)
+	}).Return(nil)
+
+	suite.NoError(suite.usecase.ProcessPendingExports())
+	suite.Require().NotNil(saved)
+	suite.Equal("ready", saved.Status)
+	suite.Require().NotNil(saved.TokenHash)
+
+	token := strings.Fields(link[strings.Index(link, "/exports/")+len("/exports/"):])[0]
+	sum := sha256.Sum256([]byte(token))
+	suite.Equal(hex.EncodeToString(sum[:]), *saved.TokenHash)
+
+	reader, err := zip.NewReader(bytes.NewReader(saved.Archive), int64(len(saved.Archive)))
+	suite.Require().NoError(err)
+	files := map[string]string{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		suite.Require().NoError(err)
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	suite.Contains(files["profile.json"], "jane@example.com")
+	suite.NotContains(files["profile.json"], "hashed")
+	suite.Contains(files["posts.json"], "hello")
+	suite.Contains(files["comments.json"], "nice")
+	suite.Contains(files["reactions.json"], `"blog_id": 12`)
+	suite.Contains(files["reactions.json"], `"kind": "dislike"`)
+	suite.NotContains(files["sessions.json"], "secret-token")
+}
+
+func (suite *AccountUsecaseTestSuite) TestProcessPendingExports_MarksFailed() {
+	var saved *domain.DataExport
+	suite.accountRepo.On("ClaimExports", mock.Anything, 15*time.Minute, 10).Return([]domain.DataExport{{ID: 9, UserID: 1, Status: "building"}}, nil)
+	suite.accountRepo.On("CollectPersonalData", int64(1)).Return(domain.PersonalData{}, errors.New("db down"))
+	suite.accountRepo.On("SaveExport", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*domain.DataExport)
+	}).Return(nil)
+
+	suite.Error(suite.usecase.ProcessPendingExports())
+	suite.Require().NotNil(saved)
+	suite.Equal("failed", saved.Status)
+}
+
+func (suite *AccountUsecaseTestSuite) TestProcessPendingExports_ReleasesLease() {
+	var saved *domain.DataExport
+	lockedUntil := time.Now().Add(time.Minute)
+	suite.accountRepo.On("ClaimExports", mock.Anything, 15*time.Minute, 10).Return([]domain.DataExport{{ID: 9, UserID: 1, Status: "building", LockedUntil: &lockedUntil}}, nil)
+	suite.accountRepo.On("CollectPersonalData", int64(1)).Return(domain.PersonalData{User: suite.user}, nil)
+	suite.accountRepo.On("SaveExport", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*domain.DataExport)
+	}).Return(nil)
+	suite.emailService.On("SendTemplate", mock.Anything).Return(nil)
+
+	suite.NoError(suite.usecase.ProcessPendingExports())
+	suite.Require().NotNil(saved)
+	suite.Nil(saved.LockedUntil)
+}
+
+func (suite *AccountUsecaseTestSuite) TestPurgeExpiredExports() {
+	suite.accountRepo.On("PurgeExpiredExports", mock.Anything).Return(int64(3), nil)
+
+	suite.NoError(suite.usecase.PurgeExpiredExports())
+	suite.accountRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AccountUsecaseTestSuite) TestDownloadExport_Expired() {
+	expired := time.Now().Add(-time.Minute)
+	suite.accountRepo.On("FetchExportByTokenHash", mock.Anything).Return(domain.DataExport{Status: "ready", Archive: []byte("zip"), ExpiresAt: &expired}, nil)
+
+	_, err := suite.usecase.DownloadExport("token")
+	suite.Error(err)
+}
+
+func (suite *AccountUsecaseTestSuite) TestDownloadExport_Success() {
+	expires := time.Now().Add(time.Hour)
+	sum := sha256.Sum256([]byte("token"))
+	suite.accountRepo.On("FetchExportByTokenHash", hex.EncodeToString(sum[:])).Return(domain.DataExport{Status: "ready", Archive: []byte("zip"), ExpiresAt: &expires}, nil)
+
+	archive, err := suite.usecase.DownloadExport("token")
+	suite.NoError(err)
+	suite.Equal([]byte("zip"), archive)
+}
+
+func (suite *AccountUsecaseTestSuite) TestRequestDeletion_WrongPassword() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.passwordService.On("ComparePassword", []byte("hashed"), []byte("wrong")).Return(errors.New("mismatch"))
+
+	_, err := suite.usecase.RequestDeletion("1", "wrong")
+	suite.EqualError(err, "invalid credentials")
+	suite.accountRepo.AssertNotCalled(suite.T(), "ScheduleDeletion", mock.Anything)
+}
+
+func (suite *AccountUsecaseTestSuite) TestRequestDeletion_RefusesSuperadmin() {
+	suite.user.Role = domain.RoleSuperadmin
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+
+	_, err := suite.usecase.RequestDeletion("1", "secret")
+	suite.Error(err)
+	suite.passwordService.AssertNotCalled(suite.T(), "ComparePassword", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountUsecaseTestSuite) TestRequestDeletion_SchedulesAndRevokesSessions() {
+	claims := &domain.TokenClaims{UserID: "1"}
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.passwordService.On("ComparePassword", []byte("hashed"), []byte("secret")).Return(nil)
+	suite.accountRepo.On("ScheduleDeletion", mock.MatchedBy(func(d *domain.AccountDeletion) bool {
+		return d.UserID == 1 && d.ScheduledFor.After(time.Now().Add(13*24*time.Hour))
+	})).Return(nil)
+	suite.accountRepo.On("RevokeSessions", int64(1), mock.Anything).Return([]domain.Token{
+		{Type: "access", Content: "access-token"},
+		{Type: "refresh", Content: "refresh-token"},
+	}, nil)
+	suite.jwtService.On("ValidateAccessToken", "Bearer access-token").Return(claims, nil)
+	suite.jwtService.On("RevokeToken", claims).Return(nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.Template == domain.EmailDeletionScheduled && m.To[0] == "jane@example.com"
+	})).Return(nil)
+
+	deletion, err := suite.usecase.RequestDeletion("1", "secret")
+	suite.NoError(err)
+	suite.Equal(int64(1), deletion.UserID)
+	suite.jwtService.AssertExpectations(suite.T())
+	suite.jwtService.AssertNotCalled(suite.T(), "ValidateAccessToken", "Bearer refresh-token")
+}
+
+func (suite *AccountUsecaseTestSuite) TestCancelDeletion_NothingScheduled() {
+	suite.accountRepo.On("CancelDeletion", int64(1)).Return(errors.New("record not found"))
+
+	suite.EqualError(suite.usecase.CancelDeletion("1"), "no deletion is scheduled")
+}
+
+func (suite *AccountUsecaseTestSuite) TestProcessDueDeletions_Anonymize() {
+	suite.accountRepo.On("FetchDueDeletions", mock.Anything).Return([]domain.AccountDeletion{{UserID: 1}}, nil)
+	suite.accountRepo.On("RevokeSessions", int64(1), mock.Anything).Return([]domain.Token{}, nil)
+	suite.accountRepo.On("AnonymizeUser", int64(1)).Return(nil)
+
+	suite.NoError(suite.usecase.ProcessDueDeletions())
+	suite.accountRepo.AssertNotCalled(suite.T(), "PurgeUser", mock.Anything)
+}
+
+func (suite *AccountUsecaseTestSuite) TestProcessDueDeletions_Delete() {
+	suite.config.Policy = domain.DeletionPolicyDelete
+	suite.usecase = suite.newUsecase()
+	suite.accountRepo.On("FetchDueDeletions", mock.Anything).Return([]domain.AccountDeletion{{UserID: 1}, {UserID: 2}}, nil)
+	suite.accountRepo.On("RevokeSessions", mock.Anything, mock.Anything).Return([]domain.Token{}, nil)
+	suite.accountRepo.On("PurgeUser", int64(1)).Return(errors.New("db down"))
+	suite.accountRepo.On("PurgeUser", int64(2)).Return(nil)
+
+	suite.Error(suite.usecase.ProcessDueDeletions())
+	suite.accountRepo.AssertCalled(suite.T(), "PurgeUser", int64(2))
+	suite.accountRepo.AssertNotCalled(suite.T(), "AnonymizeUser", mock.Anything)
+}
+
+func TestAccountUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountUsecaseTestSuite))
+}
@@ -0,0 +1,230 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminUsecaseTestSuite struct {
+	suite.Suite
+	userRepo    *mocks.MockUserRepository
+	adminRepo   *mocks.MockAdminRepository
+	accountRepo *mocks.MockAccountRepository
+	auditRepo   *mocks.MockAuditRepository
+	tokenRepo   *mocks.MockTokenRepository
+	jwtService  *mocks.MockJWTService
+	usecase     domain.IAdminUsecase
+	admin       domain.Principal
+	user        domain.User
+}
+
+func (suite *AdminUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.adminRepo = new(mocks.MockAdminRepository)
+	suite.accountRepo = new(mocks.MockAccountRepository)
+	suite.auditRepo = new(mocks.MockAuditRepository)
+	suite.tokenRepo = new(mocks.MockTokenRepository)
+	suite.jwtService = new(mocks.MockJWTService)
+	suite.usecase = usecases.NewAdminUsecase(suite.userRepo, suite.adminRepo, suite.accountRepo, suite.auditRepo, suite.tokenRepo, suite.jwtService)
+	suite.admin = domain.Principal{UserID: "1", Role: domain.RoleAdmin}
+	suite.user = domain.User{ID: 7, Username: "jane", Email: "jane@example.com", Role: domain.RoleAuthor, Status: "active"}
+}
+
+func (suite *AdminUsecaseTestSuite) auditedAction(action string) {
+	suite.auditRepo.On("Record", mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.ActorID == 1 && e.TargetID == 7 && e.Action == action
+	})).Return(nil).Once()
+}
+
+func (suite *AdminUsecaseTestSuite) TestListUsers_ClampsLimitAndProjects() {
+	suite.adminRepo.On("SearchUsers", domain.UserFilter{Query: "jane", Page: 1, Limit: 100}).
+		Return([]domain.User{{ID: 7, Username: "jane", Password: "hashed", Role: "user"}}, int64(1), nil)
+
+	page, err := suite.usecase.ListUsers(domain.UserFilter{Query: " jane ", Limit: 1000})
+	suite.NoError(err)
+	suite.Equal(int64(1), page.Total)
+	suite.Equal(domain.RoleAuthor, page.Items[0].Role)
+}
+
+func (suite *AdminUsecaseTestSuite) TestListUsers_UnknownRole() {
+	_, err := suite.usecase.ListUsers(domain.UserFilter{Role: "root"})
+	suite.Error(err)
+	suite.adminRepo.AssertNotCalled(suite.T(), "SearchUsers", mock.Anything)
+}
+
+func (suite *AdminUsecaseTestSuite) TestListUsers_UnknownEmailStatus() {
+	_, err := suite.usecase.ListUsers(domain.UserFilter{EmailStatus: "bouncy"})
+	suite.EqualError(err, "unknown email status")
+}
+
+func (suite *AdminUsecaseTestSuite) TestSessions_HidesTokenContent() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.adminRepo.On("FetchSessions", int64(7), 100).Return([]domain.Token{{ID: 3, Type: "refresh", Content: "secret", Status: "active"}}, nil)
+
+	sessions, err := suite.usecase.Sessions("7")
+	suite.NoError(err)
+	suite.Equal([]domain.SessionView{{ID: 3, Type: "refresh", Status: "active"}}, sessions)
+}
+
+func (suite *AdminUsecaseTestSuite) TestSuspend_WithExpiry() {
+	until := time.Now().Add(24 * time.Hour)
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.adminRepo.On("SetStatus", int64(7), "suspended", domain.Suspension{Reason: "spam", Until: &until}).Return(nil)
+	suite.accountRepo.On("RevokeSessions", int64(7), mock.Anything).Return([]domain.Token{}, nil)
+	suite.auditedAction("user.suspend")
+
+	suite.NoError(suite.usecase.Suspend(suite.admin, "7", domain.Suspension{Reason: " spam ", Until: &until}))
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AdminUsecaseTestSuite) TestSuspend_WithoutExpiryBans() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.adminRepo.On("SetStatus", int64(7), "banned", domain.Suspension{Reason: "abuse"}).Return(nil)
+	suite.accountRepo.On("RevokeSessions", int64(7), mock.Anything).Return([]domain.Token{}, nil)
+	suite.auditedAction("user.ban")
+
+	suite.NoError(suite.usecase.Suspend(suite.admin, "7", domain.Suspension{Reason: "abuse"}))
+}
+
+func (suite *AdminUsecaseTestSuite) TestSuspend_RequiresReason() {
+	suite.Error(suite.usecase.Suspend(suite.admin, "7", domain.Suspension{}))
+	suite.userRepo.AssertNotCalled(suite.T(), "Fetch", mock.Anything)
+}
+
+func (suite *AdminUsecaseTestSuite) TestSuspend_Self() {
+	suite.user.ID = 1
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+
+	suite.Error(suite.usecase.Suspend(suite.admin, "1", domain.Suspension{Reason: "oops"}))
+	suite.adminRepo.AssertNotCalled(suite.T(), "SetStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AdminUsecaseTestSuite) TestSuspend_AdminTargetNeedsAssignAdmin() {
+	suite.user.Role = domain.RoleAdmin
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+
+	err := suite.usecase.Suspend(suite.admin, "7", domain.Suspension{Reason: "abuse"})
+	suite.ErrorIs(err, domain.ErrRoleNotAllowed)
+}
+
+func (suite *AdminUsecaseTestSuite) TestForcePasswordReset() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.adminRepo.On("ClearPassword", int64(7)).Return(nil)
+	suite.accountRepo.On("RevokeSessions", int64(7), mock.Anything).Return([]domain.Token{}, nil)
+	suite.auditedAction("user.force_password_reset")
+	suite.jwtService.On("GenerateAccessToken", "7", domain.RoleAuthor).Return("reset_token", nil)
+	suite.tokenRepo.On("SaveWithEmail", mock.AnythingOfType("*domain.Token"), mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.Template == domain.EmailPasswordReset && m.To[0] == "jane@example.com" && strings.Contains(m.Data["Link"].(string), "/password/7/update?token=reset_token")
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.ForcePasswordReset(suite.admin, "7"))
+	suite.tokenRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AdminUsecaseTestSuite) TestImpersonate_AuditsBeforeIssuing() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.auditedAction("user.impersonate")
+	suite.jwtService.On("GenerateImpersonationToken", "7", domain.RoleAuthor, "1").Return("impersonation_token", nil)
+	suite.tokenRepo.On("Save", mock.MatchedBy(func(t *domain.Token) bool {
+		return t.UserID == 7 && t.Content == "impersonation_token" && t.Type == "access"
+	})).Return(nil)
+
+	token, err := suite.usecase.Impersonate(suite.admin, "7", "support ticket 42")
+	suite.NoError(err)
+	suite.Equal("impersonation_token", token)
+}
+
+func (suite *AdminUsecaseTestSuite) TestImpersonate_AuditFailureRefuses() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.auditRepo.On("Record", mock.Anything).Return(errors.New("db down"))
+
+	_, err := suite.usecase.Impersonate(suite.admin, "7", "support ticket 42")
+	suite.Error(err)
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateImpersonationToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AdminUsecaseTestSuite) TestImpersonate_NeverPrivilegedUsers() {
+	suite.admin.Role = domain.RoleSuperadmin
+	suite.user.Role = domain.RoleAdmin
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+
+	_, err := suite.usecase.Impersonate(suite.admin, "7", "curious")
+	suite.ErrorIs(err, domain.ErrRoleNotAllowed)
+}
+
+func (suite *AdminUsecaseTestSuite) TestHardDelete() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.accountRepo.On("RevokeSessions", int64(7), mock.Anything).Return([]domain.Token{}, nil)
+	suite.accountRepo.On("PurgeUser", int64(7)).Return(nil)
+	suite.auditedAction("user.delete")
+
+	suite.NoError(suite.usecase.HardDelete(suite.admin, "7"))
+	suite.accountRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AdminUsecaseTestSuite) TestHardDelete_RecordsNoPersonalData() {
+	var event *domain.AuditEvent
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.accountRepo.On("RevokeSessions", int64(7), mock.Anything).Return([]domain.Token{}, nil)
+	suite.accountRepo.On("PurgeUser", int64(7)).Return(nil)
+	suite.auditRepo.On("Record", mock.Anything).Run(func(args mock.Arguments) {
+		event = args.Get(0).(*domain.AuditEvent)
+	}).Return(nil)
+
+	suite.NoError(suite.usecase.HardDelete(suite.admin, "7"))
+	suite.Require().NotNil(event)
+	suite.Equal(int64(7), event.TargetID)
+	suite.NotContains(event.Metadata, "jane")
+}
+
+func (suite *AdminUsecaseTestSuite) TestHardDelete_AuditFailureRefuses() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.auditRepo.On("Record", mock.Anything).Return(errors.New("db down"))
+
+	suite.Error(suite.usecase.HardDelete(suite.admin, "7"))
+	suite.accountRepo.AssertNotCalled(suite.T(), "PurgeUser", mock.Anything)
+	suite.accountRepo.AssertNotCalled(suite.T(), "RevokeSessions", mock.Anything, mock.Anything)
+}
+
+func (suite *AdminUsecaseTestSuite) TestSuspend_AuditFailureRefuses() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.auditRepo.On("Record", mock.Anything).Return(errors.New("db down"))
+
+	suite.Error(suite.usecase.Suspend(suite.admin, "7", domain.Suspension{Reason: "spam"}))
+	suite.adminRepo.AssertNotCalled(suite.T(), "SetStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AdminUsecaseTestSuite) TestHardDelete_UnknownUser() {
+	suite.userRepo.On("Fetch", "9").Return(domain.User{}, errors.New("record not found"))
+
+	suite.ErrorIs(suite.usecase.HardDelete(suite.admin, "9"), domain.ErrResourceNotFound)
+}
+
+func (suite *AdminUsecaseTestSuite) TestClearEmailStatus() {
+	suite.user.EmailStatus = domain.EmailStatusHardBounced
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+	suite.adminRepo.On("ClearEmailStatus", int64(7)).Return(nil)
+	suite.auditedAction("user.clear_email_status")
+
+	suite.NoError(suite.usecase.ClearEmailStatus(suite.admin, "7"))
+	suite.adminRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AdminUsecaseTestSuite) TestClearEmailStatus_NotSuppressed() {
+	suite.userRepo.On("Fetch", "7").Return(suite.user, nil)
+
+	suite.EqualError(suite.usecase.ClearEmailStatus(suite.admin, "7"), "email address is not suppressed")
+	suite.adminRepo.AssertNotCalled(suite.T(), "ClearEmailStatus", mock.Anything)
+}
+
+func TestAdminUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminUsecaseTestSuite))
+}
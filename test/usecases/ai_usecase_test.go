@@ -0,0 +1,107 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	blogmocks "github.com/blog-platform/mock"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AIUsecaseTestSuite struct {
+	suite.Suite
+	ai       *mocks.MockAIInfrastructure
+	blogRepo *blogmocks.MockBlogRepo
+	limiter  *mocks.MockRateLimiter
+	usecase  *usecases.AIUsecase
+	ctx      context.Context
+}
+
+func (suite *AIUsecaseTestSuite) SetupTest() {
+	suite.ai = new(mocks.MockAIInfrastructure)
+	suite.blogRepo = new(blogmocks.MockBlogRepo)
+	suite.limiter = new(mocks.MockRateLimiter)
+	suite.usecase = usecases.NewAIUsecase(suite.ai, suite.blogRepo, suite.limiter)
+	suite.ctx = context.Background()
+}
+
+func (suite *AIUsecaseTestSuite) TestGenerateDraft_SplitsTitle() {
+	suite.limiter.On("Allow", "1").Return(time.Duration(0), nil)
+	suite.ai.On("Complete", suite.ctx, mock.MatchedBy(func(r domain.AIRequest) bool {
+		return r.Input == "Why Go interfaces are small" && strings.Contains(r.Instructions, "# ")
+	})).Return("# Small interfaces\n\nThey compose.", nil)
+
+	draft, err := suite.usecase.GenerateDraft(suite.ctx, "1", "  Why Go interfaces are small ")
+	suite.NoError(err)
+	suite.Equal(domain.AIDraft{Title: "Small interfaces", Content: "They compose."}, draft)
+}
+
+func (suite *AIUsecaseTestSuite) TestExpandOutline_WithoutTitleKeepsText() {
+	suite.limiter.On("Allow", "1").Return(time.Duration(0), nil)
+	suite.ai.On("Complete", suite.ctx, mock.Anything).Return("Just a post.", nil)
+
+	draft, err := suite.usecase.ExpandOutline(suite.ctx, "1", "- intro\n- body")
+	suite.NoError(err)
+	suite.Equal(domain.AIDraft{Content: "Just a post."}, draft)
+}
+
+func (suite *AIUsecaseTestSuite) TestGenerateDraft_RequiresPrompt() {
+	_, err := suite.usecase.GenerateDraft(suite.ctx, "1", "   ")
+	suite.EqualError(err, "a prompt of at most 2000 characters is required")
+	suite.limiter.AssertNotCalled(suite.T(), "Allow", mock.Anything)
+}
+
+func (suite *AIUsecaseTestSuite) TestImproveParagraph_SendsPostAsContext() {
+	suite.blogRepo.On("FetchByID", suite.ctx, int64(4)).Return(&domain.Blog{ID: 4, Title: "Testing in Go", Content: "Table tests are great."}, nil)
+	suite.limiter.On("Allow", "1").Return(time.Duration(0), nil)
+	suite.ai.On("Complete", suite.ctx, mock.MatchedBy(func(r domain.AIRequest) bool {
+		return strings.Contains(r.Input, "Post title: Testing in Go") && strings.Contains(r.Input, "Table tests are great.") &&
+			strings.Contains(r.Input, "What the author wants: shorter") && strings.HasSuffix(r.Input, "Paragraph to rewrite:\nTests are good because they test.")
+	})).Return("Tests catch bugs.", nil)
+
+	text, err := suite.usecase.ImproveParagraph(suite.ctx, "1", 4, "Tests are good because they test.", "shorter")
+	suite.NoError(err)
+	suite.Equal("Tests catch bugs.", text)
+}
+
+func (suite *AIUsecaseTestSuite) TestImproveParagraph_UnknownBlog() {
+	suite.blogRepo.On("FetchByID", suite.ctx, int64(9)).Return(nil, errors.New("record not found"))
+
+	_, err := suite.usecase.ImproveParagraph(suite.ctx, "1", 9, "Some text.", "")
+	suite.ErrorIs(err, domain.ErrResourceNotFound)
+}
+
+func (suite *AIUsecaseTestSuite) TestRateLimited() {
+	suite.limiter.On("Allow", "1").Return(time.Minute, nil)
+
+	_, err := suite.usecase.GenerateDraft(suite.ctx, "1", "anything")
+	var limitedErr *domain.RateLimitedError
+	suite.ErrorAs(err, &limitedErr)
+	suite.ai.AssertNotCalled(suite.T(), "Complete", mock.Anything, mock.Anything)
+}
+
+func (suite *AIUsecaseTestSuite) TestProviderFailure() {
+	suite.limiter.On("Allow", "1").Return(time.Duration(0), nil)
+	suite.ai.On("Complete", suite.ctx, mock.Anything).Return("", errors.New("ai provider answered 500"))
+
+	_, err := suite.usecase.GenerateDraft(suite.ctx, "1", "anything")
+	suite.ErrorIs(err, domain.ErrAIProviderFailed)
+}
+
+func (suite *AIUsecaseTestSuite) TestDisabled() {
+	usecase := usecases.NewAIUsecase(nil, suite.blogRepo, suite.limiter)
+
+	_, err := usecase.GenerateDraft(suite.ctx, "1", "anything")
+	suite.ErrorIs(err, domain.ErrAIUnavailable)
+}
+
+func TestAIUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AIUsecaseTestSuite))
+}
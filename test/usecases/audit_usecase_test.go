@@ -0,0 +1,76 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditUsecaseTestSuite struct {
+	suite.Suite
+	auditRepo *mocks.MockAuditRepository
+	usecase   domain.IAuditUsecase
+}
+
+func (suite *AuditUsecaseTestSuite) SetupTest() {
+	suite.auditRepo = new(mocks.MockAuditRepository)
+	suite.usecase = usecases.NewAuditUsecase(suite.auditRepo)
+}
+
+func chain(n int) []domain.AuditEvent {
+	events := make([]domain.AuditEvent, 0, n)
+	prev := ""
+	for i := 1; i <= n; i++ {
+		event := domain.AuditEvent{ID: int64(i), ActorID: 1, Action: "auth.login", Outcome: domain.AuditOutcomeSuccess, Metadata: "{}", PrevHash: prev, CreatedAt: time.Now()}
+		event.Hash = event.ComputeHash()
+		prev = event.Hash
+		events = append(events, event)
+	}
+	return events
+}
+
+func (suite *AuditUsecaseTestSuite) TestVerify_IntactChain() {
+	suite.auditRepo.On("FetchAfter", int64(0), 500).Return(chain(3), nil)
+
+	result, err := suite.usecase.Verify()
+	suite.NoError(err)
+	suite.True(result.Valid)
+	suite.Equal(int64(3), result.Checked)
+}
+
+func (suite *AuditUsecaseTestSuite) TestVerify_DetectsEditedEvent() {
+	events := chain(3)
+	events[1].Outcome = domain.AuditOutcomeFailure
+	suite.auditRepo.On("FetchAfter", int64(0), 500).Return(events, nil)
+
+	result, err := suite.usecase.Verify()
+	suite.NoError(err)
+	suite.False(result.Valid)
+	suite.Equal(int64(2), result.BrokenAt)
+}
+
+func (suite *AuditUsecaseTestSuite) TestVerify_DetectsRemovedEvent() {
+	events := chain(3)
+	suite.auditRepo.On("FetchAfter", int64(0), 500).Return([]domain.AuditEvent{events[0], events[2]}, nil)
+
+	result, err := suite.usecase.Verify()
+	suite.NoError(err)
+	suite.False(result.Valid)
+	suite.Equal(int64(3), result.BrokenAt)
+}
+
+func (suite *AuditUsecaseTestSuite) TestSearch_ClampsLimit() {
+	suite.auditRepo.On("Search", domain.AuditFilter{Action: "auth.login", Page: 1, Limit: 500}).Return([]domain.AuditEvent{}, int64(0), nil)
+
+	page, err := suite.usecase.Search(domain.AuditFilter{Action: "auth.login", Limit: 10000})
+	suite.NoError(err)
+	suite.Equal(500, page.Limit)
+}
+
+func TestAuditUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditUsecaseTestSuite))
+}
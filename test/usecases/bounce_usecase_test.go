@@ -0,0 +1,61 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type BounceUsecaseTestSuite struct {
+	suite.Suite
+	parser  *mocks.MockBounceParser
+	repo    *mocks.MockDeliverabilityRepository
+	usecase *usecases.BounceUsecase
+}
+
+func (suite *BounceUsecaseTestSuite) SetupTest() {
+	suite.parser = new(mocks.MockBounceParser)
+	suite.repo = new(mocks.MockDeliverabilityRepository)
+	suite.usecase = usecases.NewBounceUsecase(suite.parser, suite.repo)
+}
+
+func (suite *BounceUsecaseTestSuite) TestIngest_MarksHardBouncesAndComplaints() {
+	payload := []byte("{}")
+	suite.parser.On("Parse", domain.BounceSourceSendGrid, payload).Return([]domain.BounceEvent{
+		{Email: "Jane@Example.com", Type: domain.BounceHard, Reason: "550 no such user"},
+		{Email: "jane@example.com", Type: domain.BounceComplaint, Reason: "spam report"},
+		{Email: "sam@example.com", Type: domain.BounceSoft, Reason: "mailbox full"},
+		{Email: "gone@example.com", Type: domain.BounceHard, Reason: "550 no such user"},
+	}, nil)
+	suite.repo.On("MarkAddress", "jane@example.com", domain.EmailStatusComplained, "spam report", mock.Anything).Return(int64(1), nil)
+	suite.repo.On("MarkAddress", "gone@example.com", domain.EmailStatusHardBounced, "550 no such user", mock.Anything).Return(int64(0), nil)
+
+	report, err := suite.usecase.Ingest(domain.BounceSourceSendGrid, payload)
+	suite.NoError(err)
+	suite.Equal(domain.BounceReport{Received: 4, Suppressed: 1}, report)
+	suite.repo.AssertNumberOfCalls(suite.T(), "MarkAddress", 2)
+}
+
+func (suite *BounceUsecaseTestSuite) TestIngest_UnknownSource() {
+	suite.parser.On("Parse", "carrier-pigeon", mock.Anything).Return(nil, domain.ErrUnknownBounceSource)
+
+	_, err := suite.usecase.Ingest("carrier-pigeon", nil)
+	suite.ErrorIs(err, domain.ErrUnknownBounceSource)
+}
+
+func (suite *BounceUsecaseTestSuite) TestIngest_RepositoryError() {
+	suite.parser.On("Parse", domain.BounceSourceDSN, mock.Anything).Return([]domain.BounceEvent{{Email: "jane@example.com", Type: domain.BounceHard}}, nil)
+	suite.repo.On("MarkAddress", "jane@example.com", domain.EmailStatusHardBounced, "", mock.Anything).Return(int64(0), errors.New("db error"))
+
+	_, err := suite.usecase.Ingest(domain.BounceSourceDSN, nil)
+	suite.EqualError(err, "unable to record bounces")
+}
+
+func TestBounceUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(BounceUsecaseTestSuite))
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommentUsecaseTestSuite struct {
+	suite.Suite
+	userRepo    *mocks.MockUserRepository
+	commentRepo *mocks.MockCommentRepository
+	owners      *mocks.MockOwnershipRepository
+	notifier    *mocks.MockNotifier
+	usecase     domain.ICommentUsecase
+}
+
+func (suite *CommentUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.commentRepo = new(mocks.MockCommentRepository)
+	suite.owners = new(mocks.MockOwnershipRepository)
+	suite.notifier = new(mocks.MockNotifier)
+	suite.usecase = usecases.NewCommentUsecase(suite.userRepo, suite.commentRepo, suite.owners, usecases.WithCommentNotifications(suite.notifier))
+}
+
+func (suite *CommentUsecaseTestSuite) TestCreate_NotifiesAuthor() {
+	suite.owners.On("BlogOwner", int64(7)).Return(int64(2), nil)
+	suite.commentRepo.On("Create", mock.MatchedBy(func(c *domain.Comment) bool {
+		return c.UserID == 1 && c.BlogID == 7 && c.Content == "nice post"
+	})).Return(nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Username: "sam"}, nil)
+	suite.notifier.On("Notify", mock.MatchedBy(func(e domain.NotificationEvent) bool {
+		return e.Type == domain.NotificationComment && e.UserID == 2 && e.ActorID == 1 && e.Message == "sam commented: nice post"
+	})).Return(errors.New("database down"))
+
+	// a failed notification does not undo the comment
+	comment, err := suite.usecase.Create("1", "7", "  nice post ")
+	suite.NoError(err)
+	suite.Equal("nice post", comment.Content)
+	suite.notifier.AssertExpectations(suite.T())
+}
+
+func (suite *CommentUsecaseTestSuite) TestCreate_Validates() {
+	_, err := suite.usecase.Create("1", "7", "   ")
+	suite.Error(err)
+	_, err = suite.usecase.Create("1", "7", strings.Repeat("a", 5001))
+	suite.Error(err)
+	suite.commentRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *CommentUsecaseTestSuite) TestCreate_PostNotFound() {
+	suite.owners.On("BlogOwner", int64(7)).Return(int64(0), domain.ErrResourceNotFound)
+
+	_, err := suite.usecase.Create("1", "7", "hello")
+	suite.ErrorIs(err, domain.ErrResourceNotFound)
+	suite.commentRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func TestCommentUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentUsecaseTestSuite))
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DigestUsecaseTestSuite struct {
+	suite.Suite
+	subscriptionRepo *mocks.MockSubscriptionRepository
+	userRepo         *mocks.MockUserRepository
+	emailService     *mocks.MockEmailService
+	usecase          *usecases.DigestUsecase
+}
+
+func (suite *DigestUsecaseTestSuite) SetupTest() {
+	suite.subscriptionRepo = new(mocks.MockSubscriptionRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.emailService = new(mocks.MockEmailService)
+	suite.usecase = usecases.NewDigestUsecase(suite.subscriptionRepo, suite.userRepo, suite.emailService, domain.DigestConfig{Period: 7 * 24 * time.Hour, MaxPosts: 5, ConfirmTTL: time.Hour, UnsubscribeSecret: []byte("secret")})
+}
+
+func (suite *DigestUsecaseTestSuite) TestSubscribe_ActivatesWithTags() {
+	suite.subscriptionRepo.On("FetchByUserID", int64(1)).Return(domain.Subscription{}, errors.New("record not found"))
+	suite.subscriptionRepo.On("Save", mock.MatchedBy(func(s *domain.Subscription) bool {
+		return *s.UserID == 1 && s.Status == domain.SubscriptionStatusActive
+	})).Return(nil)
+
+	subscription, err := suite.usecase.Subscribe("1", []string{" go ", "go", "", "testing"})
+	suite.NoError(err)
+	suite.Equal([]string{"go", "testing"}, subscription.Tags)
+}
+
+func (suite *DigestUsecaseTestSuite) TestSubscribe_TooManyTags() {
+	tags := make([]string, 21)
+	for i := range tags {
+		tags[i] = strings.Repeat("t", i+1)
+	}
+
+	_, err := suite.usecase.Subscribe("1", tags)
+	suite.EqualError(err, "at most 20 tags can be followed")
+}
+
+func (suite *DigestUsecaseTestSuite) TestSubscribeEmail_SendsConfirmation() {
+	suite.subscriptionRepo.On("FetchByEmail", "jane@example.com").Return(domain.Subscription{}, errors.New("record not found"))
+	suite.subscriptionRepo.On("Save", mock.MatchedBy(func(s *domain.Subscription) bool {
+		return *s.Email == "jane@example.com" && s.Status == domain.SubscriptionStatusPending && s.ConfirmTokenHash != nil && s.ConfirmExpiresAt != nil
+	})).Return(nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.To[0] == "jane@example.com" && m.Template == domain.EmailNewsletterConfirm &&
+			strings.Contains(m.Data["Link"].(string), "/newsletter/confirm?token=")
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.SubscribeEmail("Jane@Example.com", []string{"go"}))
+	suite.emailService.AssertExpectations(suite.T())
+}
+
+func (suite *DigestUsecaseTestSuite) TestSubscribeEmail_SilentForSubscribedOrRecentlyMailed() {
+	email := "jane@example.com"
+	suite.subscriptionRepo.On("FetchByEmail", email).Return(domain.Subscription{Email: &email, Status: domain.SubscriptionStatusActive}, nil).Once()
+	suite.NoError(suite.usecase.SubscribeEmail(email, nil))
+
+	expiresAt := time.Now().Add(time.Minute)
+	suite.subscriptionRepo.On("FetchByEmail", email).Return(domain.Subscription{Email: &email, Status: domain.SubscriptionStatusPending, ConfirmExpiresAt: &expiresAt}, nil).Once()
+	suite.NoError(suite.usecase.SubscribeEmail(email, nil))
+
+	suite.subscriptionRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+	suite.emailService.AssertNotCalled(suite.T(), "SendTemplate", mock.Anything)
+}
+
+func (suite *DigestUsecaseTestSuite) TestSubscribeEmail_InvalidAddress() {
+	suite.EqualError(suite.usecase.SubscribeEmail("Jane <jane@example.com>", nil), "invalid email address")
+	suite.EqualError(suite.usecase.SubscribeEmail("not an address", nil), "invalid email address")
+}
+
+func (suite *DigestUsecaseTestSuite) TestConfirm_InvalidToken() {
+	suite.subscriptionRepo.On("Confirm", mock.Anything, mock.Anything).Return(domain.ErrSubscriptionTokenInvalid)
+
+	suite.ErrorIs(suite.usecase.Confirm("token"), domain.ErrSubscriptionTokenInvalid)
+}
+
+func (suite *DigestUsecaseTestSuite) TestUnsubscribeByToken_LooksUpHash() {
+	sum := sha256.Sum256([]byte("token"))
+	suite.subscriptionRepo.On("Unsubscribe", hex.EncodeToString(sum[:])).Return(nil)
+
+	suite.NoError(suite.usecase.UnsubscribeByToken("token"))
+}
+
+func (suite *DigestUsecaseTestSuite) TestSendDigests() {
+	userID := int64(1)
+	email := "reader@example.com"
+	suite.subscriptionRepo.On("FetchDue", mock.Anything, int64(0), 200).Return([]domain.Subscription{
+		{ID: 1, UserID: &userID, Tags: []string{"go"}},
+		{ID: 2, Email: &email},
+	}, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Email: "jane@example.com", Locale: "fr", Status: "active"}, nil)
+	suite.subscriptionRepo.On("TopPosts", &userID, []string{"go"}, mock.Anything, 5).Return([]domain.DigestPost{
+		{ID: 7, Title: "Getting started with Go", Content: strings.Repeat("word ", 100), Author: "sam"},
+	}, nil)
+	suite.subscriptionRepo.On("TopPosts", (*int64)(nil), []string(nil), mock.Anything, 5).Return([]domain.DigestPost{}, nil)
+	var link string
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		link, _ = m.Data["UnsubscribeLink"].(string)
+		items := m.Data["Items"].([]map[string]interface{})
+		return m.To[0] == "jane@example.com" && m.Locale == "fr" && m.Template == domain.EmailDigest &&
+			len(items) == 1 && strings.HasSuffix(items[0]["Link"].(string), "/blogs/7") &&
+			strings.HasPrefix(items[0]["Summary"].(string), "by sam — word") && strings.HasSuffix(items[0]["Summary"].(string), "…") &&
+			m.Headers["List-Unsubscribe"] == "<"+m.Data["UnsubscribeLink"].(string)+">" &&
+			strings.Contains(link, "/newsletter/unsubscribe?token=") &&
+			m.Headers["List-Unsubscribe-Post"] == "List-Unsubscribe=One-Click"
+	})).Return(nil)
+	suite.subscriptionRepo.On("MarkSent", int64(1), mock.Anything, mock.Anything).Return(nil)
+	suite.subscriptionRepo.On("MarkSent", int64(2), mock.Anything, mock.Anything).Return(nil)
+
+	suite.NoError(suite.usecase.SendDigests())
+	// only the hash of the token in the link is stored
+	token := link[strings.Index(link, "token=")+len("token="):]
+	sum := sha256.Sum256([]byte(token))
+	suite.subscriptionRepo.AssertCalled(suite.T(), "MarkSent", int64(1), mock.Anything, hex.EncodeToString(sum[:]))
+	// the email-only subscriber had nothing new and gets no email
+	suite.emailService.AssertNumberOfCalls(suite.T(), "SendTemplate", 1)
+	suite.subscriptionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *DigestUsecaseTestSuite) TestSendDigests_SameUnsubscribeLinkEveryTime() {
+	email := "reader@example.com"
+	suite.subscriptionRepo.On("FetchDue", mock.Anything, int64(0), 200).Return([]domain.Subscription{{ID: 2, Email: &email}}, nil)
+	suite.subscriptionRepo.On("TopPosts", (*int64)(nil), []string(nil), mock.Anything, 5).Return([]domain.DigestPost{{ID: 7, Title: "Go"}}, nil)
+	var links []string
+	suite.emailService.On("SendTemplate", mock.Anything).Run(func(args mock.Arguments) {
+		links = append(links, args.Get(0).(domain.EmailMessage).Data["UnsubscribeLink"].(string))
+	}).Return(nil)
+	suite.subscriptionRepo.On("MarkSent", int64(2), mock.Anything, mock.Anything).Return(nil)
+
+	suite.NoError(suite.usecase.SendDigests())
+	suite.NoError(suite.usecase.SendDigests())
+	suite.Require().Len(links, 2)
+	suite.Equal(links[0], links[1])
+}
+
+func (suite *DigestUsecaseTestSuite) TestSendDigests_FailedEmailStaysDue() {
+	email := "reader@example.com"
+	suite.subscriptionRepo.On("FetchDue", mock.Anything, int64(0), 200).Return([]domain.Subscription{{ID: 2, Email: &email}}, nil)
+	suite.subscriptionRepo.On("TopPosts", (*int64)(nil), []string(nil), mock.Anything, 5).Return([]domain.DigestPost{{ID: 7, Title: "Go"}}, nil)
+	suite.emailService.On("SendTemplate", mock.Anything).Return(errors.New("outbox down"))
+
+	suite.NoError(suite.usecase.SendDigests())
+	suite.subscriptionRepo.AssertNotCalled(suite.T(), "MarkSent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDigestUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(DigestUsecaseTestSuite))
+}
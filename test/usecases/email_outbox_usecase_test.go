@@ -0,0 +1,181 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmailOutboxUsecaseTestSuite struct {
+	suite.Suite
+	outboxRepo *mocks.MockEmailOutboxRepository
+	mailer     *mocks.MockEmailService
+	usecase    *usecases.EmailOutboxUsecase
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) SetupTest() {
+	suite.outboxRepo = new(mocks.MockEmailOutboxRepository)
+	suite.mailer = new(mocks.MockEmailService)
+	suite.usecase = usecases.NewEmailOutboxUsecase(suite.outboxRepo, suite.mailer, domain.EmailOutboxConfig{
+		Workers:     2,
+		BatchSize:   2,
+		MaxAttempts: 3,
+		RetryBase:   30 * time.Second,
+		RetryMax:    time.Hour,
+		Lease:       5 * time.Minute,
+		Retention:   24 * time.Hour,
+	})
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestSendTemplate_Enqueues() {
+	message := domain.EmailMessage{To: []string{"jane@example.com"}, Template: domain.EmailActivation, Locale: "fr", Data: map[string]interface{}{"Link": "x"}}
+	suite.outboxRepo.On("Enqueue", mock.MatchedBy(func(e *domain.OutboxEmail) bool {
+		return e.Status == domain.OutboxStatusPending && e.Template == domain.EmailActivation && e.Locale == "fr" &&
+			e.To[0] == "jane@example.com" && !e.NextAttemptAt.After(time.Now())
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.SendTemplate(message))
+	suite.mailer.AssertNotCalled(suite.T(), "SendTemplate", mock.Anything)
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestSendEmail_EnqueuesPlainMessage() {
+	suite.outboxRepo.On("Enqueue", mock.MatchedBy(func(e *domain.OutboxEmail) bool {
+		return e.Template == "" && e.Subject == "Hello" && e.Body == "Hi there" && e.Status == domain.OutboxStatusPending
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.SendEmail([]string{"jane@example.com"}, "Hello", "Hi there"))
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestProcessOutbox_DeliversAndMarksSent() {
+	emails := []domain.OutboxEmail{
+		{ID: 1, To: []string{"a@example.com"}, Template: domain.EmailActivation, Data: map[string]interface{}{"Link": "x"}, Attempts: 1},
+		{ID: 2, To: []string{"b@example.com"}, Subject: "Hello", Body: "Hi", Attempts: 1},
+	}
+	suite.outboxRepo.On("Claim", mock.Anything, 5*time.Minute, 2).Return(emails, nil).Once()
+	suite.outboxRepo.On("Claim", mock.Anything, 5*time.Minute, 2).Return([]domain.OutboxEmail{}, nil).Once()
+	suite.mailer.On("SendTemplate", emails[0].Message()).Return(nil)
+	suite.mailer.On("SendEmail", []string{"b@example.com"}, "Hello", "Hi").Return(nil)
+	suite.outboxRepo.On("MarkSent", int64(1), mock.Anything).Return(nil)
+	suite.outboxRepo.On("MarkSent", int64(2), mock.Anything).Return(nil)
+
+	suite.NoError(suite.usecase.ProcessOutbox())
+	suite.outboxRepo.AssertExpectations(suite.T())
+	suite.mailer.AssertExpectations(suite.T())
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestProcessOutbox_SchedulesRetryWithBackoff() {
+	email := domain.OutboxEmail{ID: 1, To: []string{"a@example.com"}, Subject: "Hello", Body: "Hi", Attempts: 2}
+	suite.outboxRepo.On("Claim", mock.Anything, 5*time.Minute, 2).Return([]domain.OutboxEmail{email}, nil)
+	suite.mailer.On("SendEmail", email.To, "Hello", "Hi").Return(errors.New("421 try again later"))
+	start := time.Now()
+	suite.outboxRepo.On("MarkFailed", int64(1), "421 try again later", mock.MatchedBy(func(next time.Time) bool {
+		// second attempt: 60s plus at most a tenth of jitter
+		return !next.Before(start.Add(time.Minute)) && next.Before(time.Now().Add(66*time.Second+time.Millisecond))
+	}), false).Return(nil)
+
+	suite.NoError(suite.usecase.ProcessOutbox())
+	suite.outboxRepo.AssertExpectations(suite.T())
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestProcessOutbox_DeadLettersAfterMaxAttempts() {
+	email := domain.OutboxEmail{ID: 1, To: []string{"a@example.com"}, Subject: "Hello", Body: "Hi", Attempts: 3}
+	suite.outboxRepo.On("Claim", mock.Anything, 5*time.Minute, 2).Return([]domain.OutboxEmail{email}, nil)
+	suite.mailer.On("SendEmail", email.To, "Hello", "Hi").Return(errors.New("550 mailbox unavailable"))
+	suite.outboxRepo.On("MarkFailed", int64(1), "550 mailbox unavailable", mock.Anything, true).Return(nil)
+
+	suite.NoError(suite.usecase.ProcessOutbox())
+	suite.outboxRepo.AssertExpectations(suite.T())
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestProcessOutbox_MissingTemplateIsDeadRightAway() {
+	email := domain.OutboxEmail{ID: 1, To: []string{"a@example.com"}, Template: "gone", Attempts: 1}
+	suite.outboxRepo.On("Claim", mock.Anything, 5*time.Minute, 2).Return([]domain.OutboxEmail{email}, nil)
+	suite.mailer.On("SendTemplate", email.Message()).Return(domain.ErrEmailTemplateNotFound)
+	suite.outboxRepo.On("MarkFailed", int64(1), domain.ErrEmailTemplateNotFound.Error(), mock.Anything, true).Return(nil)
+
+	suite.NoError(suite.usecase.ProcessOutbox())
+	suite.outboxRepo.AssertExpectations(suite.T())
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestProcessOutbox_SkipsSuppressedRecipients() {
+	suppression := new(mocks.MockDeliverabilityRepository)
+	usecase := usecases.NewEmailOutboxUsecase(suite.outboxRepo, suite.mailer, domain.EmailOutboxConfig{Workers: 1, BatchSize: 2, MaxAttempts: 3}, usecases.WithSuppressionList(suppression))
+	emails := []domain.OutboxEmail{
+		{ID: 1, To: []string{"Gone@example.com", "b@example.com"}, Subject: "Hello", Body: "Hi", Attempts: 1},
+		{ID: 2, To: []string{"gone@example.com"}, Subject: "Hello", Body: "Hi", Attempts: 1},
+	}
+	suite.outboxRepo.On("Claim", mock.Anything, time.Duration(0), 2).Return(emails, nil).Once()
+	suite.outboxRepo.On("Claim", mock.Anything, time.Duration(0), 2).Return([]domain.OutboxEmail{}, nil).Once()
+	suppression.On("Suppressed", emails[0].To).Return([]string{"gone@example.com"}, nil)
+	suppression.On("Suppressed", emails[1].To).Return([]string{"gone@example.com"}, nil)
+	suite.mailer.On("SendEmail", []string{"b@example.com"}, "Hello", "Hi").Return(nil)
+	suite.outboxRepo.On("MarkSent", int64(1), mock.Anything).Return(nil)
+	suite.outboxRepo.On("MarkFailed", int64(2), "all recipients are suppressed", mock.Anything, true).Return(nil)
+
+	suite.NoError(usecase.ProcessOutbox())
+	suite.outboxRepo.AssertExpectations(suite.T())
+	suite.mailer.AssertNumberOfCalls(suite.T(), "SendEmail", 1)
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestProcessOutbox_ClaimError() {
+	suite.outboxRepo.On("Claim", mock.Anything, 5*time.Minute, 2).Return(nil, errors.New("db error"))
+
+	suite.EqualError(suite.usecase.ProcessOutbox(), "unable to claim queued emails")
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestPurgeSent() {
+	suite.outboxRepo.On("PurgeSent", mock.MatchedBy(func(before time.Time) bool {
+		return time.Until(before) < -23*time.Hour
+	})).Return(int64(4), nil)
+
+	suite.NoError(suite.usecase.PurgeSent())
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestSearch_ClampsLimit() {
+	suite.outboxRepo.On("Search", domain.OutboxFilter{Status: domain.OutboxStatusDead, Page: 1, Limit: 500}).Return(nil, int64(0), nil)
+
+	page, err := suite.usecase.Search(domain.OutboxFilter{Status: domain.OutboxStatusDead, Limit: 10000})
+	suite.NoError(err)
+	suite.Equal(500, page.Limit)
+	suite.NotNil(page.Items)
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestSearch_UnknownStatus() {
+	_, err := suite.usecase.Search(domain.OutboxFilter{Status: "lost"})
+	suite.EqualError(err, "unknown status")
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestRetry() {
+	suite.outboxRepo.On("Fetch", int64(1)).Return(domain.OutboxEmail{ID: 1, Status: domain.OutboxStatusDead}, nil).Once()
+	suite.outboxRepo.On("Retry", int64(1), mock.Anything).Return(nil)
+	suite.outboxRepo.On("Fetch", int64(1)).Return(domain.OutboxEmail{ID: 1, Status: domain.OutboxStatusPending}, nil).Once()
+
+	email, err := suite.usecase.Retry(1)
+	suite.NoError(err)
+	suite.Equal(domain.OutboxStatusPending, email.Status)
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestRetry_NotFound() {
+	suite.outboxRepo.On("Fetch", int64(9)).Return(domain.OutboxEmail{}, errors.New("record not found"))
+
+	_, err := suite.usecase.Retry(9)
+	suite.ErrorIs(err, domain.ErrResourceNotFound)
+}
+
+func (suite *EmailOutboxUsecaseTestSuite) TestRetry_AlreadySent() {
+	suite.outboxRepo.On("Fetch", int64(1)).Return(domain.OutboxEmail{ID: 1, Status: domain.OutboxStatusSent}, nil)
+	suite.outboxRepo.On("Retry", int64(1), mock.Anything).Return(domain.ErrOutboxEmailNotRetryable)
+
+	_, err := suite.usecase.Retry(1)
+	suite.ErrorIs(err, domain.ErrOutboxEmailNotRetryable)
+}
+
+func TestEmailOutboxUsecase(t *testing.T) {
+	suite.Run(t, new(EmailOutboxUsecaseTestSuite))
+}
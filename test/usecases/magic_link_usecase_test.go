@@ -0,0 +1,145 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MagicLinkUsecaseTestSuite struct {
+	suite.Suite
+	userRepo      *mocks.MockUserRepository
+	emailService  *mocks.MockEmailService
+	jwtService    *mocks.MockJWTService
+	tokenRepo     *mocks.MockTokenRepository
+	twoFactorRepo *mocks.MockTwoFactorRepository
+	limiter       *mocks.MockRateLimiter
+	auditRepo     *mocks.MockAuditRepository
+	usecase       domain.IMagicLinkUsecase
+}
+
+func (suite *MagicLinkUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.emailService = new(mocks.MockEmailService)
+	suite.jwtService = new(mocks.MockJWTService)
+	suite.tokenRepo = new(mocks.MockTokenRepository)
+	suite.twoFactorRepo = new(mocks.MockTwoFactorRepository)
+	suite.limiter = new(mocks.MockRateLimiter)
+	suite.auditRepo = new(mocks.MockAuditRepository)
+	suite.auditRepo.On("Record", mock.Anything).Return(nil).Maybe()
+	suite.usecase = usecases.NewMagicLinkUsecase(suite.userRepo, suite.emailService, suite.jwtService, suite.tokenRepo, suite.twoFactorRepo, suite.limiter, suite.auditRepo)
+	os.Setenv("PROTOCOL", "http")
+	os.Setenv("DOMAIN", "localhost")
+	os.Setenv("PORT", "8080")
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestRequestLink_SendsConfirmLink() {
+	suite.limiter.On("Allow", "jane@example.com").Return(time.Duration(0), nil)
+	suite.userRepo.On("FetchByEmail", "Jane@example.com").Return(domain.User{ID: 1, Email: "Jane@example.com", Role: "user"}, nil)
+	suite.jwtService.On("GenerateMagicLinkToken", "1", "user").Return("link.token", nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.Template == domain.EmailMagicLink && m.To[0] == "Jane@example.com" && m.Data["Link"] == "http://localhost:8080/login/magic-link/confirm?token=link.token"
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.RequestLink(" Jane@example.com "))
+	suite.emailService.AssertExpectations(suite.T())
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestRequestLink_UnknownEmailIsSilent() {
+	suite.limiter.On("Allow", "ghost@example.com").Return(time.Duration(0), nil)
+	suite.userRepo.On("FetchByEmail", "ghost@example.com").Return(domain.User{}, errors.New("not found"))
+
+	suite.NoError(suite.usecase.RequestLink("ghost@example.com"))
+	suite.emailService.AssertNotCalled(suite.T(), "SendTemplate", mock.Anything)
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestRequestLink_RateLimited() {
+	suite.limiter.On("Allow", "jane@example.com").Return(5*time.Minute, nil)
+
+	err := suite.usecase.RequestLink("jane@example.com")
+	var limitedErr *domain.RateLimitedError
+	suite.Require().ErrorAs(err, &limitedErr)
+	suite.Equal(5*time.Minute, limitedErr.RetryAfter)
+	suite.userRepo.AssertNotCalled(suite.T(), "FetchByEmail", mock.Anything)
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestVerify_IssuesTokensAndRevokesLink() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user", TokenType: "magic_link"}
+	user := domain.User{ID: 1, Role: "user", Status: "active"}
+	suite.jwtService.On("ValidateMagicLinkToken", "link.token").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(user, nil)
+	suite.jwtService.On("ConsumeToken", claims).Return(nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{}, nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+
+	access, refresh, err := suite.usecase.Verify("link.token", domain.ClientInfo{IP: "10.0.0.1", UserAgent: "curl/8"})
+	suite.NoError(err)
+	suite.Equal("access_token", access)
+	suite.Equal("refresh_token", refresh)
+	suite.jwtService.AssertCalled(suite.T(), "ConsumeToken", claims)
+	suite.auditRepo.AssertCalled(suite.T(), "Record", mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == "auth.login" && e.Outcome == domain.AuditOutcomeSuccess && e.TargetID == 1 &&
+			strings.Contains(e.Metadata, `"method":"magic_link"`) && e.IP == "10.0.0.1" && e.UserAgent == "curl/8"
+	}))
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestVerify_RefusesWhenLinkCannotBeRevoked() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user", TokenType: "magic_link"}
+	suite.jwtService.On("ValidateMagicLinkToken", "link.token").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user"}, nil)
+	suite.jwtService.On("ConsumeToken", claims).Return(errors.New("token revocation is not configured"))
+
+	_, _, err := suite.usecase.Verify("link.token", domain.ClientInfo{})
+	suite.EqualError(err, "unable to redeem link")
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateAccessToken", "1", "user")
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestVerify_LinkRedeemedConcurrently() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user", TokenType: "magic_link"}
+	suite.jwtService.On("ValidateMagicLinkToken", "link.token").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "active"}, nil)
+	suite.jwtService.On("ConsumeToken", claims).Return(domain.ErrTokenUsed)
+
+	_, _, err := suite.usecase.Verify("link.token", domain.ClientInfo{})
+	suite.EqualError(err, "invalid or expired link")
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateAccessToken", "1", "user")
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestVerify_UsedLink() {
+	suite.jwtService.On("ValidateMagicLinkToken", "link.token").Return(nil, errors.New("revoked token"))
+
+	_, _, err := suite.usecase.Verify("link.token", domain.ClientInfo{IP: "10.0.0.1"})
+	suite.EqualError(err, "invalid or expired link")
+	suite.auditRepo.AssertCalled(suite.T(), "Record", mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == "auth.login" && e.Outcome == domain.AuditOutcomeFailure && e.IP == "10.0.0.1"
+	}))
+}
+
+func (suite *MagicLinkUsecaseTestSuite) TestVerify_ActivatesPendingAccount() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user", TokenType: "magic_link"}
+	suite.jwtService.On("ValidateMagicLinkToken", "link.token").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "inactive"}, nil)
+	suite.jwtService.On("ConsumeToken", claims).Return(nil)
+	suite.userRepo.On("ActivateAccount", "1").Return(nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{Enabled: true}, nil)
+	suite.jwtService.On("GenerateChallengeToken", "1", "user").Return("challenge", nil)
+
+	_, _, err := suite.usecase.Verify("link.token", domain.ClientInfo{})
+	var mfaErr *domain.MFARequiredError
+	suite.ErrorAs(err, &mfaErr)
+	suite.userRepo.AssertCalled(suite.T(), "ActivateAccount", "1")
+}
+
+func TestMagicLinkUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(MagicLinkUsecaseTestSuite))
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationUsecaseTestSuite struct {
+	suite.Suite
+	notificationRepo *mocks.MockNotificationRepository
+	userRepo         *mocks.MockUserRepository
+	emailService     *mocks.MockEmailService
+	usecase          *usecases.NotificationUsecase
+	event            domain.NotificationEvent
+}
+
+func (suite *NotificationUsecaseTestSuite) SetupTest() {
+	suite.notificationRepo = new(mocks.MockNotificationRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.emailService = new(mocks.MockEmailService)
+	suite.usecase = usecases.NewNotificationUsecase(suite.notificationRepo, suite.userRepo, suite.emailService)
+	suite.event = domain.NotificationEvent{Type: domain.NotificationFollow, UserID: 1, ActorID: 2, Title: "You have a new follower", Message: "sam started following you.", Link: "http://localhost:8000/@sam"}
+}
+
+func (suite *NotificationUsecaseTestSuite) TestNotify_DefaultsToInAppAndDigest() {
+	suite.notificationRepo.On("FetchPreferences", int64(1)).Return([]domain.NotificationPreference{}, nil)
+	suite.notificationRepo.On("Create", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == 1 && n.ActorID == 2 && n.Type == domain.NotificationFollow && n.InApp && n.DigestPending
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.Notify(suite.event))
+	suite.emailService.AssertNotCalled(suite.T(), "SendTemplate", mock.Anything)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestNotify_ImmediateEmail() {
+	suite.notificationRepo.On("FetchPreferences", int64(1)).Return([]domain.NotificationPreference{
+		{UserID: 1, Type: domain.NotificationFollow, InApp: false, Email: domain.EmailDeliveryImmediate},
+	}, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Email: "jane@example.com", Locale: "fr", Status: "active"}, nil)
+	suite.emailService.On("SendTemplate", domain.EmailMessage{
+		To:       []string{"jane@example.com"},
+		Template: domain.EmailNotification,
+		Locale:   "fr",
+		Data:     map[string]interface{}{"Title": suite.event.Title, "Message": suite.event.Message, "Link": suite.event.Link},
+	}).Return(nil)
+
+	suite.NoError(suite.usecase.Notify(suite.event))
+	suite.notificationRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+	suite.emailService.AssertExpectations(suite.T())
+}
+
+func (suite *NotificationUsecaseTestSuite) TestNotify_AllChannelsOff() {
+	suite.notificationRepo.On("FetchPreferences", int64(1)).Return([]domain.NotificationPreference{
+		{UserID: 1, Type: domain.NotificationFollow, InApp: false, Email: domain.EmailDeliveryOff},
+	}, nil)
+
+	suite.NoError(suite.usecase.Notify(suite.event))
+	suite.notificationRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+	suite.emailService.AssertNotCalled(suite.T(), "SendTemplate", mock.Anything)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestNotify_SkipsOwnActions() {
+	suite.event.ActorID = 1
+
+	suite.NoError(suite.usecase.Notify(suite.event))
+	suite.notificationRepo.AssertNotCalled(suite.T(), "FetchPreferences", mock.Anything)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestNotify_UnknownType() {
+	suite.event.Type = "mention"
+
+	suite.EqualError(suite.usecase.Notify(suite.event), "unknown notification type")
+}
+
+func (suite *NotificationUsecaseTestSuite) TestList_ClampsLimit() {
+	filter := domain.NotificationFilter{UserID: 1, UnreadOnly: true, Page: 1, Limit: 500}
+	suite.notificationRepo.On("Search", filter).Return(nil, int64(0), nil)
+	suite.notificationRepo.On("CountUnread", int64(1)).Return(int64(3), nil)
+
+	page, err := suite.usecase.List("1", true, 0, 1000)
+	suite.NoError(err)
+	suite.Equal(500, page.Limit)
+	suite.Equal(int64(3), page.Unread)
+	suite.NotNil(page.Items)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestMarkRead_NotFound() {
+	suite.notificationRepo.On("MarkRead", int64(1), int64(9), mock.Anything).Return(domain.ErrResourceNotFound)
+
+	suite.ErrorIs(suite.usecase.MarkRead("1", "9"), domain.ErrResourceNotFound)
+	suite.ErrorIs(suite.usecase.MarkRead("1", "abc"), domain.ErrResourceNotFound)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestPreferences_FillsDefaults() {
+	suite.notificationRepo.On("FetchPreferences", int64(1)).Return([]domain.NotificationPreference{
+		{UserID: 1, Type: domain.NotificationFollow, InApp: false, Email: domain.EmailDeliveryOff},
+	}, nil)
+
+	preferences, err := suite.usecase.Preferences("1")
+	suite.NoError(err)
+	suite.Equal([]domain.NotificationPreference{
+		domain.DefaultNotificationPreference(1, domain.NotificationComment),
+		{UserID: 1, Type: domain.NotificationFollow, InApp: false, Email: domain.EmailDeliveryOff},
+		domain.DefaultNotificationPreference(1, domain.NotificationReaction),
+	}, preferences)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestUpdatePreferences_Validates() {
+	_, err := suite.usecase.UpdatePreferences("1", []domain.NotificationPreference{{Type: domain.NotificationComment, Email: "weekly"}})
+	suite.EqualError(err, `unknown email delivery "weekly"`)
+
+	_, err = suite.usecase.UpdatePreferences("1", []domain.NotificationPreference{{Type: "mention", Email: domain.EmailDeliveryOff}})
+	suite.EqualError(err, `unknown notification type "mention"`)
+
+	_, err = suite.usecase.UpdatePreferences("1", []domain.NotificationPreference{
+		{Type: domain.NotificationComment, Email: domain.EmailDeliveryOff},
+		{Type: domain.NotificationComment, Email: domain.EmailDeliveryDigest},
+	})
+	suite.EqualError(err, `notification type "comment" listed twice`)
+	suite.notificationRepo.AssertNotCalled(suite.T(), "SavePreferences", mock.Anything)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestUpdatePreferences_Saves() {
+	saved := []domain.NotificationPreference{{UserID: 1, Type: domain.NotificationComment, InApp: true, Email: domain.EmailDeliveryImmediate}}
+	suite.notificationRepo.On("SavePreferences", saved).Return(nil)
+	suite.notificationRepo.On("FetchPreferences", int64(1)).Return(saved, nil)
+
+	preferences, err := suite.usecase.UpdatePreferences("1", []domain.NotificationPreference{{ID: 5, UserID: 9, Type: domain.NotificationComment, InApp: true, Email: domain.EmailDeliveryImmediate}})
+	suite.NoError(err)
+	suite.Len(preferences, len(domain.NotificationTypes))
+	suite.Equal(domain.EmailDeliveryImmediate, preferences[0].Email)
+}
+
+func (suite *NotificationUsecaseTestSuite) TestSendDigests_OneEmailPerUser() {
+	suite.notificationRepo.On("FetchPendingDigests").Return([]domain.Notification{
+		{ID: 1, UserID: 1, Title: "New follower", Message: "sam started following you.", Link: "http://localhost:8000/@sam"},
+		{ID: 2, UserID: 1, Title: "New follower", Message: "alex started following you."},
+		{ID: 3, UserID: 2, Title: "New follower", Message: "jane started following you."},
+		{ID: 4, UserID: 3, Title: "New follower", Message: "jane started following you."},
+	}, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Email: "jane@example.com", Status: "active"}, nil)
+	suite.userRepo.On("Fetch", "2").Return(domain.User{ID: 2, Email: "sam@example.com", Status: "active"}, nil)
+	suite.userRepo.On("Fetch", "3").Return(domain.User{ID: 3, Email: "gone@example.com", Status: "deleted"}, nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.To[0] == "jane@example.com" && m.Template == domain.EmailDigest && len(m.Data["Items"].([]map[string]interface{})) == 2
+	})).Return(nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.To[0] == "sam@example.com"
+	})).Return(errors.New("outbox down"))
+	suite.notificationRepo.On("MarkDigested", []int64{1, 2}).Return(nil)
+	suite.notificationRepo.On("MarkDigested", []int64{4}).Return(nil)
+
+	suite.NoError(suite.usecase.SendDigests())
+	// sam's digest failed and stays pending for the next run
+	suite.notificationRepo.AssertNotCalled(suite.T(), "MarkDigested", []int64{3})
+	suite.notificationRepo.AssertExpectations(suite.T())
+	suite.emailService.AssertNumberOfCalls(suite.T(), "SendTemplate", 2)
+}
+
+func TestNotificationUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationUsecaseTestSuite))
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OIDCUsecaseTestSuite struct {
+	suite.Suite
+	userRepo      *mocks.MockUserRepository
+	oidcRepo      *mocks.MockOIDCRepository
+	oidcService   *mocks.MockOIDCService
+	pwdService    *mocks.MockPasswordService
+	jwtService    *mocks.MockJWTService
+	tokenRepo     *mocks.MockTokenRepository
+	twoFactorRepo *mocks.MockTwoFactorRepository
+	auditRepo     *mocks.MockAuditRepository
+	usecase       domain.IOIDCUsecase
+	request       domain.OIDCAuthRequest
+	identity      domain.OIDCIdentity
+}
+
+func (suite *OIDCUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.oidcRepo = new(mocks.MockOIDCRepository)
+	suite.oidcService = new(mocks.MockOIDCService)
+	suite.pwdService = new(mocks.MockPasswordService)
+	suite.jwtService = new(mocks.MockJWTService)
+	suite.tokenRepo = new(mocks.MockTokenRepository)
+	suite.twoFactorRepo = new(mocks.MockTwoFactorRepository)
+	suite.auditRepo = new(mocks.MockAuditRepository)
+	suite.auditRepo.On("Record", mock.Anything).Return(nil).Maybe()
+	suite.usecase = usecases.NewOIDCUsecase(suite.userRepo, suite.oidcRepo, suite.oidcService, suite.pwdService, suite.jwtService, suite.tokenRepo, suite.twoFactorRepo, suite.auditRepo)
+
+	suite.request = domain.OIDCAuthRequest{State: "state", Provider: "google", Nonce: "nonce", CodeVerifier: "verifier", ExpiresAt: time.Now().Add(time.Minute)}
+	suite.identity = domain.OIDCIdentity{Provider: "google", Subject: "sub-1", Email: "jane@example.com", EmailVerified: true, PreferredUsername: "jane"}
+}
+
+func (suite *OIDCUsecaseTestSuite) expectTokens(user domain.User) {
+	suite.twoFactorRepo.On("FetchByUserID", user.ID).Return(domain.TwoFactor{}, nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+}
+
+func (suite *OIDCUsecaseTestSuite) TestBegin_StoresMatchingPKCEVerifier() {
+	var challenge string
+	suite.oidcService.On("AuthCodeURL", "google", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { challenge = args.String(3) }).
+		Return("https://provider/authorize?x", nil)
+	suite.oidcRepo.On("SaveAuthRequest", mock.MatchedBy(func(r *domain.OIDCAuthRequest) bool {
+		sum := sha256.Sum256([]byte(r.CodeVerifier))
+		return r.Provider == "google" && r.State != "" && r.Nonce != "" &&
+			base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+	})).Return(nil)
+
+	authURL, state, err := suite.usecase.Begin("google")
+	suite.NoError(err)
+	suite.Equal("https://provider/authorize?x", authURL)
+	suite.NotEmpty(state)
+	suite.oidcRepo.AssertCalled(suite.T(), "SaveAuthRequest", mock.MatchedBy(func(r *domain.OIDCAuthRequest) bool { return r.State == state }))
+	suite.oidcRepo.AssertExpectations(suite.T())
+}
+
+func (suite *OIDCUsecaseTestSuite) TestBegin_UnknownProvider() {
+	suite.oidcService.On("AuthCodeURL", "nope", mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("unknown identity provider"))
+
+	_, _, err := suite.usecase.Begin("nope")
+	suite.EqualError(err, "unknown identity provider")
+	suite.oidcRepo.AssertNotCalled(suite.T(), "SaveAuthRequest", mock.Anything)
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_LinkedIdentity() {
+	user := domain.User{ID: 1, Role: "user", Status: "active"}
+	suite.oidcRepo.On("ConsumeAuthRequest", "state").Return(suite.request, nil)
+	suite.oidcService.On("Exchange", "google", "code", "verifier", "nonce").Return(suite.identity, nil)
+	suite.oidcRepo.On("FetchIdentity", "google", "sub-1").Return(domain.LinkedIdentity{ID: 3, UserID: 1}, nil)
+	suite.userRepo.On("Fetch", "1").Return(user, nil)
+	suite.expectTokens(user)
+
+	access, refresh, err := suite.usecase.Complete("google", "state", "state", "code", domain.ClientInfo{})
+	suite.NoError(err)
+	suite.Equal("access_token", access)
+	suite.Equal("refresh_token", refresh)
+	suite.oidcRepo.AssertNotCalled(suite.T(), "CreateIdentity", mock.Anything)
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_LinksExistingUserByVerifiedEmail() {
+	user := domain.User{ID: 1, Email: "jane@example.com", Role: "user", Status: "inactive"}
+	suite.oidcRepo.On("ConsumeAuthRequest", "state").Return(suite.request, nil)
+	suite.oidcService.On("Exchange", "google", "code", "verifier", "nonce").Return(suite.identity, nil)
+	suite.oidcRepo.On("FetchIdentity", "google", "sub-1").Return(domain.LinkedIdentity{}, nil)
+	suite.userRepo.On("FetchByEmail", "jane@example.com").Return(user, nil)
+	suite.userRepo.On("ActivateAccount", "1").Return(nil)
+	suite.oidcRepo.On("CreateIdentity", mock.MatchedBy(func(li *domain.LinkedIdentity) bool {
+		return li.UserID == 1 && li.Provider == "google" && li.Subject == "sub-1"
+	})).Return(nil)
+	suite.expectTokens(user)
+
+	_, _, err := suite.usecase.Complete("google", "state", "state", "code", domain.ClientInfo{})
+	suite.NoError(err)
+	suite.userRepo.AssertCalled(suite.T(), "ActivateAccount", "1")
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_RegistersNewUser() {
+	created := domain.User{ID: 1, Username: "jane", Email: "jane@example.com", Role: "user", Status: "active"}
+	suite.oidcRepo.On("ConsumeAuthRequest", "state").Return(suite.request, nil)
+	suite.oidcService.On("Exchange", "google", "code", "verifier", "nonce").Return(suite.identity, nil)
+	suite.oidcRepo.On("FetchIdentity", "google", "sub-1").Return(domain.LinkedIdentity{}, nil)
+	suite.userRepo.On("FetchByEmail", "jane@example.com").Return(domain.User{}, errors.New("not found"))
+	suite.userRepo.On("FetchByUsername", "jane").Return(domain.User{}, errors.New("not found"))
+	suite.pwdService.On("HashPassword", mock.AnythingOfType("string")).Return("hashed", nil)
+	suite.userRepo.On("Register", mock.MatchedBy(func(u *domain.User) bool {
+		return u.Username == "jane" && u.Status == "active" && u.Role == domain.DefaultRole && u.Password == "hashed"
+	})).Return(created, nil)
+	suite.oidcRepo.On("CreateIdentity", mock.AnythingOfType("*domain.LinkedIdentity")).Return(nil)
+	suite.expectTokens(created)
+
+	_, _, err := suite.usecase.Complete("google", "state", "state", "code", domain.ClientInfo{})
+	suite.NoError(err)
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_RefusesUnverifiedEmail() {
+	suite.identity.EmailVerified = false
+	suite.oidcRepo.On("ConsumeAuthRequest", "state").Return(suite.request, nil)
+	suite.oidcService.On("Exchange", "google", "code", "verifier", "nonce").Return(suite.identity, nil)
+	suite.oidcRepo.On("FetchIdentity", "google", "sub-1").Return(domain.LinkedIdentity{}, nil)
+
+	_, _, err := suite.usecase.Complete("google", "state", "state", "code", domain.ClientInfo{})
+	suite.Error(err)
+	suite.userRepo.AssertNotCalled(suite.T(), "FetchByEmail", mock.Anything)
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_StateForOtherProvider() {
+	suite.request.Provider = "github"
+	suite.oidcRepo.On("ConsumeAuthRequest", "state").Return(suite.request, nil)
+
+	_, _, err := suite.usecase.Complete("google", "state", "state", "code", domain.ClientInfo{})
+	suite.EqualError(err, "invalid or expired state")
+	suite.oidcService.AssertNotCalled(suite.T(), "Exchange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_StateFromOtherBrowser() {
+	for _, browserState := range []string{"", "other"} {
+		_, _, err := suite.usecase.Complete("google", "state", browserState, "code", domain.ClientInfo{})
+		suite.EqualError(err, "invalid or expired state")
+	}
+	suite.oidcRepo.AssertNotCalled(suite.T(), "ConsumeAuthRequest", mock.Anything)
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_ExpiredState() {
+	suite.request.ExpiresAt = time.Now().Add(-time.Second)
+	suite.oidcRepo.On("ConsumeAuthRequest", "state").Return(suite.request, nil)
+
+	_, _, err := suite.usecase.Complete("google", "state", "state", "code", domain.ClientInfo{})
+	suite.EqualError(err, "invalid or expired state")
+}
+
+func (suite *OIDCUsecaseTestSuite) TestComplete_TwoFactorChallenge() {
+	user := domain.User{ID: 1, Role: "user", Status: "active"}
+	suite.oidcRepo.On("ConsumeAuthRequest", "state").Return(suite.request, nil)
+	suite.oidcService.On("Exchange", "google", "code", "verifier", "nonce").Return(suite.identity, nil)
+	suite.oidcRepo.On("FetchIdentity", "google", "sub-1").Return(domain.LinkedIdentity{ID: 3, UserID: 1}, nil)
+	suite.userRepo.On("Fetch", "1").Return(user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{Enabled: true}, nil)
+	suite.jwtService.On("GenerateChallengeToken", "1", "user").Return("challenge_token", nil)
+
+	_, _, err := suite.usecase.Complete("google", "state", "state", "code", domain.ClientInfo{})
+	var mfaErr *domain.MFARequiredError
+	suite.ErrorAs(err, &mfaErr)
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateAccessToken", "1", "user")
+}
+
+func (suite *OIDCUsecaseTestSuite) TestUnlink_NotFound() {
+	suite.oidcRepo.On("DeleteIdentity", int64(1), int64(9)).Return(errors.New("record not found"))
+
+	suite.EqualError(suite.usecase.Unlink("1", "9"), "linked identity not found")
+}
+
+func TestOIDCUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(OIDCUsecaseTestSuite))
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PersonalAccessTokenUsecaseTestSuite struct {
+	suite.Suite
+	userRepo  *mocks.MockUserRepository
+	tokenRepo *mocks.MockPersonalAccessTokenRepository
+	auditRepo *mocks.MockAuditRepository
+	usecase   domain.IPersonalAccessTokenUsecase
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.tokenRepo = new(mocks.MockPersonalAccessTokenRepository)
+	suite.auditRepo = new(mocks.MockAuditRepository)
+	suite.auditRepo.On("Record", mock.Anything).Return(nil).Maybe()
+	suite.usecase = usecases.NewPersonalAccessTokenUsecase(suite.userRepo, suite.tokenRepo, suite.auditRepo)
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestCreate_StoresOnlyHash() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1}, nil)
+	suite.tokenRepo.On("FetchByUserID", int64(1)).Return([]domain.PersonalAccessToken{}, nil)
+	var stored *domain.PersonalAccessToken
+	suite.tokenRepo.On("Create", mock.AnythingOfType("*domain.PersonalAccessToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*domain.PersonalAccessToken) }).
+		Return(nil)
+
+	secret, token, err := suite.usecase.Create("1", " CI publisher ", []string{"blogs:write", "Blogs:Write", "blogs:read"}, nil, domain.ClientInfo{})
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(secret, domain.PersonalAccessTokenPrefix))
+	suite.Equal("CI publisher", token.Name)
+	suite.Equal("blogs:write blogs:read", token.Scopes)
+	suite.True(strings.HasPrefix(secret, token.Prefix))
+	suite.NotContains(stored.TokenHash, secret)
+	suite.Len(stored.TokenHash, 64)
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestCreate_UnknownScope() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1}, nil)
+
+	_, _, err := suite.usecase.Create("1", "ci", []string{"everything"}, nil, domain.ClientInfo{})
+	suite.EqualError(err, "unknown scope everything")
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestCreate_ExpiryInPast() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1}, nil)
+	past := time.Now().Add(-time.Hour)
+
+	_, _, err := suite.usecase.Create("1", "ci", []string{"blogs:read"}, &past, domain.ClientInfo{})
+	suite.EqualError(err, "expiry must be in the future")
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestAuthenticate_RoundTrip() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "admin"}, nil)
+	suite.tokenRepo.On("FetchByUserID", int64(1)).Return([]domain.PersonalAccessToken{}, nil)
+	var stored domain.PersonalAccessToken
+	suite.tokenRepo.On("Create", mock.AnythingOfType("*domain.PersonalAccessToken")).
+		Run(func(args mock.Arguments) {
+			stored = *args.Get(0).(*domain.PersonalAccessToken)
+			stored.ID = 5
+		}).
+		Return(nil)
+	secret, _, err := suite.usecase.Create("1", "ci", []string{"blogs:write"}, nil, domain.ClientInfo{})
+	suite.Require().NoError(err)
+
+	suite.tokenRepo.On("FetchByHash", stored.TokenHash).Return(stored, nil)
+	suite.tokenRepo.On("TouchLastUsed", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+	claims, err := suite.usecase.Authenticate(secret)
+	suite.Require().NoError(err)
+	suite.Equal("1", claims.UserID)
+	suite.Equal("admin", claims.UserRole)
+	suite.Equal([]string{"blogs:write"}, claims.Scopes)
+	suite.tokenRepo.AssertCalled(suite.T(), "TouchLastUsed", int64(5), mock.AnythingOfType("time.Time"))
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestAuthenticate_Revoked() {
+	revokedAt := time.Now().Add(-time.Minute)
+	suite.tokenRepo.On("FetchByHash", mock.AnythingOfType("string")).Return(domain.PersonalAccessToken{ID: 5, UserID: 1, RevokedAt: &revokedAt}, nil)
+
+	_, err := suite.usecase.Authenticate("bpat_whatever")
+	suite.EqualError(err, "invalid token")
+	suite.userRepo.AssertNotCalled(suite.T(), "Fetch", mock.Anything)
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestAuthenticate_Expired() {
+	expiredAt := time.Now().Add(-time.Minute)
+	suite.tokenRepo.On("FetchByHash", mock.AnythingOfType("string")).Return(domain.PersonalAccessToken{ID: 5, UserID: 1, ExpiresAt: &expiredAt}, nil)
+
+	_, err := suite.usecase.Authenticate("bpat_whatever")
+	suite.EqualError(err, "invalid token")
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestAuthenticate_RecentlyUsedSkipsWrite() {
+	lastUsed := time.Now().Add(-10 * time.Second)
+	suite.tokenRepo.On("FetchByHash", mock.AnythingOfType("string")).Return(domain.PersonalAccessToken{ID: 5, UserID: 1, Scopes: "blogs:read", LastUsedAt: &lastUsed}, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user"}, nil)
+
+	_, err := suite.usecase.Authenticate("bpat_whatever")
+	suite.NoError(err)
+	suite.tokenRepo.AssertNotCalled(suite.T(), "TouchLastUsed", mock.Anything, mock.Anything)
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestAuthenticate_OwnerOutOfService() {
+	suite.tokenRepo.On("FetchByHash", mock.AnythingOfType("string")).Return(domain.PersonalAccessToken{ID: 5, UserID: 1, Scopes: "blogs:read"}, nil)
+	until := time.Now().Add(time.Hour)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Status: "suspended", SuspendedUntil: &until}, nil).Once()
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Status: "banned"}, nil).Once()
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Status: "inactive"}, nil).Once()
+
+	_, err := suite.usecase.Authenticate("bpat_whatever")
+	suite.ErrorIs(err, domain.ErrAccountSuspended)
+	_, err = suite.usecase.Authenticate("bpat_whatever")
+	suite.ErrorIs(err, domain.ErrAccountSuspended)
+	_, err = suite.usecase.Authenticate("bpat_whatever")
+	suite.EqualError(err, "invalid token")
+	suite.tokenRepo.AssertNotCalled(suite.T(), "TouchLastUsed", mock.Anything, mock.Anything)
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestCreate_RecordsAuditEvent() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1}, nil)
+	suite.tokenRepo.On("FetchByUserID", int64(1)).Return([]domain.PersonalAccessToken{}, nil)
+	suite.tokenRepo.On("Create", mock.AnythingOfType("*domain.PersonalAccessToken")).
+		Run(func(args mock.Arguments) { args.Get(0).(*domain.PersonalAccessToken).ID = 7 }).
+		Return(nil)
+
+	_, _, err := suite.usecase.Create("1", "ci", []string{"blogs:read"}, nil, domain.ClientInfo{IP: "10.0.0.1", UserAgent: "curl/8"})
+	suite.Require().NoError(err)
+	suite.auditRepo.AssertCalled(suite.T(), "Record", mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == "personal_access_token.create" && e.ActorID == 1 && e.TargetID == 1 &&
+			strings.Contains(e.Metadata, `"token_id":7`) && e.IP == "10.0.0.1" && e.UserAgent == "curl/8"
+	}))
+}
+
+func (suite *PersonalAccessTokenUsecaseTestSuite) TestRevoke_NotFound() {
+	suite.tokenRepo.On("Revoke", int64(1), int64(9), mock.AnythingOfType("time.Time")).Return(errors.New("record not found"))
+
+	suite.EqualError(suite.usecase.Revoke("1", "9", domain.ClientInfo{}), "token not found")
+}
+
+func TestPersonalAccessTokenUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PersonalAccessTokenUsecaseTestSuite))
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProfileUsecaseTestSuite struct {
+	suite.Suite
+	userRepo    *mocks.MockUserRepository
+	profileRepo *mocks.MockProfileRepository
+	usecase     domain.IProfileUsecase
+	user        domain.User
+}
+
+func (suite *ProfileUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.profileRepo = new(mocks.MockProfileRepository)
+	suite.usecase = usecases.NewProfileUsecase(suite.userRepo, suite.profileRepo)
+	suite.user = domain.User{ID: 1, Username: "jane", Bio: "writer", Phone: "+14155550123", Email: "jane@example.com", Status: "active", CreatedAt: time.Now()}
+}
+
+func (suite *ProfileUsecaseTestSuite) TestPublicProfile_AllPublic() {
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+	suite.profileRepo.On("FetchPrivacy", int64(1)).Return(domain.ProfilePrivacy{UserID: 1}, nil)
+	suite.profileRepo.On("AuthorStats", int64(1)).Return(domain.AuthorStats{PostCount: 3, FollowerCount: 7, ReactionsReceived: 12}, nil)
+	suite.profileRepo.On("FetchPostsByUser", int64(1), 0, 3).Return([]domain.Blog{{ID: 3}, {ID: 2}, {ID: 1}}, nil)
+
+	profile, err := suite.usecase.PublicProfile("jane", 1, 2)
+	suite.NoError(err)
+	suite.Equal("writer", *profile.Bio)
+	suite.Equal(int64(7), *profile.FollowerCount)
+	suite.Equal(int64(12), *profile.ReactionsReceived)
+	suite.Len(profile.Posts.Items, 2)
+	suite.True(profile.Posts.HasMore)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestPublicProfile_RespectsPrivacy() {
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+	suite.profileRepo.On("FetchPrivacy", int64(1)).Return(domain.ProfilePrivacy{UserID: 1, HideBio: true, HidePostCount: true, HidePosts: true}, nil)
+	suite.profileRepo.On("AuthorStats", int64(1)).Return(domain.AuthorStats{PostCount: 3}, nil)
+
+	profile, err := suite.usecase.PublicProfile("jane", 1, 10)
+	suite.NoError(err)
+	suite.Nil(profile.Bio)
+	suite.Nil(profile.PostCount)
+	suite.Nil(profile.Posts)
+	suite.NotNil(profile.JoinedAt)
+	suite.profileRepo.AssertNotCalled(suite.T(), "FetchPostsByUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestPublicProfile_CapsPageSize() {
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+	suite.profileRepo.On("FetchPrivacy", int64(1)).Return(domain.ProfilePrivacy{UserID: 1}, nil)
+	suite.profileRepo.On("AuthorStats", int64(1)).Return(domain.AuthorStats{}, nil)
+	suite.profileRepo.On("FetchPostsByUser", int64(1), 50, 51).Return([]domain.Blog{}, nil)
+
+	profile, err := suite.usecase.PublicProfile("jane", 2, 500)
+	suite.NoError(err)
+	suite.Equal(50, profile.Posts.Limit)
+	suite.NotNil(profile.Posts.Items)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestPublicProfile_InactiveUserNotFound() {
+	suite.user.Status = "inactive"
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+
+	_, err := suite.usecase.PublicProfile("jane", 1, 10)
+	suite.ErrorIs(err, domain.ErrResourceNotFound)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestPublicProfile_UnknownUser() {
+	suite.userRepo.On("FetchByUsername", "nobody").Return(domain.User{}, errors.New("record not found"))
+
+	_, err := suite.usecase.PublicProfile("nobody", 1, 10)
+	suite.ErrorIs(err, domain.ErrResourceNotFound)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestUpdatePrivacy_OwnRowOnly() {
+	suite.profileRepo.On("SavePrivacy", mock.MatchedBy(func(p *domain.ProfilePrivacy) bool {
+		return p.UserID == 1 && p.ID == 0 && p.HideBio
+	})).Return(nil)
+
+	_, err := suite.usecase.UpdatePrivacy("1", domain.ProfilePrivacy{ID: 9, UserID: 2, HideBio: true})
+	suite.NoError(err)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestFollow_Self() {
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+
+	suite.EqualError(suite.usecase.Follow("1", "jane"), "you cannot follow yourself")
+	suite.profileRepo.AssertNotCalled(suite.T(), "Follow", mock.Anything, mock.Anything)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestFollow_Success() {
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+	suite.profileRepo.On("Follow", int64(2), int64(1)).Return(true, nil)
+
+	suite.NoError(suite.usecase.Follow("2", "jane"))
+}
+
+func (suite *ProfileUsecaseTestSuite) TestFollow_NotifiesFollowee() {
+	notifier := new(mocks.MockNotifier)
+	limiter := new(mocks.MockRateLimiter)
+	usecase := usecases.NewProfileUsecase(suite.userRepo, suite.profileRepo, usecases.WithFollowNotifications(notifier, limiter))
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+	suite.userRepo.On("Fetch", "2").Return(domain.User{ID: 2, Username: "sam"}, nil)
+	suite.profileRepo.On("Follow", int64(2), int64(1)).Return(true, nil)
+	limiter.On("Allow", "2:1").Return(time.Duration(0), nil)
+	notifier.On("Notify", mock.MatchedBy(func(e domain.NotificationEvent) bool {
+		return e.Type == domain.NotificationFollow && e.UserID == 1 && e.ActorID == 2 && e.Message == "sam started following you."
+	})).Return(errors.New("database down"))
+
+	// a failed notification does not undo the follow
+	suite.NoError(usecase.Follow("2", "jane"))
+	notifier.AssertExpectations(suite.T())
+}
+
+func (suite *ProfileUsecaseTestSuite) TestFollow_AlreadyFollowingDoesNotNotify() {
+	notifier := new(mocks.MockNotifier)
+	limiter := new(mocks.MockRateLimiter)
+	usecase := usecases.NewProfileUsecase(suite.userRepo, suite.profileRepo, usecases.WithFollowNotifications(notifier, limiter))
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+	suite.profileRepo.On("Follow", int64(2), int64(1)).Return(false, nil)
+
+	suite.NoError(usecase.Follow("2", "jane"))
+	limiter.AssertNotCalled(suite.T(), "Allow", mock.Anything)
+	notifier.AssertNotCalled(suite.T(), "Notify", mock.Anything)
+}
+
+func (suite *ProfileUsecaseTestSuite) TestFollow_RefollowWithinLimitDoesNotNotify() {
+	notifier := new(mocks.MockNotifier)
+	limiter := new(mocks.MockRateLimiter)
+	usecase := usecases.NewProfileUsecase(suite.userRepo, suite.profileRepo, usecases.WithFollowNotifications(notifier, limiter))
+	suite.userRepo.On("FetchByUsername", "jane").Return(suite.user, nil)
+	suite.profileRepo.On("Follow", int64(2), int64(1)).Return(true, nil)
+	limiter.On("Allow", "2:1").Return(time.Hour, nil)
+
+	suite.NoError(usecase.Follow("2", "jane"))
+	notifier.AssertNotCalled(suite.T(), "Notify", mock.Anything)
+}
+
+func TestProfileUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileUsecaseTestSuite))
+}
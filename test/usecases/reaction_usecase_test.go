@@ -0,0 +1,86 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReactionUsecaseTestSuite struct {
+	suite.Suite
+	userRepo     *mocks.MockUserRepository
+	reactionRepo *mocks.MockReactionRepository
+	owners       *mocks.MockOwnershipRepository
+	notifier     *mocks.MockNotifier
+	limiter      *mocks.MockRateLimiter
+	usecase      domain.IReactionUsecase
+}
+
+func (suite *ReactionUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.reactionRepo = new(mocks.MockReactionRepository)
+	suite.owners = new(mocks.MockOwnershipRepository)
+	suite.notifier = new(mocks.MockNotifier)
+	suite.limiter = new(mocks.MockRateLimiter)
+	suite.usecase = usecases.NewReactionUsecase(suite.userRepo, suite.reactionRepo, suite.owners, usecases.WithReactionNotifications(suite.notifier, suite.limiter))
+}
+
+func (suite *ReactionUsecaseTestSuite) TestReact_NotifiesAuthor() {
+	suite.owners.On("BlogOwner", int64(7)).Return(int64(2), nil)
+	suite.reactionRepo.On("React", int64(1), int64(7), domain.ReactionLike).Return("", nil)
+	suite.limiter.On("Allow", "1:7").Return(time.Duration(0), nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Username: "sam"}, nil)
+	suite.notifier.On("Notify", mock.MatchedBy(func(e domain.NotificationEvent) bool {
+		return e.Type == domain.NotificationReaction && e.UserID == 2 && e.ActorID == 1 && e.Message == "sam reacted to your post."
+	})).Return(nil)
+
+	suite.NoError(suite.usecase.React("1", "7", domain.ReactionLike))
+	suite.reactionRepo.AssertExpectations(suite.T())
+	suite.notifier.AssertExpectations(suite.T())
+}
+
+func (suite *ReactionUsecaseTestSuite) TestReact_ChangingKindDoesNotNotify() {
+	suite.owners.On("BlogOwner", int64(7)).Return(int64(2), nil)
+	suite.reactionRepo.On("React", int64(1), int64(7), domain.ReactionDislike).Return(domain.ReactionLike, nil)
+
+	suite.NoError(suite.usecase.React("1", "7", domain.ReactionDislike))
+	suite.notifier.AssertNotCalled(suite.T(), "Notify", mock.Anything)
+}
+
+func (suite *ReactionUsecaseTestSuite) TestReact_ReactAgainWithinLimitDoesNotNotify() {
+	suite.owners.On("BlogOwner", int64(7)).Return(int64(2), nil)
+	suite.reactionRepo.On("React", int64(1), int64(7), domain.ReactionLike).Return("", nil)
+	suite.limiter.On("Allow", "1:7").Return(time.Hour, nil)
+
+	suite.NoError(suite.usecase.React("1", "7", domain.ReactionLike))
+	suite.notifier.AssertNotCalled(suite.T(), "Notify", mock.Anything)
+}
+
+func (suite *ReactionUsecaseTestSuite) TestReact_UnknownKind() {
+	suite.EqualError(suite.usecase.React("1", "7", "love"), "unknown reaction")
+	suite.reactionRepo.AssertNotCalled(suite.T(), "React", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReactionUsecaseTestSuite) TestReact_PostNotFound() {
+	suite.owners.On("BlogOwner", int64(7)).Return(int64(0), domain.ErrResourceNotFound)
+
+	suite.ErrorIs(suite.usecase.React("1", "7", domain.ReactionLike), domain.ErrResourceNotFound)
+	suite.reactionRepo.AssertNotCalled(suite.T(), "React", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReactionUsecaseTestSuite) TestUnreact_RepositoryError() {
+	suite.owners.On("BlogOwner", int64(7)).Return(int64(2), nil)
+	suite.reactionRepo.On("Unreact", int64(1), int64(7)).Return("", errors.New("db down"))
+
+	suite.EqualError(suite.usecase.Unreact("1", "7"), "unable to remove reaction")
+}
+
+func TestReactionUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ReactionUsecaseTestSuite))
+}
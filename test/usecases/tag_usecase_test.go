@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type stubKeywordExtractor []domain.Keyword
+
+func (s stubKeywordExtractor) Keywords(title string, content string, limit int) []domain.Keyword {
+	return s
+}
+
+type TagUsecaseTestSuite struct {
+	suite.Suite
+	tagRepo  *mocks.MockTagRepository
+	keywords stubKeywordExtractor
+	ai       *mocks.MockAIInfrastructure
+	limiter  *mocks.MockRateLimiter
+	ctx      context.Context
+}
+
+func (suite *TagUsecaseTestSuite) SetupTest() {
+	suite.tagRepo = new(mocks.MockTagRepository)
+	suite.keywords = stubKeywordExtractor{{Term: "golang", Score: 1}, {Term: "profiling", Score: 0.8}, {Term: "pprof", Score: 0.5}}
+	suite.ai = new(mocks.MockAIInfrastructure)
+	suite.limiter = new(mocks.MockRateLimiter)
+	suite.ctx = context.Background()
+	suite.tagRepo.On("FetchByNames", suite.ctx, mock.Anything).Return([]domain.TagUsage{{ID: 8, Name: "golang", Posts: 2}}, nil)
+	suite.tagRepo.On("MatchTags", suite.ctx, "profiling go services pprof and golang tooling", 50).Return([]domain.TagUsage{
+		{ID: 3, Name: "Go", Posts: 12},
+		{ID: 8, Name: "golang", Posts: 2},
+	}, nil)
+}
+
+func suggestionNames(suggestions []domain.TagSuggestion) []string {
+	result := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		result[i] = suggestion.Name
+	}
+	return result
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_PrefersExistingTags() {
+	usecase := usecases.NewTagUsecase(suite.tagRepo, suite.keywords)
+
+	suggestions, err := usecase.SuggestTags(suite.ctx, "1", " Profiling Go services ", "pprof and golang tooling", 0)
+	suite.NoError(err)
+	suite.Equal([]domain.TagSuggestion{
+		{Name: "golang", Existing: true, Posts: 2, Score: 0.94},
+		{Name: "Go", Existing: true, Posts: 12, Score: 0.72},
+		{Name: "profiling", Score: 0.4},
+		{Name: "pprof", Score: 0.25},
+	}, suggestions)
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_BlendsAssistantTags() {
+	usecase := usecases.NewTagUsecase(suite.tagRepo, suite.keywords, usecases.WithAITagSuggestions(suite.ai, suite.limiter))
+	suite.limiter.On("Allow", "1").Return(time.Duration(0), nil)
+	suite.ai.On("Complete", suite.ctx, mock.MatchedBy(func(r domain.AIRequest) bool {
+		return r.Input == "Title: Profiling Go services\n\npprof and golang tooling"
+	})).Return("1. Performance, #golang\n- observability", nil)
+
+	suggestions, err := usecase.SuggestTags(suite.ctx, "1", "Profiling Go services", "pprof and golang tooling", 3)
+	suite.NoError(err)
+	suite.Equal([]string{"golang", "Go", "performance"}, suggestionNames(suggestions))
+	suite.False(suggestions[2].Existing)
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_FallsBackWhenAssistantUnavailable() {
+	usecase := usecases.NewTagUsecase(suite.tagRepo, suite.keywords, usecases.WithAITagSuggestions(suite.ai, suite.limiter))
+	suite.limiter.On("Allow", "1").Return(time.Minute, nil)
+
+	suggestions, err := usecase.SuggestTags(suite.ctx, "1", "Profiling Go services", "pprof and golang tooling", 2)
+	suite.NoError(err)
+	suite.Equal([]string{"golang", "Go"}, suggestionNames(suggestions))
+	suite.ai.AssertNotCalled(suite.T(), "Complete", mock.Anything, mock.Anything)
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_WithKeywordExtractor() {
+	tagRepo := new(mocks.MockTagRepository)
+	tagRepo.On("FetchByNames", suite.ctx, mock.Anything).Return([]domain.TagUsage{}, nil)
+	tagRepo.On("MatchTags", suite.ctx, "notes on machine learning most machine learning is statistics", 50).Return([]domain.TagUsage{{ID: 1, Name: "Machine Learning", Posts: 4}}, nil)
+	usecase := usecases.NewTagUsecase(tagRepo, infrastructure.NewKeywordExtractor())
+
+	suggestions, err := usecase.SuggestTags(suite.ctx, "1", "Notes on machine-learning", "Most machine-learning is statistics.", 1)
+	suite.NoError(err)
+	suite.Equal([]domain.TagSuggestion{{Name: "Machine Learning", Existing: true, Posts: 4, Score: 1}}, suggestions)
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_NamedHyphenatedTag() {
+	tagRepo := new(mocks.MockTagRepository)
+	tagRepo.On("FetchByNames", suite.ctx, []string{"machine learning"}).Return([]domain.TagUsage{{ID: 4, Name: "Machine-Learning", Posts: 9}}, nil)
+	tagRepo.On("MatchTags", suite.ctx, mock.Anything, 50).Return([]domain.TagUsage{}, nil)
+	usecase := usecases.NewTagUsecase(tagRepo, stubKeywordExtractor{{Term: "machine learning", Score: 1}})
+
+	suggestions, err := usecase.SuggestTags(suite.ctx, "1", "Notes on machine learning", "", 1)
+	suite.NoError(err)
+	suite.Equal([]domain.TagSuggestion{{Name: "Machine-Learning", Existing: true, Posts: 9, Score: 1}}, suggestions)
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_NamedTagsBeyondMatchLimit() {
+	tagRepo := new(mocks.MockTagRepository)
+	tagRepo.On("FetchByNames", suite.ctx, mock.Anything).Return([]domain.TagUsage{{ID: 8, Name: "golang", Posts: 2}}, nil)
+	// the limit was used up by more popular tags
+	tagRepo.On("MatchTags", suite.ctx, mock.Anything, 50).Return([]domain.TagUsage{{ID: 3, Name: "Go", Posts: 12}}, nil)
+	usecase := usecases.NewTagUsecase(tagRepo, suite.keywords)
+
+	suggestions, err := usecase.SuggestTags(suite.ctx, "1", "Profiling Go services", "pprof and golang tooling", 2)
+	suite.NoError(err)
+	suite.Equal([]string{"golang", "Go"}, suggestionNames(suggestions))
+	suite.True(suggestions[0].Existing)
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_RequiresText() {
+	_, err := usecases.NewTagUsecase(suite.tagRepo, suite.keywords).SuggestTags(suite.ctx, "1", " ", "", 5)
+	suite.EqualError(err, "a title or content is required")
+}
+
+func (suite *TagUsecaseTestSuite) TestSuggestTags_RepositoryError() {
+	tagRepo := new(mocks.MockTagRepository)
+	tagRepo.On("FetchByNames", suite.ctx, mock.Anything).Return(nil, errors.New("db error"))
+
+	_, err := usecases.NewTagUsecase(tagRepo, suite.keywords).SuggestTags(suite.ctx, "1", "Go", "", 5)
+	suite.EqualError(err, "unable to suggest tags")
+}
+
+func TestTagUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(TagUsecaseTestSuite))
+}
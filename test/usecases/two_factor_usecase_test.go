@@ -0,0 +1,215 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blog-platform/domain"
+	"github.com/blog-platform/test/mocks"
+	"github.com/blog-platform/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TwoFactorUsecaseTestSuite struct {
+	suite.Suite
+	userRepo      *mocks.MockUserRepository
+	twoFactorRepo *mocks.MockTwoFactorRepository
+	totpService   *mocks.MockTOTPService
+	pwdService    *mocks.MockPasswordService
+	jwtService    *mocks.MockJWTService
+	tokenRepo     *mocks.MockTokenRepository
+	usecase       domain.ITwoFactorUsecase
+	user          domain.User
+}
+
+func (suite *TwoFactorUsecaseTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.twoFactorRepo = new(mocks.MockTwoFactorRepository)
+	suite.totpService = new(mocks.MockTOTPService)
+	suite.pwdService = new(mocks.MockPasswordService)
+	suite.jwtService = new(mocks.MockJWTService)
+	suite.tokenRepo = new(mocks.MockTokenRepository)
+	suite.usecase = usecases.NewTwoFactorUsecase(suite.userRepo, suite.twoFactorRepo, suite.totpService, suite.pwdService, suite.jwtService, suite.tokenRepo, nil, nil)
+	suite.user = domain.User{ID: 1, Email: "jane@example.com", Role: "user"}
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestEnroll_Success() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{}, nil)
+	suite.totpService.On("GenerateSecret").Return("SECRET", nil)
+	suite.twoFactorRepo.On("Save", mock.MatchedBy(func(tf *domain.TwoFactor) bool {
+		return tf.UserID == 1 && tf.Secret == "SECRET" && !tf.Enabled
+	})).Return(nil)
+	suite.totpService.On("ProvisioningURI", "SECRET", "jane@example.com").Return("otpauth://totp/x")
+
+	secret, uri, err := suite.usecase.Enroll("1")
+	suite.NoError(err)
+	suite.Equal("SECRET", secret)
+	suite.Equal("otpauth://totp/x", uri)
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestEnroll_AlreadyEnabled() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Enabled: true}, nil)
+
+	_, _, err := suite.usecase.Enroll("1")
+	suite.EqualError(err, "two-factor authentication is already enabled")
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestConfirm_Success() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Secret: "SECRET"}, nil)
+	suite.totpService.On("Verify", "SECRET", "123456", int64(0)).Return(int64(100), nil)
+	suite.pwdService.On("HashPassword", mock.AnythingOfType("string")).Return("hashed", nil)
+	suite.twoFactorRepo.On("ReplaceRecoveryCodes", int64(1), mock.MatchedBy(func(hashes []string) bool {
+		return len(hashes) == 10
+	})).Return(nil)
+	suite.twoFactorRepo.On("Save", mock.MatchedBy(func(tf *domain.TwoFactor) bool {
+		return tf.Enabled && tf.LastUsedStep == 100 && tf.ConfirmedAt != nil
+	})).Return(nil)
+
+	codes, err := suite.usecase.Confirm("1", "123456")
+	suite.NoError(err)
+	suite.Len(codes, 10)
+	suite.Regexp(`^[a-z2-7]{8}-[a-z2-7]{8}$`, codes[0])
+	suite.NotEqual(codes[0], codes[1])
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestConfirm_NotEnrolled() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{}, nil)
+
+	_, err := suite.usecase.Confirm("1", "123456")
+	suite.EqualError(err, "two-factor authentication has not been enrolled")
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestConfirm_InvalidCode() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Secret: "SECRET"}, nil)
+	suite.totpService.On("Verify", "SECRET", "000000", int64(0)).Return(int64(0), errors.New("invalid code"))
+
+	_, err := suite.usecase.Confirm("1", "000000")
+	suite.EqualError(err, "invalid code")
+	suite.twoFactorRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestVerifyLogin_TOTPCode() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	twoFactor := domain.TwoFactor{UserID: 1, Secret: "SECRET", Enabled: true, LastUsedStep: 99}
+	suite.jwtService.On("ValidateChallengeToken", "challenge").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(twoFactor, nil)
+	suite.totpService.On("Verify", "SECRET", "123456", int64(99)).Return(int64(100), nil)
+	suite.twoFactorRepo.On("Save", mock.MatchedBy(func(tf *domain.TwoFactor) bool { return tf.LastUsedStep == 100 })).Return(nil)
+	suite.jwtService.On("RevokeToken", claims).Return(nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+
+	access, refresh, err := suite.usecase.VerifyLogin("challenge", "123456", domain.ClientInfo{})
+	suite.NoError(err)
+	suite.Equal("access_token", access)
+	suite.Equal("refresh_token", refresh)
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestVerifyLogin_RecoveryCode() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	twoFactor := domain.TwoFactor{UserID: 1, Secret: "SECRET", Enabled: true}
+	suite.jwtService.On("ValidateChallengeToken", "challenge").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(twoFactor, nil)
+	suite.totpService.On("Verify", "SECRET", "ABCD-EFGH", int64(0)).Return(int64(0), errors.New("invalid code"))
+	suite.twoFactorRepo.On("FetchUnusedRecoveryCodes", int64(1)).Return([]domain.RecoveryCode{{ID: 7, CodeHash: "h1"}, {ID: 8, CodeHash: "h2"}}, nil)
+	suite.pwdService.On("ComparePassword", []byte("h1"), []byte("abcdefgh")).Return(errors.New("mismatch"))
+	suite.pwdService.On("ComparePassword", []byte("h2"), []byte("abcdefgh")).Return(nil)
+	suite.twoFactorRepo.On("MarkRecoveryCodeUsed", int64(8)).Return(nil)
+	suite.jwtService.On("RevokeToken", claims).Return(nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+
+	_, _, err := suite.usecase.VerifyLogin("challenge", "ABCD-EFGH", domain.ClientInfo{})
+	suite.NoError(err)
+	suite.twoFactorRepo.AssertCalled(suite.T(), "MarkRecoveryCodeUsed", int64(8))
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestVerifyLogin_InvalidChallenge() {
+	suite.jwtService.On("ValidateChallengeToken", "bad").Return(nil, errors.New("invalid token"))
+
+	_, _, err := suite.usecase.VerifyLogin("bad", "123456", domain.ClientInfo{})
+	suite.EqualError(err, "invalid or expired challenge")
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestVerifyLogin_WrongCode() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	suite.jwtService.On("ValidateChallengeToken", "challenge").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Secret: "SECRET", Enabled: true}, nil)
+	suite.totpService.On("Verify", "SECRET", "000000", int64(0)).Return(int64(0), errors.New("invalid code"))
+	suite.twoFactorRepo.On("FetchUnusedRecoveryCodes", int64(1)).Return([]domain.RecoveryCode{}, nil)
+
+	_, _, err := suite.usecase.VerifyLogin("challenge", "000000", domain.ClientInfo{})
+	suite.EqualError(err, "invalid code")
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateAccessToken", "1", "user")
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestVerifyLogin_WrongCodeCountsAsFailure() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.usecase = usecases.NewTwoFactorUsecase(suite.userRepo, suite.twoFactorRepo, suite.totpService, suite.pwdService, suite.jwtService, suite.tokenRepo, throttler, nil)
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	suite.jwtService.On("ValidateChallengeToken", "challenge").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Secret: "SECRET", Enabled: true}, nil)
+	throttler.On("Check", "1", "").Return(time.Duration(0), nil)
+	suite.totpService.On("Verify", "SECRET", "000000", int64(0)).Return(int64(0), errors.New("invalid code"))
+	suite.twoFactorRepo.On("FetchUnusedRecoveryCodes", int64(1)).Return([]domain.RecoveryCode{}, nil)
+	throttler.On("RegisterFailure", "1", "").Return(false, nil)
+
+	_, _, err := suite.usecase.VerifyLogin("challenge", "000000", domain.ClientInfo{})
+	suite.EqualError(err, "invalid code")
+	throttler.AssertExpectations(suite.T())
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestVerifyLogin_Throttled() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.usecase = usecases.NewTwoFactorUsecase(suite.userRepo, suite.twoFactorRepo, suite.totpService, suite.pwdService, suite.jwtService, suite.tokenRepo, throttler, nil)
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	suite.jwtService.On("ValidateChallengeToken", "challenge").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Secret: "SECRET", Enabled: true}, nil)
+	throttler.On("Check", "1", "").Return(time.Minute, nil)
+
+	_, _, err := suite.usecase.VerifyLogin("challenge", "123456", domain.ClientInfo{})
+	var throttledErr *domain.LoginThrottledError
+	suite.ErrorAs(err, &throttledErr)
+	suite.totpService.AssertNotCalled(suite.T(), "Verify", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestDisable_Success() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Secret: "SECRET", Enabled: true}, nil)
+	suite.totpService.On("Verify", "SECRET", "123456", int64(0)).Return(int64(5), nil)
+	suite.twoFactorRepo.On("Save", mock.AnythingOfType("*domain.TwoFactor")).Return(nil)
+	suite.twoFactorRepo.On("Delete", int64(1)).Return(nil)
+
+	suite.NoError(suite.usecase.Disable("1", "123456"))
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestReset_Success() {
+	suite.userRepo.On("Fetch", "1").Return(suite.user, nil)
+	suite.twoFactorRepo.On("Delete", int64(1)).Return(nil)
+
+	suite.NoError(suite.usecase.Reset(domain.Principal{UserID: "2", Role: domain.RoleAdmin}, "1"))
+}
+
+func (suite *TwoFactorUsecaseTestSuite) TestReset_UserNotFound() {
+	suite.userRepo.On("Fetch", "9").Return(domain.User{}, errors.New("not found"))
+
+	suite.EqualError(suite.usecase.Reset(domain.Principal{UserID: "2", Role: domain.RoleAdmin}, "9"), "user not found")
+}
+
+func TestTwoFactorUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorUsecaseTestSuite))
+}
@@ -1,12 +1,15 @@
 package test
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/blog-platform/domain"
+	"github.com/blog-platform/infrastructure"
 	"github.com/blog-platform/test/mocks"
 	"github.com/blog-platform/usecases"
 	"github.com/stretchr/testify/mock"
@@ -27,6 +30,7 @@ func (suite *UserUsecaseTestSuite) SetupTest() {
 	suite.userRepo = new(mocks.MockUserRepository)
 	suite.emailService = new(mocks.MockEmailService)
 	suite.pwdService = new(mocks.MockPasswordService)
+	suite.pwdService.On("NeedsRehash", mock.Anything).Return(false).Maybe()
 	suite.jwtService = new(mocks.MockJWTService)
 	suite.tokenRepo = new(mocks.MockTokenRepository)
 	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo)
@@ -48,11 +52,19 @@ func (suite *UserUsecaseTestSuite) TestRegister_Success() {
 	suite.userRepo.On("FetchByUsername", user.Username).Return(domain.User{}, errors.New("not found"))
 	suite.userRepo.On("FetchByEmail", user.Email).Return(domain.User{}, errors.New("not found"))
 	suite.pwdService.On("HashPassword", user.Password).Return("hashedpassword", nil)
-	suite.userRepo.On("Register", mock.AnythingOfType("*domain.User")).Return(createdUser, nil)
-	suite.emailService.On("SendEmail", []string{user.Email}, "Activate Account", "http://localhost:8080/user/1/activate").Return(nil)
+	var queued domain.EmailMessage
+	suite.userRepo.On("RegisterWithEmail", mock.AnythingOfType("*domain.User"), mock.Anything).Run(func(args mock.Arguments) {
+		queued = args.Get(1).(func(domain.User) domain.EmailMessage)(createdUser)
+	}).Return(createdUser, nil)
 
 	_, err := suite.userUsecase.Register(user)
 	suite.NoError(err)
+	suite.Equal(domain.EmailMessage{
+		To:       []string{user.Email},
+		Template: domain.EmailActivation,
+		Data:     map[string]interface{}{"Username": "testuser", "Link": "http://localhost:8080/user/1/activate"},
+	}, queued)
+	suite.emailService.AssertNotCalled(suite.T(), "SendTemplate", mock.Anything)
 }
 
 func (suite *UserUsecaseTestSuite) TestRegister_MissingFields() {
@@ -132,33 +144,13 @@ func (suite *UserUsecaseTestSuite) TestRegister_RegistrationFails_RepoError() {
 	suite.userRepo.On("FetchByUsername", user.Username).Return(domain.User{}, errors.New("not found"))
 	suite.userRepo.On("FetchByEmail", user.Email).Return(domain.User{}, errors.New("not found"))
 	suite.pwdService.On("HashPassword", user.Password).Return("hashedpassword", nil)
-	suite.userRepo.On("Register", mock.AnythingOfType("*domain.User")).Return(domain.User{}, errors.New("db error"))
+	suite.userRepo.On("RegisterWithEmail", mock.AnythingOfType("*domain.User"), mock.Anything).Return(domain.User{}, errors.New("db error"))
 
 	_, err := suite.userUsecase.Register(user)
 	suite.Error(err)
 	suite.Equal("unable to register user", err.Error())
 }
 
-func (suite *UserUsecaseTestSuite) TestRegister_SendEmailFails() {
-	user := &domain.User{
-		Username: "testuser",
-		Email:    "test@example.com",
-		Password: "Password123!",
-	}
-	createdUser := *user
-	createdUser.ID = 1
-
-	suite.userRepo.On("FetchByUsername", user.Username).Return(domain.User{}, errors.New("not found"))
-	suite.userRepo.On("FetchByEmail", user.Email).Return(domain.User{}, errors.New("not found"))
-	suite.pwdService.On("HashPassword", user.Password).Return("hashedpassword", nil)
-	suite.userRepo.On("Register", mock.AnythingOfType("*domain.User")).Return(createdUser, nil)
-	suite.emailService.On("SendEmail", []string{user.Email}, "Activate Account", "http://localhost:8080/user/1/activate").Return(errors.New("email error"))
-
-	_, err := suite.userUsecase.Register(user)
-	suite.Error(err)
-	suite.Equal("unable to send activation link", err.Error())
-}
-
 func (suite *UserUsecaseTestSuite) TestActivateAccount_Success() {
 	suite.userRepo.On("Fetch", "1").Return(domain.User{}, nil)
 	suite.userRepo.On("ActivateAccount", "1").Return(nil)
@@ -192,7 +184,7 @@ func (suite *UserUsecaseTestSuite) TestLogin_Success() {
 	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
 	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
 
-	accessToken, refreshToken, err := suite.userUsecase.Login("testuser", "Password123!")
+	accessToken, refreshToken, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 
 	suite.NoError(err)
 	suite.Equal("access_token", accessToken)
@@ -200,9 +192,33 @@ func (suite *UserUsecaseTestSuite) TestLogin_Success() {
 	suite.tokenRepo.AssertNumberOfCalls(suite.T(), "Save", 2)
 }
 
+func (suite *UserUsecaseTestSuite) TestLogin_SuspendedUser() {
+	until := time.Now().Add(time.Hour)
+	user := domain.User{ID: 1, Username: "testuser", Password: "hashedpassword", Role: "user", Status: "suspended", SuspendedUntil: &until}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("Password123!")).Return(nil)
+
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.ErrorIs(err, domain.ErrAccountSuspended)
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateAccessToken", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_ExpiredSuspension() {
+	until := time.Now().Add(-time.Hour)
+	user := domain.User{ID: 1, Username: "testuser", Password: "hashedpassword", Role: "user", Status: "suspended", SuspendedUntil: &until}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("Password123!")).Return(nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.NoError(err)
+}
+
 func (suite *UserUsecaseTestSuite) TestLogin_InvalidIdentifier() {
 	suite.userRepo.On("FetchByUsername", "unknown").Return(domain.User{}, errors.New("not found"))
-	_, _, err := suite.userUsecase.Login("unknown", "Password123!")
+	_, _, err := suite.userUsecase.Login("unknown", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.Error(err)
 }
 
@@ -214,7 +230,7 @@ func (suite *UserUsecaseTestSuite) TestLogin_InvalidPassword() {
 	}
 	suite.userRepo.On("FetchByUsername", "testuser").Return(*user, nil)
 	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("WrongPassword!")).Return(errors.New("wrong password"))
-	_, _, err := suite.userUsecase.Login("testuser", "WrongPassword!")
+	_, _, err := suite.userUsecase.Login("testuser", "WrongPassword!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.Error(err)
 }
 
@@ -229,7 +245,7 @@ func (suite *UserUsecaseTestSuite) TestLogin_GenerateAccessTokenError() {
 	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("Password123!")).Return(nil)
 	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("", errors.New("jwt error"))
 
-	_, _, err := suite.userUsecase.Login("testuser", "Password123!")
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.Error(err)
 }
 
@@ -245,7 +261,7 @@ func (suite *UserUsecaseTestSuite) TestLogin_GenerateRefreshTokenError() {
 	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
 	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("", errors.New("jwt error"))
 
-	_, _, err := suite.userUsecase.Login("testuser", "Password123!")
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.Error(err)
 }
 
@@ -262,7 +278,7 @@ func (suite *UserUsecaseTestSuite) TestLogin_SaveAccessTokenError() {
 	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
 	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(errors.New("db error")).Once()
 
-	_, _, err := suite.userUsecase.Login("testuser", "Password123!")
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.Error(err)
 }
 
@@ -280,44 +296,210 @@ func (suite *UserUsecaseTestSuite) TestLogin_SaveRefreshTokenError() {
 	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Once()
 	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(errors.New("db error")).Once()
 
-	_, _, err := suite.userUsecase.Login("testuser", "Password123!")
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.Error(err)
 }
 
-func (suite *UserUsecaseTestSuite) TestPromote_Success() {
-	suite.userRepo.On("Fetch", "1").Return(domain.User{}, nil)
-	suite.userRepo.On("Promote", "1").Return(nil)
-	err := suite.userUsecase.Promote("1")
+func (suite *UserUsecaseTestSuite) TestLogin_TwoFactorEnabledReturnsChallenge() {
+	twoFactorRepo := new(mocks.MockTwoFactorRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithTwoFactor(twoFactorRepo))
+	user := domain.User{ID: 1, Username: "testuser", Password: "hashedpassword", Role: "user"}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("Password123!")).Return(nil)
+	twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{UserID: 1, Enabled: true}, nil)
+	suite.jwtService.On("GenerateChallengeToken", "1", "user").Return("challenge_token", nil)
+
+	accessToken, refreshToken, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+
+	var mfaErr *domain.MFARequiredError
+	suite.Require().ErrorAs(err, &mfaErr)
+	suite.Equal("challenge_token", mfaErr.ChallengeToken)
+	suite.Empty(accessToken)
+	suite.Empty(refreshToken)
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateAccessToken", "1", "user")
+	suite.tokenRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_TwoFactorNotEnabled() {
+	twoFactorRepo := new(mocks.MockTwoFactorRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithTwoFactor(twoFactorRepo))
+	user := domain.User{ID: 1, Username: "testuser", Password: "hashedpassword", Role: "user"}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("Password123!")).Return(nil)
+	twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{}, nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+
+	accessToken, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.NoError(err)
+	suite.Equal("access_token", accessToken)
 }
 
-func (suite *UserUsecaseTestSuite) TestPromote_UserNotFound() {
-	suite.userRepo.On("Fetch", "1").Return(domain.User{}, errors.New("not found"))
-	err := suite.userUsecase.Promote("1")
-	suite.Error(err)
-	suite.Equal("user not found", err.Error())
+func (suite *UserUsecaseTestSuite) TestLogin_TwoFactorLookupFailsClosed() {
+	twoFactorRepo := new(mocks.MockTwoFactorRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithTwoFactor(twoFactorRepo))
+	user := domain.User{ID: 1, Username: "testuser", Password: "hashedpassword", Role: "user"}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("Password123!")).Return(nil)
+	twoFactorRepo.On("FetchByUserID", int64(1)).Return(domain.TwoFactor{}, errors.New("db error"))
+
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.EqualError(err, "unable to check two-factor status")
+	suite.jwtService.AssertNotCalled(suite.T(), "GenerateAccessToken", "1", "user")
 }
 
-func (suite *UserUsecaseTestSuite) TestDemote_Success() {
-	suite.userRepo.On("Fetch", "1").Return(domain.User{}, nil)
-	suite.userRepo.On("Demote", "1").Return(nil)
-	err := suite.userUsecase.Demote("1")
+func (suite *UserUsecaseTestSuite) TestLogin_Throttled() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithLoginThrottle(throttler))
+	user := domain.User{ID: 1, Username: "testuser", Password: "hashedpassword", Role: "user"}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	throttler.On("Check", "1", "127.0.0.1").Return(30*time.Second, nil)
+
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+
+	var throttledErr *domain.LoginThrottledError
+	suite.Require().ErrorAs(err, &throttledErr)
+	suite.Equal(30*time.Second, throttledErr.RetryAfter)
+	suite.pwdService.AssertNotCalled(suite.T(), "ComparePassword", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_FailureLocksAccountAndNotifies() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithLoginThrottle(throttler))
+	user := domain.User{ID: 1, Username: "testuser", Email: "test@example.com", Password: "hashedpassword", Role: "user"}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	throttler.On("Check", "1", "127.0.0.1").Return(time.Duration(0), nil)
+	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("WrongPassword1!")).Return(errors.New("mismatch"))
+	throttler.On("RegisterFailure", "1", "127.0.0.1").Return(true, nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.Template == domain.EmailAccountLocked && m.To[0] == "test@example.com"
+	})).Return(nil)
+
+	_, _, err := suite.userUsecase.Login("testuser", "WrongPassword1!", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.EqualError(err, "invalid credentials")
+	suite.emailService.AssertExpectations(suite.T())
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_UnknownIdentifierIsThrottled() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithLoginThrottle(throttler))
+	suite.userRepo.On("FetchByUsername", "Ghost@Example.com").Return(domain.User{}, errors.New("not found"))
+	suite.userRepo.On("FetchByEmail", "Ghost@Example.com").Return(domain.User{}, errors.New("not found"))
+	throttler.On("Check", "unknown:ghost@example.com", "127.0.0.1").Return(time.Duration(0), nil)
+	throttler.On("RegisterFailure", "unknown:ghost@example.com", "127.0.0.1").Return(false, nil)
+
+	_, _, err := suite.userUsecase.Login("Ghost@Example.com", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.EqualError(err, "invalid identifier")
+	throttler.AssertExpectations(suite.T())
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_SuccessResetsFailures() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithLoginThrottle(throttler))
+	user := domain.User{ID: 1, Username: "testuser", Password: "hashedpassword", Role: "user"}
+	suite.userRepo.On("FetchByUsername", "testuser").Return(user, nil)
+	throttler.On("Check", "1", "127.0.0.1").Return(time.Duration(0), nil)
+	suite.pwdService.On("ComparePassword", []byte(user.Password), []byte("Password123!")).Return(nil)
+	throttler.On("RegisterSuccess", "1").Return(nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access_token", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh_token", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+
+	_, _, err := suite.userUsecase.Login("testuser", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
 	suite.NoError(err)
+	throttler.AssertExpectations(suite.T())
 }
 
-func (suite *UserUsecaseTestSuite) TestDemote_UserNotFound() {
+func (suite *UserUsecaseTestSuite) TestUnlockAccount_Success() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithLoginThrottle(throttler))
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1}, nil)
+	throttler.On("Unlock", "1").Return(nil)
+
+	suite.NoError(suite.userUsecase.UnlockAccount(domain.Principal{UserID: "2", Role: domain.RoleAdmin}, "1"))
+}
+
+func (suite *UserUsecaseTestSuite) TestUnlockAccount_UserNotFound() {
+	throttler := new(mocks.MockLoginThrottler)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithLoginThrottle(throttler))
+	suite.userRepo.On("Fetch", "9").Return(domain.User{}, errors.New("not found"))
+
+	suite.EqualError(suite.userUsecase.UnlockAccount(domain.Principal{UserID: "2", Role: domain.RoleAdmin}, "9"), "user not found")
+	throttler.AssertNotCalled(suite.T(), "Unlock", "9")
+}
+
+func (suite *UserUsecaseTestSuite) TestAssignRole_Success() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: domain.RoleAuthor}, nil)
+	suite.userRepo.On("ChangeRole", "1", domain.RoleEditor).Return(nil)
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleAdmin}, "1", "Editor")
+	suite.NoError(err)
+}
+
+func (suite *UserUsecaseTestSuite) TestAssignRole_SignsUserOut() {
+	accountRepo := new(mocks.MockAccountRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithSessionRevocation(accountRepo))
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: domain.RoleAdmin}, nil)
+	suite.userRepo.On("ChangeRole", "1", domain.RoleReader).Return(nil)
+	accountRepo.On("RevokeSessions", int64(1), mock.Anything).Return([]domain.Token{{Type: "access", Content: "access"}}, nil)
+	claims := &domain.TokenClaims{UserID: "1", UserRole: domain.RoleAdmin}
+	suite.jwtService.On("ValidateAccessToken", "Bearer access").Return(claims, nil)
+	suite.jwtService.On("RevokeToken", claims).Return(nil)
+
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleSuperadmin}, "1", domain.RoleReader)
+	suite.NoError(err)
+	accountRepo.AssertExpectations(suite.T())
+	suite.jwtService.AssertCalled(suite.T(), "RevokeToken", claims)
+}
+
+func (suite *UserUsecaseTestSuite) TestAssignRole_UserNotFound() {
 	suite.userRepo.On("Fetch", "1").Return(domain.User{}, errors.New("not found"))
-	err := suite.userUsecase.Demote("1")
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleAdmin}, "1", domain.RoleEditor)
 	suite.Error(err)
 	suite.Equal("user not found", err.Error())
 }
 
+func (suite *UserUsecaseTestSuite) TestAssignRole_UnknownRole() {
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleSuperadmin}, "1", "owner")
+	suite.EqualError(err, "unknown role")
+	suite.userRepo.AssertNotCalled(suite.T(), "ChangeRole", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestAssignRole_ActorWithoutPermission() {
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleModerator}, "1", domain.RoleReader)
+	suite.ErrorIs(err, domain.ErrRoleNotAllowed)
+}
+
+func (suite *UserUsecaseTestSuite) TestAssignRole_AdminCannotCreateAdmin() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: domain.RoleAuthor}, nil)
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleAdmin}, "1", domain.RoleAdmin)
+	suite.ErrorIs(err, domain.ErrRoleNotAllowed)
+	suite.userRepo.AssertNotCalled(suite.T(), "ChangeRole", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestAssignRole_AdminCannotDemoteSuperadmin() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: domain.RoleSuperadmin}, nil)
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleAdmin}, "1", domain.RoleReader)
+	suite.ErrorIs(err, domain.ErrRoleNotAllowed)
+}
+
+func (suite *UserUsecaseTestSuite) TestAssignRole_LastSuperadmin() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: domain.RoleSuperadmin}, nil)
+	suite.userRepo.On("ChangeRole", "1", domain.RoleAdmin).Return(domain.ErrLastSuperadmin)
+	err := suite.userUsecase.AssignRole(domain.Principal{UserID: "9", Role: domain.RoleSuperadmin}, "1", domain.RoleAdmin)
+	suite.ErrorIs(err, domain.ErrLastSuperadmin)
+}
+
 func (suite *UserUsecaseTestSuite) TestGetUserProfile_Success() {
-	expectedUser := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com"}
+	expectedUser := &domain.User{ID: 1, Username: "testuser", Email: "test@example.com", Password: "$argon2id$hash", Role: domain.RoleAuthor}
 	suite.userRepo.On("GetUserProfile", int64(1)).Return(expectedUser, nil)
 	user, err := suite.userUsecase.GetUserProfile(1)
 	suite.NoError(err)
-	suite.Equal(expectedUser, user)
+	suite.Equal(&domain.UserView{ID: 1, Username: "testuser", Email: "test@example.com", Role: domain.RoleAuthor}, user)
+
+	body, err := json.Marshal(expectedUser)
+	suite.NoError(err)
+	suite.NotContains(string(body), "argon2id")
 }
 
 func (suite *UserUsecaseTestSuite) TestGetUserProfile_NotFound() {
@@ -327,25 +509,188 @@ func (suite *UserUsecaseTestSuite) TestGetUserProfile_NotFound() {
 	suite.Nil(user)
 }
 
+func strPtr(s string) *string {
+	return &s
+}
+
 func (suite *UserUsecaseTestSuite) TestUpdateUserProfile_Success() {
 	userID := int64(1)
-	updates := map[string]interface{}{
-		"Username": "updateduser",
-		"Bio":      "updated bio",
-	}
-	suite.userRepo.On("UpdateUserProfile", userID, updates).Return(nil)
-	err := suite.userUsecase.UpdateUserProfile(userID, updates)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Username: "olduser", Email: "test@example.com"}, nil)
+	suite.userRepo.On("FetchByUsername", "updateduser").Return(domain.User{}, errors.New("not found"))
+	suite.userRepo.On("UpdateUserProfile", userID, domain.ProfileUpdate{
+		Username: strPtr("updateduser"),
+		Bio:      strPtr("updated bio"),
+		Phone:    strPtr("+14155550123"),
+	}).Return(nil)
+
+	err := suite.userUsecase.UpdateUserProfile(userID, domain.ProfileUpdate{
+		Username: strPtr(" updateduser "),
+		Bio:      strPtr("updated bio"),
+		Phone:    strPtr("+14155550123"),
+	})
 	suite.NoError(err)
 }
 
 func (suite *UserUsecaseTestSuite) TestUpdateUserProfile_NoFields() {
 	userID := int64(1)
-	updates := map[string]interface{}{}
-	suite.userRepo.On("UpdateUserProfile", userID, updates).Return(nil)
-	err := suite.userUsecase.UpdateUserProfile(userID, updates)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1}, nil)
+	suite.userRepo.On("UpdateUserProfile", userID, domain.ProfileUpdate{}).Return(nil)
+	err := suite.userUsecase.UpdateUserProfile(userID, domain.ProfileUpdate{})
 	suite.NoError(err)
 }
 
+func (suite *UserUsecaseTestSuite) TestUpdateUserProfile_Invalid() {
+	cases := map[string]domain.ProfileUpdate{
+		"username":        {Username: strPtr("no spaces allowed")},
+		"phone":           {Phone: strPtr("0415 555 0123")},
+		"bio":             {Bio: strPtr(strings.Repeat("a", 501))},
+		"profile_picture": {ProfilePicture: strPtr("javascript:alert(1)")},
+		"email":           {Email: strPtr("not-an-email")},
+		"locale":          {Locale: strPtr("french")},
+	}
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Email: "test@example.com"}, nil)
+
+	for field, update := range cases {
+		err := suite.userUsecase.UpdateUserProfile(1, update)
+		var validationErr *domain.ValidationError
+		if suite.ErrorAs(err, &validationErr, field) {
+			suite.Equal(field, validationErr.Field)
+		}
+	}
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateUserProfile", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestUpdateUserProfile_UsernameTaken() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1}, nil)
+	suite.userRepo.On("FetchByUsername", "taken").Return(domain.User{ID: 2, Username: "taken"}, nil)
+
+	err := suite.userUsecase.UpdateUserProfile(1, domain.ProfileUpdate{Username: strPtr("taken")})
+	suite.EqualError(err, "this username is already in use")
+}
+
+func (suite *UserUsecaseTestSuite) TestUpdateUserProfile_EmailNeedsConfirmation() {
+	changes := new(mocks.MockEmailChangeRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithEmailChanges(changes))
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Email: "old@example.com"}, nil)
+	suite.userRepo.On("FetchByEmail", "new@example.com").Return(domain.User{}, errors.New("not found"))
+	changes.On("Save", mock.MatchedBy(func(r *domain.EmailChangeRequest) bool {
+		return r.UserID == 1 && r.NewEmail == "new@example.com" && len(r.TokenHash) == 64 && r.ExpiresAt.After(time.Now())
+	})).Return(nil)
+	suite.emailService.On("SendTemplate", domain.EmailMessage{
+		To:       []string{"old@example.com"},
+		Template: domain.EmailChangeNotice,
+		Data:     map[string]interface{}{"NewEmail": "new@example.com"},
+	}).Return(nil)
+	suite.emailService.On("SendTemplate", mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.Template == domain.EmailChangeConfirmation && m.To[0] == "new@example.com" && strings.Contains(m.Data["Link"].(string), "http://localhost:8080/email/confirm?token=")
+	})).Return(nil)
+	suite.userRepo.On("UpdateUserProfile", int64(1), domain.ProfileUpdate{Email: strPtr("new@example.com")}).Return(nil)
+
+	err := suite.userUsecase.UpdateUserProfile(1, domain.ProfileUpdate{Email: strPtr("new@example.com")})
+	suite.NoError(err)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateEmail", mock.Anything, mock.Anything)
+	suite.emailService.AssertExpectations(suite.T())
+}
+
+func (suite *UserUsecaseTestSuite) TestUpdateUserProfile_EmailChangesDisabled() {
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Email: "old@example.com"}, nil)
+
+	err := suite.userUsecase.UpdateUserProfile(1, domain.ProfileUpdate{Email: strPtr("new@example.com")})
+	suite.EqualError(err, "email address cannot be changed")
+}
+
+func (suite *UserUsecaseTestSuite) TestConfirmEmailChange_Success() {
+	changes := new(mocks.MockEmailChangeRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithEmailChanges(changes))
+	changes.On("Consume", mock.AnythingOfType("string")).Return(domain.EmailChangeRequest{UserID: 1, NewEmail: "new@example.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	suite.userRepo.On("FetchByEmail", "new@example.com").Return(domain.User{}, errors.New("not found"))
+	suite.userRepo.On("UpdateEmail", int64(1), "new@example.com").Return(nil)
+
+	suite.NoError(suite.userUsecase.ConfirmEmailChange("token"))
+}
+
+func (suite *UserUsecaseTestSuite) TestConfirmEmailChange_Expired() {
+	changes := new(mocks.MockEmailChangeRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithEmailChanges(changes))
+	changes.On("Consume", mock.AnythingOfType("string")).Return(domain.EmailChangeRequest{UserID: 1, NewEmail: "new@example.com", ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+	suite.EqualError(suite.userUsecase.ConfirmEmailChange("token"), "invalid or expired link")
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateEmail", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestConfirmEmailChange_AddressTakenMeanwhile() {
+	changes := new(mocks.MockEmailChangeRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithEmailChanges(changes))
+	changes.On("Consume", mock.AnythingOfType("string")).Return(domain.EmailChangeRequest{UserID: 1, NewEmail: "new@example.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	suite.userRepo.On("FetchByEmail", "new@example.com").Return(domain.User{ID: 2}, nil)
+
+	suite.EqualError(suite.userUsecase.ConfirmEmailChange("token"), "this email is already in use")
+}
+
+func (suite *UserUsecaseTestSuite) TestLogout_Success() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	suite.jwtService.On("ValidateAccessToken", "Bearer access").Return(claims, nil)
+	suite.jwtService.On("RevokeToken", claims).Return(nil)
+
+	refreshClaims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	suite.jwtService.On("ValidateRefreshToken", "Bearer refresh").Return(refreshClaims, nil)
+	suite.jwtService.On("RevokeToken", refreshClaims).Return(nil)
+
+	err := suite.userUsecase.Logout("Bearer access", "refresh", domain.ClientInfo{})
+	suite.NoError(err)
+	suite.jwtService.AssertExpectations(suite.T())
+}
+
+func (suite *UserUsecaseTestSuite) TestLogout_RefreshFailsAfterwards() {
+	tokenRepo := new(mocks.MockTokenRepository)
+	jwtService := &infrastructure.JWTInfrastructure{AccessSecret: []byte("access"), RefreshSecret: []byte("refresh"), TokenRepo: tokenRepo, Denylist: infrastructure.NewInMemoryTokenDenylist()}
+	usecase := usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, jwtService, tokenRepo)
+	access, _ := jwtService.GenerateAccessToken("1", "author")
+	refresh, _ := jwtService.GenerateRefreshToken("1", "author")
+	tokenRepo.On("FetchByContent", refresh).Return(domain.Token{Type: "refresh", Content: refresh, Status: "active", UserID: 1}, nil)
+
+	suite.NoError(usecase.Logout("Bearer "+access, refresh, domain.ClientInfo{}))
+	_, _, err := usecase.RefreshToken("Bearer " + refresh)
+	suite.EqualError(err, "revoked token")
+}
+
+func (suite *UserUsecaseTestSuite) TestLogout_WithoutRefreshTokenEndsAllSessions() {
+	accountRepo := new(mocks.MockAccountRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithSessionRevocation(accountRepo))
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	suite.jwtService.On("ValidateAccessToken", "Bearer access").Return(claims, nil)
+	suite.jwtService.On("RevokeToken", claims).Return(nil)
+	accountRepo.On("RevokeSessions", int64(1), mock.Anything).Return([]domain.Token{{Type: "refresh", Content: "refresh"}}, nil)
+
+	suite.NoError(suite.userUsecase.Logout("Bearer access", "", domain.ClientInfo{}))
+	accountRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUsecaseTestSuite) TestLogout_RefreshTokenOfAnotherUser() {
+	suite.jwtService.On("ValidateAccessToken", "Bearer access").Return(&domain.TokenClaims{UserID: "1"}, nil)
+	suite.jwtService.On("ValidateRefreshToken", "Bearer refresh").Return(&domain.TokenClaims{UserID: "2"}, nil)
+
+	suite.EqualError(suite.userUsecase.Logout("Bearer access", "refresh", domain.ClientInfo{}), "invalid refresh token")
+	suite.jwtService.AssertNotCalled(suite.T(), "RevokeToken", mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestLogout_InvalidToken() {
+	suite.jwtService.On("ValidateAccessToken", "Bearer bad").Return(nil, errors.New("invalid token"))
+
+	err := suite.userUsecase.Logout("Bearer bad", "", domain.ClientInfo{})
+	suite.Error(err)
+	suite.jwtService.AssertNotCalled(suite.T(), "RevokeToken", mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestLogout_RevokeError() {
+	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
+	suite.jwtService.On("ValidateAccessToken", "Bearer access").Return(claims, nil)
+	suite.jwtService.On("RevokeToken", claims).Return(errors.New("db error"))
+
+	err := suite.userUsecase.Logout("Bearer access", "", domain.ClientInfo{})
+	suite.EqualError(err, "unable to revoke token")
+}
+
 func (suite *UserUsecaseTestSuite) TestRefreshToken_Success() {
 	jwtMock := new(mocks.MockJWTService)
 	tokenMock := new(mocks.MockTokenRepository)
@@ -355,6 +700,7 @@ func (suite *UserUsecaseTestSuite) TestRefreshToken_Success() {
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	authHeader := "Bearer old_refresh"
 	jwtMock.On("ValidateRefreshToken", authHeader).Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "active"}, nil)
 	jwtMock.On("GenerateAccessToken", "1", "user").Return("new_access", nil)
 	jwtMock.On("GenerateRefreshToken", "1", "user").Return("new_refresh", nil)
 	tokenMock.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
@@ -364,6 +710,34 @@ func (suite *UserUsecaseTestSuite) TestRefreshToken_Success() {
 	suite.Equal("new_refresh", refresh)
 }
 
+func (suite *UserUsecaseTestSuite) TestRefreshToken_UsesCurrentRole() {
+	jwtMock := new(mocks.MockJWTService)
+	tokenMock := new(mocks.MockTokenRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, jwtMock, tokenMock)
+	authHeader := "Bearer old_refresh"
+	jwtMock.On("ValidateRefreshToken", authHeader).Return(&domain.TokenClaims{UserID: "1", UserRole: domain.RoleAdmin}, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: domain.RoleAuthor, Status: "active"}, nil)
+	jwtMock.On("GenerateAccessToken", "1", domain.RoleAuthor).Return("new_access", nil)
+	jwtMock.On("GenerateRefreshToken", "1", domain.RoleAuthor).Return("new_refresh", nil)
+	tokenMock.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Twice()
+
+	_, _, err := suite.userUsecase.RefreshToken(authHeader)
+	suite.NoError(err)
+	jwtMock.AssertNotCalled(suite.T(), "GenerateAccessToken", "1", domain.RoleAdmin)
+}
+
+func (suite *UserUsecaseTestSuite) TestRefreshToken_SuspendedUser() {
+	jwtMock := new(mocks.MockJWTService)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, jwtMock, suite.tokenRepo)
+	authHeader := "Bearer old_refresh"
+	jwtMock.On("ValidateRefreshToken", authHeader).Return(&domain.TokenClaims{UserID: "1", UserRole: "user"}, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "banned"}, nil)
+
+	_, _, err := suite.userUsecase.RefreshToken(authHeader)
+	suite.ErrorIs(err, domain.ErrAccountSuspended)
+	jwtMock.AssertNotCalled(suite.T(), "GenerateAccessToken", mock.Anything, mock.Anything)
+}
+
 func (suite *UserUsecaseTestSuite) TestRefreshToken_ValidateError() {
 	jwtMock := new(mocks.MockJWTService)
 	tokenMock := new(mocks.MockTokenRepository)
@@ -385,6 +759,7 @@ func (suite *UserUsecaseTestSuite) TestRefreshToken_GenerateAccessTokenError() {
 	authHeader := "Bearer old_refresh"
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	jwtMock.On("ValidateRefreshToken", authHeader).Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "active"}, nil)
 	jwtMock.On("GenerateAccessToken", "1", "user").Return("", errors.New("gen err"))
 	_, _, err := suite.userUsecase.RefreshToken(authHeader)
 	suite.Error(err)
@@ -399,6 +774,7 @@ func (suite *UserUsecaseTestSuite) TestRefreshToken_GenerateRefreshTokenError()
 	authHeader := "Bearer old_refresh"
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	jwtMock.On("ValidateRefreshToken", authHeader).Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "active"}, nil)
 	jwtMock.On("GenerateAccessToken", "1", "user").Return("new_access", nil)
 	jwtMock.On("GenerateRefreshToken", "1", "user").Return("", errors.New("gen err"))
 	_, _, err := suite.userUsecase.RefreshToken(authHeader)
@@ -414,6 +790,7 @@ func (suite *UserUsecaseTestSuite) TestRefreshToken_SaveAccessTokenError() {
 	authHeader := "Bearer old_refresh"
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	jwtMock.On("ValidateRefreshToken", authHeader).Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "active"}, nil)
 	jwtMock.On("GenerateAccessToken", "1", "user").Return("new_access", nil)
 	jwtMock.On("GenerateRefreshToken", "1", "user").Return("new_refresh", nil)
 	tokenMock.On("Save", mock.AnythingOfType("*domain.Token")).Return(errors.New("db err")).Once()
@@ -430,6 +807,7 @@ func (suite *UserUsecaseTestSuite) TestRefreshToken_SaveRefreshTokenError() {
 	authHeader := "Bearer old_refresh"
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	jwtMock.On("ValidateRefreshToken", authHeader).Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Role: "user", Status: "active"}, nil)
 	jwtMock.On("GenerateAccessToken", "1", "user").Return("new_access", nil)
 	jwtMock.On("GenerateRefreshToken", "1", "user").Return("new_refresh", nil)
 	tokenMock.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil).Once()
@@ -444,13 +822,13 @@ func (suite *UserUsecaseTestSuite) TestResetPassword_Success() {
 	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("OldPass123!")).Return(nil)
 	suite.pwdService.On("HashPassword", "NewPass123!").Return("new_hashed", nil)
 	suite.userRepo.On("ResetPassword", "1", "new_hashed").Return(nil)
-	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!")
+	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!", domain.ClientInfo{})
 	suite.NoError(err)
 }
 
 func (suite *UserUsecaseTestSuite) TestResetPassword_UserNotFound() {
 	suite.userRepo.On("Fetch", "1").Return(domain.User{}, errors.New("not found"))
-	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!")
+	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!", domain.ClientInfo{})
 	suite.Error(err)
 }
 
@@ -458,7 +836,7 @@ func (suite *UserUsecaseTestSuite) TestResetPassword_InvalidOldPassword() {
 	user := domain.User{ID: 1, Password: "old_hashed"}
 	suite.userRepo.On("Fetch", "1").Return(user, nil)
 	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("WrongOld123!")).Return(errors.New("mismatch"))
-	err := suite.userUsecase.ResetPassword("1", "WrongOld123!", "NewPass123!")
+	err := suite.userUsecase.ResetPassword("1", "WrongOld123!", "NewPass123!", domain.ClientInfo{})
 	suite.Error(err)
 }
 
@@ -466,7 +844,7 @@ func (suite *UserUsecaseTestSuite) TestResetPassword_InvalidNewPasswordFormat()
 	user := domain.User{ID: 1, Password: "old_hashed"}
 	suite.userRepo.On("Fetch", "1").Return(user, nil)
 	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("OldPass123!")).Return(nil)
-	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "weak")
+	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "weak", domain.ClientInfo{})
 	suite.Error(err)
 }
 
@@ -475,7 +853,7 @@ func (suite *UserUsecaseTestSuite) TestResetPassword_HashError() {
 	suite.userRepo.On("Fetch", "1").Return(user, nil)
 	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("OldPass123!")).Return(nil)
 	suite.pwdService.On("HashPassword", "NewPass123!").Return("", errors.New("hash fail"))
-	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!")
+	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!", domain.ClientInfo{})
 	suite.Error(err)
 }
 
@@ -485,28 +863,29 @@ func (suite *UserUsecaseTestSuite) TestResetPassword_UpdateError() {
 	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("OldPass123!")).Return(nil)
 	suite.pwdService.On("HashPassword", "NewPass123!").Return("new_hashed", nil)
 	suite.userRepo.On("ResetPassword", "1", "new_hashed").Return(errors.New("db error"))
-	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!")
+	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "NewPass123!", domain.ClientInfo{})
 	suite.Error(err)
 }
 
 func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_Success() {
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	suite.jwtService.On("ValidateAccessToken", "Bearer token123").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Username: "user1", Email: "user@example.com"}, nil)
 	suite.pwdService.On("HashPassword", "NewPass123!").Return("new_hashed", nil)
 	suite.userRepo.On("ResetPassword", "1", "new_hashed").Return(nil)
-	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "token123")
+	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "token123", domain.ClientInfo{})
 	suite.NoError(err)
 }
 
 func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_MissingToken() {
-	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "")
+	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "", domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("token required", err.Error())
 }
 
 func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_InvalidToken() {
 	suite.jwtService.On("ValidateAccessToken", "Bearer badtoken").Return((*domain.TokenClaims)(nil), errors.New("invalid token"))
-	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "badtoken")
+	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "badtoken", domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("invalid or expired token", err.Error())
 }
@@ -514,7 +893,7 @@ func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_InvalidToken() {
 func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_TokenUserMismatch() {
 	claims := &domain.TokenClaims{UserID: "2", UserRole: "user"}
 	suite.jwtService.On("ValidateAccessToken", "Bearer tokenMismatch").Return(claims, nil)
-	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "tokenMismatch")
+	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "tokenMismatch", domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("token does not match user", err.Error())
 }
@@ -522,15 +901,17 @@ func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_TokenUserMismatch()
 func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_InvalidPasswordFormat() {
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	suite.jwtService.On("ValidateAccessToken", "Bearer tokenFormat").Return(claims, nil)
-	err := suite.userUsecase.UpdatePasswordDirect("1", "weak", "tokenFormat")
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Username: "user1", Email: "user@example.com"}, nil)
+	err := suite.userUsecase.UpdatePasswordDirect("1", "weak", "tokenFormat", domain.ClientInfo{})
 	suite.Error(err)
 }
 
 func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_HashError() {
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	suite.jwtService.On("ValidateAccessToken", "Bearer tokenHash").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Username: "user1", Email: "user@example.com"}, nil)
 	suite.pwdService.On("HashPassword", "NewPass123!").Return("", errors.New("hash fail"))
-	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "tokenHash")
+	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "tokenHash", domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("could not hash password", err.Error())
 }
@@ -538,9 +919,10 @@ func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_HashError() {
 func (suite *UserUsecaseTestSuite) TestUpdatePasswordDirect_UpdateError() {
 	claims := &domain.TokenClaims{UserID: "1", UserRole: "user"}
 	suite.jwtService.On("ValidateAccessToken", "Bearer tokenUpdate").Return(claims, nil)
+	suite.userRepo.On("Fetch", "1").Return(domain.User{ID: 1, Username: "user1", Email: "user@example.com"}, nil)
 	suite.pwdService.On("HashPassword", "NewPass123!").Return("new_hashed", nil)
 	suite.userRepo.On("ResetPassword", "1", "new_hashed").Return(errors.New("db error"))
-	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "tokenUpdate")
+	err := suite.userUsecase.UpdatePasswordDirect("1", "NewPass123!", "tokenUpdate", domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("could not update password", err.Error())
 }
@@ -549,24 +931,26 @@ func (suite *UserUsecaseTestSuite) TestForgotPassword_Success() {
 	user := domain.User{ID: 1, Email: "user@example.com", Role: "user"}
 	suite.userRepo.On("FetchByEmail", user.Email).Return(user, nil)
 	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("reset_token", nil)
-	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
-	suite.emailService.On("SendEmail", []string{user.Email}, "Reset Password", mock.MatchedBy(func(body string) bool {
-		return strings.Contains(body, "/password/1/update?token=reset_token")
+	suite.tokenRepo.On("SaveWithEmail", mock.MatchedBy(func(t *domain.Token) bool {
+		return t.Content == "reset_token" && t.UserID == 1
+	}), mock.MatchedBy(func(m domain.EmailMessage) bool {
+		return m.Template == domain.EmailPasswordReset && m.To[0] == user.Email && strings.Contains(m.Data["Link"].(string), "/password/1/update?token=reset_token")
 	})).Return(nil)
 
-	err := suite.userUsecase.ForgotPassword(user.Email)
+	err := suite.userUsecase.ForgotPassword(user.Email, domain.ClientInfo{})
 	suite.NoError(err)
+	suite.emailService.AssertNotCalled(suite.T(), "SendTemplate", mock.Anything)
 }
 
 func (suite *UserUsecaseTestSuite) TestForgotPassword_EmptyEmail() {
-	err := suite.userUsecase.ForgotPassword("")
+	err := suite.userUsecase.ForgotPassword("", domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("email required", err.Error())
 }
 
 func (suite *UserUsecaseTestSuite) TestForgotPassword_UserNotFound() {
 	suite.userRepo.On("FetchByEmail", "missing@example.com").Return(domain.User{}, errors.New("not found"))
-	err := suite.userUsecase.ForgotPassword("missing@example.com")
+	err := suite.userUsecase.ForgotPassword("missing@example.com", domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("user not found", err.Error())
 }
@@ -575,35 +959,140 @@ func (suite *UserUsecaseTestSuite) TestForgotPassword_GenerateTokenError() {
 	user := domain.User{ID: 1, Email: "user@example.com", Role: "user"}
 	suite.userRepo.On("FetchByEmail", user.Email).Return(user, nil)
 	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("", errors.New("gen err"))
-	err := suite.userUsecase.ForgotPassword(user.Email)
+	err := suite.userUsecase.ForgotPassword(user.Email, domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("could not generate reset token", err.Error())
-	suite.tokenRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
-	suite.emailService.AssertNotCalled(suite.T(), "SendEmail", mock.Anything, mock.Anything, mock.Anything)
+	suite.tokenRepo.AssertNotCalled(suite.T(), "SaveWithEmail", mock.Anything, mock.Anything)
 }
 
 func (suite *UserUsecaseTestSuite) TestForgotPassword_PersistTokenError() {
 	user := domain.User{ID: 1, Email: "user@example.com", Role: "user"}
 	suite.userRepo.On("FetchByEmail", user.Email).Return(user, nil)
 	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("reset_token", nil)
-	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(errors.New("db err"))
-	err := suite.userUsecase.ForgotPassword(user.Email)
+	suite.tokenRepo.On("SaveWithEmail", mock.AnythingOfType("*domain.Token"), mock.AnythingOfType("domain.EmailMessage")).Return(errors.New("db err"))
+	err := suite.userUsecase.ForgotPassword(user.Email, domain.ClientInfo{})
 	suite.Error(err)
 	suite.Equal("could not persist reset token", err.Error())
-	suite.emailService.AssertNotCalled(suite.T(), "SendEmail", mock.Anything, mock.Anything, mock.Anything)
 }
 
-func (suite *UserUsecaseTestSuite) TestForgotPassword_SendEmailError() {
-	user := domain.User{ID: 1, Email: "user@example.com", Role: "user"}
-	suite.userRepo.On("FetchByEmail", user.Email).Return(user, nil)
-	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("reset_token", nil)
+func TestUserUsecase(t *testing.T) {
+	suite.Run(t, new(UserUsecaseTestSuite))
+}
+
+func (suite *UserUsecaseTestSuite) withPasswordPolicy() (*mocks.MockPasswordPolicy, *mocks.MockPasswordHistoryRepository) {
+	policy := new(mocks.MockPasswordPolicy)
+	history := new(mocks.MockPasswordHistoryRepository)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo, usecases.WithPasswordPolicy(policy), usecases.WithPasswordHistory(history, 3))
+	return policy, history
+}
+
+func (suite *UserUsecaseTestSuite) TestResetPassword_PolicyFeedback() {
+	policy, history := suite.withPasswordPolicy()
+	user := domain.User{ID: 1, Username: "alice", Email: "alice@example.com", Password: "old_hashed"}
+	suite.userRepo.On("Fetch", "1").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("OldPass123!")).Return(nil)
+	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("Alice2024!!")).Return(errors.New("mismatch"))
+	history.On("Recent", int64(1), 3).Return([]domain.PasswordHistory{}, nil)
+	feedback := domain.PasswordFeedback{Valid: true, Score: 1}
+	feedback.Add(domain.PasswordRuleResult{Rule: domain.PasswordRuleStrength, Message: "password is too easy to guess"})
+	policy.On("Check", "Alice2024!!", domain.PasswordContext{Username: "alice", Email: "alice@example.com"}).Return(feedback)
+
+	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "Alice2024!!", domain.ClientInfo{})
+
+	var policyErr *domain.PasswordPolicyError
+	suite.Require().ErrorAs(err, &policyErr)
+	suite.Equal("password is too easy to guess", err.Error())
+	suite.Len(policyErr.Feedback.Rules, 2)
+	suite.Equal(domain.PasswordRuleReuse, policyErr.Feedback.Rules[1].Rule)
+	suite.True(policyErr.Feedback.Rules[1].Passed)
+	suite.userRepo.AssertNotCalled(suite.T(), "ResetPassword", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUsecaseTestSuite) TestResetPassword_RejectsRecentPassword() {
+	policy, history := suite.withPasswordPolicy()
+	user := domain.User{ID: 1, Password: "current_hashed"}
+	suite.userRepo.On("Fetch", "1").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte("current_hashed"), []byte("OldPass123!")).Return(nil)
+	suite.pwdService.On("ComparePassword", []byte("current_hashed"), []byte("Earlier#Pass9")).Return(errors.New("mismatch"))
+	suite.pwdService.On("ComparePassword", []byte("earlier_hashed"), []byte("Earlier#Pass9")).Return(nil)
+	history.On("Recent", int64(1), 3).Return([]domain.PasswordHistory{{UserID: 1, Hash: "current_hashed"}, {UserID: 1, Hash: "earlier_hashed"}}, nil)
+	policy.On("Check", "Earlier#Pass9", mock.Anything).Return(domain.PasswordFeedback{Valid: true, Score: 4})
+
+	err := suite.userUsecase.ResetPassword("1", "OldPass123!", "Earlier#Pass9", domain.ClientInfo{})
+
+	var policyErr *domain.PasswordPolicyError
+	suite.Require().ErrorAs(err, &policyErr)
+	suite.Equal("password must differ from your last 3 passwords", err.Error())
+}
+
+func (suite *UserUsecaseTestSuite) TestResetPassword_RemembersNewPassword() {
+	policy, history := suite.withPasswordPolicy()
+	user := domain.User{ID: 1, Password: "old_hashed"}
+	suite.userRepo.On("Fetch", "1").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("OldPass123!")).Return(nil)
+	suite.pwdService.On("ComparePassword", []byte("old_hashed"), []byte("Fresh#Pass42")).Return(errors.New("mismatch"))
+	history.On("Recent", int64(1), 3).Return([]domain.PasswordHistory{}, nil)
+	policy.On("Check", "Fresh#Pass42", mock.Anything).Return(domain.PasswordFeedback{Valid: true, Score: 4})
+	suite.pwdService.On("HashPassword", "Fresh#Pass42").Return("new_hashed", nil)
+	suite.userRepo.On("ResetPassword", "1", "new_hashed").Return(nil)
+	history.On("Add", &domain.PasswordHistory{UserID: 1, Hash: "new_hashed"}, 3).Return(nil)
+
+	suite.NoError(suite.userUsecase.ResetPassword("1", "OldPass123!", "Fresh#Pass42", domain.ClientInfo{}))
+	history.AssertExpectations(suite.T())
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_PolicySkipsFormatCheck() {
+	suite.withPasswordPolicy()
+	user := domain.User{ID: 1, Username: "alice", Password: "hashed", Role: "user", Status: "active"}
+	suite.userRepo.On("FetchByUsername", "alice").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte("hashed"), []byte("long passphrase without symbols")).Return(nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh", nil)
 	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
-	suite.emailService.On("SendEmail", []string{user.Email}, "Reset Password", mock.AnythingOfType("string")).Return(errors.New("smtp err"))
-	err := suite.userUsecase.ForgotPassword(user.Email)
-	suite.Error(err)
-	suite.Equal("could not send reset link", err.Error())
+
+	access, _, err := suite.userUsecase.Login("alice", "long passphrase without symbols", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.NoError(err)
+	suite.Equal("access", access)
 }
 
-func TestUserUsecase(t *testing.T) {
-	suite.Run(t, new(UserUsecaseTestSuite))
+func (suite *UserUsecaseTestSuite) TestCheckPassword_WithoutPolicy() {
+	feedback := suite.userUsecase.CheckPassword("weak", domain.PasswordContext{})
+	suite.False(feedback.Valid)
+	suite.Equal(domain.PasswordRuleComposition, feedback.Rules[0].Rule)
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_RehashesOutdatedHash() {
+	suite.pwdService = new(mocks.MockPasswordService)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo)
+	user := domain.User{ID: 1, Username: "alice", Password: "$2a$10$legacy", Role: "user", Status: "active"}
+	suite.userRepo.On("FetchByUsername", "alice").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte("$2a$10$legacy"), []byte("Password123!")).Return(nil)
+	suite.pwdService.On("NeedsRehash", "$2a$10$legacy").Return(true)
+	suite.pwdService.On("HashPassword", "Password123!").Return("$argon2id$new", nil)
+	suite.userRepo.On("ResetPassword", "1", "$argon2id$new").Return(nil)
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+
+	_, _, err := suite.userUsecase.Login("alice", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.NoError(err)
+	suite.userRepo.AssertCalled(suite.T(), "ResetPassword", "1", "$argon2id$new")
+}
+
+func (suite *UserUsecaseTestSuite) TestLogin_RehashFailureDoesNotBlockLogin() {
+	suite.pwdService = new(mocks.MockPasswordService)
+	suite.userUsecase = usecases.NewUserUsecase(suite.userRepo, suite.emailService, suite.pwdService, suite.jwtService, suite.tokenRepo)
+	user := domain.User{ID: 1, Username: "alice", Password: "$2a$10$legacy", Role: "user", Status: "active"}
+	suite.userRepo.On("FetchByUsername", "alice").Return(user, nil)
+	suite.pwdService.On("ComparePassword", []byte("$2a$10$legacy"), []byte("Password123!")).Return(nil)
+	suite.pwdService.On("NeedsRehash", "$2a$10$legacy").Return(true)
+	suite.pwdService.On("HashPassword", "Password123!").Return("$argon2id$new", nil)
+	suite.userRepo.On("ResetPassword", "1", "$argon2id$new").Return(errors.New("db error"))
+	suite.jwtService.On("GenerateAccessToken", "1", "user").Return("access", nil)
+	suite.jwtService.On("GenerateRefreshToken", "1", "user").Return("refresh", nil)
+	suite.tokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+
+	access, _, err := suite.userUsecase.Login("alice", "Password123!", domain.ClientInfo{IP: "127.0.0.1"})
+	suite.NoError(err)
+	suite.Equal("access", access)
 }
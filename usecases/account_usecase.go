@@ -0,0 +1,335 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	exportTTL = 7 * 24 * time.Hour
+	// building an archive reads everything a user ever wrote, so one per day
+	// is plenty
+	exportCooldown = 24 * time.Hour
+	// a build that outlives its lease is assumed dead and picked up again
+	exportLease = 15 * time.Minute
+	exportBatch = 10
+)
+
+type AccountUsecase struct {
+	userRepo        domain.IUserRepository
+	accountRepo     domain.IAccountRepository
+	emailService    domain.IEmailInfrastructure
+	passwordService domain.IPasswordInfrastructure
+	jwtService      domain.IJWTInfrastructure
+	config          domain.AccountDeletionConfig
+}
+
+func NewAccountUsecase(ur domain.IUserRepository, ar domain.IAccountRepository, es domain.IEmailInfrastructure, ps domain.IPasswordInfrastructure, js domain.IJWTInfrastructure, config domain.AccountDeletionConfig) *AccountUsecase {
+	return &AccountUsecase{
+		userRepo:        ur,
+		accountRepo:     ar,
+		emailService:    es,
+		passwordService: ps,
+		jwtService:      js,
+		config:          config,
+	}
+}
+
+// RequestExport queues an archive of the user's data. ProcessPendingExports
+// builds it and mails the download link.
+func (au *AccountUsecase) RequestExport(userID string) error {
+	user, err := au.userRepo.Fetch(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	latest, err := au.accountRepo.LatestExport(user.ID)
+	if err != nil {
+		return errors.New("unable to request export")
+	}
+	if latest.ID != 0 && latest.Status != "failed" && time.Since(latest.CreatedAt) < exportCooldown {
+		return errors.New("an export was requested recently, check your email")
+	}
+
+	export := domain.DataExport{UserID: user.ID, Status: "pending"}
+	if err := au.accountRepo.CreateExport(&export); err != nil {
+		return errors.New("unable to request export")
+	}
+	return nil
+}
+
+// ProcessPendingExports claims a batch of queued archives and builds them. A
+// failure on one export marks it failed and does not hold up the others.
+func (au *AccountUsecase) ProcessPendingExports() error {
+	exports, err := au.accountRepo.ClaimExports(time.Now(), exportLease, exportBatch)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range exports {
+		if err := au.buildExport(&exports[i]); err != nil {
+			exports[i].Status = "failed"
+			exports[i].Archive = nil
+			exports[i].TokenHash = nil
+			exports[i].LockedUntil = nil
+			errs = append(errs, fmt.Errorf("export %d: %w", exports[i].ID, err), au.accountRepo.SaveExport(&exports[i]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (au *AccountUsecase) buildExport(export *domain.DataExport) error {
+	data, err := au.accountRepo.CollectPersonalData(export.UserID)
+	if err != nil {
+		return err
+	}
+	archive, err := buildArchive(data)
+	if err != nil {
+		return err
+	}
+	token, err := randomURLToken(32)
+	if err != nil {
+		return err
+	}
+
+	tokenHash := hashSecret(token)
+	expiresAt := time.Now().Add(exportTTL)
+	export.Status = "ready"
+	export.Archive = archive
+	export.TokenHash = &tokenHash
+	export.ExpiresAt = &expiresAt
+	export.LockedUntil = nil
+	if err := au.accountRepo.SaveExport(export); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%v://%v:%v/exports/%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), token)
+	return au.emailService.SendTemplate(domain.EmailMessage{
+		To:       []string{data.User.Email},
+		Template: domain.EmailExportReady,
+		Locale:   data.User.Locale,
+		Data:     map[string]interface{}{"Link": link, "ValidFor": "7 days"},
+	})
+}
+
+// PurgeExpiredExports drops archives whose download link has expired.
+func (au *AccountUsecase) PurgeExpiredExports() error {
+	_, err := au.accountRepo.PurgeExpiredExports(time.Now())
+	return err
+}
+
+func (au *AccountUsecase) DownloadExport(token string) ([]byte, error) {
+	export, err := au.accountRepo.FetchExportByTokenHash(hashSecret(token))
+	if err != nil || export.Status != "ready" || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, errors.New("invalid or expired link")
+	}
+	return export.Archive, nil
+}
+
+// RequestDeletion schedules the account for deletion after the grace period
+// and signs the user out everywhere. Logging in again does not cancel it;
+// CancelDeletion does.
+func (au *AccountUsecase) RequestDeletion(userID string, password string) (domain.AccountDeletion, error) {
+	user, err := au.userRepo.Fetch(userID)
+	if err != nil {
+		return domain.AccountDeletion{}, errors.New("user not found")
+	}
+	if domain.NormalizeRole(user.Role) == domain.RoleSuperadmin {
+		return domain.AccountDeletion{}, errors.New("hand over the superadmin role before deleting this account")
+	}
+	if user.Password == "" {
+		return domain.AccountDeletion{}, errors.New("set a password before deleting this account")
+	}
+	if err := au.passwordService.ComparePassword([]byte(user.Password), []byte(password)); err != nil {
+		return domain.AccountDeletion{}, errors.New("invalid credentials")
+	}
+
+	deletion := domain.AccountDeletion{UserID: user.ID, ScheduledFor: time.Now().Add(au.config.GracePeriod)}
+	if err := au.accountRepo.ScheduleDeletion(&deletion); err != nil {
+		return domain.AccountDeletion{}, errors.New("unable to schedule deletion")
+	}
+	if err := revokeAllSessions(au.accountRepo, au.jwtService, user.ID); err != nil {
+		return domain.AccountDeletion{}, errors.New("unable to sign out other sessions")
+	}
+
+	_ = au.emailService.SendTemplate(domain.EmailMessage{
+		To:       []string{user.Email},
+		Template: domain.EmailDeletionScheduled,
+		Locale:   user.Locale,
+		Data:     map[string]interface{}{"ScheduledFor": deletion.ScheduledFor.UTC().Format(time.RFC1123)},
+	})
+	return deletion, nil
+}
+
+func (au *AccountUsecase) CancelDeletion(userID string) error {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := au.accountRepo.CancelDeletion(id); err != nil {
+		return errors.New("no deletion is scheduled")
+	}
+	return nil
+}
+
+// ProcessDueDeletions applies the configured policy to every account whose
+// grace period is over.
+func (au *AccountUsecase) ProcessDueDeletions() error {
+	deletions, err := au.accountRepo.FetchDueDeletions(time.Now())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, deletion := range deletions {
+		if err := revokeAllSessions(au.accountRepo, au.jwtService, deletion.UserID); err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", deletion.UserID, err))
+			continue
+		}
+		if au.config.Policy == domain.DeletionPolicyDelete {
+			err = au.accountRepo.PurgeUser(deletion.UserID)
+		} else {
+			err = au.accountRepo.AnonymizeUser(deletion.UserID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", deletion.UserID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// revokeAllSessions blocks the stored tokens and, where access tokens are
+// checked against the denylist only, denylists them too. Tokens that no
+// longer validate have expired and need nothing further.
+func revokeAllSessions(accountRepo domain.IAccountRepository, jwtService domain.IJWTInfrastructure, userID int64) error {
+	tokens, err := accountRepo.RevokeSessions(userID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Type != "access" {
+			continue
+		}
+		if claims, err := jwtService.ValidateAccessToken("Bearer " + token.Content); err == nil {
+			_ = jwtService.RevokeToken(claims)
+		}
+	}
+	return nil
+}
+
+// buildArchive writes one JSON file per kind of data. Secrets such as the
+// password hash and token contents are left out.
+func buildArchive(data domain.PersonalData) ([]byte, error) {
+	type post struct {
+		ID        int64     `json:"id"`
+		Title     string    `json:"title"`
+		Content   string    `json:"content"`
+		ViewCount int       `json:"view_count"`
+		Likes     int       `json:"likes"`
+		Dislikes  int       `json:"dislikes"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	type comment struct {
+		ID        int64     `json:"id"`
+		BlogID    int64     `json:"blog_id"`
+		Content   string    `json:"content"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	type reaction struct {
+		BlogID    int64     `json:"blog_id"`
+		Kind      string    `json:"kind"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	type session struct {
+		Type      string    `json:"type"`
+		Status    string    `json:"status"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	type follow struct {
+		UserID    int64     `json:"user_id"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	user := data.User
+	files := map[string]interface{}{
+		"profile.json": map[string]interface{}{
+			"id":              user.ID,
+			"username":        user.Username,
+			"email":           user.Email,
+			"role":            user.Role,
+			"bio":             user.Bio,
+			"profile_picture": user.ProfilePicture,
+			"phone":           user.Phone,
+			"locale":          user.Locale,
+			"status":          user.Status,
+			"created_at":      user.CreatedAt,
+			"updated_at":      user.UpdatedAt,
+		},
+		"personal_access_tokens.json": data.PersonalAccessTokens,
+		"linked_identities.json":      data.LinkedIdentities,
+	}
+
+	posts := make([]post, 0, len(data.Posts))
+	for _, p := range data.Posts {
+		posts = append(posts, post{p.ID, p.Title, p.Content, p.ViewCount, p.Likes, p.Dislikes, p.CreatedAt, p.UpdatedAt})
+	}
+	files["posts.json"] = posts
+
+	comments := make([]comment, 0, len(data.Comments))
+	for _, c := range data.Comments {
+		comments = append(comments, comment{c.ID, c.BlogID, c.Content, c.CreatedAt})
+	}
+	files["comments.json"] = comments
+
+	reactions := make([]reaction, 0, len(data.Reactions))
+	for _, r := range data.Reactions {
+		reactions = append(reactions, reaction{r.BlogID, r.Kind, r.CreatedAt})
+	}
+	files["reactions.json"] = reactions
+
+	sessions := make([]session, 0, len(data.Sessions))
+	for _, s := range data.Sessions {
+		sessions = append(sessions, session{s.Type, s.Status, s.CreatedAt})
+	}
+	files["sessions.json"] = sessions
+
+	following := make([]follow, 0, len(data.Following))
+	for _, f := range data.Following {
+		following = append(following, follow{f.FolloweeID, f.CreatedAt})
+	}
+	files["following.json"] = following
+
+	followers := make([]follow, 0, len(data.Followers))
+	for _, f := range data.Followers {
+		followers = append(followers, follow{f.FollowerID, f.CreatedAt})
+	}
+	files["followers.json"] = followers
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"profile.json", "posts.json", "comments.json", "reactions.json", "sessions.json", "personal_access_tokens.json", "linked_identities.json", "following.json", "followers.json"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,313 @@
+package usecases
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	defaultAdminPageLimit = 20
+	maxAdminPageLimit     = 100
+	adminActivityLimit    = 20
+	adminSessionLimit     = 100
+)
+
+type AdminUsecase struct {
+	userRepo    domain.IUserRepository
+	adminRepo   domain.IAdminRepository
+	accountRepo domain.IAccountRepository
+	auditRepo   domain.IAuditRepository
+	tokenRepo   domain.ITokenRepository
+	jwtService  domain.IJWTInfrastructure
+}
+
+func NewAdminUsecase(ur domain.IUserRepository, adr domain.IAdminRepository, acr domain.IAccountRepository, aur domain.IAuditRepository, tr domain.ITokenRepository, js domain.IJWTInfrastructure) *AdminUsecase {
+	return &AdminUsecase{
+		userRepo:    ur,
+		adminRepo:   adr,
+		accountRepo: acr,
+		auditRepo:   aur,
+		tokenRepo:   tr,
+		jwtService:  js,
+	}
+}
+
+func (au *AdminUsecase) ListUsers(filter domain.UserFilter) (domain.AdminUserPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultAdminPageLimit
+	}
+	if filter.Limit > maxAdminPageLimit {
+		filter.Limit = maxAdminPageLimit
+	}
+	if filter.Role != "" && !domain.ValidRole(filter.Role) {
+		return domain.AdminUserPage{}, errors.New("unknown role")
+	}
+	if filter.EmailStatus != "" && filter.EmailStatus != domain.EmailStatusHardBounced && filter.EmailStatus != domain.EmailStatusComplained {
+		return domain.AdminUserPage{}, errors.New("unknown email status")
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	users, total, err := au.adminRepo.SearchUsers(filter)
+	if err != nil {
+		return domain.AdminUserPage{}, errors.New("unable to list users")
+	}
+
+	page := domain.AdminUserPage{Items: make([]domain.AdminUserView, 0, len(users)), Page: filter.Page, Limit: filter.Limit, Total: total}
+	for _, user := range users {
+		page.Items = append(page.Items, domain.AdminUserView{
+			ID:                user.ID,
+			Username:          user.Username,
+			Email:             user.Email,
+			Role:              domain.NormalizeRole(user.Role),
+			Status:            user.Status,
+			SuspensionReason:  user.SuspensionReason,
+			SuspendedUntil:    user.SuspendedUntil,
+			EmailStatus:       user.EmailStatus,
+			EmailStatusReason: user.EmailStatusReason,
+			EmailStatusAt:     user.EmailStatusAt,
+			CreatedAt:         user.CreatedAt,
+			UpdatedAt:         user.UpdatedAt,
+		})
+	}
+	return page, nil
+}
+
+func (au *AdminUsecase) Sessions(id string) ([]domain.SessionView, error) {
+	user, err := au.userRepo.Fetch(id)
+	if err != nil {
+		return nil, domain.ErrResourceNotFound
+	}
+	tokens, err := au.adminRepo.FetchSessions(user.ID, adminSessionLimit)
+	if err != nil {
+		return nil, errors.New("unable to load sessions")
+	}
+
+	sessions := make([]domain.SessionView, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, domain.SessionView{ID: token.ID, Type: token.Type, Status: token.Status, CreatedAt: token.CreatedAt})
+	}
+	return sessions, nil
+}
+
+func (au *AdminUsecase) Activity(id string) (domain.UserActivity, error) {
+	user, err := au.userRepo.Fetch(id)
+	if err != nil {
+		return domain.UserActivity{}, domain.ErrResourceNotFound
+	}
+
+	posts, err := au.adminRepo.RecentPosts(user.ID, adminActivityLimit)
+	if err != nil {
+		return domain.UserActivity{}, errors.New("unable to load activity")
+	}
+	comments, err := au.adminRepo.RecentComments(user.ID, adminActivityLimit)
+	if err != nil {
+		return domain.UserActivity{}, errors.New("unable to load activity")
+	}
+	events, err := au.auditRepo.FetchByUser(user.ID, adminActivityLimit)
+	if err != nil {
+		return domain.UserActivity{}, errors.New("unable to load activity")
+	}
+
+	activity := domain.UserActivity{
+		RecentPosts:    make([]domain.PublicPost, 0, len(posts)),
+		RecentComments: make([]domain.ActivityComment, 0, len(comments)),
+		AuditEvents:    events,
+	}
+	if activity.AuditEvents == nil {
+		activity.AuditEvents = []domain.AuditEvent{}
+	}
+	for _, post := range posts {
+		activity.RecentPosts = append(activity.RecentPosts, domain.PublicPost{ID: post.ID, Title: post.Title, ViewCount: post.ViewCount, Likes: post.Likes, Dislikes: post.Dislikes, CreatedAt: post.CreatedAt})
+	}
+	for _, comment := range comments {
+		activity.RecentComments = append(activity.RecentComments, domain.ActivityComment{ID: comment.ID, BlogID: comment.BlogID, Content: comment.Content, CreatedAt: comment.CreatedAt})
+	}
+	return activity, nil
+}
+
+// Suspend takes the user out of service and signs them out everywhere. A
+// suspension without an end date is a ban. Like every admin action it is
+// written to the audit trail first, so a failed write stops the action.
+func (au *AdminUsecase) Suspend(actor domain.Principal, id string, suspension domain.Suspension) error {
+	suspension.Reason = strings.TrimSpace(suspension.Reason)
+	if suspension.Reason == "" || len(suspension.Reason) > 500 {
+		return errors.New("a reason of at most 500 characters is required")
+	}
+	if suspension.Until != nil && !suspension.Until.After(time.Now()) {
+		return errors.New("expiry must be in the future")
+	}
+
+	user, err := au.target(actor, id)
+	if err != nil {
+		return err
+	}
+
+	status, action := "suspended", "user.suspend"
+	if suspension.Until == nil {
+		status, action = "banned", "user.ban"
+	}
+	if err := au.record(actor, action, user.ID, map[string]interface{}{"reason": suspension.Reason, "until": suspension.Until}); err != nil {
+		return err
+	}
+	if err := au.adminRepo.SetStatus(user.ID, status, suspension); err != nil {
+		return errors.New("unable to suspend user")
+	}
+	if err := revokeAllSessions(au.accountRepo, au.jwtService, user.ID); err != nil {
+		return errors.New("unable to sign out the user")
+	}
+	return nil
+}
+
+func (au *AdminUsecase) Reinstate(actor domain.Principal, id string) error {
+	user, err := au.target(actor, id)
+	if err != nil {
+		return err
+	}
+	if user.Status != "suspended" && user.Status != "banned" {
+		return errors.New("user is not suspended")
+	}
+	if err := au.record(actor, "user.reinstate", user.ID, nil); err != nil {
+		return err
+	}
+	if err := au.adminRepo.SetStatus(user.ID, "active", domain.Suspension{}); err != nil {
+		return errors.New("unable to reinstate user")
+	}
+	return nil
+}
+
+// ForcePasswordReset invalidates the current password and every session, then
+// mails the user a reset link.
+func (au *AdminUsecase) ForcePasswordReset(actor domain.Principal, id string) error {
+	user, err := au.target(actor, id)
+	if err != nil {
+		return err
+	}
+	if err := au.record(actor, "user.force_password_reset", user.ID, nil); err != nil {
+		return err
+	}
+	if err := au.adminRepo.ClearPassword(user.ID); err != nil {
+		return errors.New("unable to reset password")
+	}
+	if err := revokeAllSessions(au.accountRepo, au.jwtService, user.ID); err != nil {
+		return errors.New("unable to sign out the user")
+	}
+	return sendPasswordResetLink(au.jwtService, au.tokenRepo, user)
+}
+
+// Impersonate issues a short-lived access token for the user. The token names
+// the admin in its impersonator_id claim and cannot be refreshed. Admin
+// accounts cannot be impersonated, so impersonation never escalates.
+func (au *AdminUsecase) Impersonate(actor domain.Principal, id string, reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > 500 {
+		return "", errors.New("a reason of at most 500 characters is required")
+	}
+
+	user, err := au.target(actor, id)
+	if err != nil {
+		return "", err
+	}
+	if domain.PrivilegedRole(user.Role) {
+		return "", domain.ErrRoleNotAllowed
+	}
+	if user.Suspended(time.Now()) || user.Status != "active" {
+		return "", errors.New("only active users can be impersonated")
+	}
+
+	// the trail is written before the token exists, so there is never an
+	// impersonation nobody can account for
+	if err := au.record(actor, "user.impersonate", user.ID, map[string]interface{}{"reason": reason}); err != nil {
+		return "", err
+	}
+
+	token, err := au.jwtService.GenerateImpersonationToken(strconv.FormatInt(user.ID, 10), user.Role, actor.UserID)
+	if err != nil {
+		return "", errors.New("unable to impersonate user")
+	}
+	tokenObj := domain.Token{Type: "access", Content: token, Status: "active", UserID: user.ID}
+	if err := au.tokenRepo.Save(&tokenObj); err != nil {
+		return "", errors.New("unable to impersonate user")
+	}
+	return token, nil
+}
+
+// HardDelete removes the user and everything they wrote right away, skipping
+// the grace period of a self-service deletion.
+func (au *AdminUsecase) HardDelete(actor domain.Principal, id string) error {
+	user, err := au.target(actor, id)
+	if err != nil {
+		return err
+	}
+	if domain.NormalizeRole(user.Role) == domain.RoleSuperadmin {
+		return errors.New("demote the superadmin before deleting the account")
+	}
+	// only the id goes into the append-only trail, the deleted user's
+	// personal data must not outlive the account
+	if err := au.record(actor, "user.delete", user.ID, nil); err != nil {
+		return err
+	}
+	if err := revokeAllSessions(au.accountRepo, au.jwtService, user.ID); err != nil {
+		return errors.New("unable to sign out the user")
+	}
+	if err := au.accountRepo.PurgeUser(user.ID); err != nil {
+		return errors.New("unable to delete user")
+	}
+	return nil
+}
+
+// ClearEmailStatus resumes mail to a user whose address bounced or
+// complained, for instance once they confirm the mailbox works again.
+func (au *AdminUsecase) ClearEmailStatus(actor domain.Principal, id string) error {
+	user, err := au.target(actor, id)
+	if err != nil {
+		return err
+	}
+	if user.EmailStatus == "" {
+		return errors.New("email address is not suppressed")
+	}
+	if err := au.record(actor, "user.clear_email_status", user.ID, map[string]interface{}{"email_status": user.EmailStatus}); err != nil {
+		return err
+	}
+	if err := au.adminRepo.ClearEmailStatus(user.ID); err != nil {
+		return errors.New("unable to clear email status")
+	}
+	return nil
+}
+
+func (au *AdminUsecase) LiftExpiredSuspensions() error {
+	_, err := au.adminRepo.LiftExpiredSuspensions(time.Now())
+	return err
+}
+
+// target loads the user an admin action is aimed at. Admins never act on
+// themselves, and only those who may assign admin roles act on admins.
+func (au *AdminUsecase) target(actor domain.Principal, id string) (domain.User, error) {
+	user, err := au.userRepo.Fetch(id)
+	if err != nil {
+		return domain.User{}, domain.ErrResourceNotFound
+	}
+	if strconv.FormatInt(user.ID, 10) == actor.UserID {
+		return domain.User{}, errors.New("admins cannot perform this action on their own account")
+	}
+	if domain.PrivilegedRole(user.Role) && !domain.RoleHasPermission(actor.Role, domain.PermissionRolesAssignAdmin) {
+		return domain.User{}, domain.ErrRoleNotAllowed
+	}
+	return user, nil
+}
+
+func (au *AdminUsecase) record(actor domain.Principal, action string, targetID int64, metadata map[string]interface{}) error {
+	event := newAuditEvent(actor.UserID, action, targetID, domain.AuditOutcomeSuccess, metadata)
+	event.IP = actor.Client.IP
+	event.UserAgent = truncateRunes(actor.Client.UserAgent, 500)
+	if err := au.auditRepo.Record(&event); err != nil {
+		return errors.New("unable to write audit trail")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	maxAIPromptLength    = 2000
+	maxAIOutlineLength   = 10000
+	maxAIParagraphLength = 5000
+	maxAIGoalLength      = 500
+	// how much of the post is sent along to give a paragraph its context
+	aiBlogContextLength = 4000
+)
+
+const (
+	draftInstructions = "You help authors of a blogging platform. Write a complete blog post about the author's request. " +
+		"Put the title on the first line, prefixed with \"# \", then a blank line, then the post in Markdown. " +
+		"Do not add anything before the title or after the post."
+	outlineInstructions = "You help authors of a blogging platform. Expand the author's outline into a complete blog post " +
+		"that keeps the order and the points of the outline. Put the title on the first line, prefixed with \"# \", " +
+		"then a blank line, then the post in Markdown. Do not add anything before the title or after the post."
+	improveInstructions = "You help authors of a blogging platform edit their posts. Rewrite the paragraph the author gives you " +
+		"so it reads better while keeping its meaning, language and the author's voice, and so it fits the post it belongs to. " +
+		"Answer with the rewritten paragraph only."
+)
+
+// AIUsecase is the writing assistant. It drafts posts and edits paragraphs
+// with whichever provider is configured; every request counts against the
+// author's quota since providers bill per call.
+type AIUsecase struct {
+	ai       domain.IAIInfrastructure
+	blogRepo domain.IBlogRepository
+	limiter  domain.IRateLimiter
+}
+
+// NewAIUsecase takes a nil ai when the assistant is turned off.
+func NewAIUsecase(ai domain.IAIInfrastructure, br domain.IBlogRepository, rl domain.IRateLimiter) *AIUsecase {
+	return &AIUsecase{
+		ai:       ai,
+		blogRepo: br,
+		limiter:  rl,
+	}
+}
+
+// GenerateDraft writes a post about prompt.
+func (au *AIUsecase) GenerateDraft(ctx context.Context, userID string, prompt string) (domain.AIDraft, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" || utf8.RuneCountInString(prompt) > maxAIPromptLength {
+		return domain.AIDraft{}, errors.New("a prompt of at most 2000 characters is required")
+	}
+
+	text, err := au.complete(ctx, userID, domain.AIRequest{Instructions: draftInstructions, Input: prompt})
+	if err != nil {
+		return domain.AIDraft{}, err
+	}
+	return parseDraft(text), nil
+}
+
+// ExpandOutline turns an outline, usually a list of headings and points,
+// into a post.
+func (au *AIUsecase) ExpandOutline(ctx context.Context, userID string, outline string) (domain.AIDraft, error) {
+	outline = strings.TrimSpace(outline)
+	if outline == "" || utf8.RuneCountInString(outline) > maxAIOutlineLength {
+		return domain.AIDraft{}, errors.New("an outline of at most 10000 characters is required")
+	}
+
+	text, err := au.complete(ctx, userID, domain.AIRequest{Instructions: outlineInstructions, Input: outline})
+	if err != nil {
+		return domain.AIDraft{}, err
+	}
+	return parseDraft(text), nil
+}
+
+// ImproveParagraph rewrites a paragraph of blog blogID, optionally towards
+// goal, such as "shorter" or "more formal". The post is sent along so the
+// rewrite fits it.
+func (au *AIUsecase) ImproveParagraph(ctx context.Context, userID string, blogID int64, paragraph string, goal string) (string, error) {
+	paragraph = strings.TrimSpace(paragraph)
+	if paragraph == "" || utf8.RuneCountInString(paragraph) > maxAIParagraphLength {
+		return "", errors.New("a paragraph of at most 5000 characters is required")
+	}
+	goal = strings.TrimSpace(goal)
+	if utf8.RuneCountInString(goal) > maxAIGoalLength {
+		return "", errors.New("goal must be at most 500 characters")
+	}
+
+	blog, err := au.blogRepo.FetchByID(ctx, blogID)
+	if err != nil {
+		return "", domain.ErrResourceNotFound
+	}
+
+	var input strings.Builder
+	input.WriteString("Post title: " + blog.Title + "\n\n")
+	input.WriteString("Post:\n" + truncateRunes(blog.Content, aiBlogContextLength) + "\n\n")
+	if goal != "" {
+		input.WriteString("What the author wants: " + goal + "\n\n")
+	}
+	input.WriteString("Paragraph to rewrite:\n" + paragraph)
+	return au.complete(ctx, userID, domain.AIRequest{Instructions: improveInstructions, Input: input.String()})
+}
+
+func (au *AIUsecase) complete(ctx context.Context, userID string, request domain.AIRequest) (string, error) {
+	if au.ai == nil {
+		return "", domain.ErrAIUnavailable
+	}
+	wait, err := au.limiter.Allow(userID)
+	if err != nil {
+		return "", errors.New("unable to reach the writing assistant")
+	}
+	if wait > 0 {
+		return "", &domain.RateLimitedError{RetryAfter: wait}
+	}
+
+	text, err := au.ai.Complete(ctx, request)
+	if err != nil {
+		log.Printf("writing assistant: request for user %s failed: %v", userID, err)
+		return "", domain.ErrAIProviderFailed
+	}
+	if text == "" {
+		return "", domain.ErrAIProviderFailed
+	}
+	return text, nil
+}
+
+// parseDraft splits off the "# Title" line the instructions ask for. A
+// model that did not follow them still yields the whole text as content.
+func parseDraft(text string) domain.AIDraft {
+	text = strings.TrimSpace(text)
+	firstLine, rest, _ := strings.Cut(text, "\n")
+	if title, ok := strings.CutPrefix(strings.TrimSpace(firstLine), "# "); ok {
+		return domain.AIDraft{Title: strings.TrimSpace(title), Content: strings.TrimSpace(rest)}
+	}
+	return domain.AIDraft{Content: text}
+}
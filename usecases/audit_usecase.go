@@ -0,0 +1,107 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	defaultAuditPageLimit = 50
+	maxAuditPageLimit     = 500
+	auditVerifyBatch      = 500
+)
+
+type AuditUsecase struct {
+	auditRepo domain.IAuditRepository
+}
+
+func NewAuditUsecase(ar domain.IAuditRepository) *AuditUsecase {
+	return &AuditUsecase{
+		auditRepo: ar,
+	}
+}
+
+func (au *AuditUsecase) Search(filter domain.AuditFilter) (domain.AuditEventPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultAuditPageLimit
+	}
+	if filter.Limit > maxAuditPageLimit {
+		filter.Limit = maxAuditPageLimit
+	}
+
+	events, total, err := au.auditRepo.Search(filter)
+	if err != nil {
+		return domain.AuditEventPage{}, errors.New("unable to search audit log")
+	}
+	if events == nil {
+		events = []domain.AuditEvent{}
+	}
+	return domain.AuditEventPage{Items: events, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+// Verify walks the whole chain in id order and stops at the first event whose
+// PrevHash does not match its predecessor or whose Hash does not match its
+// content.
+func (au *AuditUsecase) Verify() (domain.AuditVerification, error) {
+	var result domain.AuditVerification
+	var afterID int64
+	prevHash := ""
+	for {
+		events, err := au.auditRepo.FetchAfter(afterID, auditVerifyBatch)
+		if err != nil {
+			return domain.AuditVerification{}, errors.New("unable to read audit log")
+		}
+		for _, event := range events {
+			if event.PrevHash != prevHash || event.ComputeHash() != event.Hash {
+				result.BrokenAt = event.ID
+				return result, nil
+			}
+			prevHash = event.Hash
+			afterID = event.ID
+			result.Checked++
+		}
+		if len(events) < auditVerifyBatch {
+			result.Valid = true
+			return result, nil
+		}
+	}
+}
+
+// newAuditEvent builds an event for IAuditRecorder.Record. actorID is the
+// string form used in claims; anything that does not parse is recorded as 0.
+func newAuditEvent(actorID string, action string, targetID int64, outcome string, metadata map[string]interface{}) domain.AuditEvent {
+	actor, _ := strconv.ParseInt(actorID, 10, 64)
+	encoded := []byte("{}")
+	if metadata != nil {
+		if b, err := json.Marshal(metadata); err == nil {
+			encoded = b
+		}
+	}
+	return domain.AuditEvent{ActorID: actor, Action: action, TargetID: targetID, Outcome: outcome, Metadata: string(encoded)}
+}
+
+// recordAudit stamps event with where the request came from and appends it
+// to al, which may be nil. It is best effort: the action being audited has
+// already happened and is not undone because the log could not be written.
+func recordAudit(al domain.IAuditRecorder, event domain.AuditEvent, client domain.ClientInfo) {
+	if al == nil {
+		return
+	}
+	event.IP = client.IP
+	event.UserAgent = truncateRunes(client.UserAgent, 500)
+	_ = al.Record(&event)
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
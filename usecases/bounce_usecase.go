@@ -0,0 +1,71 @@
+package usecases
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+// BounceUsecase records hard bounces and complaints so the email outbox
+// stops mailing the addresses they came from.
+type BounceUsecase struct {
+	parser             domain.IBounceParser
+	deliverabilityRepo domain.IDeliverabilityRepository
+}
+
+func NewBounceUsecase(bp domain.IBounceParser, dr domain.IDeliverabilityRepository) *BounceUsecase {
+	return &BounceUsecase{
+		parser:             bp,
+		deliverabilityRepo: dr,
+	}
+}
+
+// Ingest parses a report from source and marks every address in it that
+// hard-bounced or complained. Soft bounces are counted but change nothing.
+func (bu *BounceUsecase) Ingest(source string, payload []byte) (domain.BounceReport, error) {
+	events, err := bu.parser.Parse(source, payload)
+	if err != nil {
+		return domain.BounceReport{}, err
+	}
+
+	// a complaint outweighs a bounce for the same address
+	statuses := make(map[string]domain.BounceEvent)
+	var order []string
+	for _, event := range events {
+		address := strings.ToLower(strings.TrimSpace(event.Email))
+		if address == "" || event.Type == domain.BounceSoft {
+			continue
+		}
+		previous, seen := statuses[address]
+		if !seen {
+			order = append(order, address)
+		}
+		if !seen || (previous.Type != domain.BounceComplaint && event.Type == domain.BounceComplaint) {
+			statuses[address] = event
+		}
+	}
+
+	report := domain.BounceReport{Received: len(events)}
+	now := time.Now()
+	for _, address := range order {
+		event := statuses[address]
+		status := domain.EmailStatusHardBounced
+		if event.Type == domain.BounceComplaint {
+			status = domain.EmailStatusComplained
+		}
+		reason := event.Reason
+		if len(reason) > 500 {
+			reason = reason[:500]
+		}
+		marked, err := bu.deliverabilityRepo.MarkAddress(address, status, reason, now)
+		if err != nil {
+			log.Printf("bounces: unable to mark %s as %s: %v", address, status, err)
+			return report, errors.New("unable to record bounces")
+		}
+		report.Suppressed += int(marked)
+	}
+	return report, nil
+}
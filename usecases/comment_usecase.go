@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blog-platform/domain"
+)
+
+const maxCommentLength = 5000
+
+type CommentUsecase struct {
+	userRepo    domain.IUserRepository
+	commentRepo domain.ICommentRepository
+	owners      domain.IOwnershipRepository
+	notifier    domain.INotifier
+}
+
+type CommentUsecaseOption func(*CommentUsecase)
+
+// WithCommentNotifications tells authors when someone comments on their post.
+func WithCommentNotifications(n domain.INotifier) CommentUsecaseOption {
+	return func(cu *CommentUsecase) {
+		cu.notifier = n
+	}
+}
+
+func NewCommentUsecase(ur domain.IUserRepository, cr domain.ICommentRepository, owners domain.IOwnershipRepository, opts ...CommentUsecaseOption) *CommentUsecase {
+	cu := &CommentUsecase{
+		userRepo:    ur,
+		commentRepo: cr,
+		owners:      owners,
+	}
+	for _, opt := range opts {
+		opt(cu)
+	}
+	return cu
+}
+
+// Create adds a comment to a post and returns ErrResourceNotFound when the
+// post does not exist.
+func (cu *CommentUsecase) Create(userID string, blogID string, content string) (domain.Comment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > maxCommentLength {
+		return domain.Comment{}, fmt.Errorf("a comment of at most %d characters is required", maxCommentLength)
+	}
+	user, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return domain.Comment{}, errors.New("invalid id")
+	}
+	blog, err := strconv.ParseInt(blogID, 10, 64)
+	if err != nil {
+		return domain.Comment{}, domain.ErrResourceNotFound
+	}
+	author, err := cu.owners.BlogOwner(context.Background(), blog)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		return domain.Comment{}, err
+	}
+	if err != nil {
+		return domain.Comment{}, errors.New("unable to load post")
+	}
+
+	comment := domain.Comment{UserID: user, BlogID: blog, Content: content}
+	if err := cu.commentRepo.Create(&comment); err != nil {
+		return domain.Comment{}, errors.New("unable to save comment")
+	}
+	cu.notifyComment(comment, author)
+	return comment, nil
+}
+
+// notifyComment only logs failures, the comment has already been saved.
+func (cu *CommentUsecase) notifyComment(comment domain.Comment, author int64) {
+	if cu.notifier == nil {
+		return
+	}
+	user, err := cu.userRepo.Fetch(strconv.FormatInt(comment.UserID, 10))
+	if err != nil {
+		log.Printf("notifications: unable to load commenter %d: %v", comment.UserID, err)
+		return
+	}
+	err = cu.notifier.Notify(domain.NotificationEvent{
+		Type:    domain.NotificationComment,
+		UserID:  author,
+		ActorID: comment.UserID,
+		Title:   "New comment on your post",
+		Message: user.Username + " commented: " + truncateRunes(comment.Content, 200),
+		Link:    postLink(comment.BlogID),
+	})
+	if err != nil {
+		log.Printf("notifications: unable to notify user %d of comment %d: %v", author, comment.ID, err)
+	}
+}
+
+func postLink(blogID int64) string {
+	return fmt.Sprintf("%v://%v:%v/blogs/%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), blogID)
+}
@@ -0,0 +1,291 @@
+package usecases
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	digestBatchSize       = 200
+	digestSummaryLength   = 200
+	maxSubscriptionTags   = 20
+	maxSubscriptionTagLen = 100
+)
+
+// DigestUsecase sends the weekly digest of top posts to account and
+// email-only subscribers and handles the subscribe, confirm and unsubscribe
+// flows around it.
+type DigestUsecase struct {
+	subscriptionRepo domain.ISubscriptionRepository
+	userRepo         domain.IUserRepository
+	emailService     domain.IEmailInfrastructure
+	config           domain.DigestConfig
+}
+
+func NewDigestUsecase(sr domain.ISubscriptionRepository, ur domain.IUserRepository, es domain.IEmailInfrastructure, config domain.DigestConfig) *DigestUsecase {
+	return &DigestUsecase{
+		subscriptionRepo: sr,
+		userRepo:         ur,
+		emailService:     es,
+		config:           config,
+	}
+}
+
+// Subscription returns an unsubscribed, tagless subscription for users who
+// never subscribed.
+func (du *DigestUsecase) Subscription(userID string) (domain.Subscription, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return domain.Subscription{}, errors.New("invalid id")
+	}
+	subscription, err := du.subscriptionRepo.FetchByUserID(id)
+	if err != nil {
+		return domain.Subscription{UserID: &id, Tags: []string{}, Status: domain.SubscriptionStatusUnsubscribed}, nil
+	}
+	return subscription, nil
+}
+
+// Subscribe needs no confirmation, the account's address is verified.
+func (du *DigestUsecase) Subscribe(userID string, tags []string) (domain.Subscription, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return domain.Subscription{}, errors.New("invalid id")
+	}
+	if tags, err = normalizeTags(tags); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	subscription, err := du.subscriptionRepo.FetchByUserID(id)
+	if err != nil {
+		subscription = domain.Subscription{UserID: &id}
+	}
+	now := time.Now()
+	subscription.Tags = tags
+	subscription.Status = domain.SubscriptionStatusActive
+	subscription.ConfirmedAt = &now
+	if err := du.subscriptionRepo.Save(&subscription); err != nil {
+		return domain.Subscription{}, errors.New("unable to subscribe")
+	}
+	return subscription, nil
+}
+
+func (du *DigestUsecase) Unsubscribe(userID string) error {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return errors.New("invalid id")
+	}
+	subscription, err := du.subscriptionRepo.FetchByUserID(id)
+	if err != nil {
+		return nil
+	}
+	subscription.Status = domain.SubscriptionStatusUnsubscribed
+	if err := du.subscriptionRepo.Save(&subscription); err != nil {
+		return errors.New("unable to unsubscribe")
+	}
+	return nil
+}
+
+// SubscribeEmail sends a confirmation link, the subscription only starts
+// once it is followed. It answers the same whether or not the address is
+// already subscribed, and does not mail an address again while an earlier
+// link still works.
+func (du *DigestUsecase) SubscribeEmail(email string, tags []string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" {
+		return errors.New("invalid email address")
+	}
+	email = strings.ToLower(address.Address)
+	if tags, err = normalizeTags(tags); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	subscription, err := du.subscriptionRepo.FetchByEmail(email)
+	if err != nil {
+		subscription = domain.Subscription{Email: &email, Status: domain.SubscriptionStatusPending}
+	}
+	switch {
+	case subscription.Status == domain.SubscriptionStatusActive:
+		return nil
+	case subscription.Status == domain.SubscriptionStatusPending && subscription.ConfirmExpiresAt != nil && subscription.ConfirmExpiresAt.After(now):
+		return nil
+	}
+
+	token, err := randomURLToken(32)
+	if err != nil {
+		return errors.New("unable to subscribe")
+	}
+	tokenHash := hashSecret(token)
+	expiresAt := now.Add(du.config.ConfirmTTL)
+	subscription.Tags = tags
+	subscription.Status = domain.SubscriptionStatusPending
+	subscription.ConfirmTokenHash = &tokenHash
+	subscription.ConfirmExpiresAt = &expiresAt
+	if err := du.subscriptionRepo.Save(&subscription); err != nil {
+		return errors.New("unable to subscribe")
+	}
+
+	link := fmt.Sprintf("%v://%v:%v/newsletter/confirm?token=%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), url.QueryEscape(token))
+	err = du.emailService.SendTemplate(domain.EmailMessage{
+		To:       []string{email},
+		Template: domain.EmailNewsletterConfirm,
+		Data:     map[string]interface{}{"Link": link},
+	})
+	if err != nil {
+		return errors.New("unable to send confirmation email")
+	}
+	return nil
+}
+
+func (du *DigestUsecase) Confirm(token string) error {
+	err := du.subscriptionRepo.Confirm(hashSecret(token), time.Now())
+	if errors.Is(err, domain.ErrSubscriptionTokenInvalid) {
+		return err
+	}
+	if err != nil {
+		return errors.New("unable to confirm subscription")
+	}
+	return nil
+}
+
+func (du *DigestUsecase) UnsubscribeByToken(token string) error {
+	err := du.subscriptionRepo.Unsubscribe(hashSecret(token))
+	if errors.Is(err, domain.ErrSubscriptionTokenInvalid) {
+		return err
+	}
+	if err != nil {
+		return errors.New("unable to unsubscribe")
+	}
+	return nil
+}
+
+// SendDigests mails every subscriber whose last digest is at least a period
+// old. Subscribers without new posts are skipped until the next period, and
+// ones whose email fails are tried again on the next run.
+func (du *DigestUsecase) SendDigests() error {
+	now := time.Now()
+	since := now.Add(-du.config.Period)
+	var afterID int64
+	for {
+		subscriptions, err := du.subscriptionRepo.FetchDue(since, afterID, digestBatchSize)
+		if err != nil {
+			return errors.New("unable to load digest subscribers")
+		}
+		for _, subscription := range subscriptions {
+			if err := du.sendDigest(subscription, since, now); err != nil {
+				log.Printf("digest: unable to send digest for subscription %d: %v", subscription.ID, err)
+			}
+		}
+		if len(subscriptions) < digestBatchSize {
+			return nil
+		}
+		afterID = subscriptions[len(subscriptions)-1].ID
+	}
+}
+
+func (du *DigestUsecase) sendDigest(subscription domain.Subscription, since time.Time, now time.Time) error {
+	token := du.unsubscribeToken(subscription.ID)
+	var to, locale string
+	if subscription.UserID != nil {
+		user, err := du.userRepo.Fetch(strconv.FormatInt(*subscription.UserID, 10))
+		if err != nil || user.Status != "active" {
+			return du.subscriptionRepo.MarkSent(subscription.ID, now, hashSecret(token))
+		}
+		to, locale = user.Email, user.Locale
+	} else if subscription.Email != nil {
+		to = *subscription.Email
+	}
+
+	posts, err := du.subscriptionRepo.TopPosts(subscription.UserID, subscription.Tags, since, du.config.MaxPosts)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 || to == "" {
+		return du.subscriptionRepo.MarkSent(subscription.ID, now, hashSecret(token))
+	}
+
+	base := fmt.Sprintf("%v://%v:%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"))
+	items := make([]map[string]interface{}, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, map[string]interface{}{
+			"Title":   post.Title,
+			"Summary": digestSummary(post),
+			"Link":    fmt.Sprintf("%v/blogs/%v", base, post.ID),
+		})
+	}
+	unsubscribe := fmt.Sprintf("%v/newsletter/unsubscribe?token=%v", base, url.QueryEscape(token))
+	err = du.emailService.SendTemplate(domain.EmailMessage{
+		To:       []string{to},
+		Template: domain.EmailDigest,
+		Locale:   locale,
+		Data: map[string]interface{}{
+			"Title":           "Your weekly digest",
+			"Intro":           "Here are the top posts on Blog Platform this week.",
+			"Items":           items,
+			"UnsubscribeLink": unsubscribe,
+		},
+		// RFC 8058 one-click unsubscribe
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return du.subscriptionRepo.MarkSent(subscription.ID, now, hashSecret(token))
+}
+
+// unsubscribeToken is the same for every digest of a subscription, so the
+// link in an older digest keeps working. It is never stored, only its hash.
+func (du *DigestUsecase) unsubscribeToken(subscriptionID int64) string {
+	mac := hmac.New(sha256.New, du.config.UnsubscribeSecret)
+	fmt.Fprintf(mac, "unsubscribe:%d", subscriptionID)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func digestSummary(post domain.DigestPost) string {
+	summary := strings.Join(strings.Fields(post.Content), " ")
+	if utf8.RuneCountInString(summary) > digestSummaryLength {
+		summary = string([]rune(summary)[:digestSummaryLength]) + "…"
+	}
+	if post.Author != "" {
+		summary = "by " + post.Author + " — " + summary
+	}
+	return summary
+}
+
+// normalizeTags trims and dedupes tag names. Tags that do not exist yet are
+// kept, they start matching once someone uses them.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxSubscriptionTagLen {
+			return nil, errors.New("tag names are at most 100 characters")
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxSubscriptionTags {
+		return nil, errors.New("at most 20 tags can be followed")
+	}
+	return normalized, nil
+}
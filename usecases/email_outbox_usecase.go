@@ -0,0 +1,226 @@
+package usecases
+
+import (
+	"errors"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	defaultOutboxPageLimit = 50
+	maxOutboxPageLimit     = 500
+	maxOutboxErrorLength   = 1000
+)
+
+// EmailOutboxUsecase delivers queued emails in the background. It is also an
+// IEmailInfrastructure that queues instead of sending, so the other usecases
+// never wait on, or fail because of, the mail server.
+type EmailOutboxUsecase struct {
+	outboxRepo domain.IEmailOutboxRepository
+	mailer     domain.IEmailInfrastructure
+	config     domain.EmailOutboxConfig
+	suppressed domain.IEmailSuppressionList
+}
+
+type EmailOutboxOption func(*EmailOutboxUsecase)
+
+// WithSuppressionList drops recipients whose address bounced or complained
+// before an email goes out.
+func WithSuppressionList(sl domain.IEmailSuppressionList) EmailOutboxOption {
+	return func(eu *EmailOutboxUsecase) {
+		eu.suppressed = sl
+	}
+}
+
+func NewEmailOutboxUsecase(or domain.IEmailOutboxRepository, mailer domain.IEmailInfrastructure, config domain.EmailOutboxConfig, opts ...EmailOutboxOption) *EmailOutboxUsecase {
+	eu := &EmailOutboxUsecase{
+		outboxRepo: or,
+		mailer:     mailer,
+		config:     config,
+	}
+	for _, opt := range opts {
+		opt(eu)
+	}
+	return eu
+}
+
+func (eu *EmailOutboxUsecase) SendEmail(to []string, subject string, body string) error {
+	email := domain.NewOutboxEmail(domain.EmailMessage{To: to})
+	email.Subject = subject
+	email.Body = body
+	return eu.outboxRepo.Enqueue(&email)
+}
+
+func (eu *EmailOutboxUsecase) SendTemplate(message domain.EmailMessage) error {
+	email := domain.NewOutboxEmail(message)
+	return eu.outboxRepo.Enqueue(&email)
+}
+
+// ProcessOutbox delivers due emails with a pool of workers until no full
+// batch is left.
+func (eu *EmailOutboxUsecase) ProcessOutbox() error {
+	workers := max(eu.config.Workers, 1)
+	for {
+		emails, err := eu.outboxRepo.Claim(time.Now(), eu.config.Lease, eu.config.BatchSize)
+		if err != nil {
+			return errors.New("unable to claim queued emails")
+		}
+
+		queue := make(chan domain.OutboxEmail)
+		var wg sync.WaitGroup
+		for i := 0; i < min(workers, len(emails)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for email := range queue {
+					eu.deliver(email)
+				}
+			}()
+		}
+		for _, email := range emails {
+			queue <- email
+		}
+		close(queue)
+		wg.Wait()
+
+		if len(emails) < eu.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (eu *EmailOutboxUsecase) deliver(email domain.OutboxEmail) {
+	to, err := eu.deliverable(email.To)
+	if err == nil && len(to) == 0 {
+		// nobody is left to send it to, now or later
+		if err := eu.outboxRepo.MarkFailed(email.ID, "all recipients are suppressed", time.Now(), true); err != nil {
+			log.Printf("email outbox: unable to record failure of email %d: %v", email.ID, err)
+		}
+		return
+	}
+	if err == nil {
+		email.To = to
+		if email.Template == "" {
+			err = eu.mailer.SendEmail(email.To, email.Subject, email.Body)
+		} else {
+			err = eu.mailer.SendTemplate(email.Message())
+		}
+	}
+
+	if err == nil {
+		if err := eu.outboxRepo.MarkSent(email.ID, time.Now()); err != nil {
+			// The lease runs out and the email goes out a second time,
+			// which beats not sending it at all.
+			log.Printf("email outbox: unable to mark email %d as sent: %v", email.ID, err)
+		}
+		return
+	}
+
+	// A missing template will not turn up by retrying.
+	dead := email.Attempts >= eu.config.MaxAttempts || errors.Is(err, domain.ErrEmailTemplateNotFound)
+	lastError := err.Error()
+	if len(lastError) > maxOutboxErrorLength {
+		lastError = lastError[:maxOutboxErrorLength]
+	}
+	if err := eu.outboxRepo.MarkFailed(email.ID, lastError, time.Now().Add(eu.backoff(email.Attempts)), dead); err != nil {
+		log.Printf("email outbox: unable to record failure of email %d: %v", email.ID, err)
+	}
+}
+
+// deliverable leaves out the suppressed addresses among to.
+func (eu *EmailOutboxUsecase) deliverable(to []string) ([]string, error) {
+	if eu.suppressed == nil {
+		return to, nil
+	}
+	suppressed, err := eu.suppressed.Suppressed(to)
+	if err != nil {
+		return nil, errors.New("unable to check suppressed addresses")
+	}
+	if len(suppressed) == 0 {
+		return to, nil
+	}
+
+	skip := make(map[string]bool, len(suppressed))
+	for _, address := range suppressed {
+		skip[strings.ToLower(address)] = true
+	}
+	deliverable := make([]string, 0, len(to))
+	for _, address := range to {
+		if !skip[strings.ToLower(address)] {
+			deliverable = append(deliverable, address)
+		}
+	}
+	return deliverable, nil
+}
+
+// backoff doubles the delay after every failed attempt and adds up to a
+// tenth on top, so emails that failed together do not all retry together.
+func (eu *EmailOutboxUsecase) backoff(attempts int) time.Duration {
+	delay := eu.config.RetryBase
+	for i := 1; i < attempts && delay < eu.config.RetryMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, eu.config.RetryMax)
+	return delay + time.Duration(rand.Int64N(int64(delay)/10+1))
+}
+
+// PurgeSent removes delivered emails older than the retention period.
+func (eu *EmailOutboxUsecase) PurgeSent() error {
+	if _, err := eu.outboxRepo.PurgeSent(time.Now().Add(-eu.config.Retention)); err != nil {
+		return errors.New("unable to purge sent emails")
+	}
+	return nil
+}
+
+func (eu *EmailOutboxUsecase) Search(filter domain.OutboxFilter) (domain.OutboxEmailPage, error) {
+	switch filter.Status {
+	case "", domain.OutboxStatusPending, domain.OutboxStatusSending, domain.OutboxStatusSent, domain.OutboxStatusDead:
+	default:
+		return domain.OutboxEmailPage{}, errors.New("unknown status")
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultOutboxPageLimit
+	}
+	if filter.Limit > maxOutboxPageLimit {
+		filter.Limit = maxOutboxPageLimit
+	}
+
+	emails, total, err := eu.outboxRepo.Search(filter)
+	if err != nil {
+		return domain.OutboxEmailPage{}, errors.New("unable to search the outbox")
+	}
+	if emails == nil {
+		emails = []domain.OutboxEmail{}
+	}
+	return domain.OutboxEmailPage{Items: emails, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+func (eu *EmailOutboxUsecase) Fetch(id int64) (domain.OutboxEmail, error) {
+	email, err := eu.outboxRepo.Fetch(id)
+	if err != nil {
+		return domain.OutboxEmail{}, domain.ErrResourceNotFound
+	}
+	return email, nil
+}
+
+// Retry queues a failed email again right away.
+func (eu *EmailOutboxUsecase) Retry(id int64) (domain.OutboxEmail, error) {
+	if _, err := eu.Fetch(id); err != nil {
+		return domain.OutboxEmail{}, err
+	}
+	if err := eu.outboxRepo.Retry(id, time.Now()); err != nil {
+		if errors.Is(err, domain.ErrOutboxEmailNotRetryable) {
+			return domain.OutboxEmail{}, err
+		}
+		return domain.OutboxEmail{}, errors.New("unable to retry email")
+	}
+	return eu.Fetch(id)
+}
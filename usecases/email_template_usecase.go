@@ -0,0 +1,29 @@
+package usecases
+
+import (
+	"github.com/blog-platform/domain"
+)
+
+type EmailTemplateUsecase struct {
+	renderer domain.IEmailRenderer
+}
+
+func NewEmailTemplateUsecase(er domain.IEmailRenderer) *EmailTemplateUsecase {
+	return &EmailTemplateUsecase{
+		renderer: er,
+	}
+}
+
+func (eu *EmailTemplateUsecase) List() []string {
+	return eu.renderer.Templates()
+}
+
+// Preview renders a template with its sample data, so admins can check
+// changes and translations without sending anything.
+func (eu *EmailTemplateUsecase) Preview(template string, locale string) (domain.RenderedEmail, error) {
+	data, err := eu.renderer.SampleData(template)
+	if err != nil {
+		return domain.RenderedEmail{}, err
+	}
+	return eu.renderer.Render(template, locale, data)
+}
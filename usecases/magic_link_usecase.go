@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blog-platform/domain"
+)
+
+type MagicLinkUsecase struct {
+	userRepo      domain.IUserRepository
+	emailService  domain.IEmailInfrastructure
+	jwtService    domain.IJWTInfrastructure
+	tokenRepo     domain.ITokenRepository
+	twoFactorRepo domain.ITwoFactorRepository
+	limiter       domain.IRateLimiter
+	auditLog      domain.IAuditRecorder
+}
+
+// NewMagicLinkUsecase accepts a nil tfr, in which case magic links never ask
+// for a second factor, and a nil al, in which case they are not audited.
+func NewMagicLinkUsecase(ur domain.IUserRepository, es domain.IEmailInfrastructure, js domain.IJWTInfrastructure, tr domain.ITokenRepository, tfr domain.ITwoFactorRepository, rl domain.IRateLimiter, al domain.IAuditRecorder) *MagicLinkUsecase {
+	return &MagicLinkUsecase{
+		userRepo:      ur,
+		emailService:  es,
+		jwtService:    js,
+		tokenRepo:     tr,
+		twoFactorRepo: tfr,
+		limiter:       rl,
+		auditLog:      al,
+	}
+}
+
+// RequestLink mails a sign-in link if the address belongs to an account. It
+// succeeds silently for unknown addresses so the endpoint cannot be used to
+// find out who has an account.
+func (mu *MagicLinkUsecase) RequestLink(email string) error {
+	email = strings.TrimSpace(email)
+	if _, err := mail.ParseAddress(email); err != nil {
+		return errors.New("invalid email format")
+	}
+
+	wait, err := mu.limiter.Allow(strings.ToLower(email))
+	if err != nil {
+		return errors.New("unable to send sign-in link")
+	}
+	if wait > 0 {
+		return &domain.RateLimitedError{RetryAfter: wait}
+	}
+
+	user, err := mu.userRepo.FetchByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	token, err := mu.jwtService.GenerateMagicLinkToken(strconv.FormatInt(user.ID, 10), user.Role)
+	if err != nil {
+		return errors.New("unable to send sign-in link")
+	}
+
+	// the link opens a confirmation page instead of signing in directly, so
+	// mail scanners that prefetch links do not burn the token
+	link := fmt.Sprintf("%v://%v:%v/login/magic-link/confirm?token=%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), url.QueryEscape(token))
+	message := domain.EmailMessage{
+		To:       []string{user.Email},
+		Template: domain.EmailMagicLink,
+		Locale:   user.Locale,
+		Data:     map[string]interface{}{"Link": link},
+	}
+	if err := mu.emailService.SendTemplate(message); err != nil {
+		return errors.New("unable to send sign-in link")
+	}
+	return nil
+}
+
+// Verify redeems a magic link token for the same result Login produces. The
+// token is revoked before anything is issued; without a working denylist the
+// link cannot be single-use and is refused.
+func (mu *MagicLinkUsecase) Verify(token string, client domain.ClientInfo) (string, string, error) {
+	claims, err := mu.jwtService.ValidateMagicLinkToken(token)
+	if err != nil {
+		return "", "", mu.loginFailed(0, client, errors.New("invalid or expired link"))
+	}
+
+	user, err := mu.userRepo.Fetch(claims.UserID)
+	if err != nil {
+		return "", "", mu.loginFailed(0, client, errors.New("invalid or expired link"))
+	}
+
+	// consumed before any token is issued, so a link redeemed twice at the
+	// same time signs in only once
+	if err := mu.jwtService.ConsumeToken(claims); errors.Is(err, domain.ErrTokenUsed) {
+		return "", "", mu.loginFailed(user.ID, client, errors.New("invalid or expired link"))
+	} else if err != nil {
+		return "", "", mu.loginFailed(user.ID, client, errors.New("unable to redeem link"))
+	}
+
+	// receiving the link proves the address, just like the activation link
+	if user.Status == "inactive" {
+		if err := mu.userRepo.ActivateAccount(claims.UserID); err != nil {
+			return "", "", errors.New("unable to activate account")
+		}
+		user.Status = "active"
+	}
+
+	accessToken, refreshToken, err := completeLogin(mu.jwtService, mu.tokenRepo, mu.twoFactorRepo, user)
+	recordAudit(mu.auditLog, loginAuditEvent(user, err, map[string]interface{}{"method": "magic_link"}), client)
+	return accessToken, refreshToken, err
+}
+
+// loginFailed records a link that could not be redeemed and returns cause
+// unchanged. targetID is 0 when the link did not name a known user.
+func (mu *MagicLinkUsecase) loginFailed(targetID int64, client domain.ClientInfo, cause error) error {
+	event := newAuditEvent("", "auth.login", targetID, domain.AuditOutcomeFailure, map[string]interface{}{"method": "magic_link", "reason": cause.Error()})
+	recordAudit(mu.auditLog, event, client)
+	return cause
+}
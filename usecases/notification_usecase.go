@@ -0,0 +1,274 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	defaultNotificationPageLimit = 50
+	maxNotificationPageLimit     = 500
+)
+
+// NotificationUsecase stores notifications for the notification center and
+// mails them, right away or in a digest, as each user prefers.
+type NotificationUsecase struct {
+	notificationRepo domain.INotificationRepository
+	userRepo         domain.IUserRepository
+	emailService     domain.IEmailInfrastructure
+}
+
+func NewNotificationUsecase(nr domain.INotificationRepository, ur domain.IUserRepository, es domain.IEmailInfrastructure) *NotificationUsecase {
+	return &NotificationUsecase{
+		notificationRepo: nr,
+		userRepo:         ur,
+		emailService:     es,
+	}
+}
+
+// Notify delivers event on the recipient's channels for its type. Users are
+// not told about their own actions.
+func (nu *NotificationUsecase) Notify(event domain.NotificationEvent) error {
+	if !slices.Contains(domain.NotificationTypes, event.Type) {
+		return errors.New("unknown notification type")
+	}
+	if event.UserID == event.ActorID {
+		return nil
+	}
+
+	preference, err := nu.preference(event.UserID, event.Type)
+	if err != nil {
+		return err
+	}
+
+	if preference.InApp || preference.Email == domain.EmailDeliveryDigest {
+		notification := domain.Notification{
+			UserID:        event.UserID,
+			ActorID:       event.ActorID,
+			Type:          event.Type,
+			Title:         event.Title,
+			Message:       event.Message,
+			Link:          event.Link,
+			InApp:         preference.InApp,
+			DigestPending: preference.Email == domain.EmailDeliveryDigest,
+		}
+		if err := nu.notificationRepo.Create(&notification); err != nil {
+			return errors.New("unable to save notification")
+		}
+	}
+
+	if preference.Email != domain.EmailDeliveryImmediate {
+		return nil
+	}
+	user, err := nu.userRepo.Fetch(strconv.FormatInt(event.UserID, 10))
+	if err != nil || user.Status != "active" {
+		return nil
+	}
+	err = nu.emailService.SendTemplate(domain.EmailMessage{
+		To:       []string{user.Email},
+		Template: domain.EmailNotification,
+		Locale:   user.Locale,
+		Data:     map[string]interface{}{"Title": event.Title, "Message": event.Message, "Link": event.Link},
+	})
+	if err != nil {
+		return errors.New("unable to send notification email")
+	}
+	return nil
+}
+
+func (nu *NotificationUsecase) preference(userID int64, notificationType string) (domain.NotificationPreference, error) {
+	preferences, err := nu.notificationRepo.FetchPreferences(userID)
+	if err != nil {
+		return domain.NotificationPreference{}, errors.New("unable to load notification preferences")
+	}
+	for _, preference := range preferences {
+		if preference.Type == notificationType {
+			return preference, nil
+		}
+	}
+	return domain.DefaultNotificationPreference(userID, notificationType), nil
+}
+
+func (nu *NotificationUsecase) List(userID string, unreadOnly bool, page int, limit int) (domain.NotificationPage, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return domain.NotificationPage{}, errors.New("invalid id")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultNotificationPageLimit
+	}
+	if limit > maxNotificationPageLimit {
+		limit = maxNotificationPageLimit
+	}
+
+	notifications, total, err := nu.notificationRepo.Search(domain.NotificationFilter{UserID: id, UnreadOnly: unreadOnly, Page: page, Limit: limit})
+	if err != nil {
+		return domain.NotificationPage{}, errors.New("unable to load notifications")
+	}
+	unread, err := nu.notificationRepo.CountUnread(id)
+	if err != nil {
+		return domain.NotificationPage{}, errors.New("unable to load notifications")
+	}
+	if notifications == nil {
+		notifications = []domain.Notification{}
+	}
+	return domain.NotificationPage{Items: notifications, Page: page, Limit: limit, Total: total, Unread: unread}, nil
+}
+
+func (nu *NotificationUsecase) MarkRead(userID string, notificationID string) error {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return errors.New("invalid id")
+	}
+	nid, err := strconv.ParseInt(notificationID, 10, 64)
+	if err != nil {
+		return domain.ErrResourceNotFound
+	}
+
+	err = nu.notificationRepo.MarkRead(id, nid, time.Now())
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		return err
+	}
+	if err != nil {
+		return errors.New("unable to mark notification as read")
+	}
+	return nil
+}
+
+// MarkAllRead returns how many notifications were unread.
+func (nu *NotificationUsecase) MarkAllRead(userID string) (int64, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid id")
+	}
+
+	count, err := nu.notificationRepo.MarkAllRead(id, time.Now())
+	if err != nil {
+		return 0, errors.New("unable to mark notifications as read")
+	}
+	return count, nil
+}
+
+// Preferences returns a preference for every notification type, filling in
+// the defaults for types the user never changed.
+func (nu *NotificationUsecase) Preferences(userID string) ([]domain.NotificationPreference, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid id")
+	}
+
+	stored, err := nu.notificationRepo.FetchPreferences(id)
+	if err != nil {
+		return nil, errors.New("unable to load notification preferences")
+	}
+	preferences := make([]domain.NotificationPreference, 0, len(domain.NotificationTypes))
+	for _, notificationType := range domain.NotificationTypes {
+		preference := domain.DefaultNotificationPreference(id, notificationType)
+		for _, s := range stored {
+			if s.Type == notificationType {
+				preference = s
+			}
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences changes the listed types and leaves the others alone.
+func (nu *NotificationUsecase) UpdatePreferences(userID string, preferences []domain.NotificationPreference) ([]domain.NotificationPreference, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid id")
+	}
+
+	seen := map[string]bool{}
+	for i := range preferences {
+		if !slices.Contains(domain.NotificationTypes, preferences[i].Type) {
+			return nil, fmt.Errorf("unknown notification type %q", preferences[i].Type)
+		}
+		if seen[preferences[i].Type] {
+			return nil, fmt.Errorf("notification type %q listed twice", preferences[i].Type)
+		}
+		seen[preferences[i].Type] = true
+		switch preferences[i].Email {
+		case domain.EmailDeliveryOff, domain.EmailDeliveryImmediate, domain.EmailDeliveryDigest:
+		default:
+			return nil, fmt.Errorf("unknown email delivery %q", preferences[i].Email)
+		}
+		preferences[i].ID = 0
+		preferences[i].UserID = id
+	}
+
+	if err := nu.notificationRepo.SavePreferences(preferences); err != nil {
+		return nil, errors.New("unable to save notification preferences")
+	}
+	return nu.Preferences(userID)
+}
+
+// SendDigests mails every user with pending digest notifications one email
+// listing them. Users whose email fails are tried again on the next run.
+func (nu *NotificationUsecase) SendDigests() error {
+	pending, err := nu.notificationRepo.FetchPendingDigests()
+	if err != nil {
+		return errors.New("unable to load pending digests")
+	}
+
+	for start := 0; start < len(pending); {
+		end := start
+		for end < len(pending) && pending[end].UserID == pending[start].UserID {
+			end++
+		}
+		nu.sendDigest(pending[start].UserID, pending[start:end])
+		start = end
+	}
+	return nil
+}
+
+func (nu *NotificationUsecase) sendDigest(userID int64, notifications []domain.Notification) {
+	ids := make([]int64, 0, len(notifications))
+	for _, notification := range notifications {
+		ids = append(ids, notification.ID)
+	}
+
+	user, err := nu.userRepo.Fetch(strconv.FormatInt(userID, 10))
+	if err == nil && user.Status == "active" {
+		center := fmt.Sprintf("%v://%v:%v/notifications", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"))
+		items := make([]map[string]interface{}, 0, len(notifications))
+		for _, notification := range notifications {
+			link := notification.Link
+			if link == "" {
+				link = center
+			}
+			items = append(items, map[string]interface{}{"Title": notification.Title, "Summary": notification.Message, "Link": link})
+		}
+		err = nu.emailService.SendTemplate(domain.EmailMessage{
+			To:       []string{user.Email},
+			Template: domain.EmailDigest,
+			Locale:   user.Locale,
+			Data: map[string]interface{}{
+				"Title": "Your notification digest",
+				"Intro": fmt.Sprintf("You have %d new notifications on Blog Platform.", len(notifications)),
+				"Items": items,
+			},
+		})
+		if err != nil {
+			log.Printf("notifications: unable to send digest to user %d: %v", userID, err)
+			return
+		}
+	}
+
+	// Digests for inactive or missing accounts are dropped, not kept around.
+	if err := nu.notificationRepo.MarkDigested(ids); err != nil {
+		log.Printf("notifications: unable to mark digest for user %d as sent: %v", userID, err)
+	}
+}
@@ -0,0 +1,262 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+const oidcAuthRequestTTL = 10 * time.Minute
+
+var usernameDisallowed = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+type OIDCUsecase struct {
+	userRepo        domain.IUserRepository
+	oidcRepo        domain.IOIDCRepository
+	oidcService     domain.IOIDCInfrastructure
+	passwordService domain.IPasswordInfrastructure
+	jwtService      domain.IJWTInfrastructure
+	tokenRepo       domain.ITokenRepository
+	twoFactorRepo   domain.ITwoFactorRepository
+	auditLog        domain.IAuditRecorder
+}
+
+// NewOIDCUsecase accepts a nil tfr, in which case social logins never ask for
+// a second factor, and a nil al, in which case they are not audited.
+func NewOIDCUsecase(ur domain.IUserRepository, or domain.IOIDCRepository, oi domain.IOIDCInfrastructure, ps domain.IPasswordInfrastructure, js domain.IJWTInfrastructure, tr domain.ITokenRepository, tfr domain.ITwoFactorRepository, al domain.IAuditRecorder) *OIDCUsecase {
+	return &OIDCUsecase{
+		userRepo:        ur,
+		oidcRepo:        or,
+		oidcService:     oi,
+		passwordService: ps,
+		jwtService:      js,
+		tokenRepo:       tr,
+		twoFactorRepo:   tfr,
+		auditLog:        al,
+	}
+}
+
+// Begin starts an authorization code flow and returns the provider URL to
+// redirect the browser to, along with the state the caller must pin to that
+// browser. Nonce and the PKCE verifier stay on the server until the callback
+// arrives.
+func (ou *OIDCUsecase) Begin(provider string) (string, string, error) {
+	state, err := randomURLToken(32)
+	if err != nil {
+		return "", "", errors.New("unable to start login")
+	}
+	nonce, err := randomURLToken(32)
+	if err != nil {
+		return "", "", errors.New("unable to start login")
+	}
+	verifier, err := randomURLToken(32)
+	if err != nil {
+		return "", "", errors.New("unable to start login")
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err := ou.oidcService.AuthCodeURL(provider, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return "", "", err
+	}
+
+	request := domain.OIDCAuthRequest{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcAuthRequestTTL),
+	}
+	if err := ou.oidcRepo.SaveAuthRequest(&request); err != nil {
+		return "", "", errors.New("unable to start login")
+	}
+
+	return authURL, state, nil
+}
+
+// Complete handles the provider callback. The external account is matched by
+// its linked identity first and by verified email second; unknown people get
+// a new, already active account. browserState is the state Begin pinned to
+// the browser; a callback arriving in any other browser is refused.
+func (ou *OIDCUsecase) Complete(provider string, state string, browserState string, code string, client domain.ClientInfo) (string, string, error) {
+	metadata := map[string]interface{}{"method": "oidc", "provider": provider}
+	if state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return "", "", ou.loginFailed(metadata, client, errors.New("invalid or expired state"))
+	}
+
+	request, err := ou.oidcRepo.ConsumeAuthRequest(state)
+	if err != nil || request.Provider != provider || time.Now().After(request.ExpiresAt) {
+		return "", "", ou.loginFailed(metadata, client, errors.New("invalid or expired state"))
+	}
+
+	identity, err := ou.oidcService.Exchange(provider, code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		return "", "", ou.loginFailed(metadata, client, errors.New("unable to verify identity with provider"))
+	}
+
+	user, err := ou.resolveUser(identity)
+	if err != nil {
+		return "", "", ou.loginFailed(metadata, client, err)
+	}
+
+	accessToken, refreshToken, err := completeLogin(ou.jwtService, ou.tokenRepo, ou.twoFactorRepo, user)
+	recordAudit(ou.auditLog, loginAuditEvent(user, err, metadata), client)
+	return accessToken, refreshToken, err
+}
+
+// loginFailed records a callback that did not get as far as a user and
+// returns cause unchanged.
+func (ou *OIDCUsecase) loginFailed(metadata map[string]interface{}, client domain.ClientInfo, cause error) error {
+	metadata["reason"] = cause.Error()
+	recordAudit(ou.auditLog, newAuditEvent("", "auth.login", 0, domain.AuditOutcomeFailure, metadata), client)
+	return cause
+}
+
+func (ou *OIDCUsecase) ListIdentities(userID string) ([]domain.LinkedIdentity, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid id")
+	}
+
+	identities, err := ou.oidcRepo.FetchIdentitiesByUserID(id)
+	if err != nil {
+		return nil, errors.New("unable to fetch linked identities")
+	}
+	return identities, nil
+}
+
+func (ou *OIDCUsecase) Unlink(userID string, identityID string) error {
+	uid, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return errors.New("invalid id")
+	}
+	id, err := strconv.ParseInt(identityID, 10, 64)
+	if err != nil {
+		return errors.New("invalid id")
+	}
+
+	if err := ou.oidcRepo.DeleteIdentity(uid, id); err != nil {
+		return errors.New("linked identity not found")
+	}
+	return nil
+}
+
+func (ou *OIDCUsecase) resolveUser(identity domain.OIDCIdentity) (domain.User, error) {
+	linked, err := ou.oidcRepo.FetchIdentity(identity.Provider, identity.Subject)
+	if err != nil {
+		return domain.User{}, errors.New("unable to look up linked identity")
+	}
+	if linked.ID != 0 {
+		user, err := ou.userRepo.Fetch(strconv.FormatInt(linked.UserID, 10))
+		if err != nil {
+			return domain.User{}, errors.New("user not found")
+		}
+		return user, nil
+	}
+
+	// linking by an unverified address would let anyone who can register that
+	// address at the provider take over the local account
+	if identity.Email == "" || !identity.EmailVerified {
+		return domain.User{}, errors.New("the identity provider did not confirm your email address")
+	}
+
+	user, err := ou.userRepo.FetchByEmail(identity.Email)
+	if err != nil {
+		user, err = ou.registerFromIdentity(identity)
+		if err != nil {
+			return domain.User{}, err
+		}
+	} else if user.Status == "inactive" {
+		if err := ou.userRepo.ActivateAccount(strconv.FormatInt(user.ID, 10)); err != nil {
+			return domain.User{}, errors.New("unable to activate account")
+		}
+		user.Status = "active"
+	}
+
+	link := domain.LinkedIdentity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := ou.oidcRepo.CreateIdentity(&link); err != nil {
+		return domain.User{}, errors.New("unable to link identity")
+	}
+
+	return user, nil
+}
+
+// registerFromIdentity creates an account with a random password nobody
+// knows. The owner can still set one later through the forgot-password flow.
+func (ou *OIDCUsecase) registerFromIdentity(identity domain.OIDCIdentity) (domain.User, error) {
+	username, err := ou.availableUsername(identity)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return domain.User{}, errors.New("unable to register user")
+	}
+	password, err := ou.passwordService.HashPassword(hex.EncodeToString(secret))
+	if err != nil {
+		return domain.User{}, errors.New("unable to register user")
+	}
+
+	user := domain.User{
+		Username: username,
+		Email:    identity.Email,
+		Password: password,
+		Role:     domain.DefaultRole,
+		Status:   "active",
+	}
+	registered, err := ou.userRepo.Register(&user)
+	if err != nil {
+		return domain.User{}, errors.New("unable to register user")
+	}
+	return registered, nil
+}
+
+func (ou *OIDCUsecase) availableUsername(identity domain.OIDCIdentity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	base = strings.Trim(usernameDisallowed.ReplaceAllString(base, ""), ".-")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		if _, err := ou.userRepo.FetchByUsername(candidate); err != nil {
+			return candidate, nil
+		}
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			break
+		}
+		candidate = base + "-" + hex.EncodeToString(suffix)
+	}
+	return "", errors.New("unable to pick a username")
+}
+
+func randomURLToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
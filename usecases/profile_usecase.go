@@ -0,0 +1,237 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	defaultProfilePostLimit = 10
+	maxProfilePostLimit     = 50
+)
+
+type ProfileUsecase struct {
+	userRepo    domain.IUserRepository
+	profileRepo domain.IProfileRepository
+	notifier    domain.INotifier
+	limiter     domain.IRateLimiter
+}
+
+type ProfileUsecaseOption func(*ProfileUsecase)
+
+// WithFollowNotifications tells users when someone starts following them.
+// limiter is keyed by follower and followee, so following and unfollowing in
+// a loop does not flood the followee.
+func WithFollowNotifications(n domain.INotifier, limiter domain.IRateLimiter) ProfileUsecaseOption {
+	return func(pu *ProfileUsecase) {
+		pu.notifier = n
+		pu.limiter = limiter
+	}
+}
+
+func NewProfileUsecase(ur domain.IUserRepository, pr domain.IProfileRepository, opts ...ProfileUsecaseOption) *ProfileUsecase {
+	pu := &ProfileUsecase{
+		userRepo:    ur,
+		profileRepo: pr,
+	}
+	for _, opt := range opts {
+		opt(pu)
+	}
+	return pu
+}
+
+// PublicProfile builds the projection of a user anyone may see. Accounts that
+// are not active look the same as accounts that do not exist.
+func (pu *ProfileUsecase) PublicProfile(username string, page int, limit int) (domain.PublicProfile, error) {
+	user, err := pu.publicUser(username)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+
+	privacy, err := pu.profileRepo.FetchPrivacy(user.ID)
+	if err != nil {
+		return domain.PublicProfile{}, errors.New("unable to load profile")
+	}
+	stats, err := pu.profileRepo.AuthorStats(user.ID)
+	if err != nil {
+		return domain.PublicProfile{}, errors.New("unable to load profile")
+	}
+
+	profile := domain.PublicProfile{Username: user.Username}
+	if !privacy.HideBio {
+		profile.Bio = &user.Bio
+	}
+	if !privacy.HideProfilePicture {
+		profile.ProfilePicture = &user.ProfilePicture
+	}
+	if !privacy.HideJoinDate {
+		profile.JoinedAt = &user.CreatedAt
+	}
+	if !privacy.HidePostCount {
+		profile.PostCount = &stats.PostCount
+	}
+	if !privacy.HideFollowerCount {
+		profile.FollowerCount = &stats.FollowerCount
+	}
+	if !privacy.HideReactions {
+		profile.ReactionsReceived = &stats.ReactionsReceived
+	}
+	if !privacy.HidePosts {
+		posts, err := pu.postPage(user.ID, page, limit)
+		if err != nil {
+			return domain.PublicProfile{}, err
+		}
+		profile.Posts = &posts
+	}
+
+	return profile, nil
+}
+
+func (pu *ProfileUsecase) postPage(userID int64, page int, limit int) (domain.PublicPostPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultProfilePostLimit
+	}
+	if limit > maxProfilePostLimit {
+		limit = maxProfilePostLimit
+	}
+
+	// one extra row tells whether there is a next page
+	blogs, err := pu.profileRepo.FetchPostsByUser(userID, (page-1)*limit, limit+1)
+	if err != nil {
+		return domain.PublicPostPage{}, errors.New("unable to load posts")
+	}
+
+	result := domain.PublicPostPage{Items: []domain.PublicPost{}, Page: page, Limit: limit}
+	if len(blogs) > limit {
+		result.HasMore = true
+		blogs = blogs[:limit]
+	}
+	for _, blog := range blogs {
+		result.Items = append(result.Items, domain.PublicPost{
+			ID:        blog.ID,
+			Title:     blog.Title,
+			ViewCount: blog.ViewCount,
+			Likes:     blog.Likes,
+			Dislikes:  blog.Dislikes,
+			CreatedAt: blog.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+func (pu *ProfileUsecase) Privacy(userID string) (domain.ProfilePrivacy, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return domain.ProfilePrivacy{}, errors.New("invalid id")
+	}
+
+	privacy, err := pu.profileRepo.FetchPrivacy(id)
+	if err != nil {
+		return domain.ProfilePrivacy{}, errors.New("unable to load privacy settings")
+	}
+	return privacy, nil
+}
+
+func (pu *ProfileUsecase) UpdatePrivacy(userID string, privacy domain.ProfilePrivacy) (domain.ProfilePrivacy, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return domain.ProfilePrivacy{}, errors.New("invalid id")
+	}
+
+	privacy.ID = 0
+	privacy.UserID = id
+	if err := pu.profileRepo.SavePrivacy(&privacy); err != nil {
+		return domain.ProfilePrivacy{}, errors.New("unable to save privacy settings")
+	}
+	return privacy, nil
+}
+
+func (pu *ProfileUsecase) Follow(followerID string, username string) error {
+	follower, followee, err := pu.followPair(followerID, username)
+	if err != nil {
+		return err
+	}
+	if follower == followee {
+		return errors.New("you cannot follow yourself")
+	}
+
+	created, err := pu.profileRepo.Follow(follower, followee)
+	if err != nil {
+		return errors.New("unable to follow user")
+	}
+	if created {
+		pu.notifyFollow(follower, followee)
+	}
+	return nil
+}
+
+// notifyFollow only logs failures, the follow itself has already happened.
+func (pu *ProfileUsecase) notifyFollow(follower int64, followee int64) {
+	if pu.notifier == nil {
+		return
+	}
+	wait, err := pu.limiter.Allow(fmt.Sprintf("%d:%d", follower, followee))
+	if err != nil {
+		log.Printf("notifications: unable to check follow notification limit: %v", err)
+		return
+	}
+	if wait > 0 {
+		return
+	}
+	user, err := pu.userRepo.Fetch(strconv.FormatInt(follower, 10))
+	if err != nil {
+		log.Printf("notifications: unable to load follower %d: %v", follower, err)
+		return
+	}
+	err = pu.notifier.Notify(domain.NotificationEvent{
+		Type:    domain.NotificationFollow,
+		UserID:  followee,
+		ActorID: follower,
+		Title:   "You have a new follower",
+		Message: user.Username + " started following you.",
+		Link:    fmt.Sprintf("%v://%v:%v/@%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), user.Username),
+	})
+	if err != nil {
+		log.Printf("notifications: unable to notify user %d of follower %d: %v", followee, follower, err)
+	}
+}
+
+func (pu *ProfileUsecase) Unfollow(followerID string, username string) error {
+	follower, followee, err := pu.followPair(followerID, username)
+	if err != nil {
+		return err
+	}
+
+	if err := pu.profileRepo.Unfollow(follower, followee); err != nil {
+		return errors.New("unable to unfollow user")
+	}
+	return nil
+}
+
+func (pu *ProfileUsecase) followPair(followerID string, username string) (int64, int64, error) {
+	follower, err := strconv.ParseInt(followerID, 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid id")
+	}
+	followee, err := pu.publicUser(username)
+	if err != nil {
+		return 0, 0, err
+	}
+	return follower, followee.ID, nil
+}
+
+func (pu *ProfileUsecase) publicUser(username string) (domain.User, error) {
+	user, err := pu.userRepo.FetchByUsername(username)
+	if err != nil || user.Status != "active" {
+		return domain.User{}, domain.ErrResourceNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/blog-platform/domain"
+)
+
+type ReactionUsecase struct {
+	userRepo     domain.IUserRepository
+	reactionRepo domain.IReactionRepository
+	owners       domain.IOwnershipRepository
+	notifier     domain.INotifier
+	limiter      domain.IRateLimiter
+}
+
+type ReactionUsecaseOption func(*ReactionUsecase)
+
+// WithReactionNotifications tells authors when someone reacts to their post.
+// limiter is keyed by user and post, so reacting and unreacting in a loop
+// does not flood the author.
+func WithReactionNotifications(n domain.INotifier, limiter domain.IRateLimiter) ReactionUsecaseOption {
+	return func(ru *ReactionUsecase) {
+		ru.notifier = n
+		ru.limiter = limiter
+	}
+}
+
+func NewReactionUsecase(ur domain.IUserRepository, rr domain.IReactionRepository, owners domain.IOwnershipRepository, opts ...ReactionUsecaseOption) *ReactionUsecase {
+	ru := &ReactionUsecase{
+		userRepo:     ur,
+		reactionRepo: rr,
+		owners:       owners,
+	}
+	for _, opt := range opts {
+		opt(ru)
+	}
+	return ru
+}
+
+// React sets the user's reaction to a post, replacing any earlier one. Only
+// a first reaction notifies the author, changing its kind does not.
+func (ru *ReactionUsecase) React(userID string, blogID string, kind string) error {
+	if kind != domain.ReactionLike && kind != domain.ReactionDislike {
+		return errors.New("unknown reaction")
+	}
+	user, blog, author, err := ru.reactionTarget(userID, blogID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := ru.reactionRepo.React(user, blog, kind)
+	if err != nil {
+		return errors.New("unable to save reaction")
+	}
+	if previous == "" {
+		ru.notifyReaction(user, blog, author)
+	}
+	return nil
+}
+
+func (ru *ReactionUsecase) Unreact(userID string, blogID string) error {
+	user, blog, _, err := ru.reactionTarget(userID, blogID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ru.reactionRepo.Unreact(user, blog); err != nil {
+		return errors.New("unable to remove reaction")
+	}
+	return nil
+}
+
+// reactionTarget parses both ids and looks up the author of the post. It
+// returns ErrResourceNotFound when the post does not exist.
+func (ru *ReactionUsecase) reactionTarget(userID string, blogID string) (int64, int64, int64, error) {
+	user, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return 0, 0, 0, errors.New("invalid id")
+	}
+	blog, err := strconv.ParseInt(blogID, 10, 64)
+	if err != nil {
+		return 0, 0, 0, domain.ErrResourceNotFound
+	}
+	author, err := ru.owners.BlogOwner(context.Background(), blog)
+	if errors.Is(err, domain.ErrResourceNotFound) {
+		return 0, 0, 0, err
+	}
+	if err != nil {
+		return 0, 0, 0, errors.New("unable to load post")
+	}
+	return user, blog, author, nil
+}
+
+// notifyReaction only logs failures, the reaction has already been saved.
+func (ru *ReactionUsecase) notifyReaction(user int64, blog int64, author int64) {
+	if ru.notifier == nil {
+		return
+	}
+	wait, err := ru.limiter.Allow(fmt.Sprintf("%d:%d", user, blog))
+	if err != nil {
+		log.Printf("notifications: unable to check reaction notification limit: %v", err)
+		return
+	}
+	if wait > 0 {
+		return
+	}
+	actor, err := ru.userRepo.Fetch(strconv.FormatInt(user, 10))
+	if err != nil {
+		log.Printf("notifications: unable to load user %d: %v", user, err)
+		return
+	}
+	err = ru.notifier.Notify(domain.NotificationEvent{
+		Type:    domain.NotificationReaction,
+		UserID:  author,
+		ActorID: user,
+		Title:   "New reaction to your post",
+		Message: actor.Username + " reacted to your post.",
+		Link:    postLink(blog),
+	})
+	if err != nil {
+		log.Printf("notifications: unable to notify user %d of a reaction to post %d: %v", author, blog, err)
+	}
+}
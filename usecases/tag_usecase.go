@@ -0,0 +1,240 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blog-platform/domain"
+)
+
+const (
+	defaultTagSuggestions = 5
+	maxTagSuggestions     = 10
+	maxTagNameLength      = 100
+	// only the start of a long draft is analyzed
+	tagAnalysisLength = 20000
+	tagKeywordLimit   = 20
+	tagMatchLimit     = 50
+)
+
+// listMarker matches bullets, numbering and hashes models put before tags
+// despite being asked not to.
+var listMarker = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])?\s*#?`)
+
+const tagInstructions = "You tag posts on a blogging platform. Suggest up to 10 short, general tags for the author's post, " +
+	"most relevant first. Answer with the tags only, in lowercase, separated by commas."
+
+// TagUsecase suggests tags for drafts. Keywords of the draft, and the
+// writing assistant's ideas when it is enabled, are matched against the
+// tags already in use, and those outrank tags nobody has used yet.
+type TagUsecase struct {
+	tagRepo  domain.ITagRepository
+	keywords domain.IKeywordExtractor
+	ai       domain.IAIInfrastructure
+	limiter  domain.IRateLimiter
+}
+
+type TagUsecaseOption func(*TagUsecase)
+
+// WithAITagSuggestions asks the writing assistant for tags too, unless ai
+// is nil because it is turned off. Calls count against the same quota as
+// the other assistant features; an author over it still gets keyword based
+// suggestions.
+func WithAITagSuggestions(ai domain.IAIInfrastructure, rl domain.IRateLimiter) TagUsecaseOption {
+	return func(tu *TagUsecase) {
+		tu.ai = ai
+		tu.limiter = rl
+	}
+}
+
+func NewTagUsecase(tr domain.ITagRepository, ke domain.IKeywordExtractor, opts ...TagUsecaseOption) *TagUsecase {
+	tu := &TagUsecase{
+		tagRepo:  tr,
+		keywords: ke,
+	}
+	for _, opt := range opts {
+		opt(tu)
+	}
+	return tu
+}
+
+type tagCandidate struct {
+	name    string
+	keyword float64
+	ai      float64
+	tag     *domain.TagUsage
+	inText  bool
+}
+
+// SuggestTags ranks up to limit tags for a draft. Each is scored from 0 to
+// 1; existing tags score above 0.5 and new ones at most 0.5.
+func (tu *TagUsecase) SuggestTags(ctx context.Context, userID string, title string, content string, limit int) ([]domain.TagSuggestion, error) {
+	title = strings.TrimSpace(title)
+	content = strings.TrimSpace(content)
+	if title == "" && content == "" {
+		return nil, errors.New("a title or content is required")
+	}
+	if utf8.RuneCountInString(title) > 500 {
+		return nil, errors.New("title must be at most 500 characters")
+	}
+	if limit < 1 {
+		limit = defaultTagSuggestions
+	}
+	limit = min(limit, maxTagSuggestions)
+	content = truncateRunes(content, tagAnalysisLength)
+
+	candidates := make(map[string]*tagCandidate)
+	candidate := func(name string) *tagCandidate {
+		key := tagKey(name)
+		if key == "" || utf8.RuneCountInString(name) > maxTagNameLength {
+			return nil
+		}
+		if c, ok := candidates[key]; ok {
+			return c
+		}
+		c := &tagCandidate{name: strings.ToLower(strings.TrimSpace(name))}
+		candidates[key] = c
+		return c
+	}
+
+	for _, keyword := range tu.keywords.Keywords(title, content, tagKeywordLimit) {
+		if c := candidate(keyword.Term); c != nil {
+			c.keyword = max(c.keyword, keyword.Score)
+		}
+	}
+	aiNames := tu.aiTags(ctx, userID, title, content)
+	for i, name := range aiNames {
+		if c := candidate(name); c != nil {
+			c.ai = max(c.ai, 1-float64(i)/float64(len(aiNames)))
+		}
+	}
+
+	names := make([]string, 0, len(candidates))
+	for key := range candidates {
+		names = append(names, key)
+	}
+	// looked up apart, so popular tags in the text cannot crowd out the
+	// ones named outright
+	tags, err := tu.tagRepo.FetchByNames(ctx, names)
+	if err != nil {
+		return nil, errors.New("unable to suggest tags")
+	}
+	text := tagKey(title + "\n" + content)
+	inText, err := tu.tagRepo.MatchTags(ctx, text, tagMatchLimit)
+	if err != nil {
+		return nil, errors.New("unable to suggest tags")
+	}
+	tags = append(tags, inText...)
+
+	words := " " + text + " "
+	var mostPosts int64
+	for i := range tags {
+		key := tagKey(tags[i].Name)
+		inText := key != "" && strings.Contains(words, " "+key+" ")
+		c, named := candidates[key]
+		if !named && !inText {
+			continue // the database splits words a little differently
+		}
+		if !named {
+			c = candidate(tags[i].Name)
+		}
+		if c == nil || c.tag != nil {
+			continue
+		}
+		c.tag = &tags[i]
+		c.inText = inText
+		mostPosts = max(mostPosts, tags[i].Posts)
+	}
+
+	suggestions := make([]domain.TagSuggestion, 0, len(candidates))
+	for _, c := range candidates {
+		suggestions = append(suggestions, c.suggestion(tu.ai != nil && len(aiNames) > 0, mostPosts))
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// suggestion blends how relevant the candidate looks with whether it is a
+// tag already, and how popular. Relevance leans on the assistant when it
+// answered, since it knows topics a word count cannot see.
+func (c *tagCandidate) suggestion(withAI bool, mostPosts int64) domain.TagSuggestion {
+	relevance := c.keyword
+	if withAI {
+		relevance = 0.6*c.ai + 0.4*c.keyword
+	}
+	if c.tag == nil {
+		return domain.TagSuggestion{Name: c.name, Score: roundScore(0.5 * relevance)}
+	}
+
+	if c.inText {
+		relevance = max(relevance, 0.3)
+	}
+	popularity := 0.0
+	if mostPosts > 0 {
+		popularity = math.Log1p(float64(c.tag.Posts)) / math.Log1p(float64(mostPosts))
+	}
+	return domain.TagSuggestion{
+		Name:     c.tag.Name,
+		Existing: true,
+		Posts:    c.tag.Posts,
+		Score:    roundScore(0.5 + 0.4*relevance + 0.1*popularity),
+	}
+}
+
+// aiTags asks the writing assistant for tags. Suggestions still work
+// without it, so every failure is only logged.
+func (tu *TagUsecase) aiTags(ctx context.Context, userID string, title string, content string) []string {
+	if tu.ai == nil {
+		return nil
+	}
+	wait, err := tu.limiter.Allow(userID)
+	if err != nil || wait > 0 {
+		return nil
+	}
+
+	text, err := tu.ai.Complete(ctx, domain.AIRequest{
+		Instructions: tagInstructions,
+		Input:        "Title: " + title + "\n\n" + truncateRunes(content, aiBlogContextLength),
+		MaxTokens:    100,
+	})
+	if err != nil {
+		log.Printf("tag suggestions: writing assistant failed for user %s: %v", userID, err)
+		return nil
+	}
+
+	var names []string
+	for _, name := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' }) {
+		name = strings.TrimSpace(listMarker.ReplaceAllString(name, ""))
+		if name != "" && len(names) < maxTagSuggestions {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// tagKey is what two spellings of a tag have in common: lowercase words
+// separated by single spaces, so "Machine-Learning" matches "machine learning".
+func tagKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	}), " ")
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
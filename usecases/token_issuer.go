@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+// completeLogin is called once the user proved who they are with their first
+// factor. Accounts with two-factor authentication enabled get a challenge
+// instead of tokens; twoFactorRepo may be nil when the feature is not wired.
+// Suspended and banned users are turned away here, whichever way they signed
+// in.
+func completeLogin(jwtService domain.IJWTInfrastructure, tokenRepo domain.ITokenRepository, twoFactorRepo domain.ITwoFactorRepository, user domain.User) (string, string, error) {
+	if user.Suspended(time.Now()) {
+		return "", "", domain.ErrAccountSuspended
+	}
+
+	if twoFactorRepo != nil {
+		twoFactor, err := twoFactorRepo.FetchByUserID(user.ID)
+		if err != nil {
+			return "", "", errors.New("unable to check two-factor status")
+		}
+		if twoFactor.Enabled {
+			challenge, err := jwtService.GenerateChallengeToken(strconv.FormatInt(user.ID, 10), user.Role)
+			if err != nil {
+				return "", "", errors.New(err.Error())
+			}
+			return "", "", &domain.MFARequiredError{ChallengeToken: challenge}
+		}
+	}
+
+	return issueTokenPair(jwtService, tokenRepo, user)
+}
+
+// loginAuditEvent describes how completeLogin ended for user. A pending
+// second factor counts as success of the first step.
+func loginAuditEvent(user domain.User, err error, metadata map[string]interface{}) domain.AuditEvent {
+	outcome := domain.AuditOutcomeSuccess
+	var mfaErr *domain.MFARequiredError
+	switch {
+	case errors.As(err, &mfaErr):
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadata["second_factor"] = "pending"
+	case errors.Is(err, domain.ErrAccountSuspended):
+		outcome = domain.AuditOutcomeDenied
+	case err != nil:
+		outcome = domain.AuditOutcomeFailure
+	}
+	return newAuditEvent(strconv.FormatInt(user.ID, 10), "auth.login", user.ID, outcome, metadata)
+}
+
+// issueTokenPair generates and persists the access/refresh pair handed out at
+// the end of every successful login flow.
+func issueTokenPair(jwtService domain.IJWTInfrastructure, tokenRepo domain.ITokenRepository, user domain.User) (string, string, error) {
+	accessToken, err := jwtService.GenerateAccessToken(strconv.FormatInt(user.ID, 10), user.Role)
+	if err != nil {
+		return "", "", errors.New(err.Error())
+	}
+
+	refreshToken, err := jwtService.GenerateRefreshToken(strconv.FormatInt(user.ID, 10), user.Role)
+	if err != nil {
+		return "", "", errors.New(err.Error())
+	}
+
+	accessTokenObj := domain.Token{
+		Type:    "access",
+		Content: accessToken,
+		Status:  "active",
+		UserID:  user.ID,
+	}
+	refreshTokenObj := domain.Token{
+		Type:    "refresh",
+		Content: refreshToken,
+		Status:  "active",
+		UserID:  user.ID,
+	}
+
+	err = tokenRepo.Save(&accessTokenObj)
+	if err != nil {
+		return "", "", errors.New(err.Error())
+	}
+
+	err = tokenRepo.Save(&refreshTokenObj)
+	if err != nil {
+		return "", "", errors.New(err.Error())
+	}
+
+	return accessToken, refreshToken, nil
+}
@@ -0,0 +1,266 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/blog-platform/domain"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorUsecase struct {
+	userRepo        domain.IUserRepository
+	twoFactorRepo   domain.ITwoFactorRepository
+	totpService     domain.ITOTPInfrastructure
+	passwordService domain.IPasswordInfrastructure
+	jwtService      domain.IJWTInfrastructure
+	tokenRepo       domain.ITokenRepository
+	loginThrottler  domain.ILoginThrottler
+	auditLog        domain.IAuditRecorder
+}
+
+// NewTwoFactorUsecase accepts a nil lt, in which case second factor attempts
+// are not throttled, and a nil al, in which case they are not audited.
+func NewTwoFactorUsecase(ur domain.IUserRepository, tfr domain.ITwoFactorRepository, ts domain.ITOTPInfrastructure, ps domain.IPasswordInfrastructure, js domain.IJWTInfrastructure, tr domain.ITokenRepository, lt domain.ILoginThrottler, al domain.IAuditRecorder) *TwoFactorUsecase {
+	return &TwoFactorUsecase{
+		userRepo:        ur,
+		twoFactorRepo:   tfr,
+		totpService:     ts,
+		passwordService: ps,
+		jwtService:      js,
+		tokenRepo:       tr,
+		loginThrottler:  lt,
+		auditLog:        al,
+	}
+}
+
+// Enroll creates a fresh, not yet enabled secret and returns it together with
+// the otpauth:// URI to render as a QR code. Enrolling again before confirming
+// replaces the pending secret.
+func (tu *TwoFactorUsecase) Enroll(userID string) (string, string, error) {
+	user, err := tu.userRepo.Fetch(userID)
+	if err != nil {
+		return "", "", errors.New("user not found")
+	}
+
+	twoFactor, err := tu.twoFactorRepo.FetchByUserID(user.ID)
+	if err != nil {
+		return "", "", errors.New("unable to enroll two-factor authentication")
+	}
+	if twoFactor.Enabled {
+		return "", "", errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := tu.totpService.GenerateSecret()
+	if err != nil {
+		return "", "", errors.New("unable to enroll two-factor authentication")
+	}
+
+	twoFactor.UserID = user.ID
+	twoFactor.Secret = secret
+	twoFactor.LastUsedStep = 0
+	if err := tu.twoFactorRepo.Save(&twoFactor); err != nil {
+		return "", "", errors.New("unable to enroll two-factor authentication")
+	}
+
+	return secret, tu.totpService.ProvisioningURI(secret, user.Email), nil
+}
+
+// Confirm enables two-factor authentication once the user proves their
+// authenticator produces valid codes. The recovery codes are only ever
+// returned here; afterwards just their hashes are kept.
+func (tu *TwoFactorUsecase) Confirm(userID string, code string) ([]string, error) {
+	user, err := tu.userRepo.Fetch(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	twoFactor, err := tu.twoFactorRepo.FetchByUserID(user.ID)
+	if err != nil || twoFactor.Secret == "" {
+		return nil, errors.New("two-factor authentication has not been enrolled")
+	}
+	if twoFactor.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, err := tu.totpService.Verify(twoFactor.Secret, code, twoFactor.LastUsedStep)
+	if err != nil {
+		return nil, errors.New("invalid code")
+	}
+
+	codes, hashes, err := tu.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := tu.twoFactorRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, errors.New("unable to store recovery codes")
+	}
+
+	now := time.Now()
+	twoFactor.Enabled = true
+	twoFactor.LastUsedStep = step
+	twoFactor.ConfirmedAt = &now
+	if err := tu.twoFactorRepo.Save(&twoFactor); err != nil {
+		return nil, errors.New("unable to enable two-factor authentication")
+	}
+
+	return codes, nil
+}
+
+func (tu *TwoFactorUsecase) Disable(userID string, code string) error {
+	user, err := tu.userRepo.Fetch(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	twoFactor, err := tu.twoFactorRepo.FetchByUserID(user.ID)
+	if err != nil || !twoFactor.Enabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := tu.verifySecondFactor(&twoFactor, code); err != nil {
+		return err
+	}
+
+	if err := tu.twoFactorRepo.Delete(user.ID); err != nil {
+		return errors.New("unable to disable two-factor authentication")
+	}
+	return nil
+}
+
+// VerifyLogin completes the second step of Login. code may be either a TOTP
+// code or one of the unused recovery codes.
+func (tu *TwoFactorUsecase) VerifyLogin(challengeToken string, code string, client domain.ClientInfo) (string, string, error) {
+	claims, err := tu.jwtService.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		recordAudit(tu.auditLog, newAuditEvent("", "auth.second_factor", 0, domain.AuditOutcomeFailure, map[string]interface{}{"reason": "invalid or expired challenge"}), client)
+		return "", "", errors.New("invalid or expired challenge")
+	}
+
+	user, err := tu.userRepo.Fetch(claims.UserID)
+	if err != nil {
+		return "", "", errors.New("user not found")
+	}
+
+	twoFactor, err := tu.twoFactorRepo.FetchByUserID(user.ID)
+	if err != nil || !twoFactor.Enabled {
+		return "", "", errors.New("two-factor authentication is not enabled")
+	}
+
+	// second factor failures count against the same account budget as
+	// password failures, otherwise a challenge could be brute-forced
+	if tu.loginThrottler != nil {
+		wait, err := tu.loginThrottler.Check(claims.UserID, "")
+		if err != nil {
+			return "", "", errors.New("unable to check failed login attempts")
+		}
+		if wait > 0 {
+			recordAudit(tu.auditLog, newAuditEvent(claims.UserID, "auth.second_factor", user.ID, domain.AuditOutcomeDenied, map[string]interface{}{"reason": "throttled"}), client)
+			return "", "", &domain.LoginThrottledError{RetryAfter: wait}
+		}
+	}
+
+	if err := tu.verifySecondFactor(&twoFactor, code); err != nil {
+		if tu.loginThrottler != nil {
+			_, _ = tu.loginThrottler.RegisterFailure(claims.UserID, "")
+		}
+		recordAudit(tu.auditLog, newAuditEvent(claims.UserID, "auth.second_factor", user.ID, domain.AuditOutcomeFailure, map[string]interface{}{"reason": err.Error()}), client)
+		return "", "", err
+	}
+
+	if tu.loginThrottler != nil {
+		if err := tu.loginThrottler.RegisterSuccess(claims.UserID); err != nil {
+			return "", "", errors.New("unable to reset failed login attempts")
+		}
+	}
+
+	// best effort: the challenge also expires on its own within minutes
+	_ = tu.jwtService.RevokeToken(claims)
+
+	accessToken, refreshToken, err := issueTokenPair(tu.jwtService, tu.tokenRepo, user)
+	outcome := domain.AuditOutcomeSuccess
+	if err != nil {
+		outcome = domain.AuditOutcomeFailure
+	}
+	recordAudit(tu.auditLog, newAuditEvent(claims.UserID, "auth.second_factor", user.ID, outcome, nil), client)
+	return accessToken, refreshToken, err
+}
+
+// Reset is the administrator escape hatch for users who lost both their
+// authenticator and their recovery codes.
+func (tu *TwoFactorUsecase) Reset(actor domain.Principal, userID string) error {
+	user, err := tu.userRepo.Fetch(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := tu.twoFactorRepo.Delete(user.ID); err != nil {
+		recordAudit(tu.auditLog, newAuditEvent(actor.UserID, "user.two_factor_reset", user.ID, domain.AuditOutcomeFailure, nil), actor.Client)
+		return errors.New("unable to reset two-factor authentication")
+	}
+	recordAudit(tu.auditLog, newAuditEvent(actor.UserID, "user.two_factor_reset", user.ID, domain.AuditOutcomeSuccess, nil), actor.Client)
+	return nil
+}
+
+func (tu *TwoFactorUsecase) verifySecondFactor(twoFactor *domain.TwoFactor, code string) error {
+	step, err := tu.totpService.Verify(twoFactor.Secret, code, twoFactor.LastUsedStep)
+	if err == nil {
+		twoFactor.LastUsedStep = step
+		if err := tu.twoFactorRepo.Save(twoFactor); err != nil {
+			return errors.New("unable to verify code")
+		}
+		return nil
+	}
+
+	codes, err := tu.twoFactorRepo.FetchUnusedRecoveryCodes(twoFactor.UserID)
+	if err != nil {
+		return errors.New("unable to verify code")
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	for _, recovery := range codes {
+		if tu.passwordService.ComparePassword([]byte(recovery.CodeHash), []byte(normalized)) == nil {
+			if err := tu.twoFactorRepo.MarkRecoveryCodeUsed(recovery.ID); err != nil {
+				return errors.New("invalid code")
+			}
+			return nil
+		}
+	}
+
+	return errors.New("invalid code")
+}
+
+func (tu *TwoFactorUsecase) generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, errors.New("unable to generate recovery codes")
+		}
+
+		encoded := strings.ToLower(encoding.EncodeToString(raw))
+		code := encoded[:8] + "-" + encoded[8:16]
+
+		hash, err := tu.passwordService.HashPassword(normalizeRecoveryCode(code))
+		if err != nil {
+			return nil, nil, errors.New("unable to generate recovery codes")
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
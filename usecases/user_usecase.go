@@ -4,29 +4,119 @@ import (
 	"errors"
 	"fmt"
 	"net/mail"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/blog-platform/domain"
 )
 
+const (
+	emailChangeTTL = 24 * time.Hour
+	maxBioLength   = 500
+
+	passwordFormatMessage = "password must be consisted of at least one uppercase character, one lowercase character, one punctuation character, one number and be at least of length 8"
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,40}$`)
+	e164Pattern     = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	localePattern   = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+)
+
 type UserUsecase struct {
 	userRepo        domain.IUserRepository
 	emailService    domain.IEmailInfrastructure
 	passwordService domain.IPasswordInfrastructure
 	jwtService      domain.IJWTInfrastructure
 	tokenRepo       domain.ITokenRepository
+	twoFactorRepo   domain.ITwoFactorRepository
+	loginThrottler  domain.ILoginThrottler
+	emailChangeRepo domain.IEmailChangeRepository
+	auditLog        domain.IAuditRecorder
+	passwordPolicy  domain.IPasswordPolicy
+	passwordHistory domain.IPasswordHistoryRepository
+	historyDepth    int
+	accountRepo     domain.IAccountRepository
+}
+
+// UserUsecaseOption wires an optional collaborator into UserUsecase.
+type UserUsecaseOption func(*UserUsecase)
+
+// WithTwoFactor makes Login stop after the password step for accounts that
+// have two-factor authentication enabled.
+func WithTwoFactor(tfr domain.ITwoFactorRepository) UserUsecaseOption {
+	return func(uu *UserUsecase) {
+		uu.twoFactorRepo = tfr
+	}
+}
+
+// WithLoginThrottle enables backoff and temporary lockout after repeated
+// failed logins.
+func WithLoginThrottle(lt domain.ILoginThrottler) UserUsecaseOption {
+	return func(uu *UserUsecase) {
+		uu.loginThrottler = lt
+	}
+}
+
+// WithEmailChanges lets users change their email address by confirming the
+// new one. Without it email changes are refused.
+func WithEmailChanges(ecr domain.IEmailChangeRepository) UserUsecaseOption {
+	return func(uu *UserUsecase) {
+		uu.emailChangeRepo = ecr
+	}
+}
+
+// WithAuditLog records logins, role changes, password resets and token
+// revocations.
+func WithAuditLog(al domain.IAuditRecorder) UserUsecaseOption {
+	return func(uu *UserUsecase) {
+		uu.auditLog = al
+	}
+}
+
+// WithPasswordPolicy replaces the built-in character class check for new
+// passwords. Existing passwords are not rechecked at login.
+func WithPasswordPolicy(pp domain.IPasswordPolicy) UserUsecaseOption {
+	return func(uu *UserUsecase) {
+		uu.passwordPolicy = pp
+	}
+}
+
+// WithPasswordHistory refuses new passwords that match one of the last depth
+// passwords of the account.
+func WithPasswordHistory(phr domain.IPasswordHistoryRepository, depth int) UserUsecaseOption {
+	return func(uu *UserUsecase) {
+		uu.passwordHistory = phr
+		uu.historyDepth = depth
+	}
+}
+
+// WithSessionRevocation signs a user out everywhere after their role
+// changes, so no token keeps carrying the old role.
+func WithSessionRevocation(ar domain.IAccountRepository) UserUsecaseOption {
+	return func(uu *UserUsecase) {
+		uu.accountRepo = ar
+	}
 }
 
-func NewUserUsecase(ur domain.IUserRepository, es domain.IEmailInfrastructure, ps domain.IPasswordInfrastructure, js domain.IJWTInfrastructure, tr domain.ITokenRepository) *UserUsecase {
-	return &UserUsecase{
+func NewUserUsecase(ur domain.IUserRepository, es domain.IEmailInfrastructure, ps domain.IPasswordInfrastructure, js domain.IJWTInfrastructure, tr domain.ITokenRepository, opts ...UserUsecaseOption) *UserUsecase {
+	uu := &UserUsecase{
 		userRepo:        ur,
 		emailService:    es,
 		passwordService: ps,
 		jwtService:      js,
 		tokenRepo:       tr,
 	}
+	for _, opt := range opts {
+		opt(uu)
+	}
+	return uu
 }
 
 func (uu *UserUsecase) Register(user *domain.User) (domain.User, error) {
@@ -39,8 +129,12 @@ func (uu *UserUsecase) Register(user *domain.User) (domain.User, error) {
 		return domain.User{}, errors.New("invalid email format")
 	}
 
-	if !uu.validatePassword(user.Password) {
-		return domain.User{}, errors.New("password must be consisted of at least one uppercase character, one lowercase character, one punctuation character, one number and be at least of length 8")
+	if user.Locale != "" && !localePattern.MatchString(user.Locale) {
+		return domain.User{}, errors.New("invalid locale")
+	}
+
+	if err := uu.checkNewPassword(user.Password, *user); err != nil {
+		return domain.User{}, err
 	}
 
 	_, err = uu.userRepo.FetchByUsername(user.Username)
@@ -54,26 +148,32 @@ func (uu *UserUsecase) Register(user *domain.User) (domain.User, error) {
 	}
 
 	user.Status = "inactive"
+	user.Role = domain.DefaultRole
 	user.Password, err = uu.passwordService.HashPassword(user.Password)
 	if err != nil {
 		return domain.User{}, errors.New(err.Error())
 	}
 
-	registeredUser, err := uu.userRepo.Register(user)
+	// The activation email is queued together with the account, so a mail
+	// server outage delays it instead of failing the registration.
+	registeredUser, err := uu.userRepo.RegisterWithEmail(user, func(registered domain.User) domain.EmailMessage {
+		link := fmt.Sprintf("%v://%v:%v/user/%v/activate", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), registered.ID)
+		return domain.EmailMessage{
+			To:       []string{registered.Email},
+			Template: domain.EmailActivation,
+			Locale:   registered.Locale,
+			Data:     map[string]interface{}{"Username": registered.Username, "Link": link},
+		}
+	})
 	if err != nil {
 		return domain.User{}, errors.New("unable to register user")
 	}
-
-	emailContent := fmt.Sprintf("%v://%v:%v/user/%v/activate", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), registeredUser.ID)
-	err = uu.emailService.SendEmail([]string{registeredUser.Email}, "Activate Account", emailContent)
-	if err != nil {
-		return domain.User{}, errors.New("unable to send activation link")
-	}
+	uu.rememberPassword(registeredUser.ID, user.Password)
 
 	return registeredUser, nil
 }
 
-func (uu *UserUsecase) Login(identifier string, password string) (string, string, error) {
+func (uu *UserUsecase) Login(identifier string, password string, client domain.ClientInfo) (string, string, error) {
 	user, err := uu.userRepo.FetchByUsername(identifier)
 	if err != nil {
 		_, err := mail.ParseAddress(identifier)
@@ -83,74 +183,181 @@ func (uu *UserUsecase) Login(identifier string, password string) (string, string
 
 		user, err = uu.userRepo.FetchByEmail(identifier)
 		if err != nil {
-			return "", "", errors.New("invalid identifier")
+			account := "unknown:" + strings.ToLower(identifier)
+			if err := uu.checkLoginThrottle(account, client.IP); err != nil {
+				return "", "", err
+			}
+			return "", "", uu.loginFailed(account, client, nil, errors.New("invalid identifier"))
 		}
 	}
 
-	if !uu.validatePassword(password) {
-		return "", "", errors.New("invalid password format")
+	account := strconv.FormatInt(user.ID, 10)
+	if err := uu.checkLoginThrottle(account, client.IP); err != nil {
+		return "", "", err
+	}
+
+	if uu.passwordPolicy == nil && !uu.validatePassword(password) {
+		return "", "", uu.loginFailed(account, client, &user, errors.New("invalid password format"))
 	}
 	err = uu.passwordService.ComparePassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", "", errors.New("invalid credentials")
+		return "", "", uu.loginFailed(account, client, &user, errors.New("invalid credentials"))
+	}
+	uu.upgradePasswordHash(user, password)
+
+	if uu.loginThrottler != nil {
+		if err := uu.loginThrottler.RegisterSuccess(account); err != nil {
+			return "", "", errors.New("unable to reset failed login attempts")
+		}
 	}
 
-	accessToken, err := uu.jwtService.GenerateAccessToken(strconv.FormatInt(user.ID, 10), user.Role)
+	accessToken, refreshToken, err := completeLogin(uu.jwtService, uu.tokenRepo, uu.twoFactorRepo, user)
+	uu.audit(loginAuditEvent(user, err, nil), client)
+	return accessToken, refreshToken, err
+}
+
+// upgradePasswordHash rehashes a password whose stored hash uses an older
+// algorithm or weaker parameters. Login is the only time the plain password
+// is at hand. Failing to store the new hash is harmless, the old one stays
+// valid and is upgraded on the next login.
+func (uu *UserUsecase) upgradePasswordHash(user domain.User, password string) {
+	if !uu.passwordService.NeedsRehash(user.Password) {
+		return
+	}
+	hashed, err := uu.passwordService.HashPassword(password)
 	if err != nil {
-		return "", "", errors.New(err.Error())
+		return
+	}
+	_ = uu.userRepo.ResetPassword(strconv.FormatInt(user.ID, 10), hashed)
+}
+
+func (uu *UserUsecase) checkLoginThrottle(account string, clientIP string) error {
+	if uu.loginThrottler == nil {
+		return nil
 	}
 
-	refreshToken, err := uu.jwtService.GenerateRefreshToken(strconv.FormatInt(user.ID, 10), user.Role)
+	wait, err := uu.loginThrottler.Check(account, clientIP)
 	if err != nil {
-		return "", "", errors.New(err.Error())
+		return errors.New("unable to check failed login attempts")
+	}
+	if wait > 0 {
+		return &domain.LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// loginFailed records the failure and returns cause unchanged. The account
+// owner is told by email when the failure caused a lockout.
+func (uu *UserUsecase) loginFailed(account string, client domain.ClientInfo, user *domain.User, cause error) error {
+	event := newAuditEvent("", "auth.login", 0, domain.AuditOutcomeFailure, map[string]interface{}{"reason": cause.Error()})
+	if user != nil {
+		event.TargetID = user.ID
+	}
+	uu.audit(event, client)
+
+	if uu.loginThrottler == nil {
+		return cause
 	}
 
-	accessTokenObj := domain.Token{
-		Type:    "access",
-		Content: accessToken,
-		Status:  "active",
-		UserID:  user.ID,
+	locked, err := uu.loginThrottler.RegisterFailure(account, client.IP)
+	if err == nil && locked && user != nil {
+		link := fmt.Sprintf("%v://%v:%v/forgot-password", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"))
+		// the login already failed, a lost notification must not change that
+		_ = uu.emailService.SendTemplate(domain.EmailMessage{
+			To:       []string{user.Email},
+			Template: domain.EmailAccountLocked,
+			Locale:   user.Locale,
+			Data:     map[string]interface{}{"Link": link},
+		})
 	}
-	refreshTokenObj := domain.Token{
-		Type:    "refresh",
-		Content: refreshToken,
-		Status:  "active",
-		UserID:  user.ID,
+	return cause
+}
+
+func (uu *UserUsecase) UnlockAccount(actor domain.Principal, id string) error {
+	if uu.loginThrottler == nil {
+		return errors.New("login throttling is not configured")
 	}
 
-	err = uu.tokenRepo.Save(&accessTokenObj)
+	user, err := uu.userRepo.Fetch(id)
 	if err != nil {
-		return "", "", errors.New(err.Error())
+		return errors.New("user not found")
 	}
 
-	err = uu.tokenRepo.Save(&refreshTokenObj)
+	if err := uu.loginThrottler.Unlock(id); err != nil {
+		uu.audit(newAuditEvent(actor.UserID, "user.unlock", user.ID, domain.AuditOutcomeFailure, nil), actor.Client)
+		return errors.New("unable to unlock account")
+	}
+	uu.audit(newAuditEvent(actor.UserID, "user.unlock", user.ID, domain.AuditOutcomeSuccess, nil), actor.Client)
+	return nil
+}
+
+// Logout revokes the access token and the refresh token issued with it.
+// Clients that do not send the refresh token are signed out everywhere,
+// since there is no telling which refresh token belongs to this session.
+func (uu *UserUsecase) Logout(authHeader string, refreshToken string, client domain.ClientInfo) error {
+	claims, err := uu.jwtService.ValidateAccessToken(authHeader)
 	if err != nil {
-		return "", "", errors.New(err.Error())
+		return err
+	}
+	var refreshClaims *domain.TokenClaims
+	if refreshToken != "" {
+		refreshClaims, err = uu.jwtService.ValidateRefreshToken("Bearer " + refreshToken)
+		if err != nil || refreshClaims.UserID != claims.UserID {
+			return errors.New("invalid refresh token")
+		}
 	}
 
-	return accessToken, refreshToken, nil
+	if err := uu.jwtService.RevokeToken(claims); err != nil {
+		return errors.New("unable to revoke token")
+	}
+	userID, _ := strconv.ParseInt(claims.UserID, 10, 64)
+	switch {
+	case refreshClaims != nil:
+		if err := uu.jwtService.RevokeToken(refreshClaims); err != nil {
+			return errors.New("unable to revoke token")
+		}
+	case uu.accountRepo != nil:
+		if err := revokeAllSessions(uu.accountRepo, uu.jwtService, userID); err != nil {
+			return errors.New("unable to revoke token")
+		}
+	}
+	uu.audit(newAuditEvent(claims.UserID, "token.revoke", userID, domain.AuditOutcomeSuccess, map[string]interface{}{"jti": claims.ID, "reason": "logout"}), client)
+	return nil
 }
 
+// RefreshToken issues a new pair with the user's current role. The user is
+// read again rather than trusting the role in the refresh token, so a
+// demotion, suspension or ban ends the session at the next refresh.
 func (uu *UserUsecase) RefreshToken(authHeader string) (string, string, error) {
 	claims, err := uu.jwtService.ValidateRefreshToken(authHeader)
 	if err != nil {
 		return "", "", err
 	}
 
-	accessToken, err := uu.jwtService.GenerateAccessToken(claims.UserID, claims.UserRole)
+	user, err := uu.userRepo.Fetch(claims.UserID)
+	if err != nil {
+		return "", "", errors.New("user not found")
+	}
+	if user.Suspended(time.Now()) {
+		return "", "", domain.ErrAccountSuspended
+	}
+	if user.Status == "inactive" {
+		return "", "", errors.New("account is not active")
+	}
+
+	accessToken, err := uu.jwtService.GenerateAccessToken(claims.UserID, user.Role)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err := uu.jwtService.GenerateRefreshToken(claims.UserID, claims.UserRole)
+	refreshToken, err := uu.jwtService.GenerateRefreshToken(claims.UserID, user.Role)
 	if err != nil {
 		return "", "", err
 	}
 
 	// persist new tokens
-	uid, _ := strconv.ParseInt(claims.UserID, 10, 64)
-	accessTokenObj := domain.Token{Type: "access", Content: accessToken, Status: "active", UserID: uid}
-	refreshTokenObj := domain.Token{Type: "refresh", Content: refreshToken, Status: "active", UserID: uid}
+	accessTokenObj := domain.Token{Type: "access", Content: accessToken, Status: "active", UserID: user.ID}
+	refreshTokenObj := domain.Token{Type: "refresh", Content: refreshToken, Status: "active", UserID: user.ID}
 	if err = uu.tokenRepo.Save(&accessTokenObj); err != nil {
 		return "", "", err
 	}
@@ -204,47 +411,227 @@ func (uu *UserUsecase) ActivateAccount(id string) error {
 	return nil
 }
 
-func (uu UserUsecase) GetUserProfile(userID int64) (*domain.User, error) {
+func (uu UserUsecase) GetUserProfile(userID int64) (*domain.UserView, error) {
 	user, err := uu.userRepo.GetUserProfile(userID)
-	if err != nil {
+	if err != nil || user == nil {
 		return nil, err
 	}
-	return user, nil
+	view := domain.NewUserView(*user)
+	return &view, nil
 }
 
-func (uu *UserUsecase) Promote(id string) error {
-	_, err := uu.userRepo.Fetch(id)
+// AssignRole changes the role of a user on behalf of someone holding
+// actorRole. Admins and superadmins can only be created or demoted by an actor
+// with PermissionRolesAssignAdmin. The user is signed out everywhere
+// afterwards and picks up the new role at the next login.
+func (uu *UserUsecase) AssignRole(actor domain.Principal, id string, role string) error {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !domain.ValidRole(role) {
+		return errors.New("unknown role")
+	}
+	if !domain.RoleHasPermission(actor.Role, domain.PermissionRolesAssign) {
+		return domain.ErrRoleNotAllowed
+	}
+
+	user, err := uu.userRepo.Fetch(id)
 	if err != nil {
 		return errors.New("user not found")
 	}
-	return uu.userRepo.Promote(id)
+
+	metadata := map[string]interface{}{"from": domain.NormalizeRole(user.Role), "to": role}
+	if domain.PrivilegedRole(role) || domain.PrivilegedRole(user.Role) {
+		if !domain.RoleHasPermission(actor.Role, domain.PermissionRolesAssignAdmin) {
+			uu.audit(newAuditEvent(actor.UserID, "user.role_change", user.ID, domain.AuditOutcomeDenied, metadata), actor.Client)
+			return domain.ErrRoleNotAllowed
+		}
+	}
+
+	if err := uu.userRepo.ChangeRole(id, role); err != nil {
+		uu.audit(newAuditEvent(actor.UserID, "user.role_change", user.ID, domain.AuditOutcomeFailure, metadata), actor.Client)
+		if errors.Is(err, domain.ErrLastSuperadmin) {
+			return err
+		}
+		return errors.New("unable to change role")
+	}
+	uu.audit(newAuditEvent(actor.UserID, "user.role_change", user.ID, domain.AuditOutcomeSuccess, metadata), actor.Client)
+	if uu.accountRepo != nil {
+		if err := revokeAllSessions(uu.accountRepo, uu.jwtService, user.ID); err != nil {
+			return errors.New("unable to sign out the user")
+		}
+	}
+	return nil
 }
 
-func (uu *UserUsecase) Demote(id string) error {
-	_, err := uu.userRepo.Fetch(id)
+// UpdateUserProfile validates every field before anything is written. A new
+// email address is not stored on the user: the current address is told about
+// the request and the new one gets a link that completes the change.
+func (uu *UserUsecase) UpdateUserProfile(userID int64, update domain.ProfileUpdate) error {
+	user, err := uu.userRepo.Fetch(strconv.FormatInt(userID, 10))
 	if err != nil {
 		return errors.New("user not found")
 	}
 
-	return uu.userRepo.Demote(id)
+	if err := uu.validateProfileUpdate(user, &update); err != nil {
+		return err
+	}
+
+	if update.Email != nil {
+		if err := uu.requestEmailChange(user, *update.Email); err != nil {
+			return err
+		}
+	}
+
+	if err := uu.userRepo.UpdateUserProfile(userID, update); err != nil {
+		return errors.New("unable to update profile")
+	}
+	return nil
+}
+
+// validateProfileUpdate normalizes update in place. Fields that would not
+// change anything are cleared.
+func (uu *UserUsecase) validateProfileUpdate(user domain.User, update *domain.ProfileUpdate) error {
+	if update.Username != nil {
+		username := strings.TrimSpace(*update.Username)
+		if !usernamePattern.MatchString(username) {
+			return &domain.ValidationError{Field: "username", Message: "username must be 3 to 40 letters, digits, dots, dashes or underscores"}
+		}
+		if existing, err := uu.userRepo.FetchByUsername(username); err == nil && existing.ID != user.ID {
+			return &domain.ValidationError{Field: "username", Message: "this username is already in use"}
+		}
+		update.Username = &username
+	}
+
+	if update.Email != nil {
+		email := strings.TrimSpace(*update.Email)
+		if _, err := mail.ParseAddress(email); err != nil {
+			return &domain.ValidationError{Field: "email", Message: "invalid email format"}
+		}
+		if strings.EqualFold(email, user.Email) {
+			update.Email = nil
+		} else {
+			if uu.emailChangeRepo == nil {
+				return &domain.ValidationError{Field: "email", Message: "email address cannot be changed"}
+			}
+			if existing, err := uu.userRepo.FetchByEmail(email); err == nil && existing.ID != user.ID {
+				return &domain.ValidationError{Field: "email", Message: "this email is already in use"}
+			}
+			update.Email = &email
+		}
+	}
+
+	if update.Bio != nil {
+		bio := strings.TrimSpace(*update.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			return &domain.ValidationError{Field: "bio", Message: fmt.Sprintf("bio must be at most %d characters", maxBioLength)}
+		}
+		update.Bio = &bio
+	}
+
+	if update.ProfilePicture != nil {
+		picture := strings.TrimSpace(*update.ProfilePicture)
+		if picture != "" {
+			parsed, err := url.Parse(picture)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" || len(picture) > 500 {
+				return &domain.ValidationError{Field: "profile_picture", Message: "profile picture must be an http or https URL"}
+			}
+		}
+		update.ProfilePicture = &picture
+	}
+
+	if update.Phone != nil {
+		phone := strings.TrimSpace(*update.Phone)
+		if phone != "" && !e164Pattern.MatchString(phone) {
+			return &domain.ValidationError{Field: "phone", Message: "phone number must be in E.164 format, for example +14155550123"}
+		}
+		update.Phone = &phone
+	}
+
+	if update.Locale != nil {
+		locale := strings.TrimSpace(*update.Locale)
+		if locale != "" && !localePattern.MatchString(locale) {
+			return &domain.ValidationError{Field: "locale", Message: "locale must be a language code such as en or pt-BR"}
+		}
+		update.Locale = &locale
+	}
+
+	return nil
 }
 
-func (uu UserUsecase) UpdateUserProfile(userID int64, updates map[string]interface{}) error {
-	return uu.userRepo.UpdateUserProfile(userID, updates)
+func (uu *UserUsecase) requestEmailChange(user domain.User, email string) error {
+	token, err := randomURLToken(32)
+	if err != nil {
+		return errors.New("unable to change email")
+	}
+
+	request := domain.EmailChangeRequest{
+		UserID:    user.ID,
+		NewEmail:  email,
+		TokenHash: hashSecret(token),
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	}
+	if err := uu.emailChangeRepo.Save(&request); err != nil {
+		return errors.New("unable to change email")
+	}
+
+	// the current owner hears about it before the change can take effect
+	notice := domain.EmailMessage{
+		To:       []string{user.Email},
+		Template: domain.EmailChangeNotice,
+		Locale:   user.Locale,
+		Data:     map[string]interface{}{"NewEmail": email},
+	}
+	if err := uu.emailService.SendTemplate(notice); err != nil {
+		return errors.New("unable to send confirmation email")
+	}
+
+	link := fmt.Sprintf("%v://%v:%v/email/confirm?token=%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), url.QueryEscape(token))
+	confirmation := domain.EmailMessage{
+		To:       []string{email},
+		Template: domain.EmailChangeConfirmation,
+		Locale:   user.Locale,
+		Data:     map[string]interface{}{"Link": link},
+	}
+	if err := uu.emailService.SendTemplate(confirmation); err != nil {
+		return errors.New("unable to send confirmation email")
+	}
+	return nil
+}
+
+// ConfirmEmailChange completes a change requested through UpdateUserProfile.
+// The address is checked again because it may have been taken meanwhile.
+func (uu *UserUsecase) ConfirmEmailChange(token string) error {
+	if uu.emailChangeRepo == nil || token == "" {
+		return errors.New("invalid or expired link")
+	}
+
+	request, err := uu.emailChangeRepo.Consume(hashSecret(token))
+	if err != nil || time.Now().After(request.ExpiresAt) {
+		return errors.New("invalid or expired link")
+	}
+
+	if existing, err := uu.userRepo.FetchByEmail(request.NewEmail); err == nil && existing.ID != request.UserID {
+		return errors.New("this email is already in use")
+	}
+
+	if err := uu.userRepo.UpdateEmail(request.UserID, request.NewEmail); err != nil {
+		return errors.New("unable to change email")
+	}
+	return nil
 }
 
-func (uu *UserUsecase) ResetPassword(userID string, oldPassword string, newPassword string) error {
+func (uu *UserUsecase) ResetPassword(userID string, oldPassword string, newPassword string, client domain.ClientInfo) error {
 	user, err := uu.userRepo.Fetch(userID)
 	if err != nil {
 		return errors.New("user not found")
 	}
 
 	if err := uu.passwordService.ComparePassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		uu.audit(newAuditEvent(userID, "user.password_change", user.ID, domain.AuditOutcomeFailure, map[string]interface{}{"reason": "invalid old password"}), client)
 		return errors.New("invalid old password")
 	}
 
-	if !uu.validatePassword(newPassword) {
-		return errors.New("password must be consisted of at least one uppercase character, one lowercase character, one punctuation character, one number and be at least of length 8")
+	if err := uu.checkNewPassword(newPassword, user); err != nil {
+		return err
 	}
 
 	hashed, err := uu.passwordService.HashPassword(newPassword)
@@ -255,10 +642,12 @@ func (uu *UserUsecase) ResetPassword(userID string, oldPassword string, newPassw
 	if err := uu.userRepo.ResetPassword(userID, hashed); err != nil {
 		return errors.New("could not update password")
 	}
+	uu.rememberPassword(user.ID, hashed)
+	uu.audit(newAuditEvent(userID, "user.password_change", user.ID, domain.AuditOutcomeSuccess, nil), client)
 	return nil
 }
 
-func (uu *UserUsecase) ForgotPassword(email string) error {
+func (uu *UserUsecase) ForgotPassword(email string, client domain.ClientInfo) error {
 	if email == "" {
 		return errors.New("email required")
 	}
@@ -266,37 +655,55 @@ func (uu *UserUsecase) ForgotPassword(email string) error {
 	if err != nil {
 		return errors.New("user not found")
 	}
+	if err := sendPasswordResetLink(uu.jwtService, uu.tokenRepo, user); err != nil {
+		return err
+	}
+	uu.audit(newAuditEvent("", "user.password_reset_request", user.ID, domain.AuditOutcomeSuccess, nil), client)
+	return nil
+}
 
-	accessToken, err := uu.jwtService.GenerateAccessToken(strconv.FormatInt(user.ID, 10), user.Role)
+// sendPasswordResetLink queues a link to UpdatePasswordDirect for user in
+// the same transaction that stores the token.
+func sendPasswordResetLink(jwtService domain.IJWTInfrastructure, tokenRepo domain.ITokenRepository, user domain.User) error {
+	accessToken, err := jwtService.GenerateAccessToken(strconv.FormatInt(user.ID, 10), user.Role)
 	if err != nil {
 		return errors.New("could not generate reset token")
 	}
 
 	tokenObj := domain.Token{Type: "access", Content: accessToken, Status: "active", UserID: user.ID}
-	if err := uu.tokenRepo.Save(&tokenObj); err != nil {
-		return errors.New("could not persist reset token")
-	}
-
 	link := fmt.Sprintf("%v://%v:%v/password/%v/update?token=%v", os.Getenv("PROTOCOL"), os.Getenv("DOMAIN"), os.Getenv("PORT"), user.ID, accessToken)
-	if err := uu.emailService.SendEmail([]string{user.Email}, "Reset Password", link); err != nil {
-		return errors.New("could not send reset link")
+	message := domain.EmailMessage{
+		To:       []string{user.Email},
+		Template: domain.EmailPasswordReset,
+		Locale:   user.Locale,
+		Data:     map[string]interface{}{"Username": user.Username, "Link": link},
+	}
+	if err := tokenRepo.SaveWithEmail(&tokenObj, message); err != nil {
+		return errors.New("could not persist reset token")
 	}
 	return nil
 }
-func (uu *UserUsecase) UpdatePasswordDirect(userID string, newPassword string, token string) error {
+func (uu *UserUsecase) UpdatePasswordDirect(userID string, newPassword string, token string, client domain.ClientInfo) error {
 	if token == "" {
 		return errors.New("token required")
 	}
 
+	targetID, _ := strconv.ParseInt(userID, 10, 64)
 	claims, err := uu.jwtService.ValidateAccessToken("Bearer " + token)
 	if err != nil {
+		uu.audit(newAuditEvent("", "user.password_reset", targetID, domain.AuditOutcomeFailure, map[string]interface{}{"reason": "invalid or expired token"}), client)
 		return errors.New("invalid or expired token")
 	}
 	if claims.UserID != userID {
+		uu.audit(newAuditEvent(claims.UserID, "user.password_reset", targetID, domain.AuditOutcomeFailure, map[string]interface{}{"reason": "token does not match user"}), client)
 		return errors.New("token does not match user")
 	}
-	if !uu.validatePassword(newPassword) {
-		return errors.New("password must be consisted of at least one uppercase character, one lowercase character, one punctuation character, one number and be at least of length 8")
+	user, err := uu.userRepo.Fetch(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := uu.checkNewPassword(newPassword, user); err != nil {
+		return err
 	}
 	hashed, err := uu.passwordService.HashPassword(newPassword)
 	if err != nil {
@@ -305,5 +712,84 @@ func (uu *UserUsecase) UpdatePasswordDirect(userID string, newPassword string, t
 	if err := uu.userRepo.ResetPassword(userID, hashed); err != nil {
 		return errors.New("could not update password")
 	}
+	uu.rememberPassword(user.ID, hashed)
+	uu.audit(newAuditEvent(claims.UserID, "user.password_reset", targetID, domain.AuditOutcomeSuccess, nil), client)
+	return nil
+}
+
+// CheckPassword reports how a candidate password fares against the policy so
+// clients can give feedback while the user is typing. Reuse is not checked
+// because the caller is not necessarily signed in.
+func (uu *UserUsecase) CheckPassword(password string, pc domain.PasswordContext) domain.PasswordFeedback {
+	if uu.passwordPolicy != nil {
+		return uu.passwordPolicy.Check(password, pc)
+	}
+	valid := uu.validatePassword(password)
+	feedback := domain.PasswordFeedback{Valid: valid}
+	feedback.Rules = append(feedback.Rules, domain.PasswordRuleResult{Rule: domain.PasswordRuleComposition, Passed: valid})
+	if !valid {
+		feedback.Rules[0].Message = passwordFormatMessage
+	}
+	return feedback
+}
+
+// checkNewPassword applies the password policy, or the built-in character
+// class check without one, plus the reuse check for existing accounts.
+func (uu *UserUsecase) checkNewPassword(password string, user domain.User) error {
+	feedback := domain.PasswordFeedback{Valid: true}
+	if uu.passwordPolicy != nil {
+		feedback = uu.passwordPolicy.Check(password, domain.PasswordContext{Username: user.Username, Email: user.Email})
+	} else if !uu.validatePassword(password) {
+		return errors.New(passwordFormatMessage)
+	}
+
+	if uu.passwordHistory != nil && uu.historyDepth > 0 && user.ID != 0 {
+		reused, err := uu.reusesPassword(user, password)
+		if err != nil {
+			return errors.New("unable to check password history")
+		}
+		result := domain.PasswordRuleResult{Rule: domain.PasswordRuleReuse, Passed: !reused}
+		if reused {
+			result.Message = fmt.Sprintf("password must differ from your last %d passwords", uu.historyDepth)
+		}
+		feedback.Add(result)
+	}
+
+	if !feedback.Valid {
+		return &domain.PasswordPolicyError{Feedback: feedback}
+	}
 	return nil
 }
+
+// reusesPassword compares against the current hash as well, accounts created
+// before the history was kept have no entries yet.
+func (uu *UserUsecase) reusesPassword(user domain.User, password string) (bool, error) {
+	if user.Password != "" && uu.passwordService.ComparePassword([]byte(user.Password), []byte(password)) == nil {
+		return true, nil
+	}
+	entries, err := uu.passwordHistory.Recent(user.ID, uu.historyDepth)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if uu.passwordService.ComparePassword([]byte(entry.Hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rememberPassword is best effort, the password has already been changed and
+// a missing history entry only weakens the reuse check.
+func (uu *UserUsecase) rememberPassword(userID int64, hash string) {
+	if uu.passwordHistory == nil || uu.historyDepth <= 0 {
+		return
+	}
+	_ = uu.passwordHistory.Add(&domain.PasswordHistory{UserID: userID, Hash: hash}, uu.historyDepth)
+}
+
+// audit is best effort: a user's own action is not refused because the audit
+// log could not be written. Admin actions are, see AdminUsecase.
+func (uu *UserUsecase) audit(event domain.AuditEvent, client domain.ClientInfo) {
+	recordAudit(uu.auditLog, event, client)
+}